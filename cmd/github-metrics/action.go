@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/v50/github"
+
+	"handshake/stats/metrics"
+)
+
+// actionInputs sets flags from the inputs of a GitHub Actions step, which
+// the runner passes as INPUT_<NAME> environment variables. Inputs of
+// repeatable flags take one value per line.
+func actionInputs(fs *flag.FlagSet) error {
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+		name := "INPUT_" + strings.ToUpper(f.Name)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			name = strings.ReplaceAll(name, "-", "_")
+			value = os.Getenv(name)
+		}
+		for _, line := range strings.Split(value, "\n") {
+			if line = strings.TrimSpace(line); line == "" {
+				continue
+			}
+			if err = fs.Set(f.Name, line); err != nil {
+				err = fmt.Errorf("%s: %w", name, err)
+				return
+			}
+		}
+	})
+	return err
+}
+
+// reportAction publishes the results of a run in GitHub Actions: the
+// Markdown leaderboard goes to the job summary and, with --comment-issue, to
+// a comment on that issue, and the file paths and leader become step outputs.
+func (o *collectOptions) reportAction(ctx context.Context, results *metrics.Results) error {
+	report := metrics.NewReport(results.Users, results.ViewOptions())
+	var md bytes.Buffer
+	md.WriteString("## GitHub Metrics")
+	if report.Organization != "" {
+		md.WriteString(" for " + report.Organization)
+	}
+	md.WriteString("\n\n")
+	if err := (metrics.MarkdownRenderer{}).Render(ctx, &md, report); err != nil {
+		return err
+	}
+
+	if path := os.Getenv("GITHUB_STEP_SUMMARY"); path != "" {
+		if err := appendFile(path, md.Bytes()); err != nil {
+			return fmt.Errorf("writing job summary: %w", err)
+		}
+	}
+
+	if path := os.Getenv("GITHUB_OUTPUT"); path != "" {
+		var leader string
+		if len(report.Users) > 0 {
+			leader = report.Users[0].User
+		}
+		outputs := fmt.Sprintf("output-file=%s\nresults-file=%s\nusers=%d\nleader=%s\ninactive=%d\ninactive-alert=%t\n",
+			o.outputFile, o.resultsFile, len(report.Users), leader, len(report.Inactive), o.inactiveAlert(results))
+		if err := appendFile(path, []byte(outputs)); err != nil {
+			return fmt.Errorf("writing step outputs: %w", err)
+		}
+	}
+
+	if o.commentIssue == 0 {
+		return nil
+	}
+	owner, repo, ok := strings.Cut(os.Getenv("GITHUB_REPOSITORY"), "/")
+	if !ok {
+		return errors.New("--comment-issue requires GITHUB_REPOSITORY")
+	}
+	client, err := o.client(ctx)
+	if err != nil {
+		return err
+	}
+	body := md.String()
+	_, _, err = client.Issues.CreateComment(ctx, owner, repo, o.commentIssue, &github.IssueComment{Body: &body})
+	if err != nil {
+		return fmt.Errorf("commenting on issue #%d: %w", o.commentIssue, err)
+	}
+	return nil
+}
+
+func appendFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
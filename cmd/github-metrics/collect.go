@@ -0,0 +1,1406 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+
+	"handshake/stats/metrics"
+)
+
+// collectOptions holds the flags of every subcommand that collects metrics.
+type collectOptions struct {
+	provider     string
+	tokens       stringList
+	tokenFile    string
+	appID        int64
+	installID    int64
+	appKeyFile   string
+	coders       coderList
+	repos        repoList
+	teams        teamList
+	metric       string
+	verbose      bool
+	quiet        bool
+	days         int
+	since        string
+	until        string
+	orgs         orgList // --organization
+	delay        int
+	maxRetries   int
+	backoffBase  time.Duration
+	backoffMax   time.Duration
+	errorMode    string
+	statusFile   string
+	configFile   string
+	outputFile   string
+	concurrency  int
+	commitConc   int // --commit-concurrency
+	hocSource    string
+	squash       bool // --squash-attribution
+	codeOwners   bool // --codeowners
+	security     bool
+	tone         bool
+	project      string
+	milestone    string
+	plan         metrics.Plan
+	workHours    string
+	timezone     string
+	hours        metrics.WorkingHours // Parsed --working-hours
+	staleAfter   string
+	staleDays    int // Parsed --stale-after
+	format       string
+	template     string
+	charts       bool
+	groupBy      string
+	aggregation  string
+	baseURL      string
+	uploadURL    string
+	api          string
+	strategy     string
+	cacheDir     string
+	noCache      bool
+	recordDir    string // --record-fixtures
+	replayDir    string // --replay-fixtures
+	snapshotFile string
+	localClones  string
+	offline      bool
+	input        string // Raw data snapshot read with --offline
+	checkpoint   string
+	resume       bool
+	storeURI     string
+	resultsFile  string
+	allMembers   bool
+	memberRole   string
+	memberTeams  teamList
+	discovery    string
+	archived     bool
+	forks        bool
+	visibility   string
+	repoTopics   stringList
+	repoMatch    stringList
+	repoExclude  stringList // --repo-exclude-match
+	excludeUsers coderList
+	optOut       coderList
+	optOutFile   string
+	includeBots  bool
+	inactive     bool
+	inactiveWarn int // Alert when at least this many users are inactive
+	excludePaths stringList
+	docsPaths    stringList
+	languages    stringList
+	issueLabels  stringList
+	pullLabels   stringList
+	countGen     bool // Count generated and binary files towards HoC
+	excludeRepos repoList
+	identityFile string
+	evidence     string
+	slackWebhook string
+	teamsWebhook string
+	webhook      string
+	emailTo      stringList
+	emailFrom    string
+	smtpHost     string
+	smtpPort     int
+	smtpUser     string
+	smtpPassword string
+	action       bool
+	dryRun       bool
+	commentIssue int
+	scoring      string
+	scoreExpr    string
+	expr         metrics.ExprScorer    // Parsed --score-expr
+	weights      metrics.Weights       // Score multipliers, only set from the configuration file
+	boards       []metrics.Leaderboard // Named leaderboards, only set from the configuration file
+	last         metrics.Provider      // Provider of the last run, for its status
+	raw          *metrics.RawRecorder  // Records the data fetched, for the export subcommand
+	pool         *metrics.TokenPool    // Tokens of the client when there are several, for the run status
+	skipCheck    bool                  // --skip-permission-check
+}
+
+func (o *collectOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.provider, "provider", metrics.ProviderGitHub, "Platform to collect from (github, gitlab, gitea for Gitea and Forgejo)")
+	fs.Var(&o.tokens, "token", "GitHub token (defaults to GITHUB_TOKEN, GH_TOKEN or 'gh auth token'), or GitLab or Gitea token with --provider (defaults to GITLAB_TOKEN or GITEA_TOKEN); repeat to rotate among several GitHub tokens")
+	fs.StringVar(&o.tokenFile, "token-file", "", "File with GitHub tokens to rotate among, one per line")
+	fs.BoolVar(&o.skipCheck, "skip-permission-check", false, "Collect without first checking that the token can read what the selected metrics need")
+	fs.Int64Var(&o.appID, "app-id", 0, "GitHub App ID to authenticate as instead of a token")
+	fs.Int64Var(&o.installID, "installation-id", 0, "GitHub App installation ID, required with --app-id")
+	fs.StringVar(&o.appKeyFile, "private-key", "", "Path to the GitHub App private key (PEM), required with --app-id")
+	fs.IntVar(&o.days, "days", 30, "Number of days to measure")
+	fs.StringVar(&o.since, "since", "", "First day of the window as YYYY-MM-DD, overriding --days")
+	fs.StringVar(&o.until, "until", "", "Last day of the window as YYYY-MM-DD (defaults to today)")
+	fs.Var(&o.coders, "coder", "GitHub usernames to measure (can be specified multiple times)")
+	fs.Var(&o.teams, "team", "GitHub team as org/team-slug whose members are measured and rolled up (can be specified multiple times)")
+	fs.Var(&o.repos, "repo", "GitHub repositories to measure (can be specified multiple times)")
+	fs.BoolVar(&o.verbose, "verbose", false, "Enable verbose logging")
+	fs.BoolVar(&o.quiet, "quiet", false, "Do not report progress")
+	fs.StringVar(&o.metric, "metric", "all", "Specific metric to calculate (commits, hoc, issues, lcp, msgs, pulls, reviews, triage, reverts, discussions, releases, workflows, security, score)")
+	fs.IntVar(&o.delay, "delay", 30, "Delay between API calls in seconds")
+	fs.IntVar(&o.maxRetries, "max-retries", metrics.DefaultRetryPolicy.MaxRetries, "Times a failed API request is retried")
+	fs.DurationVar(&o.backoffBase, "backoff-base", metrics.DefaultRetryPolicy.BackoffBase, "Delay before the first retry of a failed API request, doubled for every retry after it")
+	fs.DurationVar(&o.backoffMax, "backoff-max", metrics.DefaultRetryPolicy.BackoffMax, "Longest delay between two retries of a failed API request (0 for no limit)")
+	fs.StringVar(&o.errorMode, "error-mode", metrics.ErrorModePartial, "What API errors do: partial marks the metrics they left incomplete as unknown and carries on, fail aborts the run")
+	fs.Var(&o.orgs, "organization", "GitHub organization to filter repositories (can be specified multiple times or separated by commas)")
+	fs.StringVar(&o.configFile, "config", defaultConfigFile, "Path to the YAML configuration file")
+	fs.StringVar(&o.outputFile, "output-file", "metrics.html", "Path to the output file")
+	fs.StringVar(&o.format, "format", "html", "Output format (html, csv, markdown, json)")
+	fs.StringVar(&o.template, "template", "", "Custom HTML template file (defaults to the built-in template)")
+	fs.StringVar(&o.aggregation, "aggregation", metrics.AggregationMean, "How pull request lifecycles are aggregated into LcP: mean, median, p90 or p95")
+	fs.StringVar(&o.groupBy, "group-by", "user", "Report grouping: user, or repo to add a table of commits, HoC, merged pull requests, review time and contributors per repository")
+	fs.BoolVar(&o.charts, "charts", false, "Draw bar charts, and the score over time with --store, instead of the HTML leaderboard table")
+	fs.StringVar(&o.baseURL, "base-url", "", "GitHub Enterprise Server API URL, e.g. https://github.example.com/api/v3/, or self-managed GitLab or Gitea API URL, e.g. https://gitlab.example.com/api/v4/")
+	fs.StringVar(&o.uploadURL, "upload-url", "", "GitHub Enterprise Server upload URL (defaults to --base-url)")
+	fs.StringVar(&o.api, "api", "rest", "GitHub API used for collection (rest, graphql)")
+	fs.StringVar(&o.strategy, "strategy", metrics.StrategyUser, "How API objects are fetched: user queries each user in each repository, repo lists each repository once and attributes its commits, issues and pull requests to the users locally")
+	fs.StringVar(&o.cacheDir, "cache-dir", "", "Directory for cached commit details and API responses (defaults to the user cache directory)")
+	fs.BoolVar(&o.noCache, "no-cache", false, "Disable the commit details and API response caches")
+	fs.StringVar(&o.recordDir, "record-fixtures", "", "Directory to record every GitHub API response to, for replaying with --replay-fixtures")
+	fs.StringVar(&o.replayDir, "replay-fixtures", "", "Directory of responses recorded with --record-fixtures to answer GitHub API requests from instead of calling GitHub")
+	fs.StringVar(&o.checkpoint, "checkpoint-file", ".githubmetrics-checkpoint.json", "Path to the file recording collection progress")
+	fs.BoolVar(&o.resume, "resume", false, "Resume an interrupted run from the checkpoint file")
+	fs.StringVar(&o.snapshotFile, "snapshot-file", "", "Path to save the raw data of the run to, the unscored metrics of every user in every repository, for recomputing the report with --offline")
+	fs.StringVar(&o.localClones, "local-clones", "", "Directory of local clones, as owner/name or name, to count commits and HoC from with git log instead of the API")
+	fs.BoolVar(&o.offline, "offline", false, "Compute the report from the raw data snapshot given with --input instead of calling GitHub")
+	fs.StringVar(&o.evidence, "evidence", "", "Path to write the commits, pull requests, reviews, issues and comments counted for each user as JSON")
+	fs.StringVar(&o.statusFile, "status-file", "", "Path to write the run status to as JSON: status, exit code, API calls, errors and skipped repositories")
+	fs.StringVar(&o.resultsFile, "results-file", "metrics-results.json", "Path to save the collected results for the render, serve and compare subcommands")
+	fs.StringVar(&o.slackWebhook, "slack-webhook", "", "Slack incoming webhook URL to post a leaderboard summary to after each run")
+	fs.StringVar(&o.teamsWebhook, "teams-webhook", "", "Microsoft Teams webhook URL to post a leaderboard summary card to after each run")
+	fs.StringVar(&o.webhook, "webhook", "", "URL to post a JSON run.completed event with the summary to after each run")
+	fs.Var(&o.emailTo, "email-to", "Address to email the HTML report to after each run (can be specified multiple times)")
+	fs.StringVar(&o.emailFrom, "email-from", "", "Sender address of report emails (defaults to --smtp-username)")
+	fs.StringVar(&o.smtpHost, "smtp-host", "", "SMTP server that sends report emails")
+	fs.IntVar(&o.smtpPort, "smtp-port", 587, "SMTP server port; 465 uses implicit TLS, others STARTTLS when offered")
+	fs.StringVar(&o.smtpUser, "smtp-username", "", "SMTP username")
+	fs.StringVar(&o.smtpPassword, "smtp-password", "", "SMTP password (defaults to SMTP_PASSWORD)")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "Resolve users and repositories and print the estimated API requests and duration instead of collecting")
+	fs.BoolVar(&o.action, "github-action", false, "Run as a GitHub Actions step: read INPUT_* inputs, write the job summary and step outputs")
+	fs.IntVar(&o.commentIssue, "comment-issue", 0, "With --github-action, comment the Markdown leaderboard on this issue of the workflow's repository")
+	fs.StringVar(&o.storeURI, "store", "", "History store for run snapshots, e.g. sqlite://metrics.db")
+	fs.BoolVar(&o.allMembers, "all-org-members", false, "Measure every member of --organization")
+	fs.BoolVar(&o.inactive, "include-inactive", false, "List users without any activity in the window in a separate section instead of as empty leaderboard rows")
+	fs.IntVar(&o.inactiveWarn, "inactive-alert", 0, "Alert in the log, notifications and GitHub Actions outputs when at least this many users are inactive; implies --include-inactive")
+	fs.StringVar(&o.memberRole, "member-role", "all", "Only measure organization members with this role (all, admin, member)")
+	fs.Var(&o.memberTeams, "member-team", "Only measure organization members in this team, as a slug or org/slug with several organizations (can be specified multiple times)")
+	fs.StringVar(&o.discovery, "repo-discovery", metrics.DiscoveryOrg, "How repositories are found when no --repo is given: org lists the organization's repositories, activity uses each user's pull requests")
+	fs.BoolVar(&o.archived, "include-archived", false, "Include archived organization repositories")
+	fs.BoolVar(&o.forks, "include-forks", false, "Include forked repositories; otherwise forks found through activity are replaced by their upstream repository")
+	fs.StringVar(&o.visibility, "visibility", "all", "Only include discovered repositories with this visibility (all, public, private, internal)")
+	fs.Var(&o.repoTopics, "repo-topic", "Only include discovered repositories with this topic (can be specified multiple times)")
+	fs.Var(&o.repoMatch, "repo-match", "Only include discovered repositories whose name matches this glob, e.g. 'platform-*' (can be specified multiple times)")
+	fs.Var(&o.repoExclude, "repo-exclude-match", "Leave out discovered repositories whose name matches this glob, e.g. '*-deprecated' (can be specified multiple times)")
+	fs.Var(&o.excludeUsers, "exclude-user", "GitHub username to leave out of the report (can be specified multiple times)")
+	fs.Var(&o.optOut, "opt-out", "GitHub username to leave out of individual reporting while still counting them in team totals (can be specified multiple times)")
+	fs.StringVar(&o.optOutFile, "opt-out-file", defaultOptOutFile, "File listing users who opted out of individual reporting, one login per line; read when it exists")
+	fs.BoolVar(&o.includeBots, "include-bots", false, "Keep bots and service accounts such as dependabot and *[bot] in the report")
+	fs.Var(&o.excludePaths, "exclude-path", "Glob of file paths left out of HoC, e.g. vendor/** or *.lock (can be specified multiple times)")
+	fs.Var(&o.languages, "language", "Only count files in this language towards HoC, e.g. Go (can be specified multiple times)")
+	fs.Var(&o.docsPaths, "docs-path", "Glob of file paths counted as documentation, e.g. docs/** or *.md, instead of the defaults (can be specified multiple times)")
+	fs.Var(&o.issueLabels, "issue-label", "Only count issues with this label, e.g. bug (can be specified multiple times)")
+	fs.Var(&o.pullLabels, "pr-label", "Only count pull requests with this label, e.g. release (can be specified multiple times)")
+	fs.BoolVar(&o.countGen, "count-generated", false, "Count generated files, such as lock files and *.pb.go, and binary files towards HoC")
+	fs.Var(&o.excludeRepos, "exclude-repo", "GitHub repository as owner/name to leave out (can be specified multiple times)")
+	fs.StringVar(&o.identityFile, "identity-file", "", "JSON file mapping logins to commit emails and alternate logins")
+	fs.IntVar(&o.concurrency, "concurrency", 1, "Number of collection tasks to run in parallel")
+	fs.StringVar(&o.hocSource, "hoc-source", metrics.HoCSourceCommits, "Where HoC comes from: commits fetches every commit's details, stats reads the weekly totals of the repository statistics with a request per repository")
+	fs.BoolVar(&o.squash, "squash-attribution", false, "Count commits squash-merging a pull request for the pull request's author rather than whoever merged it")
+	fs.BoolVar(&o.codeOwners, "codeowners", false, "With the reviews metric, count the merged pull requests changing files each user owns by the repository's CODEOWNERS file and how many of them the user reviewed")
+	fs.BoolVar(&o.security, "security", false, "Collect the security metric, alerts fixed and dismissed and Dependabot pull requests merged and reviewed, which needs access to the repositories' security alerts")
+	fs.StringVar(&o.project, "project", "", "With the issues metric, count the issues and pull requests of this GitHub Project as owner/number, e.g. acme/5, completed by each user")
+	fs.StringVar(&o.milestone, "milestone", "", "With the issues metric, count the issues and pull requests of the milestone with this title in each repository completed by each user")
+	fs.StringVar(&o.staleAfter, "stale-after", fmt.Sprintf("%dd", metrics.DefaultStaleDays), "List pull requests still open after this long, in days (14d) or weeks (2w); 0 to skip the open pull requests")
+	fs.StringVar(&o.workHours, "working-hours", "", "Report the share of commits and reviews made on weekends or outside these working hours, e.g. 09:00-18:00 (never part of the score)")
+	fs.BoolVar(&o.tone, "tone", false, "Rate the tone of each user's review comments with an experimental politeness heuristic (never part of the score)")
+	fs.StringVar(&o.timezone, "timezone", "", "Time zone of --working-hours, e.g. Europe/Berlin (defaults to local time)")
+	fs.IntVar(&o.commitConc, "commit-concurrency", metrics.DefaultCommitConcurrency, "Number of commit details each HoC task fetches in parallel, paced by the rate limit like every request")
+	fs.StringVar(&o.scoring, "scoring", metrics.ScoringRaw, "How scores are computed: raw weighs the metrics themselves, percentile weighs each user's percentile rank in every metric")
+	fs.StringVar(&o.scoreExpr, "score-expr", "", "Score each user with this expression over their metrics instead of the weights, e.g. \"hoc*0.5 + pulls*300 + reviews*200 - reverts*500\"")
+}
+
+// parse parses args and the configuration file. The command line is parsed
+// again after the file so its flags take precedence; repeatable flags ignore
+// values they already hold, so they are not listed twice.
+func (o *collectOptions) parse(fs *flag.FlagSet, args []string) {
+	fs.Parse(args)
+	o.parseConfig(fs, args, o.loadConfig(fs))
+}
+
+// loadConfig reads the configuration file, returning nil without one.
+func (o *collectOptions) loadConfig(fs *flag.FlagSet) *Config {
+	if _, err := os.Stat(o.configFile); err == nil {
+		cfg, err := LoadConfig(o.configFile)
+		if err != nil {
+			log.Fatalf("Error reading configuration: %v", err)
+		}
+		return cfg
+	} else if _, err := os.Stat(legacyConfigFile); err == nil && !isFlagSet(fs, "config") {
+		log.Fatalf("%s uses the old --key=value format, which is no longer read. Convert it with 'github-metrics config migrate'.", legacyConfigFile)
+	}
+	return nil
+}
+
+// parseConfig applies the configuration, which may be nil, and parses args
+// over it as parse does.
+func (o *collectOptions) parseConfig(fs *flag.FlagSet, args []string, cfg *Config) {
+	o.applyConfig(fs, args, cfg)
+
+	if o.action && len(o.repos) == 0 && len(o.orgs) == 0 {
+		if repo := os.Getenv("GITHUB_REPOSITORY"); repo != "" {
+			o.repos.Set(repo)
+		}
+	}
+
+	if o.offline {
+		o.checkOffline(fs)
+	} else if len(o.repos) == 0 && len(o.orgs) == 0 && o.provider != metrics.ProviderGitLab {
+		log.Fatal("No repositories or organization specified. Use --repo to add repositories or --organization to filter by organization.")
+	}
+	if o.allMembers && len(o.orgs) == 0 {
+		log.Fatal("--all-org-members requires --organization.")
+	}
+	if len(o.orgs) > 1 && o.provider != metrics.ProviderGitHub {
+		log.Fatalf("--provider %s measures a single --organization.", o.provider)
+	}
+	if o.strategy != metrics.StrategyUser && o.strategy != metrics.StrategyRepo {
+		log.Fatalf("Unknown --strategy %q, expected user or repo.", o.strategy)
+	}
+	if o.strategy == metrics.StrategyRepo && o.api != "rest" {
+		log.Fatal("--strategy repo requires --api rest.")
+	}
+	if o.hocSource != metrics.HoCSourceCommits && o.hocSource != metrics.HoCSourceStats {
+		log.Fatalf("Unknown --hoc-source %q, expected commits or stats.", o.hocSource)
+	}
+	if o.hocSource == metrics.HoCSourceStats && (o.api != "rest" || o.provider != metrics.ProviderGitHub) {
+		log.Fatal("--hoc-source stats requires --api rest and --provider github.")
+	}
+	if o.hocSource == metrics.HoCSourceStats && (len(o.excludePaths) > 0 || len(o.languages) > 0) {
+		log.Fatal("--hoc-source stats counts whole commits and cannot be combined with --exclude-path or --language.")
+	}
+	if len(o.repoTopics)+len(o.repoMatch)+len(o.repoExclude) > 0 && o.provider != metrics.ProviderGitHub {
+		log.Fatal("--repo-topic, --repo-match and --repo-exclude-match require --provider github.")
+	}
+	if o.squash && (o.api != "rest" || o.provider != metrics.ProviderGitHub) {
+		log.Fatal("--squash-attribution requires --api rest and --provider github.")
+	}
+	if o.squash && (o.hocSource == metrics.HoCSourceStats || o.localClones != "") {
+		log.Fatal("--squash-attribution moves commits between users and cannot be combined with --hoc-source stats or --local-clones.")
+	}
+	if o.workHours != "" {
+		if o.provider != metrics.ProviderGitHub {
+			log.Fatal("--working-hours requires --provider github.")
+		}
+		location := time.Local
+		if o.timezone != "" {
+			var err error
+			if location, err = time.LoadLocation(o.timezone); err != nil {
+				log.Fatalf("Invalid --timezone: %v", err)
+			}
+		}
+		var err error
+		if o.hours, err = metrics.ParseWorkingHours(o.workHours, location); err != nil {
+			log.Fatalf("Invalid --working-hours: %v", err)
+		}
+	} else if o.timezone != "" {
+		log.Fatal("--timezone requires --working-hours.")
+	}
+	if o.staleAfter != "0" {
+		var err error
+		if o.staleDays, err = parsePeriodLength(o.staleAfter); err != nil {
+			log.Fatalf("Invalid --stale-after: %v", err)
+		}
+	}
+	if o.provider != metrics.ProviderGitHub {
+		if isFlagSet(fs, "stale-after") && o.staleDays > 0 {
+			log.Fatal("--stale-after requires --provider github.")
+		}
+		o.staleDays = 0
+	}
+	if o.project != "" {
+		var err error
+		if o.plan.ProjectOwner, o.plan.ProjectNumber, err = metrics.ParseProject(o.project); err != nil {
+			log.Fatalf("Invalid --project: %v", err)
+		}
+	}
+	o.plan.Milestone = o.milestone
+	if o.metric == metrics.MetricSecurity {
+		o.security = true
+	}
+	if o.commitConc < 1 {
+		log.Fatal("--commit-concurrency must be at least 1.")
+	}
+	if o.maxRetries < 0 {
+		log.Fatal("--max-retries must not be negative.")
+	}
+	if o.backoffBase <= 0 || o.backoffMax < 0 {
+		log.Fatal("--backoff-base must be positive and --backoff-max must not be negative.")
+	}
+	if o.errorMode != metrics.ErrorModePartial && o.errorMode != metrics.ErrorModeFail {
+		log.Fatalf("Unknown --error-mode %q, expected partial or fail.", o.errorMode)
+	}
+	if o.recordDir != "" && o.replayDir != "" {
+		log.Fatal("--record-fixtures and --replay-fixtures cannot be combined.")
+	}
+	switch o.provider {
+	case metrics.ProviderGitHub:
+	case metrics.ProviderGitLab, metrics.ProviderGitea:
+		if !o.offline {
+			o.checkForge(fs)
+		}
+	default:
+		log.Fatalf("Unknown --provider %q, expected github, gitlab or gitea.", o.provider)
+	}
+	if o.groupBy != "user" && o.groupBy != "repo" {
+		log.Fatalf("Unknown --group-by %q, expected user or repo.", o.groupBy)
+	}
+	if _, err := metrics.ParseAggregation(o.aggregation); err != nil {
+		log.Fatalf("Invalid --aggregation: %v", err)
+	}
+	switch o.scoring {
+	case metrics.ScoringRaw:
+		o.weights = metrics.DefaultWeights
+	case metrics.ScoringPercentile:
+		o.weights = metrics.PercentileWeights
+	default:
+		log.Fatalf("Unknown --scoring %q, expected raw or percentile.", o.scoring)
+	}
+	if cfg != nil {
+		o.weights = cfg.scoreWeights(o.weights)
+		boards, err := cfg.leaderboards()
+		if err != nil {
+			log.Fatalf("Error in configuration: %v", err)
+		}
+		o.boards = boards
+	}
+	if o.scoreExpr != "" {
+		if o.scoring == metrics.ScoringPercentile {
+			log.Fatal("--score-expr cannot be combined with --scoring percentile.")
+		}
+		expr, err := metrics.NewExprScorer(o.scoreExpr)
+		if err != nil {
+			log.Fatalf("Invalid --score-expr: %v", err)
+		}
+		o.expr = expr
+	}
+	if o.inactiveWarn < 0 {
+		log.Fatal("--inactive-alert must not be negative.")
+	}
+	if o.inactiveWarn > 0 {
+		o.inactive = true
+	}
+	if len(o.emailTo) > 0 && o.smtpHost == "" {
+		log.Fatal("--email-to requires --smtp-host.")
+	}
+	optOut, err := readOptOut(o.optOutFile, isFlagSet(fs, "opt-out-file"))
+	if err != nil {
+		log.Fatalf("Error reading --opt-out-file: %v", err)
+	}
+	for _, user := range optOut {
+		o.optOut.Set(user)
+	}
+}
+
+// applyConfig sets the flags from the configuration, which may be nil, and
+// the action inputs, then parses args over them, without checking that they
+// make a collection.
+func (o *collectOptions) applyConfig(fs *flag.FlagSet, args []string, cfg *Config) {
+	if cfg != nil {
+		if err := cfg.apply(fs); err != nil {
+			log.Fatalf("Error applying configuration: %v", err)
+		}
+	}
+
+	if o.action {
+		if err := actionInputs(fs); err != nil {
+			log.Fatalf("Error reading action inputs: %v", err)
+		}
+	}
+
+	// Parse command-line flags
+	fs.Parse(args)
+}
+
+// readOptOut reads the users listed in the opt-out file. A missing file lists
+// nobody unless it was given explicitly.
+func readOptOut(path string, explicit bool) ([]string, error) {
+	users, err := metrics.LoadOptOut(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return nil, nil
+	}
+	return users, err
+}
+
+// checkOffline validates the options of an --offline run. The users, teams,
+// window and metric default to those of the snapshot; the options that only
+// apply when calling GitHub, such as --store and --evidence, are ignored.
+func (o *collectOptions) checkOffline(fs *flag.FlagSet) {
+	if o.input == "" {
+		log.Fatal("--offline requires --input with a snapshot saved by --snapshot-file.")
+	}
+	for _, name := range []string{"dry-run", "resume", "record-fixtures", "replay-fixtures", "local-clones"} {
+		if isFlagSet(fs, name) {
+			log.Fatalf("--offline cannot be combined with --%s.", name)
+		}
+	}
+	if !isFlagSet(fs, "metric") {
+		o.metric = ""
+	}
+}
+
+// checkForge validates the options of a --provider gitlab or gitea run,
+// rejecting those only GitHub supports.
+func (o *collectOptions) checkForge(fs *flag.FlagSet) {
+	if o.provider == metrics.ProviderGitea && o.baseURL == "" {
+		log.Fatal("--provider gitea requires --base-url, e.g. https://gitea.example.com/api/v1/.")
+	}
+	if len(o.tokens) > 1 {
+		log.Fatalf("--provider %s takes a single --token.", o.provider)
+	}
+	for _, name := range []string{"team", "all-org-members", "app-id", "token-file", "evidence", "exclude-path", "language", "docs-path", "dry-run", "record-fixtures", "replay-fixtures", "codeowners", "security", "tone", "project", "milestone"} {
+		if isFlagSet(fs, name) {
+			log.Fatalf("--%s is not supported with --provider %s.", name, o.provider)
+		}
+	}
+	if o.api != "rest" || o.strategy != metrics.StrategyUser {
+		log.Fatalf("--provider %s requires --api rest and --strategy user.", o.provider)
+	}
+}
+
+// runCollect implements the collect subcommand, which is also what runs when
+// no subcommand is given.
+func runCollect(args []string) {
+	fs := flag.NewFlagSet("collect", flag.ExitOnError)
+	o := &collectOptions{}
+	o.register(fs)
+	// serve has an --input of its own, the results it serves.
+	fs.StringVar(&o.input, "input", "", "Raw data snapshot saved with --snapshot-file, read with --offline")
+	o.parse(fs, args)
+
+	renderer, ext, err := newRenderer(o.format, o.template, o.charts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !isFlagSet(fs, "output-file") {
+		o.outputFile = "metrics." + ext
+	}
+
+	started := time.Now()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if o.dryRun {
+		if err := o.estimate(ctx); err != nil {
+			log.Fatalf("Error estimating API usage: %v", err)
+		}
+		return
+	}
+	render := func(results *metrics.Results) error {
+		return metrics.RenderFile(context.Background(), renderer, o.outputFile, metrics.NewReport(results.Users, results.ViewOptions()))
+	}
+	results, err := o.collect(ctx, render)
+	// Stopping cancels ctx, so whether the run was interrupted is read first.
+	interrupted := ctx.Err() != nil
+	// Restore the default signal handling so a second Ctrl-C exits at once.
+	stop()
+	if err != nil && interrupted && results != nil {
+		flushPartial(o, results, render)
+		o.finish(started, context.Canceled)
+	}
+	if err != nil {
+		o.finish(started, fmt.Errorf("calculating metrics: %w", err))
+	}
+
+	if err := render(results); err != nil {
+		o.finish(started, fmt.Errorf("rendering template: %w", err))
+	}
+	if o.resultsFile != "" {
+		if err := results.Save(o.resultsFile); err != nil {
+			o.finish(started, fmt.Errorf("saving results: %w", err))
+		}
+	}
+	if o.inactiveAlert(results) {
+		log.Printf("Warning: %d users had no activity: %s", len(results.Inactive), strings.Join(results.Inactive, ", "))
+	}
+	o.notify(ctx, results)
+	if o.action {
+		if err := o.reportAction(ctx, results); err != nil {
+			o.finish(started, fmt.Errorf("reporting to GitHub Actions: %w", err))
+		}
+	}
+	o.finish(started, nil)
+}
+
+// notifiers returns the configured notification drivers keyed by name.
+func (o *collectOptions) notifiers() map[string]metrics.Notifier {
+	notifiers := make(map[string]metrics.Notifier)
+	if o.slackWebhook != "" {
+		notifiers["Slack"] = metrics.SlackNotifier{WebhookURL: o.slackWebhook}
+	}
+	if o.teamsWebhook != "" {
+		notifiers["Teams"] = metrics.TeamsNotifier{WebhookURL: o.teamsWebhook}
+	}
+	if o.webhook != "" {
+		notifiers["webhook"] = metrics.WebhookNotifier{URL: o.webhook}
+	}
+	return notifiers
+}
+
+// notify posts a summary of the results to every configured notifier and
+// emails the HTML report. Failures are logged without failing the run.
+func (o *collectOptions) notify(ctx context.Context, results *metrics.Results) {
+	report := metrics.NewReport(results.Users, results.ViewOptions())
+	if len(o.emailTo) > 0 {
+		if err := o.email(ctx, report); err != nil {
+			log.Printf("Error emailing report: %v", err)
+		}
+	}
+	notifiers := o.notifiers()
+	if len(notifiers) == 0 {
+		return
+	}
+	summary := metrics.NewSummary(report, 5)
+	summary.Alert = o.inactiveAlert(results)
+	for name, notifier := range notifiers {
+		if err := notifier.Notify(ctx, summary); err != nil {
+			log.Printf("Error posting to %s: %v", name, err)
+		}
+	}
+}
+
+// inactiveAlert reports whether the inactive users reached --inactive-alert.
+func (o *collectOptions) inactiveAlert(results *metrics.Results) bool {
+	return o.inactiveWarn > 0 && len(results.Inactive) >= o.inactiveWarn
+}
+
+// email renders report as HTML and sends it to --email-to, whatever the
+// --format of the output file.
+func (o *collectOptions) email(ctx context.Context, report metrics.Report) error {
+	var html bytes.Buffer
+	renderer := metrics.HTMLRenderer{TemplatePath: o.template, Charts: o.charts}
+	if err := renderer.Render(ctx, &html, report); err != nil {
+		return err
+	}
+
+	var to []string
+	for _, addr := range o.emailTo {
+		if !contains(to, addr) {
+			to = append(to, addr)
+		}
+	}
+	mailer := metrics.Mailer{
+		Host:     o.smtpHost,
+		Port:     o.smtpPort,
+		Username: o.smtpUser,
+		Password: o.smtpPassword,
+		From:     o.emailFrom,
+		To:       to,
+	}
+	if mailer.Password == "" {
+		mailer.Password = os.Getenv("SMTP_PASSWORD")
+	}
+	if mailer.From == "" {
+		mailer.From = o.smtpUser
+	}
+	subject := "GitHub Metrics"
+	if report.Organization != "" {
+		subject += " for " + report.Organization
+	}
+	subject += ": activity " + report.Window()
+	return mailer.SendHTML(subject, html.Bytes(), "metrics.html")
+}
+
+// flushPartial writes the results collected before an interrupt, so a
+// cancelled run still leaves its output behind.
+func flushPartial(o *collectOptions, results *metrics.Results, render func(*metrics.Results) error) {
+	results.CollectedAt = time.Now()
+	log.Printf("Interrupted; writing partial results for %d users\n", len(results.Users))
+	if err := render(results); err != nil {
+		log.Printf("Error rendering template: %v", err)
+	}
+	if o.resultsFile != "" {
+		if err := results.Save(o.resultsFile); err != nil {
+			log.Printf("Error saving results: %v", err)
+		}
+	}
+	log.Printf("Run again with --resume to finish collecting\n")
+}
+
+// window returns the measured period. --since and --until take precedence
+// over --days; until is zero for a window that ends now.
+func (o *collectOptions) window() (since, until time.Time, err error) {
+	if o.until != "" {
+		day, err := time.ParseInLocation("2006-01-02", o.until, time.Local)
+		if err != nil {
+			return since, until, fmt.Errorf("invalid --until %q, expected YYYY-MM-DD", o.until)
+		}
+		// The last day counts in full.
+		until = day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	end := until
+	if end.IsZero() {
+		end = time.Now()
+	}
+	since = end.AddDate(0, 0, -o.days)
+	if o.since != "" {
+		since, err = time.ParseInLocation("2006-01-02", o.since, time.Local)
+		if err != nil {
+			return since, until, fmt.Errorf("invalid --since %q, expected YYYY-MM-DD", o.since)
+		}
+	}
+	if !since.Before(end) {
+		return since, until, fmt.Errorf("--since %s is not before the end of the window", since.Format("2006-01-02"))
+	}
+	return since, until, nil
+}
+
+// collection is a run set up from the options: the collector and the users
+// to measure.
+type collection struct {
+	provider    metrics.Provider
+	rest        *metrics.GitHubCollector // Nil unless collecting from GitHub
+	collector   metrics.Collector
+	since       time.Time
+	until       time.Time
+	coders      []string
+	teamMembers map[string][]string
+	checkpoint  *metrics.Checkpoint
+}
+
+// prepare creates the collector and resolves the users to measure from
+// --coder, --team and --all-org-members.
+func (o *collectOptions) prepare(ctx context.Context) (*collection, error) {
+	if o.provider != metrics.ProviderGitHub {
+		return o.prepareForge()
+	}
+	client, err := o.client(ctx)
+	if err != nil {
+		return nil, credentialsError{fmt.Errorf("creating GitHub client: %w", err)}
+	}
+	// Replayed fixtures need no credentials to check.
+	if !o.skipCheck && o.replayDir == "" {
+		if err := o.checkAccess(ctx); err != nil {
+			return nil, credentialsError{err}
+		}
+	}
+	rest := metrics.NewGitHubCollector(client, o.days, o.orgs.first(), o.verbose)
+	if len(o.orgs) > 1 {
+		rest.Organizations = o.orgs[1:]
+	}
+	o.last = rest
+	rest.Since, rest.Until, err = o.window()
+	if err != nil {
+		return nil, err
+	}
+	rest.Repos = o.repos
+	rest.ExcludeRepos = o.excludeRepos
+	if o.identityFile != "" {
+		rest.Identities, err = metrics.LoadIdentities(o.identityFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading identity file: %w", err)
+		}
+	}
+	if o.evidence != "" {
+		rest.Evidence = metrics.NewEvidence()
+	}
+	rest.HoCFilter = o.hocFilter()
+	rest.DocPaths = o.docPaths()
+	rest.Discovery = o.discovery
+	rest.Strategy = o.strategy
+	rest.Retry = metrics.RetryPolicy{MaxRetries: o.maxRetries, BackoffBase: o.backoffBase, BackoffMax: o.backoffMax}
+	rest.ErrorMode = o.errorMode
+	rest.CommitConcurrency = o.commitConc
+	rest.HoCSource = o.hocSource
+	rest.SquashAttribution = o.squash
+	rest.WorkingHours = o.hours
+	rest.StaleDays = o.staleDays
+	rest.CodeOwners = o.codeOwners
+	rest.Security = o.security
+	rest.Tone = o.tone
+	rest.Labels = o.labels()
+	rest.Plan = o.plan
+	rest.RepoFilter = metrics.RepoFilter{
+		IncludeArchived: o.archived,
+		IncludeForks:    o.forks,
+		Visibility:      o.visibility,
+		Topics:          o.repoTopics,
+		Match:           o.repoMatch,
+		ExcludeMatch:    o.repoExclude,
+	}
+	// Fixtures hold every commit's details, so they are neither recorded
+	// from nor replayed into the commit cache.
+	if !o.noCache && o.recordDir == "" && o.replayDir == "" {
+		cacheDir, err := o.cacheDirectory()
+		if err != nil {
+			return nil, err
+		}
+		rest.Cache = metrics.FileCache{Dir: cacheDir}
+	}
+	if o.raw != nil {
+		rest.API = o.raw.API(metrics.NewGitHubAPI(client))
+		if rest.Cache != nil {
+			rest.Cache = o.raw.Cache(rest.Cache)
+		}
+	}
+	cp, err := o.loadCheckpoint(&rest.Since, &rest.Until)
+	if err != nil {
+		return nil, err
+	}
+
+	coders := append([]string(nil), o.coders...)
+	teamMembers := make(map[string][]string)
+	for _, team := range o.teams {
+		org, slug := metrics.ParseRepo(team)
+		if org == "" || slug == "" {
+			return nil, fmt.Errorf("invalid team %q, expected org/team-slug", team)
+		}
+		members, err := rest.TeamMembers(ctx, org, slug)
+		if err != nil {
+			return nil, err
+		}
+		members = metrics.FilterUsers(members, o.excludeUsers, o.includeBots)
+		teamMembers[team] = members
+		for _, member := range members {
+			if !contains(coders, member) {
+				coders = append(coders, member)
+			}
+		}
+	}
+
+	if o.allMembers {
+		members, err := o.orgMembers(ctx, rest)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			if !contains(coders, member) {
+				coders = append(coders, member)
+			}
+		}
+	}
+
+	coders = metrics.FilterUsers(coders, o.excludeUsers, o.includeBots)
+
+	var collector metrics.Collector
+	switch o.api {
+	case "rest":
+		collector = rest
+	case "graphql":
+		collector = metrics.NewGraphQLCollector(rest)
+	default:
+		return nil, fmt.Errorf("unknown API: %s", o.api)
+	}
+	collector = o.localGit(collector, rest.Since, rest.Until, rest.Identities, rest.Evidence, coders)
+	return &collection{
+		provider:    rest,
+		rest:        rest,
+		collector:   collector,
+		since:       rest.Since,
+		until:       rest.Until,
+		coders:      coders,
+		teamMembers: teamMembers,
+		checkpoint:  cp,
+	}, nil
+}
+
+// prepareForge creates the collector for --provider gitlab or gitea. Users
+// are only given with --coder, and --organization names the group or
+// organization whose repositories are measured.
+func (o *collectOptions) prepareForge() (*collection, error) {
+	since, until, err := o.window()
+	if err != nil {
+		return nil, err
+	}
+	cp, err := o.loadCheckpoint(&since, &until)
+	if err != nil {
+		return nil, err
+	}
+	var identities metrics.Identities
+	if o.identityFile != "" {
+		identities, err = metrics.LoadIdentities(o.identityFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading identity file: %w", err)
+		}
+	}
+	retry := metrics.RetryPolicy{MaxRetries: o.maxRetries, BackoffBase: o.backoffBase, BackoffMax: o.backoffMax}
+
+	var provider metrics.Provider
+	switch o.provider {
+	case metrics.ProviderGitLab:
+		token := o.forgeToken("GITLAB_TOKEN")
+		if token == "" {
+			return nil, credentialsError{errors.New("no GitLab token: set --token or GITLAB_TOKEN")}
+		}
+		gitlab := metrics.NewGitLabCollector(o.baseURL, token, o.days, o.verbose)
+		gitlab.Since, gitlab.Until = since, until
+		gitlab.Projects = o.repos
+		gitlab.Group = o.orgs.first()
+		gitlab.ExcludeRepos = o.excludeRepos
+		gitlab.Identities = identities
+		gitlab.Retry = retry
+		gitlab.ErrorMode = o.errorMode
+		gitlab.Labels = o.labels()
+		provider = gitlab
+	case metrics.ProviderGitea:
+		token := o.forgeToken("GITEA_TOKEN")
+		if token == "" {
+			return nil, credentialsError{errors.New("no Gitea token: set --token or GITEA_TOKEN")}
+		}
+		gitea := metrics.NewGiteaCollector(o.baseURL, token, o.days, o.verbose)
+		gitea.Since, gitea.Until = since, until
+		gitea.Repos = o.repos
+		gitea.Organization = o.orgs.first()
+		gitea.ExcludeRepos = o.excludeRepos
+		gitea.Identities = identities
+		gitea.Retry = retry
+		gitea.ErrorMode = o.errorMode
+		gitea.Labels = o.labels()
+		provider = gitea
+	default:
+		return nil, fmt.Errorf("unknown provider: %s", o.provider)
+	}
+	o.last = provider
+	coders := metrics.FilterUsers(o.coders, o.excludeUsers, o.includeBots)
+	return &collection{
+		provider:   provider,
+		collector:  o.localGit(provider, since, until, identities, nil, coders),
+		since:      since,
+		until:      until,
+		coders:     coders,
+		checkpoint: cp,
+	}, nil
+}
+
+// localGit returns collector reading commits and HoC from the clones under
+// --local-clones, or collector itself when it is not set. Local commits are
+// only attributed through the identity file and GitHub noreply emails, so
+// users without emails in it are warned about.
+func (o *collectOptions) localGit(collector metrics.Collector, since, until time.Time, identities metrics.Identities, evidence *metrics.Evidence, coders []string) metrics.Collector {
+	if o.localClones == "" {
+		return collector
+	}
+	for _, user := range coders {
+		hasEmail := false
+		for _, alias := range identities.Aliases(user) {
+			if strings.Contains(alias, "@") {
+				hasEmail = true
+				break
+			}
+		}
+		if !hasEmail {
+			log.Printf("Warning: no commit emails for %s in the identity file; only noreply commits count from local clones\n", user)
+		}
+	}
+	return &metrics.LocalGitCollector{
+		Collector:    collector,
+		Dir:          o.localClones,
+		Since:        since,
+		Until:        until,
+		Identities:   identities,
+		HoCFilter:    o.hocFilter(),
+		DocPaths:     o.docPaths(),
+		Evidence:     evidence,
+		Verbose:      o.verbose,
+		WorkingHours: o.hours,
+	}
+}
+
+// hocFilter returns the files that count towards HoC.
+func (o *collectOptions) hocFilter() metrics.PathFilter {
+	return metrics.PathFilter{Exclude: o.excludePaths, Languages: o.languages, SkipGenerated: !o.countGen}
+}
+
+// docPaths returns the files that count towards Docs.
+func (o *collectOptions) docPaths() metrics.DocPaths {
+	if len(o.docsPaths) == 0 {
+		return metrics.DefaultDocPaths
+	}
+	return metrics.DocPaths(o.docsPaths)
+}
+
+// labels returns the issues and pull requests that count.
+func (o *collectOptions) labels() metrics.LabelFilter {
+	return metrics.LabelFilter{Issues: o.issueLabels, Pulls: o.pullLabels}
+}
+
+// forgeToken returns --token, or the environment variable env when it is
+// not set.
+func (o *collectOptions) forgeToken(env string) string {
+	if len(o.tokens) > 0 {
+		return o.tokens[0]
+	}
+	return os.Getenv(env)
+}
+
+// loadCheckpoint returns the checkpoint to record progress in. With --resume
+// it is the one saved by the interrupted run, whose window replaces since
+// and until.
+func (o *collectOptions) loadCheckpoint(since, until *time.Time) (*metrics.Checkpoint, error) {
+	cp := metrics.NewCheckpoint(o.checkpoint)
+	if o.resume {
+		var err error
+		cp, err = metrics.LoadCheckpoint(o.checkpoint)
+		if err != nil {
+			return nil, fmt.Errorf("loading checkpoint: %w", err)
+		}
+		if cp.Metric != o.metric {
+			return nil, fmt.Errorf("checkpoint was created for metric %s, not %s", cp.Metric, o.metric)
+		}
+		*since, *until = cp.Since, cp.Until
+		log.Printf("Resuming from checkpoint with %d finished tasks\n", len(cp.Tasks))
+	}
+	cp.Metric, cp.Since, cp.Until = o.metric, *since, *until
+	return cp, nil
+}
+
+// collect runs one collection. onUpdate, when set, is called with the partial
+// results each time a user has been processed.
+func (o *collectOptions) collect(ctx context.Context, onUpdate func(*metrics.Results) error) (*metrics.Results, error) {
+	if o.offline {
+		return o.collectOffline(ctx, onUpdate)
+	}
+	run, err := o.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cp := run.checkpoint
+
+	webURL := metrics.WebURL(o.baseURL)
+	switch o.provider {
+	case metrics.ProviderGitLab:
+		webURL = metrics.GitLabWebURL(o.baseURL)
+	case metrics.ProviderGitea:
+		webURL = metrics.GiteaWebURL(o.baseURL)
+	}
+	results := &metrics.Results{
+		Metric:       o.metric,
+		Since:        run.since,
+		Until:        run.until,
+		Organization: strings.Join(o.orgs, ", "),
+		WebURL:       webURL,
+		Teams:        run.teamMembers,
+		WorkingHours: o.hours.String(),
+		Aggregation:  o.aggregation,
+		StaleDays:    o.staleDays,
+		Tone:         o.tone,
+		OptOut:       o.optOut,
+	}
+
+	var store metrics.Store
+	if o.storeURI != "" {
+		store, err = metrics.OpenStore(o.storeURI)
+		if err != nil {
+			return nil, fmt.Errorf("opening store: %w", err)
+		}
+		defer store.Close()
+		last, err := store.Latest(ctx, o.metric)
+		if err != nil {
+			return nil, fmt.Errorf("loading previous snapshot: %w", err)
+		}
+		if last != nil {
+			results.Previous = last.Users
+			if o.verbose {
+				log.Printf("Comparing with snapshot %d taken at %s\n", last.ID, last.TakenAt.Local().Format("2006-01-02 15:04"))
+			}
+		}
+	}
+
+	calculator := &metrics.Calculator{
+		Collector:   run.collector,
+		Scorer:      o.scorer(),
+		Verbose:     o.verbose,
+		Concurrency: o.concurrency,
+		Checkpoint:  cp,
+	}
+	if o.groupBy == "repo" {
+		calculator.Repos = make(metrics.RepoTotals)
+		results.Repos = calculator.Repos
+	}
+	var snapshot *metrics.RawSnapshot
+	if o.snapshotFile != "" {
+		snapshot = metrics.NewRawSnapshot()
+		// A resumed run takes the tasks it finished before from the
+		// checkpoint instead of the collector.
+		snapshot.AddCheckpoint(cp)
+		calculator.Collector = snapshot.Record(run.collector)
+	}
+	if !o.quiet {
+		// Redrawing a single line only works on a terminal and would be
+		// interleaved with verbose logging.
+		calculator.Progress = metrics.NewProgress(os.Stderr, isTerminal(os.Stderr) && !o.verbose, run.provider.APICalls)
+	}
+	if onUpdate != nil {
+		calculator.OnUser = func(m map[string]metrics.UserMetrics) error {
+			results.Users = m
+			return onUpdate(results)
+		}
+	}
+	results.Users, err = calculator.Calculate(ctx, run.coders, o.metric)
+	results.Leaderboards = metrics.ScoreLeaderboards(results.Users, o.boards)
+	results.Failures = run.provider.EndpointFailures()
+	for _, failure := range results.Failures {
+		log.Printf("Stopped calling %s after repeated failures, skipping %d calls: %s\n", failure.Endpoint, failure.Skipped, failure.Error)
+	}
+	if err != nil {
+		return results, err
+	}
+	results.CollectedAt = time.Now()
+	if o.inactive {
+		results.Inactive = metrics.InactiveUsers(metrics.FilterUsers(run.coders, o.optOut, true), results.Users)
+	}
+
+	if run.rest != nil && run.rest.Evidence != nil {
+		run.rest.Evidence.Forget(o.optOut)
+		if err := run.rest.Evidence.Save(o.evidence); err != nil {
+			return results, fmt.Errorf("saving evidence: %w", err)
+		}
+	}
+	if snapshot != nil {
+		snapshot.Metric, snapshot.Since, snapshot.Until, snapshot.CollectedAt = o.metric, results.Since, results.Until, results.CollectedAt
+		snapshot.Organization, snapshot.WebURL, snapshot.Teams = results.Organization, results.WebURL, results.Teams
+		snapshot.Users, snapshot.Failures, snapshot.WorkingHours = run.coders, results.Failures, results.WorkingHours
+		snapshot.StaleDays, snapshot.Tone = results.StaleDays, results.Tone
+		if err := snapshot.Save(o.snapshotFile); err != nil {
+			return results, fmt.Errorf("saving snapshot: %w", err)
+		}
+	}
+
+	if store != nil {
+		snapshot := &metrics.Snapshot{TakenAt: results.CollectedAt, Since: results.Since, Metric: o.metric, Users: results.Users}
+		if err := store.Save(ctx, snapshot); err != nil {
+			return results, fmt.Errorf("saving snapshot: %w", err)
+		}
+		if o.verbose {
+			log.Printf("Saved snapshot %d to %s\n", snapshot.ID, o.storeURI)
+		}
+		results.History, err = store.Scores(ctx, o.metric)
+		if err != nil {
+			return results, fmt.Errorf("loading score history: %w", err)
+		}
+	}
+
+	if err := cp.Remove(); err != nil {
+		log.Printf("Error removing checkpoint: %v", err)
+	}
+	return results, nil
+}
+
+// collectOffline computes the results from the raw data snapshot given with
+// --input instead of collecting them. --coder selects among the snapshot's
+// users, which are all measured by default.
+func (o *collectOptions) collectOffline(ctx context.Context, onUpdate func(*metrics.Results) error) (*metrics.Results, error) {
+	snapshot, err := metrics.LoadRawSnapshot(o.input)
+	if err != nil {
+		return nil, fmt.Errorf("loading snapshot: %w", err)
+	}
+	if o.metric == "" {
+		o.metric = snapshot.Metric
+	}
+	coders := snapshot.Users
+	if len(o.coders) > 0 {
+		coders = o.coders
+	}
+	coders = metrics.FilterUsers(coders, o.excludeUsers, o.includeBots)
+
+	results := &metrics.Results{
+		Metric:       o.metric,
+		Since:        snapshot.Since,
+		Until:        snapshot.Until,
+		CollectedAt:  snapshot.CollectedAt,
+		Organization: snapshot.Organization,
+		WebURL:       snapshot.WebURL,
+		Teams:        snapshot.Teams,
+		Failures:     snapshot.Failures,
+		WorkingHours: snapshot.WorkingHours,
+		Aggregation:  o.aggregation,
+		StaleDays:    snapshot.StaleDays,
+		Tone:         snapshot.Tone,
+		OptOut:       o.optOut,
+	}
+	calculator := &metrics.Calculator{
+		Collector: &metrics.SnapshotCollector{Snapshot: snapshot, ExcludeRepos: o.excludeRepos},
+		Scorer:    o.scorer(),
+		Verbose:   o.verbose,
+	}
+	if o.groupBy == "repo" {
+		calculator.Repos = make(metrics.RepoTotals)
+		results.Repos = calculator.Repos
+	}
+	if onUpdate != nil {
+		calculator.OnUser = func(m map[string]metrics.UserMetrics) error {
+			results.Users = m
+			return onUpdate(results)
+		}
+	}
+	results.Users, err = calculator.Calculate(ctx, coders, o.metric)
+	if err != nil {
+		return results, err
+	}
+	results.Leaderboards = metrics.ScoreLeaderboards(results.Users, o.boards)
+	if o.inactive {
+		results.Inactive = metrics.InactiveUsers(metrics.FilterUsers(coders, o.optOut, true), results.Users)
+	}
+	return results, nil
+}
+
+// scorer returns the scorer selected by --score-expr or --scoring.
+func (o *collectOptions) scorer() metrics.Scorer {
+	switch {
+	case o.scoreExpr != "":
+		return o.expr
+	case o.scoring == metrics.ScoringPercentile:
+		return metrics.PercentileScorer{Weights: o.weights}
+	}
+	return metrics.WeightedScorer{Weights: o.weights}
+}
+
+// client returns a GitHub client authenticated as the app installation when
+// --app-id is set, or with a token otherwise. With --replay-fixtures it
+// answers from the recorded responses instead, and with --record-fixtures it
+// records them.
+func (o *collectOptions) client(ctx context.Context) (*github.Client, error) {
+	if o.replayDir != "" {
+		return metrics.NewFixtureClient(metrics.Fixtures{Dir: o.replayDir}, o.baseURL, o.uploadURL)
+	}
+	client, err := o.cachedClient(ctx)
+	if err != nil || o.recordDir == "" {
+		return client, err
+	}
+	return metrics.WithFixtureRecorder(client, metrics.Fixtures{Dir: o.recordDir}), nil
+}
+
+// cachedClient returns the authenticated client, making its requests
+// conditional on the cached responses unless --no-cache is set.
+func (o *collectOptions) cachedClient(ctx context.Context) (*github.Client, error) {
+	var (
+		client *github.Client
+		scope  string
+		err    error
+	)
+	if o.appID == 0 {
+		var tokens []string
+		tokens, err = o.githubTokens()
+		if err != nil {
+			return nil, err
+		}
+		if len(tokens) > 1 {
+			o.pool = metrics.NewTokenPool(tokens)
+			o.pool.Verbose = o.verbose
+			client, err = metrics.NewTokenPoolClient(o.pool, o.baseURL, o.uploadURL)
+		} else {
+			client, err = metrics.NewGitHubClient(ctx, tokens[0], o.baseURL, o.uploadURL)
+		}
+		scope = o.baseURL + "\n" + strings.Join(tokens, "\n")
+	} else {
+		client, err = o.appClient(ctx)
+		scope = fmt.Sprintf("%s\napp %d installation %d", o.baseURL, o.appID, o.installID)
+	}
+	if err != nil || o.noCache {
+		return client, err
+	}
+	cacheDir, err := o.cacheDirectory()
+	if err != nil {
+		return nil, err
+	}
+	return metrics.WithETagCache(client, metrics.ETagCache{Dir: cacheDir, Scope: scope}), nil
+}
+
+// githubTokens returns the tokens of --token and --token-file or, when there
+// are none, the one resolveToken finds.
+func (o *collectOptions) githubTokens() ([]string, error) {
+	tokens := append([]string(nil), o.tokens...)
+	if o.tokenFile != "" {
+		fromFile, err := metrics.LoadTokens(o.tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading --token-file: %w", err)
+		}
+		if len(fromFile) == 0 {
+			return nil, fmt.Errorf("no tokens in --token-file %s", o.tokenFile)
+		}
+		for _, token := range fromFile {
+			if !contains(tokens, token) {
+				tokens = append(tokens, token)
+			}
+		}
+	}
+	if len(tokens) > 0 {
+		return tokens, nil
+	}
+	token, err := resolveToken("", o.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return []string{token}, nil
+}
+
+// appClient returns a client authenticated as the GitHub App installation.
+func (o *collectOptions) appClient(ctx context.Context) (*github.Client, error) {
+	if o.installID == 0 || o.appKeyFile == "" {
+		return nil, errors.New("--app-id requires --installation-id and --private-key")
+	}
+	pem, err := os.ReadFile(o.appKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := metrics.ParsePrivateKey(pem)
+	if err != nil {
+		return nil, err
+	}
+	app := metrics.GitHubApp{AppID: o.appID, InstallationID: o.installID, PrivateKey: key}
+	return metrics.NewGitHubAppClient(ctx, app, o.baseURL, o.uploadURL)
+}
+
+// cacheDirectory returns --cache-dir or the default cache directory.
+func (o *collectOptions) cacheDirectory() (string, error) {
+	if o.cacheDir != "" {
+		return o.cacheDir, nil
+	}
+	dir, err := metrics.DefaultCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locating cache directory: %w", err)
+	}
+	return dir, nil
+}
+
+// orgMembers returns the members of the organizations matching the role and
+// team filters. Teams are given as org/slug, or as a slug of the only
+// organization.
+func (o *collectOptions) orgMembers(ctx context.Context, rest *metrics.GitHubCollector) ([]string, error) {
+	var members []string
+	for _, org := range o.orgs {
+		orgMembers, err := rest.OrgMembers(ctx, org, o.memberRole)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range orgMembers {
+			if !contains(members, member) {
+				members = append(members, member)
+			}
+		}
+	}
+	if len(o.memberTeams) == 0 {
+		return members, nil
+	}
+
+	inTeams := make(map[string]bool)
+	for _, team := range o.memberTeams {
+		org, slug, ok := strings.Cut(team, "/")
+		if !ok {
+			if len(o.orgs) > 1 {
+				return nil, fmt.Errorf("--member-team %s must be given as org/slug with several organizations", team)
+			}
+			org, slug = o.orgs.first(), team
+		}
+		teamMembers, err := rest.TeamMembers(ctx, org, slug)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range teamMembers {
+			inTeams[member] = true
+		}
+	}
+	var filtered []string
+	for _, member := range members {
+		if inTeams[member] {
+			filtered = append(filtered, member)
+		}
+	}
+	return filtered, nil
+}
+
+// coderList is a custom flag.Value implementation to handle multiple coders
+type coderList []string
+
+func (c *coderList) String() string {
+	return fmt.Sprint(*c)
+}
+
+func (c *coderList) Set(value string) error {
+	if !contains(*c, value) {
+		*c = append(*c, value)
+	}
+	return nil
+}
+
+// repoList is a custom flag.Value implementation to handle multiple repositories
+type repoList []string
+
+func (r *repoList) String() string {
+	return fmt.Sprint(*r)
+}
+
+func (r *repoList) Set(value string) error {
+	if !contains(*r, value) {
+		*r = append(*r, value)
+	}
+	return nil
+}
+
+// teamList is a custom flag.Value implementation to handle multiple teams
+type teamList []string
+
+func (t *teamList) String() string {
+	return fmt.Sprint(*t)
+}
+
+func (t *teamList) Set(value string) error {
+	if !contains(*t, value) {
+		*t = append(*t, value)
+	}
+	return nil
+}
+
+// orgList is a custom flag.Value implementation to handle multiple
+// organizations, repeated or separated by commas
+type orgList []string
+
+func (l *orgList) String() string {
+	return fmt.Sprint(*l)
+}
+
+func (l *orgList) Set(value string) error {
+	for _, org := range strings.Split(value, ",") {
+		if org = strings.TrimSpace(org); org != "" && !contains(*l, org) {
+			*l = append(*l, org)
+		}
+	}
+	return nil
+}
+
+// first returns the first organization, or "" when there is none.
+func (l orgList) first() string {
+	if len(l) == 0 {
+		return ""
+	}
+	return l[0]
+}
+
+// stringList is a custom flag.Value implementation for other repeatable flags
+type stringList []string
+
+func (s *stringList) String() string {
+	return fmt.Sprint(*s)
+}
+
+func (s *stringList) Set(value string) error {
+	if !contains(*s, value) {
+		*s = append(*s, value)
+	}
+	return nil
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"handshake/stats/metrics"
+)
+
+// runCompare implements the compare subcommand, which renders results with
+// the change of every metric against earlier results. With --periods it
+// instead collects several consecutive windows and compares them side by
+// side.
+func runCompare(args []string) {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	o := &collectOptions{}
+	o.register(fs)
+	input := fs.String("input", "metrics-results.json", "Path to the current results saved by collect")
+	previous := fs.String("previous", "", "Path to the earlier results to compare against")
+	periods := fs.Int("periods", 0, "Collect this many consecutive windows and compare them side by side")
+	periodLength := fs.String("period-length", "30d", "Length of each window with --periods, in days (30d) or weeks (4w)")
+	fs.Parse(args)
+
+	if *periods > 0 {
+		o.parse(fs, args)
+		comparePeriods(fs, o, *periods, *periodLength)
+		return
+	}
+
+	if *previous == "" {
+		log.Fatal("No earlier results specified. Use --previous to compare against a results file, or --periods to collect consecutive windows.")
+	}
+
+	renderer, ext, err := newRenderer(o.format, o.template, o.charts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !isFlagSet(fs, "output-file") {
+		o.outputFile = "metrics." + ext
+	}
+
+	current, err := metrics.LoadResults(*input)
+	if err != nil {
+		log.Fatalf("Error loading results: %v", err)
+	}
+	earlier, err := metrics.LoadResults(*previous)
+	if err != nil {
+		log.Fatalf("Error loading earlier results: %v", err)
+	}
+
+	opts := current.ViewOptions()
+	opts.Previous = earlier.Users
+	report := metrics.NewReport(current.Users, opts)
+	if err := metrics.RenderFile(context.Background(), renderer, o.outputFile, report); err != nil {
+		log.Fatalf("Error rendering report: %v", err)
+	}
+}
+
+// comparePeriods collects n windows of the given length ending with --until
+// (today by default). The report shows the latest window with the change
+// against the one before it, followed by every window side by side.
+func comparePeriods(fs *flag.FlagSet, o *collectOptions, n int, length string) {
+	days, err := parsePeriodLength(length)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if o.since != "" {
+		log.Fatal("--since cannot be combined with --periods; use --until to choose the end of the latest window.")
+	}
+	if o.resume {
+		log.Fatal("--resume is not supported with --periods.")
+	}
+	// Each window would otherwise be stored as a run of its own.
+	o.storeURI = ""
+
+	end := time.Now()
+	if o.until != "" {
+		end, err = time.ParseInLocation("2006-01-02", o.until, time.Local)
+		if err != nil {
+			log.Fatalf("Invalid --until %q, expected YYYY-MM-DD", o.until)
+		}
+	}
+
+	renderer, ext, err := newRenderer(o.format, o.template, o.charts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !isFlagSet(fs, "output-file") {
+		o.outputFile = "metrics." + ext
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var results *metrics.Results
+	var collected []metrics.Period
+	for i := n - 1; i >= 0; i-- {
+		last := end.AddDate(0, 0, -i*days)
+		o.since = last.AddDate(0, 0, 1-days).Format("2006-01-02")
+		o.until = last.Format("2006-01-02")
+		log.Printf("Collecting period %d of %d: %s to %s\n", n-i, n, o.since, o.until)
+		results, err = o.collect(ctx, nil)
+		if err != nil {
+			log.Fatalf("Error calculating metrics: %v", err)
+		}
+		collected = append(collected, metrics.Period{Since: results.Since, Until: results.Until, Users: results.Users})
+	}
+
+	results.Periods = collected
+	if len(collected) > 1 {
+		results.Previous = collected[len(collected)-2].Users
+	}
+	report := metrics.NewReport(results.Users, results.ViewOptions())
+	if err := metrics.RenderFile(context.Background(), renderer, o.outputFile, report); err != nil {
+		log.Fatalf("Error rendering report: %v", err)
+	}
+	if o.resultsFile != "" {
+		if err := results.Save(o.resultsFile); err != nil {
+			log.Fatalf("Error saving results: %v", err)
+		}
+	}
+}
+
+// parsePeriodLength converts a period length such as 30d or 4w to days.
+func parsePeriodLength(s string) (int, error) {
+	number, unit := s, 1
+	switch {
+	case strings.HasSuffix(s, "d"):
+		number = strings.TrimSuffix(s, "d")
+	case strings.HasSuffix(s, "w"):
+		number, unit = strings.TrimSuffix(s, "w"), 7
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid period length %q, expected days (30d) or weeks (4w)", s)
+	}
+	return n * unit, nil
+}
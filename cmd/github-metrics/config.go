@@ -0,0 +1,658 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"handshake/stats/metrics"
+)
+
+// defaultConfigFile is read when --config is not given.
+const defaultConfigFile = ".githubmetrics.yml"
+
+// defaultOptOutFile lists the users who opted out of individual reporting.
+const defaultOptOutFile = ".githubmetrics-optout"
+
+// legacyConfigFile is the --key=value file earlier versions read, converted
+// by 'config migrate'.
+const legacyConfigFile = ".githubmetrics"
+
+// Config is the schema of the YAML configuration file. Each setting has a
+// command-line flag of the same name, which takes precedence over the file.
+type Config struct {
+	Auth         AuthConfig          `yaml:"auth,omitempty"`
+	Window       WindowConfig        `yaml:"window,omitempty"`
+	Users        UsersConfig         `yaml:"users,omitempty"`
+	Repos        ReposConfig         `yaml:"repos,omitempty"`
+	Weights      WeightsConfig       `yaml:"weights,omitempty"`
+	Output       OutputConfig        `yaml:"output,omitempty"`
+	Notify       NotifyConfig        `yaml:"notify,omitempty"`
+	Email        EmailConfig         `yaml:"email,omitempty"`
+	Filters      FiltersConfig       `yaml:"filters,omitempty"`
+	Collection   CollectionConfig    `yaml:"collection,omitempty"`
+	Leaderboards []LeaderboardConfig `yaml:"leaderboards,omitempty"` // Named leaderboards besides the main one
+	Tenants      []TenantConfig      `yaml:"tenants,omitempty"`      // Departments collected by a single serve instance
+
+	base *Config // Settings of the file a tenant's are layered over
+}
+
+// TenantConfig is one of the departments a single serve instance collects
+// metrics for, on its own schedule and into its own outputs. Its settings
+// override those at the top of the file, with lists replacing rather than
+// adding to them.
+type TenantConfig struct {
+	Name     string `yaml:"name"`               // Served on /{name}/, outputs kept in --tenants-dir/{name}
+	Schedule string `yaml:"schedule,omitempty"` // Cron expression, see serve --schedule
+	Interval string `yaml:"interval,omitempty"` // Duration such as "24h", see serve --interval
+	Config   `yaml:",inline"`
+}
+
+// AuthConfig selects the GitHub instance and credentials.
+type AuthConfig struct {
+	Provider       string   `yaml:"provider,omitempty"` // github, gitlab or gitea
+	Token          string   `yaml:"token,omitempty"`
+	Tokens         []string `yaml:"tokens,omitempty"`     // Rotated among with token, see --token
+	TokenFile      string   `yaml:"token_file,omitempty"` // See --token-file
+	AppID          int64    `yaml:"app_id,omitempty"`
+	InstallationID int64    `yaml:"installation_id,omitempty"`
+	PrivateKey     string   `yaml:"private_key,omitempty"`
+	BaseURL        string   `yaml:"base_url,omitempty"`
+	UploadURL      string   `yaml:"upload_url,omitempty"`
+	IdentityFile   string   `yaml:"identity_file,omitempty"`
+
+	SkipPermissionCheck bool `yaml:"skip_permission_check,omitempty"` // See --skip-permission-check
+}
+
+// WindowConfig is the measured period.
+type WindowConfig struct {
+	Days  int    `yaml:"days,omitempty"`
+	Since string `yaml:"since,omitempty"` // YYYY-MM-DD, overrides days
+	Until string `yaml:"until,omitempty"` // YYYY-MM-DD
+}
+
+// UsersConfig lists who is measured.
+type UsersConfig struct {
+	Coders          []string `yaml:"coders,omitempty"`
+	Teams           []string `yaml:"teams,omitempty"`
+	AllOrgMembers   bool     `yaml:"all_org_members,omitempty"`
+	MemberRole      string   `yaml:"member_role,omitempty"`
+	MemberTeams     []string `yaml:"member_teams,omitempty"`
+	Exclude         []string `yaml:"exclude,omitempty"`
+	IncludeBots     bool     `yaml:"include_bots,omitempty"`
+	IncludeInactive bool     `yaml:"include_inactive,omitempty"`
+	InactiveAlert   int      `yaml:"inactive_alert,omitempty"` // Alert when at least this many users are inactive
+	OptOut          []string `yaml:"opt_out,omitempty"`        // Left out of individual reporting, still counted in team totals
+	OptOutFile      string   `yaml:"opt_out_file,omitempty"`
+}
+
+// ReposConfig lists or discovers the repositories measured.
+type ReposConfig struct {
+	Organization    string   `yaml:"organization,omitempty"`
+	Organizations   []string `yaml:"organizations,omitempty"`
+	Repos           []string `yaml:"repos,omitempty"`
+	Exclude         []string `yaml:"exclude,omitempty"`
+	Discovery       string   `yaml:"discovery,omitempty"`
+	IncludeArchived bool     `yaml:"include_archived,omitempty"`
+	IncludeForks    bool     `yaml:"include_forks,omitempty"`
+	Visibility      string   `yaml:"visibility,omitempty"`
+	Topics          []string `yaml:"topics,omitempty"`
+	Match           []string `yaml:"match,omitempty"`
+	ExcludeMatch    []string `yaml:"exclude_match,omitempty"`
+}
+
+// WeightsConfig selects how scores are computed and overrides the score
+// multipliers; unset weights keep the defaults of the scoring mode.
+type WeightsConfig struct {
+	Scoring string   `yaml:"scoring,omitempty"` // raw or percentile
+	Expr    string   `yaml:"expr,omitempty"`    // Replaces the weights, see --score-expr
+	HoC     *float64 `yaml:"hoc,omitempty"`
+	Pulls   *float64 `yaml:"pulls,omitempty"`
+	Issues  *float64 `yaml:"issues,omitempty"`
+	Commits *float64 `yaml:"commits,omitempty"`
+	Reviews *float64 `yaml:"reviews,omitempty"`
+	Msgs    *float64 `yaml:"msgs,omitempty"`
+	Reverts *float64 `yaml:"reverts,omitempty"`
+
+	Deletions *float64 `yaml:"deletions,omitempty"`
+	Churn     *float64 `yaml:"churn,omitempty"`
+	NetLines  *float64 `yaml:"net_lines,omitempty"`
+
+	ActiveDays  *float64 `yaml:"active_days,omitempty"`
+	IssuesFixed *float64 `yaml:"issues_fixed,omitempty"`
+	Planned     *float64 `yaml:"planned,omitempty"`
+	Docs        *float64 `yaml:"docs,omitempty"`
+}
+
+// LeaderboardConfig defines a named leaderboard ranked next to the main one.
+// Its weights default to 0, so only the metrics it lists count.
+type LeaderboardConfig struct {
+	Name          string `yaml:"name"`
+	WeightsConfig `yaml:",inline"`
+}
+
+// OutputConfig controls where results are written.
+type OutputConfig struct {
+	Format      string `yaml:"format,omitempty"`
+	File        string `yaml:"file,omitempty"`
+	Template    string `yaml:"template,omitempty"`
+	Charts      bool   `yaml:"charts,omitempty"`
+	GroupBy     string `yaml:"group_by,omitempty"`
+	Aggregation string `yaml:"aggregation,omitempty"` // mean, median, p90 or p95
+	ResultsFile string `yaml:"results_file,omitempty"`
+	Evidence    string `yaml:"evidence,omitempty"`
+	Store       string `yaml:"store,omitempty"`
+}
+
+// NotifyConfig selects where run summaries are posted.
+type NotifyConfig struct {
+	SlackWebhook string `yaml:"slack_webhook,omitempty"`
+	TeamsWebhook string `yaml:"teams_webhook,omitempty"`
+	Webhook      string `yaml:"webhook,omitempty"`
+}
+
+// EmailConfig sends the HTML report by email after each run.
+type EmailConfig struct {
+	To           []string `yaml:"to,omitempty"`
+	From         string   `yaml:"from,omitempty"`
+	SMTPHost     string   `yaml:"smtp_host,omitempty"`
+	SMTPPort     int      `yaml:"smtp_port,omitempty"`
+	SMTPUsername string   `yaml:"smtp_username,omitempty"`
+	SMTPPassword string   `yaml:"smtp_password,omitempty"`
+}
+
+// FiltersConfig narrows which files count towards HoC, which count as
+// documentation and which issues and pull requests count at all.
+type FiltersConfig struct {
+	ExcludePaths   []string `yaml:"exclude_paths,omitempty"`
+	Languages      []string `yaml:"languages,omitempty"`
+	CountGenerated bool     `yaml:"count_generated,omitempty"`
+	IssueLabels    []string `yaml:"issue_labels,omitempty"`
+	PullLabels     []string `yaml:"pr_labels,omitempty"`
+	DocsPaths      []string `yaml:"docs_paths,omitempty"`
+}
+
+// CollectionConfig tunes how metrics are collected.
+type CollectionConfig struct {
+	Metric            string `yaml:"metric,omitempty"`
+	API               string `yaml:"api,omitempty"`
+	Strategy          string `yaml:"strategy,omitempty"`
+	Concurrency       int    `yaml:"concurrency,omitempty"`
+	CommitConcurrency int    `yaml:"commit_concurrency,omitempty"`
+	HoCSource         string `yaml:"hoc_source,omitempty"` // commits or stats
+	SquashAttribution bool   `yaml:"squash_attribution,omitempty"`
+	CodeOwners        bool   `yaml:"codeowners,omitempty"`
+	Security          bool   `yaml:"security,omitempty"`
+	Tone              bool   `yaml:"tone,omitempty"`
+	Project           string `yaml:"project,omitempty"` // owner/number
+	Milestone         string `yaml:"milestone,omitempty"`
+	WorkingHours      string `yaml:"working_hours,omitempty"` // HH:MM-HH:MM
+	StaleAfter        string `yaml:"stale_after,omitempty"`   // Days (14d), weeks (2w) or 0
+	Timezone          string `yaml:"timezone,omitempty"`
+	Delay             int    `yaml:"delay,omitempty"`
+	MaxRetries        int    `yaml:"max_retries,omitempty"`
+	BackoffBase       string `yaml:"backoff_base,omitempty"` // Duration such as "2s"
+	BackoffMax        string `yaml:"backoff_max,omitempty"`  // Duration, 0s for no limit
+	ErrorMode         string `yaml:"error_mode,omitempty"`   // partial or fail
+	StatusFile        string `yaml:"status_file,omitempty"`
+	CacheDir          string `yaml:"cache_dir,omitempty"`
+	NoCache           bool   `yaml:"no_cache,omitempty"`
+	RecordFixtures    string `yaml:"record_fixtures,omitempty"`
+	ReplayFixtures    string `yaml:"replay_fixtures,omitempty"`
+	CheckpointFile    string `yaml:"checkpoint_file,omitempty"`
+	SnapshotFile      string `yaml:"snapshot_file,omitempty"`
+	LocalClones       string `yaml:"local_clones,omitempty"`
+	Verbose           bool   `yaml:"verbose,omitempty"`
+	Quiet             bool   `yaml:"quiet,omitempty"`
+}
+
+// LoadConfig reads a YAML configuration file. Unknown keys are rejected so
+// typos do not go unnoticed.
+func LoadConfig(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	cfg := &Config{}
+	dec := yaml.NewDecoder(file)
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Save writes the configuration as YAML. The file may hold a token, so it is
+// only readable by the owner.
+func (c *Config) Save(path string) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o600)
+}
+
+// flags returns the flag name and value of every setting in the file.
+func (c *Config) flags() [][2]string {
+	var flags [][2]string
+	str := func(name, value string) {
+		if value != "" {
+			flags = append(flags, [2]string{name, value})
+		}
+	}
+	num := func(name string, value int) {
+		if value != 0 {
+			str(name, strconv.Itoa(value))
+		}
+	}
+	boolean := func(name string, value bool) {
+		if value {
+			str(name, "true")
+		}
+	}
+	list := func(name string, values []string) {
+		for _, value := range values {
+			str(name, value)
+		}
+	}
+
+	str("provider", c.Auth.Provider)
+	str("token", c.Auth.Token)
+	list("token", c.Auth.Tokens)
+	str("token-file", c.Auth.TokenFile)
+	boolean("skip-permission-check", c.Auth.SkipPermissionCheck)
+	if c.Auth.AppID != 0 {
+		str("app-id", strconv.FormatInt(c.Auth.AppID, 10))
+	}
+	if c.Auth.InstallationID != 0 {
+		str("installation-id", strconv.FormatInt(c.Auth.InstallationID, 10))
+	}
+	str("private-key", c.Auth.PrivateKey)
+	str("base-url", c.Auth.BaseURL)
+	str("upload-url", c.Auth.UploadURL)
+	str("identity-file", c.Auth.IdentityFile)
+	num("days", c.Window.Days)
+	str("since", c.Window.Since)
+	str("until", c.Window.Until)
+	list("coder", c.Users.Coders)
+	list("team", c.Users.Teams)
+	boolean("all-org-members", c.Users.AllOrgMembers)
+	str("member-role", c.Users.MemberRole)
+	list("member-team", c.Users.MemberTeams)
+	list("exclude-user", c.Users.Exclude)
+	boolean("include-bots", c.Users.IncludeBots)
+	boolean("include-inactive", c.Users.IncludeInactive)
+	num("inactive-alert", c.Users.InactiveAlert)
+	list("opt-out", c.Users.OptOut)
+	str("opt-out-file", c.Users.OptOutFile)
+	str("organization", c.Repos.Organization)
+	list("organization", c.Repos.Organizations)
+	list("repo", c.Repos.Repos)
+	list("exclude-repo", c.Repos.Exclude)
+	str("repo-discovery", c.Repos.Discovery)
+	boolean("include-archived", c.Repos.IncludeArchived)
+	boolean("include-forks", c.Repos.IncludeForks)
+	str("visibility", c.Repos.Visibility)
+	list("repo-topic", c.Repos.Topics)
+	list("repo-match", c.Repos.Match)
+	list("repo-exclude-match", c.Repos.ExcludeMatch)
+	str("scoring", c.Weights.Scoring)
+	str("score-expr", c.Weights.Expr)
+	str("format", c.Output.Format)
+	str("output-file", c.Output.File)
+	str("template", c.Output.Template)
+	boolean("charts", c.Output.Charts)
+	str("group-by", c.Output.GroupBy)
+	str("aggregation", c.Output.Aggregation)
+	str("results-file", c.Output.ResultsFile)
+	str("evidence", c.Output.Evidence)
+	str("store", c.Output.Store)
+	str("slack-webhook", c.Notify.SlackWebhook)
+	str("teams-webhook", c.Notify.TeamsWebhook)
+	str("webhook", c.Notify.Webhook)
+	list("email-to", c.Email.To)
+	str("email-from", c.Email.From)
+	str("smtp-host", c.Email.SMTPHost)
+	num("smtp-port", c.Email.SMTPPort)
+	str("smtp-username", c.Email.SMTPUsername)
+	str("smtp-password", c.Email.SMTPPassword)
+	list("exclude-path", c.Filters.ExcludePaths)
+	list("language", c.Filters.Languages)
+	boolean("count-generated", c.Filters.CountGenerated)
+	list("issue-label", c.Filters.IssueLabels)
+	list("pr-label", c.Filters.PullLabels)
+	list("docs-path", c.Filters.DocsPaths)
+	str("metric", c.Collection.Metric)
+	str("api", c.Collection.API)
+	str("strategy", c.Collection.Strategy)
+	num("concurrency", c.Collection.Concurrency)
+	num("commit-concurrency", c.Collection.CommitConcurrency)
+	str("hoc-source", c.Collection.HoCSource)
+	boolean("squash-attribution", c.Collection.SquashAttribution)
+	boolean("codeowners", c.Collection.CodeOwners)
+	boolean("security", c.Collection.Security)
+	boolean("tone", c.Collection.Tone)
+	str("project", c.Collection.Project)
+	str("milestone", c.Collection.Milestone)
+	str("working-hours", c.Collection.WorkingHours)
+	str("stale-after", c.Collection.StaleAfter)
+	str("timezone", c.Collection.Timezone)
+	num("delay", c.Collection.Delay)
+	num("max-retries", c.Collection.MaxRetries)
+	str("backoff-base", c.Collection.BackoffBase)
+	str("backoff-max", c.Collection.BackoffMax)
+	str("error-mode", c.Collection.ErrorMode)
+	str("status-file", c.Collection.StatusFile)
+	str("cache-dir", c.Collection.CacheDir)
+	boolean("no-cache", c.Collection.NoCache)
+	str("record-fixtures", c.Collection.RecordFixtures)
+	str("replay-fixtures", c.Collection.ReplayFixtures)
+	str("checkpoint-file", c.Collection.CheckpointFile)
+	str("snapshot-file", c.Collection.SnapshotFile)
+	str("local-clones", c.Collection.LocalClones)
+	boolean("verbose", c.Collection.Verbose)
+	boolean("quiet", c.Collection.Quiet)
+	if c.base != nil {
+		set := make(map[string]bool)
+		for _, f := range flags {
+			set[f[0]] = true
+		}
+		var inherited [][2]string
+		for _, f := range c.base.flags() {
+			if !set[f[0]] {
+				inherited = append(inherited, f)
+			}
+		}
+		flags = append(inherited, flags...)
+	}
+	return flags
+}
+
+// apply sets the flags for every setting in the file.
+func (c *Config) apply(fs *flag.FlagSet) error {
+	for _, f := range c.flags() {
+		if err := fs.Set(f[0], f[1]); err != nil {
+			return fmt.Errorf("%s: %w", f[0], err)
+		}
+	}
+	return nil
+}
+
+// tenants returns the tenants of the file, each layered over the rest of it.
+func (c *Config) tenants() ([]TenantConfig, error) {
+	var tenants []TenantConfig
+	seen := make(map[string]bool)
+	for i, tenant := range c.Tenants {
+		switch {
+		case tenant.Name == "":
+			return nil, fmt.Errorf("tenant %d has no name", i+1)
+		case !validTenantName(tenant.Name):
+			return nil, fmt.Errorf("tenant %q: names may only use letters, digits, - and _", tenant.Name)
+		case seen[strings.ToLower(tenant.Name)]:
+			return nil, fmt.Errorf("tenant %q is defined twice", tenant.Name)
+		case len(tenant.Tenants) > 0:
+			return nil, fmt.Errorf("tenant %q: tenants cannot have tenants", tenant.Name)
+		case tenant.Schedule != "" && tenant.Interval != "":
+			return nil, fmt.Errorf("tenant %q: schedule and interval cannot be combined", tenant.Name)
+		}
+		seen[strings.ToLower(tenant.Name)] = true
+		tenant.base = c
+		tenants = append(tenants, tenant)
+	}
+	return tenants, nil
+}
+
+// validTenantName reports whether a tenant name only uses letters, digits,
+// - and _, so it can name a URL path and a directory.
+func validTenantName(name string) bool {
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// scoreWeights returns the defaults with the weights of the file, layered
+// over those of its base.
+func (c *Config) scoreWeights(defaults metrics.Weights) metrics.Weights {
+	if c.base != nil {
+		defaults = c.base.scoreWeights(defaults)
+	}
+	return c.Weights.scoreWeights(defaults)
+}
+
+// leaderboards returns the named leaderboards of the file, or those of its
+// base when it has none.
+func (c *Config) leaderboards() ([]metrics.Leaderboard, error) {
+	if len(c.Leaderboards) == 0 && c.base != nil {
+		return c.base.leaderboards()
+	}
+	var boards []metrics.Leaderboard
+	seen := make(map[string]bool)
+	for i, board := range c.Leaderboards {
+		if board.Name == "" {
+			return nil, fmt.Errorf("leaderboard %d has no name", i+1)
+		}
+		if seen[board.Name] {
+			return nil, fmt.Errorf("leaderboard %q is defined twice", board.Name)
+		}
+		seen[board.Name] = true
+
+		var scorer metrics.Scorer
+		weights := board.scoreWeights(metrics.Weights{})
+		switch {
+		case board.Expr != "":
+			if board.Scoring == metrics.ScoringPercentile {
+				return nil, fmt.Errorf("leaderboard %q: expr cannot be combined with percentile scoring", board.Name)
+			}
+			expr, err := metrics.NewExprScorer(board.Expr)
+			if err != nil {
+				return nil, fmt.Errorf("leaderboard %q: %w", board.Name, err)
+			}
+			scorer = expr
+		case weights == metrics.Weights{}:
+			return nil, fmt.Errorf("leaderboard %q has neither weights nor expr", board.Name)
+		case board.Scoring == metrics.ScoringPercentile:
+			scorer = metrics.PercentileScorer{Weights: weights}
+		case board.Scoring == "" || board.Scoring == metrics.ScoringRaw:
+			scorer = metrics.WeightedScorer{Weights: weights}
+		default:
+			return nil, fmt.Errorf("leaderboard %q: unknown scoring %q, expected raw or percentile", board.Name, board.Scoring)
+		}
+		boards = append(boards, metrics.Leaderboard{Name: board.Name, Scorer: scorer})
+	}
+	return boards, nil
+}
+
+// scoreWeights returns the defaults with the configured overrides.
+func (c WeightsConfig) scoreWeights(defaults metrics.Weights) metrics.Weights {
+	w := defaults
+	for _, o := range []struct {
+		value  *float64
+		weight *float64
+	}{
+		{c.HoC, &w.HoC},
+		{c.Pulls, &w.Pulls},
+		{c.Issues, &w.Issues},
+		{c.Commits, &w.Commits},
+		{c.Reviews, &w.Reviews},
+		{c.Msgs, &w.Msgs},
+		{c.Reverts, &w.Reverts},
+		{c.Deletions, &w.Deletions},
+		{c.Churn, &w.Churn},
+		{c.NetLines, &w.NetLines},
+		{c.ActiveDays, &w.ActiveDays},
+		{c.IssuesFixed, &w.IssuesFixed},
+		{c.Planned, &w.Planned},
+		{c.Docs, &w.Docs},
+	} {
+		if o.value != nil {
+			*o.weight = *o.value
+		}
+	}
+	return w
+}
+
+// readLegacyConfig converts a --key=value file into a Config. Unknown keys
+// are reported and skipped.
+func readLegacyConfig(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	cfg := &Config{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if err := cfg.setLegacy(key, value); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, key, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// setLegacy sets the setting named by a legacy --key.
+func (c *Config) setLegacy(key, value string) error {
+	var err error
+	parseBool := func(b *bool) {
+		*b, err = strconv.ParseBool(value)
+	}
+	parseInt := func(n *int) {
+		*n, err = strconv.Atoi(value)
+	}
+
+	switch key {
+	case "--token":
+		c.Auth.Token = value
+	case "--base-url":
+		c.Auth.BaseURL = value
+	case "--upload-url":
+		c.Auth.UploadURL = value
+	case "--identity-file":
+		c.Auth.IdentityFile = value
+	case "--days":
+		parseInt(&c.Window.Days)
+	case "--coder":
+		c.Users.Coders = append(c.Users.Coders, value)
+	case "--team":
+		c.Users.Teams = append(c.Users.Teams, value)
+	case "--all-org-members":
+		parseBool(&c.Users.AllOrgMembers)
+	case "--member-role":
+		c.Users.MemberRole = value
+	case "--member-team":
+		c.Users.MemberTeams = append(c.Users.MemberTeams, value)
+	case "--exclude-user":
+		c.Users.Exclude = append(c.Users.Exclude, value)
+	case "--include-bots":
+		parseBool(&c.Users.IncludeBots)
+	case "--organization":
+		if c.Repos.Organization == "" {
+			c.Repos.Organization = value
+		} else {
+			c.Repos.Organizations = append(c.Repos.Organizations, value)
+		}
+	case "--repo":
+		c.Repos.Repos = append(c.Repos.Repos, value)
+	case "--exclude-repo":
+		c.Repos.Exclude = append(c.Repos.Exclude, value)
+	case "--repo-discovery":
+		c.Repos.Discovery = value
+	case "--include-archived":
+		parseBool(&c.Repos.IncludeArchived)
+	case "--include-forks":
+		parseBool(&c.Repos.IncludeForks)
+	case "--visibility":
+		c.Repos.Visibility = value
+	case "--repo-topic":
+		c.Repos.Topics = append(c.Repos.Topics, value)
+	case "--repo-match":
+		c.Repos.Match = append(c.Repos.Match, value)
+	case "--repo-exclude-match":
+		c.Repos.ExcludeMatch = append(c.Repos.ExcludeMatch, value)
+	case "--format":
+		c.Output.Format = value
+	case "--output-file":
+		c.Output.File = value
+	case "--results-file":
+		c.Output.ResultsFile = value
+	case "--store":
+		c.Output.Store = value
+	case "--exclude-path":
+		c.Filters.ExcludePaths = append(c.Filters.ExcludePaths, value)
+	case "--language":
+		c.Filters.Languages = append(c.Filters.Languages, value)
+	case "--metric":
+		c.Collection.Metric = value
+	case "--api":
+		c.Collection.API = value
+	case "--concurrency":
+		parseInt(&c.Collection.Concurrency)
+	case "--delay":
+		parseInt(&c.Collection.Delay)
+	case "--cache-dir":
+		c.Collection.CacheDir = value
+	case "--no-cache":
+		parseBool(&c.Collection.NoCache)
+	case "--checkpoint-file":
+		c.Collection.CheckpointFile = value
+	case "--verbose":
+		parseBool(&c.Collection.Verbose)
+	case "--quiet":
+		parseBool(&c.Collection.Quiet)
+	case "--resume":
+		// Resuming is decided per run, not configured.
+	default:
+		log.Printf("Skipping unknown setting %s\n", key)
+	}
+	return err
+}
+
+// runConfig implements the config subcommand.
+func runConfig(args []string) {
+	if len(args) == 0 || args[0] != "migrate" {
+		fmt.Fprintln(os.Stderr, "Usage: github-metrics config migrate [--from .githubmetrics] [--to .githubmetrics.yml] [--force]")
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("config migrate", flag.ExitOnError)
+	from := fs.String("from", legacyConfigFile, "Legacy --key=value configuration file to convert")
+	to := fs.String("to", defaultConfigFile, "YAML configuration file to write")
+	force := fs.Bool("force", false, "Overwrite the YAML file if it exists")
+	fs.Parse(args[1:])
+
+	if _, err := os.Stat(*to); err == nil && !*force {
+		log.Fatalf("%s already exists; pass --force to overwrite it", *to)
+	}
+	cfg, err := readLegacyConfig(*from)
+	if err != nil {
+		log.Fatalf("Error reading legacy configuration: %v", err)
+	}
+	if err := cfg.Save(*to); err != nil {
+		log.Fatalf("Error writing configuration: %v", err)
+	}
+	fmt.Printf("Wrote %s from %s\n", *to, *from)
+}
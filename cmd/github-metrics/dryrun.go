@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+
+	"handshake/stats/metrics"
+)
+
+// estimate implements --dry-run: it resolves the users and their
+// repositories, which takes a few discovery requests, and prints the
+// projected API usage of collecting them without collecting anything.
+func (o *collectOptions) estimate(ctx context.Context) error {
+	run, err := o.prepare(ctx)
+	if err != nil {
+		return err
+	}
+	repos := make(map[string][]string)
+	for _, user := range run.coders {
+		repos[user], err = run.collector.Repositories(ctx, user)
+		if err != nil {
+			return err
+		}
+		if o.verbose {
+			log.Printf("User %s has %d repositories\n", user, len(repos[user]))
+		}
+	}
+
+	api := o.api
+	if o.evidence != "" {
+		// Evidence is always collected through the REST API.
+		api = "rest"
+	}
+	estimate := metrics.EstimateRequests(repos, o.metric, api, o.strategy)
+	limits, _, err := run.rest.Client.RateLimits(ctx)
+	if err != nil && o.verbose {
+		log.Printf("Could not check rate limits: %v\n", err)
+	}
+
+	fmt.Printf("Dry run: %d users, %d repositories, %d tasks (%d discovery requests made)\n\n",
+		estimate.Users, estimate.Repos, estimate.Tasks, run.rest.APICalls())
+	fmt.Printf("%-12s %8s %8s %8s\n", "METRIC", "CORE", "SEARCH", "GRAPHQL")
+	for _, m := range estimate.Metrics {
+		fmt.Printf("%-12s %8d %8d %8d\n", m.Metric, m.Requests.Core, m.Requests.Search, m.Requests.GraphQL)
+	}
+	fmt.Printf("%-12s %8d %8d %8d\n\n", "total", estimate.Total.Core, estimate.Total.Search, estimate.Total.GraphQL)
+
+	fmt.Printf("%-8s %10s %10s %8s  %s\n", "QUOTA", "ESTIMATED", "REMAINING", "LIMIT", "RESETS")
+	for _, q := range []struct {
+		name     string
+		requests int
+		rate     *github.Rate
+	}{
+		{"core", estimate.Total.Core, limits.GetCore()},
+		{"search", estimate.Total.Search, limits.GetSearch()},
+		{"graphql", estimate.Total.GraphQL, limits.GetGraphQL()},
+	} {
+		if q.rate == nil || q.rate.Limit == 0 {
+			fmt.Printf("%-8s %10d %10s %8s  %s\n", q.name, q.requests, "-", "-", "-")
+			continue
+		}
+		fmt.Printf("%-8s %10d %10d %8d  %s\n", q.name, q.requests, q.rate.Remaining, q.rate.Limit, q.rate.Reset.Local().Format(time.Kitchen))
+	}
+
+	fmt.Printf("\nEstimated duration: %s with --concurrency %d\n", estimate.Duration(o.concurrency, limits).Round(time.Second), o.concurrency)
+	fmt.Println("These count the first page of every listing and search. HoC commit details (unless cached), pull request sizes and reviews, and review timings add core requests per item, so busy users cost more.")
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"handshake/stats/metrics"
+)
+
+// runExport implements the export subcommand, which collects like collect
+// but saves the commits, pull requests, issues and reviews it fetched
+// instead of a report, for analysis elsewhere.
+func runExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	o := &collectOptions{}
+	o.register(fs)
+	raw := fs.String("raw", "raw.json.gz", "Path to write the fetched data to as JSON, compressed with gzip when it ends in .gz")
+	o.parse(fs, args)
+
+	if o.provider != metrics.ProviderGitHub {
+		log.Fatal("export only supports --provider github.")
+	}
+	if o.api != "rest" {
+		log.Fatal("export fetches through the REST API and does not support --api graphql.")
+	}
+	if o.offline || o.dryRun || o.resume {
+		log.Fatal("export cannot be combined with --offline, --dry-run or --resume.")
+	}
+	o.raw = metrics.NewRawRecorder()
+
+	started := time.Now()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	results, err := o.collect(ctx, nil)
+	if ctx.Err() != nil {
+		o.finish(started, context.Canceled)
+	}
+	if err != nil {
+		o.finish(started, fmt.Errorf("calculating metrics: %w", err))
+	}
+
+	data := o.raw.Data()
+	data.Since, data.Until = results.Since, results.Until
+	if err := data.Save(*raw); err != nil {
+		o.finish(started, fmt.Errorf("saving raw data: %w", err))
+	}
+	log.Printf("Exported %d commits, %d pull requests, %d issues and %d reviews to %s\n",
+		len(data.Commits), len(data.PullRequests), len(data.Issues), len(data.Reviews), *raw)
+	o.finish(started, nil)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"handshake/stats/metrics"
+)
+
+const historyUsage = `Usage: github-metrics history <command> [flags]
+
+Commands:
+  list                 List stored snapshots
+  prune                Delete all but the newest --keep snapshots
+  purge                Delete the snapshots taken more than --older-than ago
+  delete-user <login>  Delete a user's metrics from every snapshot
+`
+
+// runHistory implements the history subcommand, which inspects and prunes
+// the snapshots kept in a --store and erases them for data retention.
+func runHistory(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, historyUsage)
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("history "+args[0], flag.ExitOnError)
+	storeURI := fs.String("store", "sqlite://metrics.db", "History store, e.g. sqlite://metrics.db")
+	keep := fs.Int("keep", 10, "Number of newest snapshots to keep when pruning")
+	olderThan := fs.String("older-than", "", "Age of the snapshots to purge, in days (365d) or weeks (52w)")
+	fs.Parse(args[1:])
+
+	var before time.Time
+	switch args[0] {
+	case "purge":
+		if *olderThan == "" {
+			log.Fatal("history purge requires --older-than, e.g. --older-than 365d.")
+		}
+		days, err := parsePeriodLength(*olderThan)
+		if err != nil {
+			log.Fatalf("Invalid --older-than: %v", err)
+		}
+		before = time.Now().AddDate(0, 0, -days)
+	case "delete-user":
+		if fs.NArg() != 1 {
+			log.Fatal("history delete-user requires exactly one login, e.g. history delete-user alice.")
+		}
+	}
+
+	store, err := metrics.OpenStore(*storeURI)
+	if err != nil {
+		log.Fatalf("Error opening store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	switch args[0] {
+	case "list":
+		snapshots, err := store.Snapshots(ctx)
+		if err != nil {
+			log.Fatalf("Error listing snapshots: %v", err)
+		}
+		fmt.Printf("%-6s %-20s %-11s %-8s %s\n", "ID", "TAKEN", "SINCE", "METRIC", "USERS")
+		for _, s := range snapshots {
+			fmt.Printf("%-6d %-20s %-11s %-8s %d\n", s.ID, s.TakenAt.Local().Format("2006-01-02 15:04:05"), s.Since.Format("2006-01-02"), s.Metric, s.Users)
+		}
+	case "prune":
+		n, err := store.Prune(ctx, *keep)
+		if err != nil {
+			log.Fatalf("Error pruning snapshots: %v", err)
+		}
+		fmt.Printf("Deleted %d snapshots\n", n)
+	case "purge":
+		n, err := store.Purge(ctx, before)
+		if err != nil {
+			log.Fatalf("Error purging snapshots: %v", err)
+		}
+		fmt.Printf("Deleted %d snapshots taken before %s\n", n, before.Local().Format("2006-01-02 15:04:05"))
+	case "delete-user":
+		n, err := store.DeleteUser(ctx, fs.Arg(0))
+		if err != nil {
+			log.Fatalf("Error deleting user: %v", err)
+		}
+		fmt.Printf("Deleted the metrics of %s from %d snapshots\n", fs.Arg(0), n)
+	default:
+		fmt.Fprint(os.Stderr, historyUsage)
+		os.Exit(2)
+	}
+}
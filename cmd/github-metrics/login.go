@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/oauth2"
+
+	"handshake/stats/metrics"
+)
+
+// keyringService names the OS keychain entries holding login tokens. Entries
+// are keyed by the web host, so github.com and each GitHub Enterprise Server
+// keep their own token.
+const keyringService = "github-metrics"
+
+// runLogin implements the login subcommand, which runs the OAuth device flow
+// and stores the token in the OS keychain for later runs.
+func runLogin(args []string) {
+	fs := flag.NewFlagSet("login", flag.ExitOnError)
+	clientID := fs.String("client-id", os.Getenv("GITHUB_METRICS_CLIENT_ID"), "Client ID of the OAuth app to log in with (defaults to GITHUB_METRICS_CLIENT_ID)")
+	baseURL := fs.String("base-url", "", "GitHub Enterprise Server API URL, e.g. https://github.example.com/api/v3/")
+	scopes := fs.String("scopes", "repo,read:org", "Comma-separated OAuth scopes to request")
+	logout := fs.Bool("logout", false, "Remove the stored token instead of logging in")
+	fs.Parse(args)
+
+	host := webHost(*baseURL)
+	if *logout {
+		if err := keyring.Delete(keyringService, host); err != nil {
+			log.Fatalf("Error removing token for %s: %v", host, err)
+		}
+		fmt.Printf("Logged out of %s\n", host)
+		return
+	}
+	if *clientID == "" {
+		log.Fatal("--client-id is required: create an OAuth app with device flow enabled and pass its client ID.")
+	}
+
+	web := metrics.WebURL(*baseURL)
+	config := &oauth2.Config{
+		ClientID: *clientID,
+		Scopes:   strings.Split(*scopes, ","),
+		Endpoint: oauth2.Endpoint{
+			AuthURL:       web + "/login/oauth/authorize",
+			TokenURL:      web + "/login/oauth/access_token",
+			DeviceAuthURL: web + "/login/device/code",
+		},
+	}
+
+	ctx := context.Background()
+	auth, err := config.DeviceAuth(ctx)
+	if err != nil {
+		log.Fatalf("Error starting device login: %v", err)
+	}
+	fmt.Printf("Open %s and enter the code %s\n", auth.VerificationURI, auth.UserCode)
+
+	token, err := config.DeviceAccessToken(ctx, auth)
+	if err != nil {
+		log.Fatalf("Error completing device login: %v", err)
+	}
+	if err := keyring.Set(keyringService, host, token.AccessToken); err != nil {
+		log.Fatalf("Error storing token in the keychain: %v", err)
+	}
+	fmt.Printf("Logged in to %s; the token is stored in the OS keychain\n", host)
+}
+
+// keyringToken returns the token stored by login for the host of baseURL.
+func keyringToken(baseURL string) (string, bool) {
+	token, err := keyring.Get(keyringService, webHost(baseURL))
+	if err != nil || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+// webHost returns the host of the web UI for an API base URL.
+func webHost(baseURL string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(metrics.WebURL(baseURL), "https://"), "http://")
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"handshake/stats/metrics"
+)
+
+const usage = `Usage: github-metrics [command] [flags]
+
+Commands:
+  collect   Collect metrics from GitHub and render them (default)
+  render    Render previously collected results
+  export    Collect from GitHub and save the fetched commits, pull requests, issues and reviews
+  serve     Serve rendered results over HTTP
+  compare   Render results with the changes against earlier results
+  history   List and prune snapshots in a history store
+  login     Log in with the OAuth device flow and keep the token in the OS keychain
+  config    Convert a legacy .githubmetrics file to YAML (config migrate)
+  schema    Print the JSON Schema of the json output format
+
+Run 'github-metrics <command> -h' for the flags of a command.
+`
+
+func main() {
+	args := os.Args[1:]
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		runCollect(args)
+		return
+	}
+
+	switch args[0] {
+	case "collect":
+		runCollect(args[1:])
+	case "render":
+		runRender(args[1:])
+	case "export":
+		runExport(args[1:])
+	case "serve":
+		runServe(args[1:])
+	case "compare":
+		runCompare(args[1:])
+	case "history":
+		runHistory(args[1:])
+	case "login":
+		runLogin(args[1:])
+	case "config":
+		runConfig(args[1:])
+	case "schema":
+		os.Stdout.Write(metrics.JSONSchema)
+	case "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n%s", args[0], usage)
+		os.Exit(2)
+	}
+}
+
+// newRenderer returns the renderer for the output format along with the
+// default file extension for it. templatePath overrides the built-in HTML
+// template when set, and charts draws charts in place of the HTML leaderboard.
+func newRenderer(format, templatePath string, charts bool) (metrics.Renderer, string, error) {
+	switch format {
+	case "html":
+		return metrics.HTMLRenderer{TemplatePath: templatePath, Charts: charts}, "html", nil
+	case "csv":
+		return metrics.CSVRenderer{}, "csv", nil
+	case "markdown":
+		return metrics.MarkdownRenderer{}, "md", nil
+	case "json":
+		return metrics.JSONRenderer{}, "json", nil
+	default:
+		return nil, "", fmt.Errorf("unknown output format: %s", format)
+	}
+}
+
+// isFlagSet reports whether the named flag was set on the command line or in
+// the metrics file.
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// isTerminal reports whether f is a character device such as a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
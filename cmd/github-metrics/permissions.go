@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/go-github/v50/github"
+
+	"handshake/stats/metrics"
+)
+
+// checkAccess verifies before collecting that the credentials can read what
+// the selected metrics need, and fails with the permissions they lack
+// instead of collecting metrics that would count zero. With several tokens
+// each is checked, as any of them may fetch any repository, and those GitHub
+// rejects are left to the pool to drop unless all of them are. A check that
+// cannot be made, e.g. on an older GitHub Enterprise Server, is logged and
+// the run goes on.
+func (o *collectOptions) checkAccess(ctx context.Context) error {
+	access := metrics.Access{
+		Repos:    o.repos,
+		Orgs:     o.orgs,
+		Teams:    o.teams,
+		Metric:   o.metric,
+		Security: o.security,
+		Plan:     o.plan,
+	}
+	if o.allMembers {
+		access.Members = o.orgs
+	}
+	names, clients, err := o.accessClients(ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		missing  []string
+		rejected int
+	)
+	for i, client := range clients {
+		prefix := ""
+		if len(clients) > 1 {
+			prefix = names[i] + ": "
+		}
+		found, err := metrics.CheckAccess(ctx, metrics.NewGitHubAPI(client), access)
+		if metrics.IsAuthError(err) {
+			if rejected++; rejected < len(clients) {
+				log.Printf("%srejected as bad credentials\n", prefix)
+				continue
+			}
+			return fmt.Errorf("%schecking permissions: %w", prefix, err)
+		}
+		if err != nil {
+			log.Printf("%sCould not check permissions: %v\n", prefix, err)
+			continue
+		}
+		for _, m := range found {
+			missing = append(missing, "  - "+prefix+m.String())
+		}
+	}
+	if len(missing) == 0 {
+		if o.verbose {
+			log.Printf("Permissions checked: the credentials can read what the selected metrics need\n")
+		}
+		return nil
+	}
+	return fmt.Errorf("the credentials lack permissions the selected metrics need:\n%s\nGrant them to the token or GitHub App and run again, or pass --skip-permission-check to collect anyway", strings.Join(missing, "\n"))
+}
+
+// accessClients returns a client for each set of credentials of the run,
+// named by the last characters of the token, without the caches so that
+// every check reaches GitHub.
+func (o *collectOptions) accessClients(ctx context.Context) ([]string, []*github.Client, error) {
+	if o.appID != 0 {
+		client, err := o.appClient(ctx)
+		return []string{"GitHub App"}, []*github.Client{client}, err
+	}
+	tokens, err := o.githubTokens()
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		names   []string
+		clients []*github.Client
+	)
+	for _, token := range tokens {
+		client, err := metrics.NewGitHubClient(ctx, token, o.baseURL, o.uploadURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		names = append(names, "token "+metrics.MaskToken(token))
+		clients = append(clients, client)
+	}
+	return names, clients, nil
+}
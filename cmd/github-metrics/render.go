@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"handshake/stats/metrics"
+)
+
+// runRender implements the render subcommand, which renders results saved by
+// collect in any output format without collecting again.
+func runRender(args []string) {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	input := fs.String("input", "metrics-results.json", "Path to the results saved by collect")
+	format := fs.String("format", "html", "Output format (html, csv, markdown, json)")
+	output := fs.String("output-file", "metrics.html", "Path to the output file")
+	templatePath := fs.String("template", "", "Custom HTML template file (defaults to the built-in template)")
+	charts := fs.Bool("charts", false, "Draw bar charts, and the score over time when saved with --store, instead of the HTML leaderboard table")
+	aggregation := fs.String("aggregation", "", "How pull request lifecycles are aggregated into LcP: mean, median, p90 or p95 (defaults to the one saved with the results)")
+	optOutFile := fs.String("opt-out-file", defaultOptOutFile, "File listing users who opted out of individual reporting, added to those saved with the results; read when it exists")
+	fs.Parse(args)
+
+	renderer, ext, err := newRenderer(*format, *templatePath, *charts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !isFlagSet(fs, "output-file") {
+		*output = "metrics." + ext
+	}
+
+	results, err := metrics.LoadResults(*input)
+	if err != nil {
+		log.Fatalf("Error loading results: %v", err)
+	}
+
+	optOut, err := readOptOut(*optOutFile, isFlagSet(fs, "opt-out-file"))
+	if err != nil {
+		log.Fatalf("Error reading --opt-out-file: %v", err)
+	}
+	results.OptOut = append(results.OptOut, optOut...)
+
+	if *aggregation != "" {
+		if _, err := metrics.ParseAggregation(*aggregation); err != nil {
+			log.Fatalf("Invalid --aggregation: %v", err)
+		}
+		results.Aggregation = *aggregation
+	}
+	report := metrics.NewReport(results.Users, results.ViewOptions())
+	if err := metrics.RenderFile(context.Background(), renderer, *output, report); err != nil {
+		log.Fatalf("Error rendering report: %v", err)
+	}
+}
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"handshake/stats/metrics"
+	"handshake/stats/metricspb"
+)
+
+// serveOptions holds the flags of the serve subcommand besides those it
+// shares with collect.
+type serveOptions struct {
+	listen      string
+	interval    time.Duration
+	schedule    string
+	keepReports int
+	reportsDir  string
+	input       string
+	receive     bool // --receive-webhooks
+	secret      string
+	grpcListen  string
+	tenantsDir  string
+}
+
+// newServeFlags returns the flags of the serve subcommand.
+func newServeFlags() (*flag.FlagSet, *serveOptions, *collectOptions) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	s := &serveOptions{}
+	fs.StringVar(&s.listen, "listen", ":8080", "Address to listen on")
+	fs.DurationVar(&s.interval, "interval", 0, "Collect metrics on this interval, e.g. 24h; without it the --input file is served")
+	fs.StringVar(&s.schedule, "schedule", "", "Collect metrics on a cron schedule in local time, e.g. \"0 6 * * MON\"")
+	fs.IntVar(&s.keepReports, "keep-reports", 10, "Number of reports of --schedule runs kept and served on /reports/; 0 keeps none")
+	fs.StringVar(&s.reportsDir, "reports-dir", "reports", "Directory the reports of --schedule runs are kept in")
+	fs.StringVar(&s.input, "input", "metrics-results.json", "Path to the results saved by collect")
+	fs.BoolVar(&s.receive, "receive-webhooks", false, "Receive GitHub webhook events on /webhook and add them to the latest snapshot in --store instead of collecting")
+	fs.StringVar(&s.secret, "webhook-secret", "", "Secret the webhook payloads are signed with (defaults to GITHUB_WEBHOOK_SECRET)")
+	fs.StringVar(&s.grpcListen, "grpc-listen", "", "Also answer queries about the snapshots in --store over gRPC on this address, e.g. :9090")
+	fs.StringVar(&s.tenantsDir, "tenants-dir", "tenants", "Directory the outputs of the tenants of the configuration file are kept in, one directory per tenant")
+	o := &collectOptions{}
+	o.register(fs)
+	return fs, s, o
+}
+
+// runServe implements the serve subcommand. With --interval or --schedule it
+// collects metrics on that schedule and serves the latest results, keeping
+// the reports of scheduled runs at dated URLs; with --receive-webhooks it
+// keeps the latest stored snapshot up to date from GitHub webhook events;
+// otherwise it serves the results file written by collect, re-reading it on
+// every request. With --store, the stored snapshots are also queried on
+// /api/v1/ and, with --grpc-listen, over gRPC. When the configuration file
+// has tenants, each of them is collected and served on its own instead.
+func runServe(args []string) {
+	fs, s, o := newServeFlags()
+	fs.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if s.interval > 0 && s.schedule != "" {
+		log.Fatal("--interval and --schedule cannot be combined.")
+	}
+	cfg := o.loadConfig(fs)
+	if cfg != nil && len(cfg.Tenants) > 0 {
+		if s.receive || s.grpcListen != "" {
+			log.Fatal("--receive-webhooks and --grpc-listen cannot be combined with the tenants of the configuration file.")
+		}
+		serveTenants(ctx, args, s, cfg)
+		return
+	}
+	if s.receive {
+		if s.interval > 0 || s.schedule != "" {
+			log.Fatal("--receive-webhooks cannot be combined with --interval or --schedule.")
+		}
+		o.parse(fs, args)
+		if s.secret == "" {
+			s.secret = os.Getenv("GITHUB_WEBHOOK_SECRET")
+		}
+		routes := make(map[string]http.Handler)
+		defer serveAPI(ctx, o, s.grpcListen, routes)()
+		serveWebhook(ctx, s.listen, o, s.secret, routes)
+		return
+	}
+	routes := make(map[string]http.Handler)
+	if s.interval <= 0 && s.schedule == "" {
+		// Serving the results file needs no repositories, so the
+		// configuration is applied without the checks of a collection.
+		o.applyConfig(fs, args, cfg)
+		optOut, err := readOptOut(o.optOutFile, isFlagSet(fs, "opt-out-file"))
+		if err != nil {
+			log.Fatalf("Error reading --opt-out-file: %v", err)
+		}
+		for _, user := range optOut {
+			o.optOut.Set(user)
+		}
+		defer serveAPI(ctx, o, s.grpcListen, routes)()
+		serveDashboard(ctx, s.listen, o.template, o.charts, func() (*metrics.Results, error) {
+			return metrics.LoadResults(s.input)
+		}, routes)
+		return
+	}
+	o.parse(fs, args)
+	defer serveAPI(ctx, o, s.grpcListen, routes)()
+
+	next, err := nextRun(s.interval, s.schedule)
+	if err != nil {
+		log.Fatalf("Invalid --schedule: %v", err)
+	}
+	archive := s.archive(o)
+	latest := collectOnSchedule(ctx, o, next, s.schedule != "", archive, "")
+	if archive != nil {
+		routes["/reports/"] = archive
+	}
+	serveDashboard(ctx, s.listen, o.template, o.charts, latest, routes)
+}
+
+// nextRun returns when the run after a time is due on the interval or the
+// cron schedule.
+func nextRun(interval time.Duration, schedule string) (func(time.Time) time.Time, error) {
+	if schedule == "" {
+		return func(t time.Time) time.Time { return t.Add(interval) }, nil
+	}
+	s, err := metrics.ParseSchedule(schedule)
+	if err != nil {
+		return nil, err
+	}
+	if s.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("%q never runs", schedule)
+	}
+	return s.Next, nil
+}
+
+// archive returns the archive the reports of --schedule runs are kept in,
+// or nil when they are not kept.
+func (s *serveOptions) archive(o *collectOptions) *metrics.ReportArchive {
+	if s.schedule == "" {
+		return nil
+	}
+	if s.keepReports < 0 {
+		log.Fatal("--keep-reports must not be negative.")
+	}
+	if s.keepReports == 0 {
+		return nil
+	}
+	renderer, _, err := newRenderer("html", o.template, o.charts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return &metrics.ReportArchive{Dir: s.reportsDir, Keep: s.keepReports, Renderer: renderer}
+}
+
+// collectOnSchedule collects metrics whenever next says a run is due until
+// ctx is cancelled, saving, archiving and notifying each run, and returns a
+// function returning the latest results. With wait, the first run waits for
+// its time rather than starting right away. The runs of a tenant are logged
+// with its name.
+func collectOnSchedule(ctx context.Context, o *collectOptions, next func(time.Time) time.Time, wait bool, archive *metrics.ReportArchive, tenant string) func() (*metrics.Results, error) {
+	prefix, base := "", ""
+	if tenant != "" {
+		prefix, base = "["+tenant+"] ", "/"+tenant
+	}
+	var (
+		mu     sync.RWMutex
+		latest *metrics.Results
+	)
+	if results, err := metrics.LoadResults(o.resultsFile); err == nil {
+		latest = results
+	} else if archive != nil {
+		if latest, err = archive.Latest(); err != nil {
+			log.Printf("%sError loading the latest report: %v", prefix, err)
+		}
+	}
+
+	go func() {
+		for {
+			if wait {
+				at := next(time.Now())
+				log.Printf("%sNext run at %s\n", prefix, at.Format("2006-01-02 15:04"))
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Until(at)):
+				}
+			}
+			wait = true
+			log.Printf("%sCollecting metrics\n", prefix)
+			results, err := o.collect(ctx, nil)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Printf("%sError calculating metrics: %v", prefix, err)
+				continue
+			}
+			mu.Lock()
+			latest = results
+			mu.Unlock()
+			if o.resultsFile != "" {
+				if err := results.Save(o.resultsFile); err != nil {
+					log.Printf("%sError saving results: %v", prefix, err)
+				}
+			}
+			if archive != nil {
+				if date, err := archive.Add(results); err != nil {
+					log.Printf("%sError keeping report: %v", prefix, err)
+				} else {
+					log.Printf("%sKept report at %s/reports/%s/\n", prefix, base, date)
+				}
+			}
+			o.notify(ctx, results)
+			log.Printf("%sCollected metrics for %d users\n", prefix, len(results.Users))
+		}
+	}()
+
+	return func() (*metrics.Results, error) {
+		mu.RLock()
+		defer mu.RUnlock()
+		return latest, nil
+	}
+}
+
+// serveAPI adds the REST API on the snapshots in --store to routes and, with
+// grpcListen, serves it over gRPC until ctx is cancelled. It returns a
+// function closing the store.
+func serveAPI(ctx context.Context, o *collectOptions, grpcListen string, routes map[string]http.Handler) func() {
+	api := storeAPI(o)
+	if api == nil {
+		if grpcListen != "" {
+			log.Fatal("--grpc-listen requires --store to answer from.")
+		}
+		return func() {}
+	}
+	routes["/api/v1/"] = api
+	if grpcListen != "" {
+		listener, err := net.Listen("tcp", grpcListen)
+		if err != nil {
+			log.Fatal(err)
+		}
+		server := grpc.NewServer()
+		metricspb.RegisterMetricsServiceServer(server, &metrics.GRPCService{API: api})
+		go func() {
+			<-ctx.Done()
+			server.GracefulStop()
+		}()
+		go func() {
+			log.Printf("Serving gRPC on %s\n", grpcListen)
+			if err := server.Serve(listener); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	}
+	return func() { api.Store.Close() }
+}
+
+// storeAPI returns the REST API on the snapshots in --store, or nil without
+// one. The caller closes its store.
+func storeAPI(o *collectOptions) *metrics.StoreAPI {
+	if o.storeURI == "" {
+		return nil
+	}
+	store, err := metrics.OpenStore(o.storeURI)
+	if err != nil {
+		log.Fatalf("Error opening store: %v", err)
+	}
+	return &metrics.StoreAPI{
+		Store:        store,
+		Metric:       o.metric,
+		Organization: strings.Join(o.orgs, ", "),
+		WebURL:       metrics.WebURL(o.baseURL),
+		OptOut:       o.optOut,
+	}
+}
+
+// serveWebhook serves the dashboard of the latest snapshot of the metric in
+// --store and adds the webhook events received on /webhook to it. Without a
+// snapshot to start from, it starts an empty one.
+func serveWebhook(ctx context.Context, listen string, o *collectOptions, secret string, routes map[string]http.Handler) {
+	if o.storeURI == "" {
+		log.Fatal("--receive-webhooks requires --store to keep the metrics in.")
+	}
+	if secret == "" {
+		log.Fatal("--receive-webhooks requires --webhook-secret or GITHUB_WEBHOOK_SECRET to validate the payloads.")
+	}
+	if o.provider != metrics.ProviderGitHub {
+		log.Fatal("--receive-webhooks requires --provider github.")
+	}
+	store, err := metrics.OpenStore(o.storeURI)
+	if err != nil {
+		log.Fatalf("Error opening store: %v", err)
+	}
+	defer store.Close()
+	snapshot, err := store.Latest(ctx, o.metric)
+	if err != nil {
+		log.Fatalf("Error loading snapshot: %v", err)
+	}
+	if snapshot == nil {
+		now := time.Now()
+		snapshot = &metrics.Snapshot{TakenAt: now, Since: now, Metric: o.metric}
+		if err := store.Save(ctx, snapshot); err != nil {
+			log.Fatalf("Error saving snapshot: %v", err)
+		}
+		log.Printf("Started snapshot %d in %s\n", snapshot.ID, o.storeURI)
+	} else {
+		log.Printf("Updating snapshot %d of %d users taken at %s\n", snapshot.ID, len(snapshot.Users), snapshot.TakenAt.Local().Format("2006-01-02 15:04"))
+	}
+	var identities metrics.Identities
+	if o.identityFile != "" {
+		if identities, err = metrics.LoadIdentities(o.identityFile); err != nil {
+			log.Fatalf("Error loading identity file: %v", err)
+		}
+	}
+
+	ingester := metrics.NewWebhookIngester(&metrics.Results{
+		Metric:       o.metric,
+		Since:        snapshot.Since,
+		CollectedAt:  snapshot.TakenAt,
+		Organization: strings.Join(o.orgs, ", "),
+		WebURL:       metrics.WebURL(o.baseURL),
+		Users:        snapshot.Users,
+		OptOut:       o.optOut,
+	}, snapshot.PendingReviews)
+	ingester.Secret = []byte(secret)
+	ingester.Users = o.coders
+	ingester.ExcludeUsers = o.excludeUsers
+	ingester.Repos = o.repos
+	ingester.Organizations = o.orgs
+	ingester.ExcludeRepos = o.excludeRepos
+	ingester.Identities = identities
+	ingester.Scorer = o.scorer()
+	ingester.Leaderboards = o.boards
+	ingester.Store = store
+	ingester.SnapshotID = snapshot.ID
+	ingester.Verbose = o.verbose
+	routes["/webhook"] = ingester
+	serveDashboard(ctx, listen, o.template, o.charts, ingester.Results, routes)
+}
+
+// serveDashboard serves the dashboard until ctx is cancelled, then lets
+// in-flight requests finish before returning. routes, keyed by pattern,
+// serve what the dashboard does not.
+func serveDashboard(ctx context.Context, listen, templatePath string, charts bool, results func() (*metrics.Results, error), routes map[string]http.Handler) {
+	log.Printf("Serving dashboard on %s\n", listen)
+	serveHandler(ctx, listen, dashboardHandler(templatePath, charts, results, routes))
+	log.Printf("Dashboard stopped\n")
+}
+
+// dashboardHandler returns the dashboard of the results with the routes
+// next to it.
+func dashboardHandler(templatePath string, charts bool, results func() (*metrics.Results, error), routes map[string]http.Handler) http.Handler {
+	renderer, _, err := newRenderer("html", templatePath, charts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var handler http.Handler = &metrics.Dashboard{Renderer: renderer, Results: results}
+	if len(routes) > 0 {
+		mux := http.NewServeMux()
+		for pattern, route := range routes {
+			mux.Handle(pattern, route)
+		}
+		mux.Handle("/", handler)
+		handler = mux
+	}
+	return handler
+}
+
+// serveHandler serves handler until ctx is cancelled, then lets in-flight
+// requests finish before returning.
+func serveHandler(ctx context.Context, listen string, handler http.Handler) {
+	server := &http.Server{
+		Addr:    listen,
+		Handler: handler,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error shutting down: %v", err)
+		}
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"handshake/stats/metrics"
+)
+
+// Exit codes of the collect subcommand, for CI pipelines to react to.
+const (
+	exitSuccess     = 0
+	exitError       = 1
+	exitPartial     = 2 // Finished, but some metrics are incomplete
+	exitAuth        = 3
+	exitRateLimited = 4
+	exitInterrupted = 130
+)
+
+// credentialsError is a failure to set up the credentials of the GitHub
+// client, such as a missing token or an unreadable private key.
+type credentialsError struct {
+	error
+}
+
+func (e credentialsError) Unwrap() error {
+	return e.error
+}
+
+// runStatus is the machine-readable outcome of a collect run written to
+// --status-file.
+type runStatus struct {
+	Status       string                    `json:"status"` // success, partial, auth_error, rate_limited, error or interrupted
+	ExitCode     int                       `json:"exitCode"`
+	StartedAt    time.Time                 `json:"startedAt"`
+	FinishedAt   time.Time                 `json:"finishedAt"`
+	Calls        int64                     `json:"calls"`            // GitHub API requests made
+	Error        string                    `json:"error,omitempty"`  // What ended the run early
+	Errors       []metrics.IncompleteTask  `json:"errors"`           // Tasks left incomplete by request errors
+	SkippedRepos []string                  `json:"skippedRepos"`     // Repositories with incomplete metrics
+	Failures     []metrics.EndpointFailure `json:"failures"`         // Endpoints the collection stopped calling
+	Tokens       []metrics.TokenUsage      `json:"tokens,omitempty"` // Use of each token when rotating among several
+}
+
+// classify returns the status and exit code of a run that ended with err,
+// nil when it finished. A finished run is partial when tasks or endpoints
+// failed, unless they failed on the credentials or the rate limit.
+func classify(err error, incomplete []metrics.IncompleteTask, failures []metrics.EndpointFailure) (string, int) {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "interrupted", exitInterrupted
+	case err != nil && (errors.As(err, new(credentialsError)) || metrics.IsAuthError(err)):
+		return "auth_error", exitAuth
+	case err != nil && metrics.IsRateLimited(err):
+		return "rate_limited", exitRateLimited
+	case err != nil:
+		return "error", exitError
+	}
+	for _, task := range incomplete {
+		if metrics.IsAuthError(task.Err) {
+			return "auth_error", exitAuth
+		}
+	}
+	for _, task := range incomplete {
+		if metrics.IsRateLimited(task.Err) {
+			return "rate_limited", exitRateLimited
+		}
+	}
+	if len(incomplete) > 0 || len(failures) > 0 {
+		return "partial", exitPartial
+	}
+	return "success", exitSuccess
+}
+
+// finish ends a collect run: it writes the status of the run, ended early by
+// err when not nil, to --status-file and exits with its code unless the run
+// succeeded.
+func (o *collectOptions) finish(started time.Time, err error) {
+	status := runStatus{
+		StartedAt:    started,
+		FinishedAt:   time.Now(),
+		Errors:       []metrics.IncompleteTask{},
+		SkippedRepos: []string{},
+		Failures:     []metrics.EndpointFailure{},
+	}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	if o.last != nil {
+		status.Calls = o.last.APICalls()
+		status.Errors = append(status.Errors, o.last.Incomplete()...)
+		status.Failures = append(status.Failures, o.last.EndpointFailures()...)
+	}
+	if o.pool != nil {
+		status.Tokens = o.pool.Usage()
+	}
+	skipped := make(map[string]bool)
+	for _, task := range status.Errors {
+		if task.Repo != "" && !skipped[task.Repo] {
+			skipped[task.Repo] = true
+			status.SkippedRepos = append(status.SkippedRepos, task.Repo)
+		}
+	}
+	sort.Strings(status.SkippedRepos)
+	status.Status, status.ExitCode = classify(err, status.Errors, status.Failures)
+
+	if o.statusFile != "" {
+		data, jsonErr := json.MarshalIndent(status, "", "  ")
+		if jsonErr == nil {
+			jsonErr = os.WriteFile(o.statusFile, data, 0o644)
+		}
+		if jsonErr != nil {
+			log.Printf("Error writing run status: %v", jsonErr)
+		}
+	}
+	if o.verbose {
+		for _, token := range status.Tokens {
+			log.Printf("Token %s: %d requests, %d of %d core requests remaining, revoked: %t", token.Token, token.Requests, token.Remaining, token.Limit, token.Revoked)
+		}
+	}
+	if err != nil && status.ExitCode != exitInterrupted {
+		log.Printf("Error %v", err)
+	}
+	if status.ExitCode == exitPartial {
+		log.Printf("Finished with incomplete metrics: %d tasks failed, %d endpoints stopped", len(status.Errors), len(status.Failures))
+	}
+	if status.ExitCode != exitSuccess {
+		os.Exit(status.ExitCode)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"html/template"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var tenantIndex = template.Must(template.New("tenants").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>GitHub Metrics</title></head>
+<body>
+<h1>GitHub Metrics</h1>
+<ul>
+{{range .}}<li><a href="{{.}}/">{{.}}</a></li>
+{{end}}</ul>
+</body>
+</html>
+`))
+
+// serveTenants collects the metrics of every tenant of the configuration on
+// its own schedule and serves each on /{name}/, with its reports and REST
+// API below it, until ctx is cancelled. A tenant's results, checkpoint,
+// reports and caches default to its directory in --tenants-dir, and tenants
+// may not share the files they write, so no tenant sees another's data.
+func serveTenants(ctx context.Context, args []string, s *serveOptions, cfg *Config) {
+	tenants, err := cfg.tenants()
+	if err != nil {
+		log.Fatalf("Error in configuration: %v", err)
+	}
+	mux := http.NewServeMux()
+	owners := make(map[string]string) // Tenant writing each output, keyed by kind and path
+	var names []string
+	for _, tenant := range tenants {
+		fs, ts, o := newServeFlags()
+		dir := filepath.Join(s.tenantsDir, tenant.Name)
+		for name, value := range map[string]string{
+			"results-file":    filepath.Join(dir, "metrics-results.json"),
+			"checkpoint-file": filepath.Join(dir, ".githubmetrics-checkpoint.json"),
+			"cache-dir":       filepath.Join(dir, "cache"),
+			"reports-dir":     filepath.Join(dir, "reports"),
+		} {
+			fs.Lookup(name).Value.Set(value)
+		}
+		fs.Parse(args)
+		o.parseConfig(fs, args, &tenant.Config)
+
+		interval, schedule := s.interval, s.schedule
+		if tenant.Schedule != "" || tenant.Interval != "" {
+			interval, schedule = 0, tenant.Schedule
+			if tenant.Interval != "" {
+				if interval, err = time.ParseDuration(tenant.Interval); err != nil || interval <= 0 {
+					log.Fatalf("Tenant %q has an invalid interval %q, expected a duration such as 24h.", tenant.Name, tenant.Interval)
+				}
+			}
+		}
+		if interval <= 0 && schedule == "" {
+			log.Fatalf("Tenant %q has neither a schedule nor an interval; set one in the configuration or give --schedule or --interval.", tenant.Name)
+		}
+		next, err := nextRun(interval, schedule)
+		if err != nil {
+			log.Fatalf("Tenant %q has an invalid schedule: %v", tenant.Name, err)
+		}
+		ts.schedule = schedule
+		archive := ts.archive(o)
+
+		outputs := map[string]string{"results file": o.resultsFile, "checkpoint file": o.checkpoint, "store": o.storeURI}
+		if archive != nil {
+			outputs["reports directory"] = archive.Dir
+		}
+		for kind, path := range outputs {
+			if path == "" {
+				continue
+			}
+			if other, ok := owners[kind+" "+path]; ok {
+				log.Fatalf("Tenants %q and %q share the %s %s; give each tenant its own.", other, tenant.Name, kind, path)
+			}
+			owners[kind+" "+path] = tenant.Name
+		}
+		for _, path := range []string{o.resultsFile, o.checkpoint} {
+			if path == "" {
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		latest := collectOnSchedule(ctx, o, next, schedule != "", archive, tenant.Name)
+		routes := make(map[string]http.Handler)
+		if archive != nil {
+			routes["/reports/"] = archive
+		}
+		defer serveAPI(ctx, o, "", routes)()
+		prefix := "/" + tenant.Name
+		mux.Handle(prefix+"/", http.StripPrefix(prefix, dashboardHandler(o.template, o.charts, latest, routes)))
+		names = append(names, tenant.Name)
+	}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := tenantIndex.Execute(w, names); err != nil {
+			log.Printf("Error listing tenants: %v", err)
+		}
+	})
+
+	log.Printf("Serving %d tenants on %s\n", len(names), s.listen)
+	serveHandler(ctx, s.listen, mux)
+	log.Printf("Dashboard stopped\n")
+}
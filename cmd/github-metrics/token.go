@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// tokenEnvVars are read in order when no token is configured.
+var tokenEnvVars = []string{"GITHUB_TOKEN", "GH_TOKEN"}
+
+// resolveToken returns the configured token or, when there is none, one from
+// the environment, the OS keychain (see login) or the GitHub CLI, so tokens
+// need not be passed on the command line where they end up in shell history.
+func resolveToken(token, baseURL string) (string, error) {
+	if token != "" {
+		return token, nil
+	}
+	for _, name := range tokenEnvVars {
+		if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+			return value, nil
+		}
+	}
+	if value, ok := keyringToken(baseURL); ok {
+		return value, nil
+	}
+	return ghAuthToken(baseURL)
+}
+
+// ghAuthToken asks the GitHub CLI for the token it is logged in with, for the
+// GitHub Enterprise Server host of baseURL when set.
+func ghAuthToken(baseURL string) (string, error) {
+	args := []string{"auth", "token"}
+	if baseURL != "" {
+		u, err := url.Parse(baseURL)
+		if err == nil && u.Host != "" {
+			args = append(args, "--hostname", u.Host)
+		}
+	}
+	cmd := exec.Command("gh", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", errors.New("no token: set --token, GITHUB_TOKEN or GH_TOKEN, or log in with 'github-metrics login' or the GitHub CLI (gh auth login)")
+		}
+		return "", errors.New("no token and 'gh auth token' failed: " + strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+)
+
+// activeDayLayout is the layout of the days of UserMetrics.DailyActivity.
+const activeDayLayout = "2006-01-02"
+
+// addDay counts a contribution on the UTC day of t.
+func (m *UserMetrics) addDay(t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	m.mergeDays(map[string]int{t.UTC().Format(activeDayLayout): 1})
+}
+
+// mergeDays adds the contributions of days to DailyActivity and computes
+// ActiveDays and LongestStreak again. DailyActivity is copied first, as
+// merged metrics may share it.
+func (m *UserMetrics) mergeDays(days map[string]int) {
+	if len(days) == 0 {
+		return
+	}
+	merged := make(map[string]int, len(m.DailyActivity)+len(days))
+	for day, count := range m.DailyActivity {
+		merged[day] = count
+	}
+	for day, count := range days {
+		merged[day] += count
+	}
+	m.DailyActivity = merged
+	m.ActiveDays = len(merged)
+	m.LongestStreak = longestStreak(merged)
+}
+
+// clearDays forgets the active days, for metrics that read commits without
+// counting them.
+func (m *UserMetrics) clearDays() {
+	m.DailyActivity, m.ActiveDays, m.LongestStreak = nil, 0, 0
+}
+
+// longestStreak returns the most consecutive days among the days.
+func longestStreak(days map[string]int) int {
+	dates := make([]string, 0, len(days))
+	for day := range days {
+		dates = append(dates, day)
+	}
+	sort.Strings(dates)
+	longest, streak := 0, 0
+	var previous time.Time
+	for _, date := range dates {
+		day, err := time.Parse(activeDayLayout, date)
+		if err != nil {
+			continue
+		}
+		if !previous.IsZero() && day.Sub(previous) == 24*time.Hour {
+			streak++
+		} else {
+			streak = 1
+		}
+		previous = day
+		if streak > longest {
+			longest = streak
+		}
+	}
+	return longest
+}
+
+// DailyActivity returns the contributions per day of all users of the
+// report, for the team heatmap.
+func (r Report) DailyActivity() map[string]int {
+	var total UserMetrics
+	for _, view := range r.Users {
+		total.mergeDays(view.Metrics.DailyActivity)
+	}
+	return total.DailyActivity
+}
+
+// heatmapColors shade the days of a heatmap from no contribution to the
+// most contributions of the heatmap.
+var heatmapColors = []string{"#ebedf0", "#9be9a8", "#40c463", "#30a14e", "#216e39"}
+
+// heatmap draws an inline SVG calendar of the contributions per day in the
+// report's window, a column per week from Sunday to Saturday, like the
+// contribution graph of GitHub profiles. Without an end of the window, the
+// calendar ends at the collection, or at the last active day.
+func heatmap(report Report, label string, days map[string]int) template.HTML {
+	const cell, gap, left, top = 11, 3, 30, 20
+	first := report.Since.UTC().Truncate(24 * time.Hour)
+	last := report.Until
+	if last.IsZero() {
+		last = report.CollectedAt
+	}
+	if last.IsZero() {
+		for day := range days {
+			if t, err := time.Parse(activeDayLayout, day); err == nil && t.After(last) {
+				last = t
+			}
+		}
+	}
+	last = last.UTC().Truncate(24 * time.Hour)
+	if last.Before(first) {
+		last = first
+	}
+	most := 0
+	for _, count := range days {
+		if count > most {
+			most = count
+		}
+	}
+
+	start := first.AddDate(0, 0, -int(first.Weekday()))
+	weeks := int(last.Sub(start).Hours()/24)/7 + 1
+	width, height := left+weeks*(cell+gap), top+7*(cell+gap)
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="chart heatmap" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-label="Contributions per day of %s">`, width, height, width, height, template.HTMLEscapeString(label))
+	for i, weekday := range []string{"Mon", "Wed", "Fri"} {
+		fmt.Fprintf(&b, `<text x="0" y="%d">%s</text>`, top+(2*i+1)*(cell+gap)+cell-1, weekday)
+	}
+	month := -1
+	for day := start; !day.After(last); day = day.AddDate(0, 0, 1) {
+		week := int(day.Sub(start).Hours()/24) / 7
+		x, y := left+week*(cell+gap), top+int(day.Weekday())*(cell+gap)
+		if day.Weekday() == time.Sunday && int(day.Month()) != month {
+			month = int(day.Month())
+			fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`, x, top-6, day.Format("Jan"))
+		}
+		if day.Before(first) {
+			continue
+		}
+		date := day.Format(activeDayLayout)
+		count := days[date]
+		level := 0
+		if count > 0 && most > 0 {
+			level = 1 + (count-1)*(len(heatmapColors)-1)/most
+		}
+		noun := "contributions"
+		if count == 1 {
+			noun = "contribution"
+		}
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="%s"><title>%s: %d %s</title></rect>`,
+			x, y, cell, cell, heatmapColors[level], date, count, noun)
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Aggregations of the lifecycle metrics, which reduce the hours of each pull
+// request to the value reported.
+const (
+	AggregationMean   = "mean"
+	AggregationMedian = "median"
+	AggregationP90    = "p90"
+	AggregationP95    = "p95"
+)
+
+// ParseAggregation checks an aggregation name; empty means the mean.
+func ParseAggregation(name string) (string, error) {
+	switch name {
+	case "":
+		return AggregationMean, nil
+	case AggregationMean, AggregationMedian, AggregationP90, AggregationP95:
+		return name, nil
+	default:
+		return "", fmt.Errorf("unknown aggregation %q, expected mean, median, p90 or p95", name)
+	}
+}
+
+// Distribution summarizes the hours of a lifecycle metric over pull
+// requests.
+type Distribution struct {
+	Count  int     `json:"count"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	P90    float64 `json:"p90"`
+	P95    float64 `json:"p95"`
+	Max    float64 `json:"max"`
+}
+
+// NewDistribution summarizes values.
+func NewDistribution(values []float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+	return Distribution{
+		Count:  len(values),
+		Mean:   mean(values),
+		Median: median(values),
+		P90:    quantile(values, 0.9),
+		P95:    quantile(values, 0.95),
+		Max:    quantile(values, 1),
+	}
+}
+
+// Value returns the aggregation of the distribution, the mean by default.
+func (d Distribution) Value(aggregation string) float64 {
+	switch aggregation {
+	case AggregationMedian:
+		return d.Median
+	case AggregationP90:
+		return d.P90
+	case AggregationP95:
+		return d.P95
+	default:
+		return d.Mean
+	}
+}
+
+// Lifecycles are the distributions of the lifecycle metrics of a user or
+// team.
+type Lifecycles struct {
+	LcP         Distribution // Hours from opening to closing each pull request
+	FirstReview Distribution // Hours until the first review by someone else
+	Merge       Distribution // Hours from the last approval to the merge
+}
+
+// NewLifecycles summarizes the lifecycle metrics of m.
+func NewLifecycles(m UserMetrics) Lifecycles {
+	return Lifecycles{
+		LcP:         NewDistribution(m.PullLifecycles),
+		FirstReview: NewDistribution(m.FirstReviewWaits),
+		Merge:       NewDistribution(m.MergeWaits),
+	}
+}
+
+// quantile returns the q-th quantile of values, from 0 to 1, interpolating
+// between the two closest values, or 0 when there are none.
+func quantile(values []float64, q float64) float64 {
+	n := len(values)
+	if n == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	pos := q * float64(n-1)
+	lower := int(math.Floor(pos))
+	if lower >= n-1 {
+		return sorted[n-1]
+	}
+	return sorted[lower] + (pos-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
@@ -0,0 +1,180 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// GitHubAPI is the part of the GitHub API the collectors call. Set
+// GitHubCollector.API to substitute an implementation, such as a fake in a
+// test; by default the calls go to GitHubCollector.Client.
+type GitHubAPI interface {
+	ListCommits(ctx context.Context, owner, repo string, opts *github.CommitsListOptions) ([]*github.RepositoryCommit, *github.Response, error)
+	GetCommit(ctx context.Context, owner, repo, sha string) (*github.RepositoryCommit, *github.Response, error)
+	ListContributorsStats(ctx context.Context, owner, repo string) ([]*github.ContributorStats, *github.Response, error)
+	GetContents(ctx context.Context, owner, repo, path string) (*github.RepositoryContent, *github.Response, error)
+	ListOrgRepositories(ctx context.Context, org string, opts *github.RepositoryListByOrgOptions) ([]*github.Repository, *github.Response, error)
+	GetRepository(ctx context.Context, owner, repo string) (*github.Repository, *github.Response, error)
+	ListReleases(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error)
+	CompareCommits(ctx context.Context, owner, repo, base, head string, opts *github.ListOptions) (*github.CommitsComparison, *github.Response, error)
+	ListWorkflowRuns(ctx context.Context, owner, repo string, opts *github.ListWorkflowRunsOptions) (*github.WorkflowRuns, *github.Response, error)
+	ListDependabotAlerts(ctx context.Context, owner, repo string, opts *github.ListAlertsOptions) ([]*github.DependabotAlert, *github.Response, error)
+	ListCodeScanningAlerts(ctx context.Context, owner, repo string, opts *github.AlertListOptions) ([]*github.Alert, *github.Response, error)
+
+	ListIssues(ctx context.Context, owner, repo string, opts *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error)
+	ListIssueComments(ctx context.Context, owner, repo string, opts *github.IssueListCommentsOptions) ([]*github.IssueComment, *github.Response, error)
+	ListIssueTimeline(ctx context.Context, owner, repo string, number int, opts *github.ListOptions) ([]*github.Timeline, *github.Response, error)
+	ListIssueEvents(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.IssueEvent, *github.Response, error)
+
+	ListPullRequests(ctx context.Context, owner, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error)
+	GetPullRequest(ctx context.Context, owner, repo string, number int) (*github.PullRequest, *github.Response, error)
+	ListReviews(ctx context.Context, owner, repo string, number int, opts *github.ListOptions) ([]*github.PullRequestReview, *github.Response, error)
+	ListPullCommits(ctx context.Context, owner, repo string, number int, opts *github.ListOptions) ([]*github.RepositoryCommit, *github.Response, error)
+	ListPullFiles(ctx context.Context, owner, repo string, number int, opts *github.ListOptions) ([]*github.CommitFile, *github.Response, error)
+	ListReviewComments(ctx context.Context, owner, repo string, opts *github.PullRequestListCommentsOptions) ([]*github.PullRequestComment, *github.Response, error)
+
+	ListOrgMembers(ctx context.Context, org string, opts *github.ListMembersOptions) ([]*github.User, *github.Response, error)
+	ListTeamMembers(ctx context.Context, org, team string, opts *github.TeamListTeamMembersOptions) ([]*github.User, *github.Response, error)
+	SearchIssues(ctx context.Context, query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error)
+	RateLimits(ctx context.Context) (*github.RateLimits, *github.Response, error)
+
+	// GraphQL posts a GraphQL request body and decodes the response into v.
+	GraphQL(ctx context.Context, body interface{}, v interface{}) (*github.Response, error)
+}
+
+// NewGitHubAPI returns the GitHubAPI calling GitHub through client.
+func NewGitHubAPI(client *github.Client) GitHubAPI {
+	return clientAPI{client}
+}
+
+// api returns c.API, or the API of c.Client when it is not set.
+func (c *GitHubCollector) api() GitHubAPI {
+	if c.API != nil {
+		return c.API
+	}
+	return clientAPI{c.Client}
+}
+
+// clientAPI implements GitHubAPI with a go-github client.
+type clientAPI struct {
+	client *github.Client
+}
+
+func (a clientAPI) ListCommits(ctx context.Context, owner, repo string, opts *github.CommitsListOptions) ([]*github.RepositoryCommit, *github.Response, error) {
+	return a.client.Repositories.ListCommits(ctx, owner, repo, opts)
+}
+
+func (a clientAPI) GetCommit(ctx context.Context, owner, repo, sha string) (*github.RepositoryCommit, *github.Response, error) {
+	return a.client.Repositories.GetCommit(ctx, owner, repo, sha, nil)
+}
+
+// ListContributorsStats returns *github.AcceptedError while GitHub computes
+// the statistics.
+func (a clientAPI) ListContributorsStats(ctx context.Context, owner, repo string) ([]*github.ContributorStats, *github.Response, error) {
+	return a.client.Repositories.ListContributorsStats(ctx, owner, repo)
+}
+
+// GetContents returns the file at path on the default branch.
+func (a clientAPI) GetContents(ctx context.Context, owner, repo, path string) (*github.RepositoryContent, *github.Response, error) {
+	file, _, resp, err := a.client.Repositories.GetContents(ctx, owner, repo, path, nil)
+	return file, resp, err
+}
+
+func (a clientAPI) ListOrgRepositories(ctx context.Context, org string, opts *github.RepositoryListByOrgOptions) ([]*github.Repository, *github.Response, error) {
+	return a.client.Repositories.ListByOrg(ctx, org, opts)
+}
+
+func (a clientAPI) GetRepository(ctx context.Context, owner, repo string) (*github.Repository, *github.Response, error) {
+	return a.client.Repositories.Get(ctx, owner, repo)
+}
+
+func (a clientAPI) ListReleases(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+	return a.client.Repositories.ListReleases(ctx, owner, repo, opts)
+}
+
+func (a clientAPI) CompareCommits(ctx context.Context, owner, repo, base, head string, opts *github.ListOptions) (*github.CommitsComparison, *github.Response, error) {
+	return a.client.Repositories.CompareCommits(ctx, owner, repo, base, head, opts)
+}
+
+func (a clientAPI) ListWorkflowRuns(ctx context.Context, owner, repo string, opts *github.ListWorkflowRunsOptions) (*github.WorkflowRuns, *github.Response, error) {
+	return a.client.Actions.ListRepositoryWorkflowRuns(ctx, owner, repo, opts)
+}
+
+func (a clientAPI) ListDependabotAlerts(ctx context.Context, owner, repo string, opts *github.ListAlertsOptions) ([]*github.DependabotAlert, *github.Response, error) {
+	return a.client.Dependabot.ListRepoAlerts(ctx, owner, repo, opts)
+}
+
+func (a clientAPI) ListCodeScanningAlerts(ctx context.Context, owner, repo string, opts *github.AlertListOptions) ([]*github.Alert, *github.Response, error) {
+	return a.client.CodeScanning.ListAlertsForRepo(ctx, owner, repo, opts)
+}
+
+func (a clientAPI) ListIssues(ctx context.Context, owner, repo string, opts *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error) {
+	return a.client.Issues.ListByRepo(ctx, owner, repo, opts)
+}
+
+// ListIssueComments lists the comments on every issue and pull request of
+// the repository.
+func (a clientAPI) ListIssueComments(ctx context.Context, owner, repo string, opts *github.IssueListCommentsOptions) ([]*github.IssueComment, *github.Response, error) {
+	return a.client.Issues.ListComments(ctx, owner, repo, 0, opts)
+}
+
+func (a clientAPI) ListIssueTimeline(ctx context.Context, owner, repo string, number int, opts *github.ListOptions) ([]*github.Timeline, *github.Response, error) {
+	return a.client.Issues.ListIssueTimeline(ctx, owner, repo, number, opts)
+}
+
+func (a clientAPI) ListIssueEvents(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.IssueEvent, *github.Response, error) {
+	return a.client.Issues.ListRepositoryEvents(ctx, owner, repo, opts)
+}
+
+func (a clientAPI) ListPullRequests(ctx context.Context, owner, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error) {
+	return a.client.PullRequests.List(ctx, owner, repo, opts)
+}
+
+func (a clientAPI) GetPullRequest(ctx context.Context, owner, repo string, number int) (*github.PullRequest, *github.Response, error) {
+	return a.client.PullRequests.Get(ctx, owner, repo, number)
+}
+
+func (a clientAPI) ListReviews(ctx context.Context, owner, repo string, number int, opts *github.ListOptions) ([]*github.PullRequestReview, *github.Response, error) {
+	return a.client.PullRequests.ListReviews(ctx, owner, repo, number, opts)
+}
+
+func (a clientAPI) ListPullCommits(ctx context.Context, owner, repo string, number int, opts *github.ListOptions) ([]*github.RepositoryCommit, *github.Response, error) {
+	return a.client.PullRequests.ListCommits(ctx, owner, repo, number, opts)
+}
+
+func (a clientAPI) ListPullFiles(ctx context.Context, owner, repo string, number int, opts *github.ListOptions) ([]*github.CommitFile, *github.Response, error) {
+	return a.client.PullRequests.ListFiles(ctx, owner, repo, number, opts)
+}
+
+// ListReviewComments lists the review comments on every pull request of the
+// repository.
+func (a clientAPI) ListReviewComments(ctx context.Context, owner, repo string, opts *github.PullRequestListCommentsOptions) ([]*github.PullRequestComment, *github.Response, error) {
+	return a.client.PullRequests.ListComments(ctx, owner, repo, 0, opts)
+}
+
+func (a clientAPI) ListOrgMembers(ctx context.Context, org string, opts *github.ListMembersOptions) ([]*github.User, *github.Response, error) {
+	return a.client.Organizations.ListMembers(ctx, org, opts)
+}
+
+func (a clientAPI) ListTeamMembers(ctx context.Context, org, team string, opts *github.TeamListTeamMembersOptions) ([]*github.User, *github.Response, error) {
+	return a.client.Teams.ListTeamMembersBySlug(ctx, org, team, opts)
+}
+
+func (a clientAPI) SearchIssues(ctx context.Context, query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+	return a.client.Search.Issues(ctx, query, opts)
+}
+
+func (a clientAPI) RateLimits(ctx context.Context) (*github.RateLimits, *github.Response, error) {
+	return a.client.RateLimits(ctx)
+}
+
+// GraphQL posts to the GraphQL endpoint, which sits next to the REST root:
+// /graphql on api.github.com and /api/graphql on GitHub Enterprise Server.
+func (a clientAPI) GraphQL(ctx context.Context, body interface{}, v interface{}) (*github.Response, error) {
+	req, err := a.client.NewRequest("POST", "../graphql", body)
+	if err != nil {
+		return nil, err
+	}
+	return a.client.Do(ctx, req, v)
+}
@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// archiveLayout names the archived reports by the local time their results
+// were collected, e.g. 2024-03-04-0600.
+const archiveLayout = "2006-01-02-1504"
+
+var archiveIndex = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Reports</title></head>
+<body>
+<h1>Reports</h1>
+<ul>
+{{range .}}<li><a href="{{.}}/">{{.}}</a> (<a href="{{.}}.json">JSON</a>)</li>
+{{else}}<li>No reports yet</li>
+{{end}}</ul>
+</body>
+</html>
+`))
+
+// ReportArchive keeps the results of the last Keep runs in Dir, one file per
+// run named by when it was collected, and serves them at dated URLs: the
+// list on /reports/, each report on /reports/{date}/ and as JSON on
+// /reports/{date}.json.
+type ReportArchive struct {
+	Dir      string
+	Keep     int      // Runs kept; the oldest are deleted as new ones are added
+	Renderer Renderer // Renders the reports, usually an HTMLRenderer
+}
+
+// Add saves the results and deletes the runs beyond Keep, returning the date
+// the report is served at.
+func (a *ReportArchive) Add(results *Results) (string, error) {
+	if err := os.MkdirAll(a.Dir, 0o755); err != nil {
+		return "", err
+	}
+	date := results.CollectedAt.Local().Format(archiveLayout)
+	if err := results.Save(filepath.Join(a.Dir, date+".json")); err != nil {
+		return "", err
+	}
+	dates, err := a.Dates()
+	if err != nil {
+		return date, err
+	}
+	for i := a.Keep; i < len(dates); i++ {
+		if err := os.Remove(filepath.Join(a.Dir, dates[i]+".json")); err != nil {
+			return date, err
+		}
+	}
+	return date, nil
+}
+
+// Dates lists the dates of the archived reports, newest first.
+func (a *ReportArchive) Dates() ([]string, error) {
+	entries, err := os.ReadDir(a.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var dates []string
+	for _, entry := range entries {
+		date, ok := strings.CutSuffix(entry.Name(), ".json")
+		if _, err := time.Parse(archiveLayout, date); ok && err == nil && !entry.IsDir() {
+			dates = append(dates, date)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
+	return dates, nil
+}
+
+// Latest returns the newest archived results, or nil without any.
+func (a *ReportArchive) Latest() (*Results, error) {
+	dates, err := a.Dates()
+	if err != nil || len(dates) == 0 {
+		return nil, err
+	}
+	return LoadResults(filepath.Join(a.Dir, dates[0]+".json"))
+}
+
+func (a *ReportArchive) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/reports"), "/")
+	if path == "" {
+		dates, err := a.Dates()
+		if err != nil {
+			log.Printf("Error listing reports: %v", err)
+			http.Error(w, "reports not available", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := archiveIndex.Execute(w, dates); err != nil {
+			log.Printf("Error listing reports: %v", err)
+		}
+		return
+	}
+
+	date, asJSON := strings.CutSuffix(path, ".json")
+	if _, err := time.Parse(archiveLayout, date); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	results, err := LoadResults(filepath.Join(a.Dir, date+".json"))
+	if os.IsNotExist(err) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading report %s: %v", date, err)
+		http.Error(w, "report not available", http.StatusInternalServerError)
+		return
+	}
+	var renderer Renderer = a.Renderer
+	if asJSON {
+		renderer = JSONRenderer{}
+		w.Header().Set("Content-Type", "application/json")
+	}
+	if err := renderer.Render(r.Context(), w, NewReport(results.Users, results.ViewOptions())); err != nil {
+		log.Printf("Error rendering report %s: %v", date, err)
+	}
+}
@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// RetryPolicy is how GitHubCollector retries a failed request.
+type RetryPolicy struct {
+	MaxRetries  int           // Retries after the first attempt
+	BackoffBase time.Duration // Delay before the first retry, doubled for every retry after it
+	BackoffMax  time.Duration // Longest delay between two retries; 0 for no limit
+}
+
+// DefaultRetryPolicy makes five attempts at most, waiting about 1s, 2s, 4s
+// and 8s in between.
+var DefaultRetryPolicy = RetryPolicy{MaxRetries: 4, BackoffBase: time.Second, BackoffMax: time.Minute}
+
+// delay returns the jittered delay before retry attempt n, counted from 0.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := backoff(p.BackoffBase, attempt)
+	if p.BackoffMax > 0 && d > p.BackoffMax {
+		return p.BackoffMax
+	}
+	return d
+}
+
+// maxRateLimitWaits is how many times a request waits for a rate limit to
+// lift, on top of the retries of the RetryPolicy, before it fails.
+const maxRateLimitWaits = 10
+
+// IsRateLimited reports whether err is a primary or secondary rate limit,
+// which says nothing about the health of the endpoint.
+func IsRateLimited(err error) bool {
+	var rateErr *github.RateLimitError
+	if errors.As(err, &rateErr) {
+		return true
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	_, ok := secondaryLimit(err)
+	return ok
+}
+
+// ErrCircuitOpen is returned, wrapped, for calls to an endpoint that has
+// failed too often in a row to be called again yet.
+var ErrCircuitOpen = errors.New("circuit open")
+
+const (
+	// breakerThreshold is the number of calls to an endpoint that must fail
+	// in a row, each after all its retries, for its circuit to open.
+	breakerThreshold = 3
+	// breakerCooldown is how long an open circuit rejects calls before a
+	// single trial call may find out whether the endpoint has recovered.
+	breakerCooldown = 5 * time.Minute
+)
+
+// EndpointFailure records an endpoint whose circuit breaker opened, so the
+// report can tell that the metrics relying on it are incomplete.
+type EndpointFailure struct {
+	Endpoint string `json:"endpoint"` // e.g. "GET /repos/{owner}/{repo}/commits"
+	Error    string `json:"error"`    // Last error returned by the endpoint
+	Skipped  int    `json:"skipped"`  // Calls rejected while the circuit was open
+}
+
+// circuitBreakers stops calling an endpoint of the GitHub API that keeps
+// failing instead of retrying every call to it, one circuit per endpoint.
+type circuitBreakers struct {
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	failures  int       // Calls failed in a row
+	openUntil time.Time // Zero while closed
+	opened    bool      // Set once the circuit has opened
+	lastErr   error
+	skipped   int
+}
+
+func (b *circuitBreakers) circuit(endpoint string) *circuit {
+	if b.circuits == nil {
+		b.circuits = make(map[string]*circuit)
+	}
+	c, ok := b.circuits[endpoint]
+	if !ok {
+		c = &circuit{}
+		b.circuits[endpoint] = c
+	}
+	return c
+}
+
+// allow returns an error wrapping ErrCircuitOpen when the endpoint must not
+// be called. Each time the cooldown passes one caller is let through.
+func (b *circuitBreakers) allow(endpoint string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuit(endpoint)
+	if c.openUntil.IsZero() {
+		return nil
+	}
+	if now := time.Now(); !now.Before(c.openUntil) {
+		c.openUntil = now.Add(breakerCooldown)
+		return nil
+	}
+	c.skipped++
+	return fmt.Errorf("%s: %w after %d failed calls in a row: %v", endpoint, ErrCircuitOpen, c.failures, c.lastErr)
+}
+
+// success closes the endpoint's circuit.
+func (b *circuitBreakers) success(endpoint string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuit(endpoint)
+	c.failures = 0
+	c.openUntil = time.Time{}
+}
+
+// failure counts a call that failed after all its retries, opening the
+// circuit at the threshold, and again when its trial call fails.
+func (b *circuitBreakers) failure(endpoint string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuit(endpoint)
+	c.failures++
+	c.lastErr = err
+	if c.failures >= breakerThreshold {
+		c.openUntil = time.Now().Add(breakerCooldown)
+		c.opened = true
+	}
+}
+
+// failures lists the endpoints whose circuit opened, sorted by endpoint.
+func (b *circuitBreakers) failures() []EndpointFailure {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var result []EndpointFailure
+	for endpoint, c := range b.circuits {
+		if c.opened {
+			result = append(result, EndpointFailure{Endpoint: endpoint, Error: c.lastErr.Error(), Skipped: c.skipped})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Endpoint < result[j].Endpoint })
+	return result
+}
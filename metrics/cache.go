@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// CommitDetails holds the immutable statistics of a commit.
+type CommitDetails struct {
+	SHA   string       `json:"sha"`
+	Files []CommitFile `json:"files"`
+}
+
+// CommitFile is the per-file part of CommitDetails.
+type CommitFile struct {
+	Filename  string `json:"filename"`
+	Status    string `json:"status,omitempty"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Changes   int    `json:"changes"`
+	NoPatch   bool   `json:"noPatch,omitempty"` // GitHub sent no diff: a binary file or one too large to show
+}
+
+// CommitCache stores commit details by SHA. Commits never change once
+// pushed, so entries never expire.
+type CommitCache interface {
+	Get(sha string) (*CommitDetails, bool)
+	Put(details *CommitDetails) error
+}
+
+// FileCache is a CommitCache keeping one JSON file per commit under Dir.
+type FileCache struct {
+	Dir string
+}
+
+// DefaultCacheDir returns github-metrics inside the user's cache directory,
+// e.g. ~/.cache/github-metrics on Linux.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "github-metrics"), nil
+}
+
+func (c FileCache) path(sha string) string {
+	prefix := sha
+	if len(prefix) > 2 {
+		prefix = prefix[:2]
+	}
+	return filepath.Join(c.Dir, "commits", prefix, sha+".json")
+}
+
+func (c FileCache) Get(sha string) (*CommitDetails, bool) {
+	data, err := os.ReadFile(c.path(sha))
+	if err != nil {
+		return nil, false
+	}
+	var details CommitDetails
+	if err := json.Unmarshal(data, &details); err != nil || details.SHA != sha {
+		return nil, false
+	}
+	return &details, true
+}
+
+func (c FileCache) Put(details *CommitDetails) error {
+	if details.SHA == "" {
+		return errors.New("commit details without SHA")
+	}
+	data, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+	return writeCacheFile(c.path(details.SHA), data)
+}
+
+// writeCacheFile creates path with data, along with its directory.
+func writeCacheFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so concurrent readers never see a
+	// partially written entry.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strings"
+	"time"
+)
+
+// ScorePoint is a user's score in one stored run.
+type ScorePoint struct {
+	TakenAt time.Time `json:"takenAt"`
+	Score   float64   `json:"score"`
+}
+
+// ScoreSeries is a user's score over the stored runs, oldest first.
+type ScoreSeries struct {
+	User   string       `json:"user"`
+	Points []ScorePoint `json:"points"`
+}
+
+// trends returns the series with at least two points, the ones a line can
+// be drawn through.
+func trends(history []ScoreSeries) []ScoreSeries {
+	var series []ScoreSeries
+	for _, s := range history {
+		if len(s.Points) > 1 {
+			series = append(series, s)
+		}
+	}
+	return series
+}
+
+// chartColors are cycled through for the users of a chart.
+var chartColors = []string{"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f", "#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#bab0ac"}
+
+// metricValue returns the named metric of PeriodMetrics as a number.
+func metricValue(m UserMetrics, name string) (float64, error) {
+	switch name {
+	case "Commits":
+		return float64(m.Commits), nil
+	case "HoC":
+		return float64(m.HoC), nil
+	case "Issues":
+		return float64(m.Issues), nil
+	case "LcP":
+		return m.LcP, nil
+	case "Msgs":
+		return float64(m.Msgs), nil
+	case "Pulls":
+		return float64(m.Pulls), nil
+	case "Reviews":
+		return float64(m.Reviews), nil
+	case "Score":
+		return m.Score, nil
+	default:
+		return 0, fmt.Errorf("unknown metric %q", name)
+	}
+}
+
+// barChart draws an inline SVG with one horizontal bar per user for the
+// named metric, in the order of users.
+func barChart(metric string, users []UserMetricsView) (template.HTML, error) {
+	const width, labelWidth, valueWidth, rowHeight = 600, 140, 80, 22
+	values := make([]float64, len(users))
+	maxValue := 0.0
+	for i, view := range users {
+		v, err := metricValue(view.Metrics, metric)
+		if err != nil {
+			return "", err
+		}
+		values[i] = v
+		maxValue = math.Max(maxValue, v)
+	}
+
+	height := rowHeight*len(users) + 30
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="chart" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-label="%s per user">`, width, height, width, height, template.HTMLEscapeString(metric))
+	fmt.Fprintf(&b, `<text x="0" y="16" class="chart-title">%s</text>`, template.HTMLEscapeString(metric))
+	barSpace := float64(width - labelWidth - valueWidth)
+	for i, view := range users {
+		y := 30 + i*rowHeight
+		barWidth := 0.0
+		if maxValue > 0 {
+			barWidth = values[i] / maxValue * barSpace
+		}
+		number, _ := formatNumber(values[i])
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`, labelWidth-8, y+15, template.HTMLEscapeString(view.User))
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%.1f" height="%d" fill="%s"><title>%s: %s</title></rect>`,
+			labelWidth, y+3, barWidth, rowHeight-6, chartColors[i%len(chartColors)], template.HTMLEscapeString(view.User), number)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d">%s</text>`, float64(labelWidth)+barWidth+6, y+15, strings.TrimSuffix(number, ".00"))
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String()), nil
+}
+
+// lineChart draws an inline SVG with a line per user through their scores
+// over time, followed by a legend.
+func lineChart(series []ScoreSeries) template.HTML {
+	const width, height, left, right, top, bottom = 700, 300, 60, 20, 20, 40
+	var first, last time.Time
+	maxScore := 0.0
+	for _, s := range series {
+		for _, p := range s.Points {
+			if first.IsZero() || p.TakenAt.Before(first) {
+				first = p.TakenAt
+			}
+			if p.TakenAt.After(last) {
+				last = p.TakenAt
+			}
+			maxScore = math.Max(maxScore, p.Score)
+		}
+	}
+	span := last.Sub(first).Seconds()
+	x := func(t time.Time) float64 {
+		if span == 0 {
+			return left
+		}
+		return left + t.Sub(first).Seconds()/span*(width-left-right)
+	}
+	y := func(score float64) float64 {
+		if maxScore == 0 {
+			return height - bottom
+		}
+		return height - bottom - score/maxScore*(height-top-bottom)
+	}
+
+	legendHeight := 20 * len(series)
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="chart" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-label="Score over time">`, width, height+legendHeight, width, height+legendHeight)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" class="axis"/>`, left, height-bottom, width-right, height-bottom)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" class="axis"/>`, left, top, left, height-bottom)
+	maxLabel, _ := formatNumber(maxScore)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`, left-6, top+4, maxLabel)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">0</text>`, left-6, height-bottom+4)
+	fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`, left, height-bottom+20, first.Local().Format("2006-01-02"))
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`, width-right, height-bottom+20, last.Local().Format("2006-01-02"))
+	for i, s := range series {
+		color := chartColors[i%len(chartColors)]
+		var points []string
+		for _, p := range s.Points {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x(p.TakenAt), y(p.Score)))
+		}
+		fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"><title>%s</title></polyline>`,
+			strings.Join(points, " "), color, template.HTMLEscapeString(s.User))
+		ly := height + 20*i + 5
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="12" height="12" fill="%s"/>`, left, ly, color)
+		fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`, left+18, ly+11, template.HTMLEscapeString(s.User))
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Checkpoint persists the progress of a Calculator run so an interrupted run
+// can continue where it left off. Every finished (user, repo, metric) task is
+// written to disk together with its result.
+type Checkpoint struct {
+	Metric string    `json:"metric"`
+	Since  time.Time `json:"since"`
+	Until  time.Time `json:"until"`
+
+	Repositories map[string][]string    `json:"repositories"` // Discovered repositories per user
+	Tasks        map[string]UserMetrics `json:"tasks"`        // Results of finished tasks
+
+	path string
+	mu   sync.Mutex
+}
+
+// NewCheckpoint returns an empty checkpoint saved to path.
+func NewCheckpoint(path string) *Checkpoint {
+	return &Checkpoint{
+		Repositories: make(map[string][]string),
+		Tasks:        make(map[string]UserMetrics),
+		path:         path,
+	}
+}
+
+// LoadCheckpoint reads the checkpoint saved at path.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cp := NewCheckpoint(path)
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, err
+	}
+	if cp.Repositories == nil {
+		cp.Repositories = make(map[string][]string)
+	}
+	if cp.Tasks == nil {
+		cp.Tasks = make(map[string]UserMetrics)
+	}
+	return cp, nil
+}
+
+// Remove deletes the checkpoint file once a run has completed.
+func (c *Checkpoint) Remove() error {
+	err := os.Remove(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func taskKey(t task) string {
+	return t.user + "|" + t.repo + "|" + t.metric
+}
+
+func (c *Checkpoint) repositories(user string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	repos, ok := c.Repositories[user]
+	return repos, ok
+}
+
+func (c *Checkpoint) setRepositories(user string, repos []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if repos == nil {
+		repos = []string{}
+	}
+	c.Repositories[user] = repos
+	return c.save()
+}
+
+func (c *Checkpoint) result(t task) (UserMetrics, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m, ok := c.Tasks[taskKey(t)]
+	return m, ok
+}
+
+func (c *Checkpoint) record(t task, m UserMetrics) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Tasks[taskKey(t)] = m
+	return c.save()
+}
+
+// save writes the checkpoint atomically. It must be called with mu held.
+func (c *Checkpoint) save() error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".checkpoint-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
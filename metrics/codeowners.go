@@ -0,0 +1,335 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// CodeOwnersFiles are where GitHub looks for a repository's CODEOWNERS
+// file, in order.
+var CodeOwnersFiles = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// CodeOwners are the rules of a CODEOWNERS file, in file order.
+type CodeOwners []codeOwnersRule
+
+type codeOwnersRule struct {
+	pattern string
+	owners  []string // @login, @org/team-slug or email; empty when the path has no owner
+}
+
+// ParseCodeOwners reads the rules of a CODEOWNERS file: a path pattern
+// followed by its owners on each line, with comments starting with #.
+func ParseCodeOwners(data string) CodeOwners {
+	var rules CodeOwners
+	for _, line := range strings.Split(data, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		rules = append(rules, codeOwnersRule{pattern: fields[0], owners: fields[1:]})
+	}
+	return rules
+}
+
+// Owners returns the pattern of the last rule matching filename, as on
+// GitHub, and its owners; ok is false when no rule matches.
+func (o CodeOwners) Owners(filename string) (pattern string, owners []string, ok bool) {
+	for i := len(o) - 1; i >= 0; i-- {
+		if matchCodeOwners(o[i].pattern, filename) {
+			return o[i].pattern, o[i].owners, true
+		}
+	}
+	return "", nil, false
+}
+
+// matchCodeOwners matches a file against a CODEOWNERS pattern, which
+// follows .gitignore: a pattern also matches everything under the
+// directories it names, and one without a slash but at its end matches at
+// any depth. A leading slash anchors the pattern to the root of the
+// repository. A pattern ending in /* only matches the files directly in the
+// directory.
+func matchCodeOwners(pattern, filename string) bool {
+	anchored := strings.HasPrefix(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	match := func(pattern string) bool {
+		if anchored {
+			// Matched by segments from the root, never by the base name
+			// as MatchGlob does for a pattern without a slash.
+			return matchSegments(strings.Split(pattern, "/"), strings.Split(filename, "/"))
+		}
+		return MatchGlob(pattern, filename)
+	}
+	dir := strings.TrimSuffix(pattern, "/")
+	if dir == pattern && match(pattern) {
+		return true
+	}
+	if strings.HasSuffix(dir, "/*") {
+		return false
+	}
+	if !anchored && !strings.Contains(dir, "/") {
+		dir = "**/" + dir
+	}
+	return match(dir + "/**")
+}
+
+// OwnedPull is a pull request by someone else, merged during the window,
+// that changed files the user is a code owner of.
+type OwnedPull struct {
+	Repo          string `json:"repo"`
+	Number        int    `json:"number"`
+	Path          string `json:"path"`          // CODEOWNERS pattern of the files owned by the user
+	Reviewed      bool   `json:"reviewed"`      // Whether the user reviewed the pull request
+	OwnerReviewed bool   `json:"ownerReviewed"` // Whether any code owner of Path did
+}
+
+// ownership adds to m the pull requests merged during the window that
+// changed files the user owns by the repository's CODEOWNERS file, and how
+// many of them the user reviewed. Pull requests the user authored are left
+// out.
+func (c *GitHubCollector) ownership(ctx context.Context, owner, repo, user string, m *UserMetrics) {
+	if !c.CodeOwners {
+		return
+	}
+	rules := c.codeOwners(ctx, owner, repo)
+	if len(rules) == 0 {
+		return
+	}
+	pulls, err := c.repoMergedPulls(ctx, owner, repo)
+	if err != nil {
+		logError(ctx, err, "fetching merged pull requests in repo %s/%s", owner, repo)
+	}
+	for _, pull := range c.labeledPulls(pulls) {
+		if strings.EqualFold(pull.author, user) {
+			continue
+		}
+		files, err := c.pullFiles(ctx, owner, repo, pull.number)
+		if err != nil {
+			logError(ctx, err, "fetching files of pull request #%d in repo %s/%s", pull.number, owner, repo)
+		}
+		paths := make(map[string][]string)
+		for _, file := range files {
+			pattern, owners, ok := rules.Owners(file)
+			if ok && c.isCodeOwner(ctx, user, owners) {
+				paths[pattern] = owners
+			}
+		}
+		if len(paths) == 0 {
+			continue
+		}
+		reviewed := c.reviewedBy(ctx, owner, repo, user, pull.number)
+		m.CodeOwnerPulls++
+		kind := "owned_pull_unreviewed"
+		if reviewed {
+			m.CodeOwnerReviews++
+			kind = "owned_pull_reviewed"
+		}
+		for pattern, owners := range paths {
+			m.OwnedPulls = append(m.OwnedPulls, OwnedPull{
+				Repo:          owner + "/" + repo,
+				Number:        pull.number,
+				Path:          pattern,
+				Reviewed:      reviewed,
+				OwnerReviewed: reviewed || c.ownerReviewed(ctx, owner, repo, pull, owners),
+			})
+		}
+		c.Evidence.Add(user, EvidenceItem{Metric: MetricReviews, Kind: kind, Repo: owner + "/" + repo, ID: strconv.Itoa(pull.number), URL: pull.url})
+		if c.Verbose {
+			log.Printf("Pull request #%d in repo %s/%s changed files owned by %s, reviewed: %t\n", pull.number, owner, repo, user, reviewed)
+		}
+	}
+}
+
+// CodeOwnerReviewShare returns the percentage of the pull requests changing
+// files the user owns that the user reviewed, or 0 without any.
+func (m UserMetrics) CodeOwnerReviewShare() float64 {
+	if m.CodeOwnerPulls == 0 {
+		return 0
+	}
+	return float64(m.CodeOwnerReviews) * 100 / float64(m.CodeOwnerPulls)
+}
+
+// isCodeOwner reports whether login is one of the owners, directly, through
+// an email recorded in Identities or as a member of an owning team.
+func (c *GitHubCollector) isCodeOwner(ctx context.Context, login string, owners []string) bool {
+	for _, owner := range owners {
+		if !strings.HasPrefix(owner, "@") {
+			if c.Identities.IsCommitAuthor(login, "", owner) {
+				return true
+			}
+			continue
+		}
+		org, slug, team := strings.Cut(owner[1:], "/")
+		if !team {
+			if c.Identities.IsCommitAuthor(login, org, "") {
+				return true
+			}
+			continue
+		}
+		for _, member := range c.codeOwnersTeam(ctx, org, slug) {
+			if strings.EqualFold(member, login) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ownerReviewed reports whether any of the owners but the author submitted
+// a review of the pull request.
+func (c *GitHubCollector) ownerReviewed(ctx context.Context, owner, repo string, pull mergedPull, owners []string) bool {
+	reviews, err := c.pullReviews(ctx, owner, repo, pull.number)
+	if err != nil {
+		logError(ctx, err, "fetching reviews of pull request #%d in repo %s/%s", pull.number, owner, repo)
+	}
+	for _, review := range reviews {
+		login := review.GetUser().GetLogin()
+		if review.GetState() != "PENDING" && !strings.EqualFold(login, pull.author) && c.isCodeOwner(ctx, login, owners) {
+			return true
+		}
+	}
+	return false
+}
+
+// codeOwnersTeam lists the members of a team owning code, once per run. A
+// team that cannot be listed owns nothing.
+func (c *GitHubCollector) codeOwnersTeam(ctx context.Context, org, slug string) []string {
+	result, err := c.shared.do("team/"+org+"/"+slug, func() (interface{}, error) {
+		return c.TeamMembers(ctx, org, slug)
+	})
+	if err != nil {
+		log.Printf("Error listing code owners of team %s/%s: %v\n", org, slug, err)
+	}
+	members, _ := result.([]string)
+	return members
+}
+
+// codeOwners fetches the CODEOWNERS file of the repository's default
+// branch, once per run. A repository without one has no rules.
+func (c *GitHubCollector) codeOwners(ctx context.Context, owner, repo string) CodeOwners {
+	result, err := c.shared.do("codeowners/"+owner+"/"+repo, func() (interface{}, error) {
+		for _, path := range CodeOwnersFiles {
+			result, _, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/contents/{path}", func() (interface{}, *github.Response, error) {
+				file, resp, err := c.api().GetContents(ctx, owner, repo, path)
+				var respErr *github.ErrorResponse
+				if errors.As(err, &respErr) && respErr.Response != nil && respErr.Response.StatusCode == http.StatusNotFound {
+					return (*github.RepositoryContent)(nil), resp, nil
+				}
+				return file, resp, err
+			})
+			if err != nil {
+				return CodeOwners(nil), err
+			}
+			if result.(*github.RepositoryContent) == nil {
+				continue
+			}
+			content, err := result.(*github.RepositoryContent).GetContent()
+			return ParseCodeOwners(content), err
+		}
+		return CodeOwners(nil), nil
+	})
+	if err != nil {
+		log.Printf("Error reading CODEOWNERS of %s/%s, leaving out code ownership: %v\n", owner, repo, err)
+	}
+	return result.(CodeOwners)
+}
+
+// pullFiles lists the names of the files a pull request changed, once for
+// every code owner measured. The files fetched before an error are returned
+// with it.
+func (c *GitHubCollector) pullFiles(ctx context.Context, owner, repo string, number int) ([]string, error) {
+	result, err := c.shared.do(fmt.Sprintf("pull-files/%s/%s#%d", owner, repo, number), func() (interface{}, error) {
+		var files []string
+		opts := &github.ListOptions{PerPage: 100}
+		for {
+			result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/pulls/{pull_number}/files", func() (interface{}, *github.Response, error) {
+				return c.api().ListPullFiles(ctx, owner, repo, number, opts)
+			})
+			if err != nil {
+				return files, err
+			}
+			for _, file := range result.([]*github.CommitFile) {
+				files = append(files, file.GetFilename())
+			}
+			if resp.NextPage == 0 {
+				return files, nil
+			}
+			opts.Page = resp.NextPage
+		}
+	})
+	return result.([]string), err
+}
+
+// PathCoverage is a row of the code ownership coverage: the pull requests
+// that changed the files of a CODEOWNERS pattern owned by measured users,
+// and how many of them a code owner reviewed.
+type PathCoverage struct {
+	Repo     string
+	Path     string
+	Pulls    int
+	Reviewed int
+	Owners   []string // Measured users owning the path, sorted
+}
+
+// Coverage returns the percentage of the pull requests a code owner
+// reviewed.
+func (p PathCoverage) Coverage() float64 {
+	if p.Pulls == 0 {
+		return 0
+	}
+	return float64(p.Reviewed) / float64(p.Pulls) * 100
+}
+
+// CodeOwnerCoverage builds the coverage of every path owned by the users,
+// least covered first. A pull request is counted once per path, however
+// many users own it.
+func CodeOwnerCoverage(views []UserMetricsView) []PathCoverage {
+	var rows []PathCoverage
+	byPath := make(map[string]int)
+	counted := make(map[string]bool)
+	for _, view := range views {
+		for _, pull := range view.Metrics.OwnedPulls {
+			key := pull.Repo + " " + pull.Path
+			i, ok := byPath[key]
+			if !ok {
+				i = len(rows)
+				byPath[key] = i
+				rows = append(rows, PathCoverage{Repo: pull.Repo, Path: pull.Path})
+			}
+			row := &rows[i]
+			if n := len(row.Owners); n == 0 || row.Owners[n-1] != view.User {
+				row.Owners = append(row.Owners, view.User)
+			}
+			if id := fmt.Sprintf("%s#%d", key, pull.Number); !counted[id] {
+				counted[id] = true
+				row.Pulls++
+				if pull.OwnerReviewed {
+					row.Reviewed++
+				}
+			}
+		}
+	}
+	for i := range rows {
+		sort.Strings(rows[i].Owners)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if ci, cj := rows[i].Coverage(), rows[j].Coverage(); ci != cj {
+			return ci < cj
+		}
+		if rows[i].Repo != rows[j].Repo {
+			return rows[i].Repo < rows[j].Repo
+		}
+		return rows[i].Path < rows[j].Path
+	})
+	return rows
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import "testing"
+
+func TestMatchCodeOwners(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		filename string
+		want     bool
+	}{
+		{"/build.sh", "build.sh", true},
+		{"/build.sh", "tools/build.sh", false},
+		{"/build.sh", "build.sh/run", true},
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/app/main.go", true},
+		{"*.go", "main.go.orig", false},
+		{"docs/", "docs/index.md", true},
+		{"docs/", "docs/guides/setup.md", true},
+		{"docs/", "web/docs/index.md", true},
+		{"docs/", "docs.md", false},
+		{"docs/*", "docs/index.md", true},
+		{"docs/*", "docs/guides/setup.md", false},
+		{"docs/*", "web/docs/index.md", false},
+		{"/docs/**", "docs/index.md", true},
+		{"/docs/**", "docs/guides/setup.md", true},
+		{"/docs/**", "web/docs/index.md", false},
+		{"apps/", "apps/web/main.go", true},
+		{"apps/", "src/apps/web/main.go", true},
+		{"apps/", "myapps/main.go", false},
+	}
+	for _, tt := range tests {
+		if got := matchCodeOwners(tt.pattern, tt.filename); got != tt.want {
+			t.Errorf("matchCodeOwners(%q, %q) = %v, want %v", tt.pattern, tt.filename, got, tt.want)
+		}
+	}
+}
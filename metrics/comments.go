@@ -0,0 +1,168 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// comment is a comment on an issue or pull request, or a review comment on
+// a pull request diff.
+type comment struct {
+	author  string
+	created time.Time
+	pull    bool
+	body    string // Text of review comments, kept only with Tone
+	item    EvidenceItem
+}
+
+// repoComments holds the comments of one repository created since the start
+// of the window, fetched once and shared by every user measured in it.
+type repoComments struct {
+	once     sync.Once
+	comments []comment
+	err      error
+}
+
+// msgs counts the comments the user wrote during the window on issues and
+// on pull requests, in the conversation and on the diff.
+func (c *GitHubCollector) msgs(ctx context.Context, owner, repo, user string) UserMetrics {
+	var m UserMetrics
+	comments, err := c.comments(ctx, owner, repo)
+	if err != nil {
+		logError(ctx, err, "fetching comments in repo %s/%s", owner, repo)
+		return m
+	}
+	for _, comment := range comments {
+		if !strings.EqualFold(comment.author, user) || !c.inWindow(comment.created) {
+			continue
+		}
+		if comment.pull {
+			m.PRComments++
+		} else {
+			m.IssueComments++
+		}
+		m.addDay(comment.created)
+		c.Evidence.Add(user, comment.item)
+	}
+	m.Msgs = m.IssueComments + m.PRComments
+	if c.Verbose {
+		log.Printf("User %s in repo %s/%s wrote %d issue comments and %d pull request comments\n", user, owner, repo, m.IssueComments, m.PRComments)
+	}
+	return m
+}
+
+// comments returns the repository's comments, listing them on first use.
+func (c *GitHubCollector) comments(ctx context.Context, owner, repo string) ([]comment, error) {
+	c.commentsMu.Lock()
+	if c.commentsByRepo == nil {
+		c.commentsByRepo = make(map[string]*repoComments)
+	}
+	entry, ok := c.commentsByRepo[owner+"/"+repo]
+	if !ok {
+		entry = &repoComments{}
+		c.commentsByRepo[owner+"/"+repo] = entry
+	}
+	c.commentsMu.Unlock()
+
+	entry.once.Do(func() {
+		entry.comments, entry.err = c.listComments(ctx, owner, repo)
+		if entry.err == nil {
+			var review []comment
+			review, entry.err = c.listReviewComments(ctx, owner, repo)
+			entry.comments = append(entry.comments, review...)
+		}
+	})
+	return entry.comments, entry.err
+}
+
+// listComments lists the issue and pull request conversation comments
+// created since the start of the window.
+func (c *GitHubCollector) listComments(ctx context.Context, owner, repo string) ([]comment, error) {
+	var comments []comment
+	since := c.Since
+	opts := &github.IssueListCommentsOptions{
+		Sort:      github.String("created"),
+		Direction: github.String("desc"),
+		Since:     &since,
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	for {
+		result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/issues/comments", func() (interface{}, *github.Response, error) {
+			return c.api().ListIssueComments(ctx, owner, repo, opts)
+		})
+		if err != nil {
+			return comments, err
+		}
+		for _, ic := range result.([]*github.IssueComment) {
+			// Comments are listed newest first, so the window ends here.
+			if ic.GetCreatedAt().Before(c.Since) {
+				return comments, nil
+			}
+			// Issue comment URLs point to /pull/ for pull requests.
+			pull := strings.Contains(ic.GetHTMLURL(), "/pull/")
+			kind := "issue_comment"
+			if pull {
+				kind = "pull_comment"
+			}
+			comments = append(comments, comment{
+				author:  ic.GetUser().GetLogin(),
+				created: ic.GetCreatedAt().Time,
+				pull:    pull,
+				item:    EvidenceItem{Metric: MetricMsgs, Kind: kind, Repo: owner + "/" + repo, ID: strconv.FormatInt(ic.GetID(), 10), URL: ic.GetHTMLURL()},
+			})
+		}
+		if resp.NextPage == 0 {
+			return comments, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// listReviewComments lists the review comments on pull request diffs
+// created since the start of the window.
+func (c *GitHubCollector) listReviewComments(ctx context.Context, owner, repo string) ([]comment, error) {
+	var comments []comment
+	opts := &github.PullRequestListCommentsOptions{
+		Sort:      "created",
+		Direction: "desc",
+		Since:     c.Since,
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	for {
+		result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/pulls/comments", func() (interface{}, *github.Response, error) {
+			return c.api().ListReviewComments(ctx, owner, repo, opts)
+		})
+		if err != nil {
+			return comments, err
+		}
+		for _, rc := range result.([]*github.PullRequestComment) {
+			if rc.GetCreatedAt().Before(c.Since) {
+				return comments, nil
+			}
+			found := comment{
+				author:  rc.GetUser().GetLogin(),
+				created: rc.GetCreatedAt().Time,
+				pull:    true,
+				item:    EvidenceItem{Metric: MetricMsgs, Kind: "review_comment", Repo: owner + "/" + repo, ID: strconv.FormatInt(rc.GetID(), 10), URL: rc.GetHTMLURL()},
+			}
+			if c.Tone {
+				found.body = rc.GetBody()
+			}
+			comments = append(comments, found)
+		}
+		if resp.NextPage == 0 {
+			return comments, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
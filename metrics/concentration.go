@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"sort"
+)
+
+// Concentration describes how evenly a metric is spread across users.
+type Concentration struct {
+	Total     int     `json:"total"`
+	Gini      float64 `json:"gini"`      // 0 when spread evenly, approaching 1 when one user has it all
+	BusFactor int     `json:"busFactor"` // Fewest users accounting for at least half of the total
+	TopUser   string  `json:"topUser,omitempty"`
+	TopShare  float64 `json:"topShare"` // Percentage of the total by TopUser
+}
+
+// RepoConcentration is the concentration of HoC and merged pull requests
+// among the contributors of one repository.
+type RepoConcentration struct {
+	Repo  string        `json:"repo"`
+	HoC   Concentration `json:"hoc"`
+	Pulls Concentration `json:"pulls"`
+}
+
+// SinglePerson reports whether one user accounts for at least half of the
+// repository's hits of code and of its merged pull requests.
+func (r RepoConcentration) SinglePerson() bool {
+	return r.HoC.BusFactor == 1 && r.Pulls.BusFactor <= 1
+}
+
+// ConcentrationReport holds the concentration over all measured users and
+// per repository.
+type ConcentrationReport struct {
+	HoC   Concentration       `json:"hoc"`
+	Pulls Concentration       `json:"pulls"`
+	Repos []RepoConcentration `json:"repos"` // Single-person repositories first, then by top share
+}
+
+// NewConcentration measures how concentrated values, keyed by user, are.
+func NewConcentration(values map[string]int) Concentration {
+	type share struct {
+		user  string
+		value int
+	}
+	shares := make([]share, 0, len(values))
+	var c Concentration
+	for user, value := range values {
+		shares = append(shares, share{user, value})
+		c.Total += value
+	}
+	if c.Total == 0 {
+		return c
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].value != shares[j].value {
+			return shares[i].value > shares[j].value
+		}
+		return shares[i].user < shares[j].user
+	})
+	c.TopUser = shares[0].user
+	c.TopShare = float64(shares[0].value) / float64(c.Total) * 100
+
+	sum := 0
+	for _, s := range shares {
+		sum += s.value
+		c.BusFactor++
+		if 2*sum >= c.Total {
+			break
+		}
+	}
+
+	// Gini coefficient, ranking the values in ascending order.
+	n := float64(len(shares))
+	weighted := 0.0
+	for i := range shares {
+		weighted += float64(len(shares)-i) * float64(shares[i].value)
+	}
+	c.Gini = 2*weighted/(n*float64(c.Total)) - (n+1)/n
+	if c.Gini < 0 {
+		// Rounding when every value is equal.
+		c.Gini = 0
+	}
+	return c
+}
+
+// Concentrations measures the concentration of HoC and merged pull requests
+// across all measured users, and per repository across its contributors.
+func Concentrations(views []UserMetricsView) ConcentrationReport {
+	hoc := make(map[string]int)
+	pulls := make(map[string]int)
+	repoHoC := make(map[string]map[string]int)
+	repoPulls := make(map[string]map[string]int)
+	add := func(byRepo map[string]map[string]int, repo, user string, value int) {
+		if value <= 0 {
+			return
+		}
+		if byRepo[repo] == nil {
+			byRepo[repo] = make(map[string]int)
+		}
+		byRepo[repo][user] += value
+	}
+	for _, view := range views {
+		hoc[view.User] = view.Metrics.HoC
+		pulls[view.User] = view.Metrics.Pulls
+		for repo, value := range view.Metrics.Repos {
+			add(repoHoC, repo, view.User, value)
+		}
+		for repo, value := range view.Metrics.RepoPulls {
+			add(repoPulls, repo, view.User, value)
+		}
+	}
+
+	report := ConcentrationReport{HoC: NewConcentration(hoc), Pulls: NewConcentration(pulls)}
+	repos := make(map[string]bool)
+	for repo := range repoHoC {
+		repos[repo] = true
+	}
+	for repo := range repoPulls {
+		repos[repo] = true
+	}
+	for repo := range repos {
+		report.Repos = append(report.Repos, RepoConcentration{
+			Repo:  repo,
+			HoC:   NewConcentration(repoHoC[repo]),
+			Pulls: NewConcentration(repoPulls[repo]),
+		})
+	}
+	sort.Slice(report.Repos, func(i, j int) bool {
+		a, b := report.Repos[i], report.Repos[j]
+		if a.SinglePerson() != b.SinglePerson() {
+			return a.SinglePerson()
+		}
+		if a.HoC.TopShare != b.HoC.TopShare {
+			return a.HoC.TopShare > b.HoC.TopShare
+		}
+		return a.Repo < b.Repo
+	})
+	return report
+}
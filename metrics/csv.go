@@ -0,0 +1,439 @@
+package metrics
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVRenderer writes one row per user with every metric, the score, the top
+// repositories and the measured window, preceded by a header row. Named
+// leaderboards, team roll-ups, repository totals, inactive users, failed
+// endpoints, newcomers, review load, contribution concentration and a
+// period comparison follow after empty lines with their own headers.
+type CSVRenderer struct{}
+
+func (CSVRenderer) Render(_ context.Context, w io.Writer, report Report) error {
+	cw := csv.NewWriter(w)
+	header := []string{"User", "Commits", "HoC", "Issues", "LcP", "Msgs", "Pulls", "Reviews", "Score", "Top Repositories", "Review Comments", "Approvals", "Changes Requested", "Time To First Review", "Pending Review Requests", "Ignored Review Requests", "Code Owner PRs", "Code Owner Reviews"}
+	for _, bucket := range PullSizeBuckets {
+		header = append(header, bucket.Label+" PRs")
+	}
+	header = append(header, "Median PR Size", "Issues Labeled", "Issues Assigned", "Issues Closed", "Time To Triage", "Issues Closed Manually", "Issues Fixed", "Planned Issues", "Planned PRs", "Discussions Started", "Discussion Comments", "Discussion Answers", "Reverts", "Force Pushes", "Releases Published", "Tags Created", "Shipped In Releases", "Workflows Added", "Workflows Modified", "Runs Fixed", "Security Alerts Fixed", "Security Alerts Dismissed", "Dependabot PRs Merged", "Dependabot PRs Reviewed", "Issue Comments", "PR Comments", "Deletions", "Churn", "Net Lines", "Docs", "Docs Commits", "Public Activity", "Private Activity", "Internal Activity", "Off-Hours Commits", "Off-Hours Reviews", "Off-Hours Share", "Tone", "Tone Comments", "Harsh Comments", "Active Days", "Longest Streak", "Median LcP", "LcP P90", "LcP P95", "First Review Wait", "Median First Review Wait", "Review Rounds", "Median Review Rounds", "Merge Wait", "Median Merge Wait", "Unknown Metrics", "Since", "Until")
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	until := ""
+	if !report.Until.IsZero() {
+		until = report.Until.Format("2006-01-02")
+	}
+	for _, view := range report.Users {
+		m := view.Metrics
+		record := []string{
+			view.User,
+			strconv.Itoa(m.Commits),
+			strconv.Itoa(m.HoC),
+			strconv.Itoa(m.Issues),
+			strconv.FormatFloat(m.LcP, 'f', 2, 64),
+			strconv.Itoa(m.Msgs),
+			strconv.Itoa(m.Pulls),
+			strconv.Itoa(m.Reviews),
+			strconv.FormatFloat(m.Score, 'f', 2, 64),
+			view.TopRepos,
+			strconv.Itoa(m.ReviewComments),
+			strconv.Itoa(m.Approvals),
+			strconv.Itoa(m.ChangesRequested),
+			strconv.FormatFloat(m.TimeToFirstReview, 'f', 2, 64),
+			strconv.Itoa(m.PendingReviewRequests),
+			strconv.Itoa(m.IgnoredReviewRequests),
+			strconv.Itoa(m.CodeOwnerPulls),
+			strconv.Itoa(m.CodeOwnerReviews),
+		}
+		for _, bucket := range m.PullSizeDistribution() {
+			record = append(record, strconv.Itoa(bucket.Count))
+		}
+		record = append(record,
+			strconv.Itoa(m.MedianPullSize()),
+			strconv.Itoa(m.Labeled),
+			strconv.Itoa(m.Assigned),
+			strconv.Itoa(m.IssuesClosed),
+			strconv.FormatFloat(m.TimeToTriage, 'f', 2, 64),
+			strconv.Itoa(m.IssuesClosedManually),
+			strconv.Itoa(m.IssuesFixed),
+			strconv.Itoa(m.PlannedIssues),
+			strconv.Itoa(m.PlannedPulls),
+			strconv.Itoa(m.DiscussionsStarted),
+			strconv.Itoa(m.DiscussionComments),
+			strconv.Itoa(m.DiscussionAnswers),
+			strconv.Itoa(m.Reverts),
+			strconv.Itoa(m.ForcePushes),
+			strconv.Itoa(m.ReleasesPublished),
+			strconv.Itoa(m.TagsCreated),
+			strconv.Itoa(m.ShippedIn),
+			strconv.Itoa(m.WorkflowsAdded),
+			strconv.Itoa(m.WorkflowsModified),
+			strconv.Itoa(m.RunsFixed),
+			strconv.Itoa(m.SecurityAlertsFixed),
+			strconv.Itoa(m.SecurityAlertsDismissed),
+			strconv.Itoa(m.DependabotPullsMerged),
+			strconv.Itoa(m.DependabotPullsReviewed),
+			strconv.Itoa(m.IssueComments),
+			strconv.Itoa(m.PRComments),
+			strconv.Itoa(m.Deletions),
+			strconv.Itoa(m.Churn),
+			strconv.Itoa(m.NetLines),
+			strconv.Itoa(m.Docs),
+			strconv.Itoa(m.DocsCommits),
+			strconv.Itoa(m.PublicActivity),
+			strconv.Itoa(m.PrivateActivity),
+			strconv.Itoa(m.InternalActivity),
+			strconv.Itoa(m.OffHoursCommits),
+			strconv.Itoa(m.OffHoursReviews),
+			strconv.FormatFloat(m.OffHoursShare(), 'f', 2, 64),
+			strconv.FormatFloat(m.Tone, 'f', 2, 64),
+			strconv.Itoa(m.ToneComments),
+			strconv.Itoa(m.HarshComments),
+			strconv.Itoa(m.ActiveDays),
+			strconv.Itoa(m.LongestStreak),
+			strconv.FormatFloat(m.MedianLcP(), 'f', 2, 64),
+			strconv.FormatFloat(view.Lifecycles.LcP.P90, 'f', 2, 64),
+			strconv.FormatFloat(view.Lifecycles.LcP.P95, 'f', 2, 64),
+			strconv.FormatFloat(m.FirstReviewWait(), 'f', 2, 64),
+			strconv.FormatFloat(m.MedianFirstReviewWait(), 'f', 2, 64),
+			strconv.FormatFloat(m.AverageReviewRounds(), 'f', 2, 64),
+			strconv.FormatFloat(m.MedianReviewRounds(), 'f', 2, 64),
+			strconv.FormatFloat(m.MergeWait(), 'f', 2, 64),
+			strconv.FormatFloat(m.MedianMergeWait(), 'f', 2, 64),
+			strings.Join(m.Unknown, " "),
+			report.Since.Format("2006-01-02"),
+			until,
+		)
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	for _, board := range report.Leaderboards {
+		if err := writeLeaderboardCSV(cw, board); err != nil {
+			return err
+		}
+	}
+	if len(report.Teams) > 0 {
+		if err := writeTeamsCSV(cw, report.Teams); err != nil {
+			return err
+		}
+	}
+	if len(report.Repos) > 0 {
+		if err := writeReposCSV(cw, report.Repos); err != nil {
+			return err
+		}
+	}
+	if len(report.Inactive) > 0 {
+		if err := cw.Write(nil); err != nil {
+			return err
+		}
+		if err := cw.Write([]string{"Inactive"}); err != nil {
+			return err
+		}
+		for _, user := range report.Inactive {
+			if err := cw.Write([]string{user}); err != nil {
+				return err
+			}
+		}
+	}
+	if len(report.Failures) > 0 {
+		if err := writeFailuresCSV(cw, report.Failures); err != nil {
+			return err
+		}
+	}
+	if len(report.Newcomers) > 0 {
+		if err := writeNewcomersCSV(cw, report.Newcomers); err != nil {
+			return err
+		}
+	}
+	if len(report.StalePulls) > 0 {
+		if err := writeStalePullsCSV(cw, report.StalePulls); err != nil {
+			return err
+		}
+	}
+	if len(report.CodeOwners) > 0 {
+		if err := writeCodeOwnersCSV(cw, report.CodeOwners); err != nil {
+			return err
+		}
+	}
+	if len(report.PlannedWork) > 0 {
+		if err := writePlannedWorkCSV(cw, report.PlannedWork); err != nil {
+			return err
+		}
+	}
+	if len(report.ReviewLoad.Users) > 0 {
+		if err := writeReviewLoadCSV(cw, report.ReviewLoad); err != nil {
+			return err
+		}
+	}
+	if len(report.Ownership.Repos) > 0 {
+		if err := writeConcentrationCSV(cw, report.Ownership); err != nil {
+			return err
+		}
+	}
+	if len(report.PeriodUsers) > 0 {
+		if err := writePeriodsCSV(cw, report); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeLeaderboardCSV writes the ranking of a named leaderboard under a
+// header starting with its name.
+func writeLeaderboardCSV(cw *csv.Writer, board LeaderboardView) error {
+	if err := cw.Write(nil); err != nil {
+		return err
+	}
+	if err := cw.Write([]string{board.Name, "Rank", "Score"}); err != nil {
+		return err
+	}
+	for _, view := range board.Users {
+		record := []string{view.User, strconv.Itoa(view.Rank), strconv.FormatFloat(view.Metrics.Score, 'f', 2, 64)}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeTeamsCSV(cw *csv.Writer, teams []TeamMetricsView) error {
+	header := []string{"Team", "Members"}
+	for _, name := range []string{"Commits", "HoC", "Issues", "Msgs", "Pulls", "Reviews", "Score"} {
+		header = append(header, name, "Avg "+name)
+	}
+	header = append(header, "Avg LcP")
+	if err := cw.Write(nil); err != nil {
+		return err
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	for _, team := range teams {
+		t, a := team.Total, team.Average
+		record := []string{
+			team.Team,
+			strconv.Itoa(team.Members),
+			strconv.Itoa(t.Commits), f(a.Commits),
+			strconv.Itoa(t.HoC), f(a.HoC),
+			strconv.Itoa(t.Issues), f(a.Issues),
+			strconv.Itoa(t.Msgs), f(a.Msgs),
+			strconv.Itoa(t.Pulls), f(a.Pulls),
+			strconv.Itoa(t.Reviews), f(a.Reviews),
+			f(t.Score), f(a.Score),
+			f(a.LcP),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePeriodsCSV(cw *csv.Writer, report Report) error {
+	header := []string{"User", "Metric"}
+	for _, period := range report.Periods {
+		header = append(header, period.Label())
+	}
+	if err := cw.Write(nil); err != nil {
+		return err
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, view := range report.PeriodUsers {
+		for _, row := range view.Rows() {
+			if err := cw.Write(append([]string{view.User, row.Metric}, row.Values...)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeReposCSV(cw *csv.Writer, repos []RepoMetricsView) error {
+	if err := cw.Write(nil); err != nil {
+		return err
+	}
+	header := []string{"Repository", "Commits", "HoC", "Merged PRs", "Median Review Time", "Contributors", "Contributor Logins"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		record := []string{
+			repo.Repo,
+			strconv.Itoa(repo.Metrics.Commits),
+			strconv.Itoa(repo.Metrics.HoC),
+			strconv.Itoa(repo.Metrics.Pulls),
+			strconv.FormatFloat(repo.MedianReviewTime, 'f', 2, 64),
+			strconv.Itoa(len(repo.Contributors)),
+			strings.Join(repo.Contributors, " "),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeConcentrationCSV writes the concentration of every repository after
+// an "All repositories" row for all users.
+func writeConcentrationCSV(cw *csv.Writer, own ConcentrationReport) error {
+	if err := cw.Write(nil); err != nil {
+		return err
+	}
+	header := []string{"Repository"}
+	for _, metric := range []string{"HoC", "PR"} {
+		header = append(header, metric+" Total", metric+" Gini", metric+" Bus Factor", metric+" Top Contributor", metric+" Top Share")
+	}
+	header = append(header, "Single Person")
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	row := func(name string, hoc, pulls Concentration, single bool) []string {
+		record := []string{name}
+		for _, c := range []Concentration{hoc, pulls} {
+			record = append(record, strconv.Itoa(c.Total), f(c.Gini), strconv.Itoa(c.BusFactor), c.TopUser, f(c.TopShare))
+		}
+		return append(record, strconv.FormatBool(single))
+	}
+	if err := cw.Write(row("All repositories", own.HoC, own.Pulls, false)); err != nil {
+		return err
+	}
+	for _, repo := range own.Repos {
+		if err := cw.Write(row(repo.Repo, repo.HoC, repo.Pulls, repo.SinglePerson())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeReviewLoadCSV writes the review balance of every user, then of every
+// team, after an "All users" row with the overall balance.
+func writeReviewLoadCSV(cw *csv.Writer, load ReviewLoad) error {
+	if err := cw.Write(nil); err != nil {
+		return err
+	}
+	if err := cw.Write([]string{"Review Load", "Reviews Given", "Merged PRs", "Reviews per PR", "Heavy Author", "Balance"}); err != nil {
+		return err
+	}
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	if err := cw.Write([]string{"All users", "", "", "", "", f(load.Balance)}); err != nil {
+		return err
+	}
+	for _, user := range load.Users {
+		record := []string{user.User, strconv.Itoa(user.Reviews), strconv.Itoa(user.Pulls), f(user.Ratio), strconv.FormatBool(user.HeavyAuthor), ""}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	for _, team := range load.Teams {
+		record := []string{team.Team, strconv.Itoa(team.Reviews), strconv.Itoa(team.Pulls), f(team.Ratio), "", f(team.Balance)}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFailuresCSV writes the endpoints the collection stopped calling.
+func writeFailuresCSV(cw *csv.Writer, failures []EndpointFailure) error {
+	if err := cw.Write(nil); err != nil {
+		return err
+	}
+	if err := cw.Write([]string{"Failed Endpoint", "Skipped Calls", "Last Error"}); err != nil {
+		return err
+	}
+	for _, f := range failures {
+		if err := cw.Write([]string{f.Endpoint, strconv.Itoa(f.Skipped), f.Error}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeStalePullsCSV writes the open pull requests past the stale threshold
+// per user and repository, with the oldest one and its age in hours.
+func writeStalePullsCSV(cw *csv.Writer, stale []StalePulls) error {
+	if err := cw.Write(nil); err != nil {
+		return err
+	}
+	if err := cw.Write([]string{"User", "Repository", "Stale PRs", "Oldest Pull Request", "Title", "Opened", "Age"}); err != nil {
+		return err
+	}
+	for _, s := range stale {
+		record := []string{s.User, s.Repo, strconv.Itoa(s.Count), s.Oldest.URL, s.Oldest.Title, s.Oldest.CreatedAt.Format(time.RFC3339), strconv.FormatFloat(s.Age, 'f', 2, 64)}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCodeOwnersCSV writes the code owner review coverage of every owned
+// path, with the coverage in percent.
+func writeCodeOwnersCSV(cw *csv.Writer, paths []PathCoverage) error {
+	if err := cw.Write(nil); err != nil {
+		return err
+	}
+	if err := cw.Write([]string{"Repository", "Path", "Pull Requests", "Owner Reviewed", "Coverage", "Owners"}); err != nil {
+		return err
+	}
+	for _, p := range paths {
+		record := []string{p.Repo, p.Path, strconv.Itoa(p.Pulls), strconv.Itoa(p.Reviewed), strconv.FormatFloat(p.Coverage(), 'f', 2, 64), strings.Join(p.Owners, " ")}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writePlannedWorkCSV writes the items of every project or milestone the
+// users completed.
+func writePlannedWorkCSV(cw *csv.Writer, plans []PlanProgress) error {
+	if err := cw.Write(nil); err != nil {
+		return err
+	}
+	if err := cw.Write([]string{"Plan", "Issues", "Pull Requests", "Users"}); err != nil {
+		return err
+	}
+	for _, p := range plans {
+		if err := cw.Write([]string{p.Plan, strconv.Itoa(p.Issues), strconv.Itoa(p.Pulls), strings.Join(p.Users, " ")}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeNewcomersCSV writes the first pull request of every newcomer and the
+// hours until their first merge, empty while nothing is merged.
+func writeNewcomersCSV(cw *csv.Writer, newcomers []Newcomer) error {
+	if err := cw.Write(nil); err != nil {
+		return err
+	}
+	if err := cw.Write([]string{"Newcomer", "First Pull Request", "Title", "Opened", "First Merge", "Time to First Merge"}); err != nil {
+		return err
+	}
+	for _, n := range newcomers {
+		merge, hours := "", ""
+		if n.FirstMerge != nil {
+			merge = n.FirstMerge.Format(time.RFC3339)
+			hours = strconv.FormatFloat(n.TimeToMerge, 'f', 2, 64)
+		}
+		record := []string{n.User, n.FirstPull.URL, n.FirstPull.Title, n.FirstPull.CreatedAt.Format(time.RFC3339), merge, hours}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// Dashboard is an http.Handler serving the latest results as a rendered
+// leaderboard on / and as JSON on /api/users and /api/users/{login}.
+type Dashboard struct {
+	Renderer Renderer // Renders the leaderboard, usually an HTMLRenderer
+
+	// Results returns the results to serve.
+	Results func() (*Results, error)
+}
+
+func (d *Dashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	results, err := d.Results()
+	if err != nil {
+		log.Printf("Error loading results: %v", err)
+		http.Error(w, "results not available", http.StatusServiceUnavailable)
+		return
+	}
+	if results == nil {
+		http.Error(w, "no results collected yet", http.StatusServiceUnavailable)
+		return
+	}
+	report := NewReport(results.Users, results.ViewOptions())
+
+	switch {
+	case r.URL.Path == "/":
+		if err := d.Renderer.Render(r.Context(), w, report); err != nil {
+			log.Printf("Error rendering report: %v", err)
+		}
+	case r.URL.Path == "/api/users":
+		users := []jsonUser{}
+		for _, view := range report.Users {
+			users = append(users, newJSONUser(view))
+		}
+		writeJSON(w, users)
+	case strings.HasPrefix(r.URL.Path, "/api/users/"):
+		login := strings.TrimPrefix(r.URL.Path, "/api/users/")
+		for _, view := range report.Users {
+			if strings.EqualFold(view.User, login) {
+				writeJSON(w, newJSONUser(view))
+				return
+			}
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Printf("Error writing JSON response: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+)
+
+// Delta is the change of a metric against the previous run.
+type Delta struct {
+	Previous float64  `json:"previous"`
+	Change   float64  `json:"change"`
+	Percent  *float64 `json:"percent,omitempty"` // Nil when the previous value was zero
+}
+
+// UserDeltas holds the Delta of every metric of a user.
+type UserDeltas struct {
+	Commits Delta `json:"commits"`
+	HoC     Delta `json:"hoc"`
+	Issues  Delta `json:"issues"`
+	LcP     Delta `json:"lcp"`
+	Msgs    Delta `json:"msgs"`
+	Pulls   Delta `json:"pulls"`
+	Reviews Delta `json:"reviews"`
+	Score   Delta `json:"score"`
+}
+
+// NewDelta compares a current value with the previous one.
+func NewDelta(current, previous float64) Delta {
+	d := Delta{Previous: previous, Change: current - previous}
+	if previous != 0 {
+		percent := d.Change / math.Abs(previous) * 100
+		d.Percent = &percent
+	}
+	return d
+}
+
+// Deltas compares a user's metrics with those of the previous run.
+func Deltas(current, previous UserMetrics) *UserDeltas {
+	return &UserDeltas{
+		Commits: NewDelta(float64(current.Commits), float64(previous.Commits)),
+		HoC:     NewDelta(float64(current.HoC), float64(previous.HoC)),
+		Issues:  NewDelta(float64(current.Issues), float64(previous.Issues)),
+		LcP:     NewDelta(current.LcP, previous.LcP),
+		Msgs:    NewDelta(float64(current.Msgs), float64(previous.Msgs)),
+		Pulls:   NewDelta(float64(current.Pulls), float64(previous.Pulls)),
+		Reviews: NewDelta(float64(current.Reviews), float64(previous.Reviews)),
+		Score:   NewDelta(current.Score, previous.Score),
+	}
+}
+
+// String formats the delta as an arrow with the percentage change, e.g.
+// "▲ 12.5%". Changes from zero show the absolute change instead.
+func (d Delta) String() string {
+	arrow := "▲"
+	if d.Change < 0 {
+		arrow = "▼"
+	} else if d.Change == 0 {
+		return "="
+	}
+	if d.Percent == nil {
+		return fmt.Sprintf("%s %+g", arrow, d.Change)
+	}
+	return fmt.Sprintf("%s %.1f%%", arrow, math.Abs(*d.Percent))
+}
+
+// trendHTML renders a delta as a span colored by direction.
+func trendHTML(d Delta) template.HTML {
+	class := "flat"
+	if d.Change > 0 {
+		class = "up"
+	} else if d.Change < 0 {
+		class = "down"
+	}
+	return template.HTML(fmt.Sprintf(`<span class="trend %s" title="previous: %g">%s</span>`, class, d.Previous, template.HTMLEscapeString(d.String())))
+}
@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// repoDiscussions holds the discussions of one repository active during the
+// window, fetched once and shared by every user measured in it.
+type repoDiscussions struct {
+	once        sync.Once
+	discussions []discussionNode
+	err         error
+}
+
+type discussionAuthor struct {
+	Login string `json:"login"`
+}
+
+type discussionComment struct {
+	ID        string            `json:"id"`
+	URL       string            `json:"url"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Author    *discussionAuthor `json:"author"`
+}
+
+type discussionNode struct {
+	Number         int                `json:"number"`
+	URL            string             `json:"url"`
+	CreatedAt      time.Time          `json:"createdAt"`
+	UpdatedAt      time.Time          `json:"updatedAt"`
+	Author         *discussionAuthor  `json:"author"`
+	Answer         *discussionComment `json:"answer"`
+	AnswerChosenAt *time.Time         `json:"answerChosenAt"`
+	Comments       struct {
+		Nodes []struct {
+			discussionComment
+			Replies struct {
+				Nodes []discussionComment `json:"nodes"`
+			} `json:"replies"`
+		} `json:"nodes"`
+	} `json:"comments"`
+}
+
+// Discussions are listed most recently updated first so paging can stop at
+// the start of the window. Comments and replies beyond the first page of
+// each are not counted, which keeps the query within GraphQL node limits.
+const discussionsQuery = `query($owner: String!, $name: String!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    discussions(first: 50, after: $cursor, orderBy: {field: UPDATED_AT, direction: DESC}) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        number url createdAt updatedAt
+        author { login }
+        answer { id url createdAt author { login } }
+        answerChosenAt
+        comments(first: 50) {
+          nodes {
+            id url createdAt author { login }
+            replies(first: 20) { nodes { id url createdAt author { login } } }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// discussions counts the discussions the user started, their comments and
+// replies, and their comments that were marked as the answer, during the
+// window.
+func (c *GraphQLCollector) discussions(ctx context.Context, owner, repo, user string) UserMetrics {
+	var m UserMetrics
+	discussions, err := c.repoDiscussions(ctx, owner, repo)
+	if err != nil {
+		logError(ctx, err, "fetching discussions in repo %s/%s", owner, repo)
+		return m
+	}
+
+	isUser := func(author *discussionAuthor) bool {
+		return author != nil && strings.EqualFold(author.Login, user)
+	}
+	for _, d := range discussions {
+		if isUser(d.Author) && c.inWindow(d.CreatedAt) {
+			m.DiscussionsStarted++
+			c.Evidence.Add(user, EvidenceItem{Metric: MetricDiscussions, Kind: "discussion", Repo: owner + "/" + repo, ID: strconv.Itoa(d.Number), URL: d.URL})
+		}
+		if d.Answer != nil && isUser(d.Answer.Author) && d.AnswerChosenAt != nil && c.inWindow(*d.AnswerChosenAt) {
+			m.DiscussionAnswers++
+			c.Evidence.Add(user, EvidenceItem{Metric: MetricDiscussions, Kind: "answer", Repo: owner + "/" + repo, ID: d.Answer.ID, URL: d.Answer.URL})
+			if c.Verbose {
+				log.Printf("Discussion #%d in repo %s/%s: answer by %s chosen at %s\n", d.Number, owner, repo, user, d.AnswerChosenAt)
+			}
+		}
+		for _, comment := range d.Comments.Nodes {
+			if isUser(comment.Author) && c.inWindow(comment.CreatedAt) {
+				m.DiscussionComments++
+				c.Evidence.Add(user, EvidenceItem{Metric: MetricDiscussions, Kind: "discussion_comment", Repo: owner + "/" + repo, ID: comment.ID, URL: comment.URL})
+			}
+			for _, reply := range comment.Replies.Nodes {
+				if isUser(reply.Author) && c.inWindow(reply.CreatedAt) {
+					m.DiscussionComments++
+					c.Evidence.Add(user, EvidenceItem{Metric: MetricDiscussions, Kind: "discussion_comment", Repo: owner + "/" + repo, ID: reply.ID, URL: reply.URL})
+				}
+			}
+		}
+	}
+	return m
+}
+
+func (c *GraphQLCollector) repoDiscussions(ctx context.Context, owner, repo string) ([]discussionNode, error) {
+	c.discussionsMu.Lock()
+	if c.discussionsByRepo == nil {
+		c.discussionsByRepo = make(map[string]*repoDiscussions)
+	}
+	entry, ok := c.discussionsByRepo[owner+"/"+repo]
+	if !ok {
+		entry = &repoDiscussions{}
+		c.discussionsByRepo[owner+"/"+repo] = entry
+	}
+	c.discussionsMu.Unlock()
+
+	entry.once.Do(func() {
+		entry.discussions, entry.err = c.listDiscussions(ctx, owner, repo)
+	})
+	return entry.discussions, entry.err
+}
+
+func (c *GraphQLCollector) listDiscussions(ctx context.Context, owner, repo string) ([]discussionNode, error) {
+	var discussions []discussionNode
+	variables := map[string]interface{}{"owner": owner, "name": repo, "cursor": nil}
+	for {
+		var data struct {
+			Repository *struct {
+				Discussions struct {
+					PageInfo pageInfo         `json:"pageInfo"`
+					Nodes    []discussionNode `json:"nodes"`
+				} `json:"discussions"`
+			} `json:"repository"`
+		}
+		if err := c.query(ctx, discussionsQuery, variables, &data); err != nil {
+			return discussions, err
+		}
+		if data.Repository == nil {
+			return discussions, nil
+		}
+		for _, d := range data.Repository.Discussions.Nodes {
+			if d.UpdatedAt.Before(c.Since) {
+				return discussions, nil
+			}
+			discussions = append(discussions, d)
+		}
+		if !data.Repository.Discussions.PageInfo.HasNextPage {
+			return discussions, nil
+		}
+		variables["cursor"] = data.Repository.Discussions.PageInfo.EndCursor
+	}
+}
@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Mailer emails rendered reports over SMTP. Port 465 uses implicit TLS;
+// other ports upgrade with STARTTLS when the server offers it.
+type Mailer struct {
+	Host     string
+	Port     int // Defaults to 587
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// SendHTML sends the HTML report as the message body and attaches it as
+// filename, so it can also be saved and opened in a browser.
+func (m Mailer) SendHTML(subject string, html []byte, filename string) error {
+	msg, err := m.message(subject, html, filename)
+	if err != nil {
+		return err
+	}
+	port := m.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(m.Host, strconv.Itoa(port))
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+	if port != 465 {
+		return smtp.SendMail(addr, auth, m.From, m.To, msg)
+	}
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: m.Host})
+	if err != nil {
+		return err
+	}
+	client, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(m.From); err != nil {
+		return err
+	}
+	for _, to := range m.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// message builds a multipart/mixed message with the HTML body and the same
+// HTML as an attachment.
+func (m Mailer) message(subject string, html []byte, filename string) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	parts := []textproto.MIMEHeader{
+		{"Content-Type": {"text/html; charset=utf-8"}},
+		{
+			"Content-Type":        {"text/html; charset=utf-8"},
+			"Content-Disposition": {mime.FormatMediaType("attachment", map[string]string{"filename": filename})},
+		},
+	}
+	for _, header := range parts {
+		header.Set("Content-Transfer-Encoding", "base64")
+		w, err := mw.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(wrapBase64(html)); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", m.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(m.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// wrapBase64 encodes data as base64 in lines of 76 characters.
+func wrapBase64(data []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var b bytes.Buffer
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	return b.Bytes()
+}
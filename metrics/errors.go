@@ -0,0 +1,189 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// Error modes of GitHubCollector, deciding what a request error that leaves
+// a metric incomplete does to the run.
+const (
+	// ErrorModePartial carries on and lists the metric in
+	// UserMetrics.Unknown, so its count is known to be a lower bound rather
+	// than mistaken for no activity.
+	ErrorModePartial = "partial"
+	// ErrorModeFail returns the error from Collect, which aborts the run.
+	ErrorModeFail = "fail"
+)
+
+// IncompleteTask is a collection task whose metrics request errors left
+// incomplete with ErrorModePartial.
+type IncompleteTask struct {
+	User   string `json:"user"`
+	Repo   string `json:"repo,omitempty"`   // Empty when discovering the user's repositories failed
+	Metric string `json:"metric,omitempty"` // Empty when discovering the user's repositories failed
+	Error  string `json:"error"`
+	Err    error  `json:"-"`
+}
+
+// IsAuthError reports whether GitHub, GitLab or Gitea rejected the
+// credentials of a request.
+func IsAuthError(err error) bool {
+	var respErr *github.ErrorResponse
+	if errors.As(err, &respErr) && respErr.Response != nil && respErr.Response.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusUnauthorized
+}
+
+// taskErrors records the errors of the collection task running under a
+// context.
+type taskErrors struct {
+	mu  sync.Mutex
+	err error // First error
+}
+
+type taskErrorsKey struct{}
+
+func (e *taskErrors) first() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.err
+}
+
+// logError logs a request error, as "Error <description>: <err>", and
+// records it against the task collecting under ctx, whose metrics are then
+// incomplete.
+func logError(ctx context.Context, err error, format string, args ...interface{}) {
+	description := fmt.Sprintf(format, args...)
+	log.Printf("Error %s: %v\n", description, err)
+	if errs, ok := ctx.Value(taskErrorsKey{}).(*taskErrors); ok {
+		errs.mu.Lock()
+		if errs.err == nil {
+			errs.err = fmt.Errorf("%s: %w", description, err)
+		}
+		errs.mu.Unlock()
+	}
+}
+
+// track runs collect for a task and applies the ErrorMode to the request
+// errors logged meanwhile. Tasks run inside another, such as a GraphQL task
+// falling back to REST, are left to the outer one.
+func (c *GitHubCollector) track(ctx context.Context, user, repo, metric string, collect func(context.Context) (UserMetrics, error)) (UserMetrics, error) {
+	if ctx.Value(taskErrorsKey{}) != nil {
+		return collect(ctx)
+	}
+	errs := &taskErrors{}
+	m, err := collect(context.WithValue(ctx, taskErrorsKey{}, errs))
+	if err != nil {
+		return m, err
+	}
+	taskErr := errs.first()
+	if taskErr == nil && !c.undiscovered(user) {
+		return m, nil
+	}
+	if c.ErrorMode == ErrorModeFail {
+		return m, taskErr
+	}
+	if taskErr != nil {
+		c.addIncomplete(IncompleteTask{User: user, Repo: repo, Metric: metric, Error: taskErr.Error(), Err: taskErr})
+	}
+	return markUnknown(m, metric), nil
+}
+
+// incompleteTasks records the tasks of the GitLab and Gitea collectors left
+// incomplete by request errors.
+type incompleteTasks struct {
+	mu    sync.Mutex
+	tasks []IncompleteTask
+}
+
+// track runs collect for a task and applies errorMode to the request errors
+// logged meanwhile.
+func (t *incompleteTasks) track(ctx context.Context, errorMode, user, repo, metric string, collect func(context.Context) (UserMetrics, error)) (UserMetrics, error) {
+	errs := &taskErrors{}
+	m, err := collect(context.WithValue(ctx, taskErrorsKey{}, errs))
+	taskErr := errs.first()
+	if err != nil || taskErr == nil {
+		return m, err
+	}
+	if errorMode == ErrorModeFail {
+		return m, taskErr
+	}
+	t.mu.Lock()
+	t.tasks = append(t.tasks, IncompleteTask{User: user, Repo: repo, Metric: metric, Error: taskErr.Error(), Err: taskErr})
+	t.mu.Unlock()
+	return markUnknown(m, metric), nil
+}
+
+func (t *incompleteTasks) list() []IncompleteTask {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]IncompleteTask(nil), t.tasks...)
+}
+
+// markUnknown lists the metric, or all of them for MetricAll, as unknown.
+func markUnknown(m UserMetrics, metric string) UserMetrics {
+	m.Unknown = []string{metric}
+	if metric == MetricAll {
+		m.Unknown = append([]string(nil), AllMetrics...)
+	}
+	return m
+}
+
+func (c *GitHubCollector) addIncomplete(task IncompleteTask) {
+	c.incompleteMu.Lock()
+	defer c.incompleteMu.Unlock()
+	c.incomplete = append(c.incomplete, task)
+}
+
+// Incomplete returns the tasks whose metrics are incomplete so far, with
+// ErrorModePartial.
+func (c *GitHubCollector) Incomplete() []IncompleteTask {
+	c.incompleteMu.Lock()
+	defer c.incompleteMu.Unlock()
+	return append([]IncompleteTask(nil), c.incomplete...)
+}
+
+// undiscovered reports whether finding the user's repositories failed, so
+// that any of the user's metrics may be missing repositories.
+func (c *GitHubCollector) undiscovered(user string) bool {
+	c.incompleteMu.Lock()
+	defer c.incompleteMu.Unlock()
+	return c.undiscoveredUsers[user]
+}
+
+// IsUnknown reports whether request errors left the metric incomplete.
+func (m UserMetrics) IsUnknown(metric string) bool {
+	for _, unknown := range m.Unknown {
+		if unknown == metric {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeUnknown returns the sorted union of two lists of unknown metrics.
+func mergeUnknown(a, b []string) []string {
+	if len(b) == 0 {
+		return a
+	}
+	seen := make(map[string]bool, len(a)+len(b))
+	var merged []string
+	for _, metric := range append(append([]string(nil), a...), b...) {
+		if !seen[metric] {
+			seen[metric] = true
+			merged = append(merged, metric)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
@@ -0,0 +1,202 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// RequestCount is a number of API requests per rate-limit resource.
+type RequestCount struct {
+	Core    int
+	Search  int
+	GraphQL int
+}
+
+func (r RequestCount) add(o RequestCount) RequestCount {
+	return RequestCount{Core: r.Core + o.Core, Search: r.Search + o.Search, GraphQL: r.GraphQL + o.GraphQL}
+}
+
+func (r RequestCount) times(n int) RequestCount {
+	return RequestCount{Core: r.Core * n, Search: r.Search * n, GraphQL: r.GraphQL * n}
+}
+
+// MetricEstimate is the projected number of requests for one metric.
+type MetricEstimate struct {
+	Metric   string
+	Requests RequestCount
+}
+
+// Estimate is the projected API usage of a collection run, for --dry-run.
+// It counts the first page of every listing and search; HoC commit details,
+// pull request sizes and reviews, and review timings add requests per item
+// on top.
+type Estimate struct {
+	Users   int
+	Repos   int // Distinct repositories over all users
+	Tasks   int // (user, repository, metric) collection tasks
+	Metrics []MetricEstimate
+	Total   RequestCount
+}
+
+// taskCosts are the requests a metric makes for each (user, repository) task
+// with the REST API.
+var taskCosts = map[string]RequestCount{
+	MetricCommits: {Core: 1},
+	MetricHoC:     {Core: 1},
+	MetricIssues:  {Core: 1},
+	MetricLcP:     {Core: 1},
+	MetricPulls:   {Search: 2},
+	MetricReviews: {Search: 1},
+}
+
+// graphQLCosts replace taskCosts for metrics the GraphQL API collects.
+var graphQLCosts = map[string]RequestCount{
+	MetricCommits: {GraphQL: 1},
+	MetricHoC:     {GraphQL: 1},
+	MetricIssues:  {GraphQL: 1},
+	MetricLcP:     {GraphQL: 1},
+	MetricPulls:   {GraphQL: 1, Search: 1},
+}
+
+// sharedListings are metrics that reuse the listing of another metric, and
+// cost nothing more when both are collected.
+var sharedListings = map[string]string{
+	MetricHoC: MetricCommits,
+	MetricLcP: MetricIssues,
+}
+
+// repoStrategyCosts replace taskCosts with StrategyRepo, where each
+// repository is listed once for all users and only the first pull request
+// of each user is still searched per task.
+var repoStrategyCosts = map[string]struct{ repo, task RequestCount }{
+	MetricCommits: {repo: RequestCount{Core: 1}},
+	MetricHoC:     {repo: RequestCount{Core: 1}},
+	MetricIssues:  {repo: RequestCount{Core: 1}},
+	MetricLcP:     {repo: RequestCount{Core: 1}},
+	MetricPulls:   {repo: RequestCount{Core: 1}, task: RequestCount{Search: 2}},
+	MetricReviews: {repo: RequestCount{Core: 1}},
+}
+
+// repoStrategySharedListings replace sharedListings with StrategyRepo, where
+// reviews are found among the merged pull requests.
+var repoStrategySharedListings = map[string]string{
+	MetricHoC:     MetricCommits,
+	MetricLcP:     MetricIssues,
+	MetricReviews: MetricPulls,
+}
+
+// graphQLSharedListings replace sharedListings with the GraphQL API, where
+// commits and HoC come from the same history.
+var graphQLSharedListings = map[string]string{
+	MetricHoC: MetricCommits,
+}
+
+// perRepo are the requests of metrics that list a repository once and share
+// the result between users.
+var perRepo = map[string]RequestCount{
+	MetricMsgs:        {Core: 2},
+	MetricTriage:      {Core: 1},
+	MetricReverts:     {Core: 2},
+	MetricDiscussions: {GraphQL: 1},
+	MetricReleases:    {Core: 1, GraphQL: 1},
+	MetricWorkflows:   {Core: 2},
+	MetricSecurity:    {Core: 3},
+}
+
+// estimatedLatency is the assumed round trip of a request, which bounds
+// how fast a single worker can go.
+const estimatedLatency = 300 * time.Millisecond
+
+// EstimateRequests projects the requests of collecting metric for the given
+// repositories of each user with api (rest or graphql) and the collection
+// strategy, StrategyUser or StrategyRepo.
+func EstimateRequests(repos map[string][]string, metric, api, strategy string) Estimate {
+	names := []string{metric}
+	if metric == MetricAll {
+		names = AllMetrics
+	}
+
+	e := Estimate{Users: len(repos)}
+	distinct := make(map[string]bool)
+	tasks := 0
+	for _, list := range repos {
+		tasks += len(list)
+		for _, repo := range list {
+			distinct[repo] = true
+		}
+	}
+	e.Repos = len(distinct)
+	e.Tasks = tasks * len(names)
+
+	shares := sharedListings
+	switch {
+	case api == "graphql":
+		shares = graphQLSharedListings
+	case strategy == StrategyRepo:
+		shares = repoStrategySharedListings
+	}
+	collected := make(map[string]bool, len(names))
+	for _, name := range names {
+		collected[name] = true
+	}
+	for _, name := range names {
+		var requests RequestCount
+		if cost, ok := perRepo[name]; ok {
+			requests = cost.times(e.Repos)
+		} else if cost, ok := graphQLCosts[name]; ok && api == "graphql" {
+			requests = cost.times(tasks)
+		} else if cost, ok := repoStrategyCosts[name]; ok && strategy == StrategyRepo {
+			requests = cost.repo.times(e.Repos).add(cost.task.times(tasks))
+		} else {
+			requests = taskCosts[name].times(tasks)
+		}
+		if collected[shares[name]] {
+			// Listed along with the metric it shares with.
+			requests = RequestCount{}
+		}
+		e.Metrics = append(e.Metrics, MetricEstimate{Metric: name, Requests: requests})
+		e.Total = e.Total.add(requests)
+	}
+	return e
+}
+
+// Duration estimates how long the requests take with the given number of
+// workers. Requests are paced per resource as during collection and, when
+// limits are known, wait for the quota to reset once it runs out.
+func (e Estimate) Duration(concurrency int, limits *github.RateLimits) time.Duration {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	resources := []struct {
+		name     string
+		requests int
+		rate     *github.Rate
+		window   time.Duration
+	}{
+		{resourceCore, e.Total.Core, limits.GetCore(), time.Hour},
+		{resourceSearch, e.Total.Search, limits.GetSearch(), time.Minute},
+		{resourceGraphQL, e.Total.GraphQL, limits.GetGraphQL(), time.Hour},
+	}
+
+	var longest time.Duration
+	for _, r := range resources {
+		interval := estimatedLatency / time.Duration(concurrency)
+		if minInterval[r.name] > interval {
+			interval = minInterval[r.name]
+		}
+		d := time.Duration(r.requests) * interval
+		if r.rate != nil && r.rate.Limit > 0 && r.requests > r.rate.Remaining {
+			// The remaining quota, then a full quota per window.
+			exhausted := time.Until(r.rate.Reset.Time)
+			exhausted += time.Duration((r.requests-r.rate.Remaining-1)/r.rate.Limit) * r.window
+			if exhausted > d {
+				d = exhausted
+			}
+		}
+		if d > longest {
+			longest = d
+		}
+	}
+	return longest
+}
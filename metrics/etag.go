@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// ETagCache stores GitHub API responses with their ETag under Dir, so that
+// repeating a request can make it conditional. GitHub answers a conditional
+// request for an unchanged resource with 304 Not Modified, which does not
+// count against the rate limit, and the stored response is used instead.
+type ETagCache struct {
+	Dir string
+	// Scope identifies the credentials, such as the token, since what a
+	// response contains depends on what they can see. It is hashed into the
+	// cache keys and never stored.
+	Scope string
+}
+
+// etagEntry is a cached response.
+type etagEntry struct {
+	URL    string      `json:"url"`
+	ETag   string      `json:"etag"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+func (c ETagCache) path(req *http.Request) string {
+	sum := sha256.Sum256([]byte(c.Scope + "\n" + req.Header.Get("Accept") + "\n" + req.URL.String()))
+	key := hex.EncodeToString(sum[:])
+	return filepath.Join(c.Dir, "etags", key[:2], key+".json")
+}
+
+func (c ETagCache) get(req *http.Request) (*etagEntry, bool) {
+	data, err := os.ReadFile(c.path(req))
+	if err != nil {
+		return nil, false
+	}
+	var entry etagEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != req.URL.String() || entry.ETag == "" {
+		return nil, false
+	}
+	return &entry, true
+}
+
+func (c ETagCache) put(req *http.Request, entry etagEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return writeCacheFile(c.path(req), data)
+}
+
+// WithETagCache returns a copy of client whose GET requests are made
+// conditional on the responses stored in cache.
+func WithETagCache(client *github.Client, cache ETagCache) *github.Client {
+	return withTransport(client, func(base http.RoundTripper) http.RoundTripper {
+		return &etagTransport{cache: cache, base: base}
+	})
+}
+
+// withTransport returns a copy of client sending its requests through the
+// transport wrap returns for the client's own.
+func withTransport(client *github.Client, wrap func(base http.RoundTripper) http.RoundTripper) *github.Client {
+	hc := client.Client()
+	base := hc.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	hc.Transport = wrap(base)
+	wrapped := github.NewClient(hc)
+	wrapped.BaseURL = client.BaseURL
+	wrapped.UploadURL = client.UploadURL
+	wrapped.UserAgent = client.UserAgent
+	return wrapped
+}
+
+// etagTransport sends If-None-Match with the ETag of the cached response of
+// a request and replays that response when GitHub reports it unchanged.
+type etagTransport struct {
+	cache ETagCache
+	base  http.RoundTripper
+}
+
+func (t *etagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" {
+		return t.base.RoundTrip(req)
+	}
+	entry, cached := t.cache.get(req)
+	if cached {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case cached && resp.StatusCode == http.StatusNotModified:
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return entry.response(resp), nil
+	case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		entry := etagEntry{URL: req.URL.String(), ETag: resp.Header.Get("ETag"), Header: resp.Header.Clone(), Body: body}
+		if err := t.cache.put(req, entry); err != nil {
+			log.Printf("Error caching response of %s: %v\n", req.URL, err)
+		}
+	}
+	return resp, nil
+}
+
+// response turns the 304 answer to a conditional request into the cached
+// response. The headers of the 304, such as the current rate limit, take
+// precedence over the cached ones; the pagination links are only cached.
+func (e *etagEntry) response(notModified *http.Response) *http.Response {
+	resp := *notModified
+	resp.StatusCode = http.StatusOK
+	resp.Status = "200 OK"
+	resp.Header = e.Header.Clone()
+	if resp.Header == nil {
+		resp.Header = make(http.Header)
+	}
+	for name, values := range notModified.Header {
+		resp.Header[name] = values
+	}
+	resp.Header.Set("Content-Length", strconv.Itoa(len(e.Body)))
+	resp.ContentLength = int64(len(e.Body))
+	resp.TransferEncoding = nil
+	resp.Body = io.NopCloser(bytes.NewReader(e.Body))
+	return &resp
+}
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// EvidenceItem references one item that was counted towards a metric.
+type EvidenceItem struct {
+	Metric string  `json:"metric"`          // Metric the item counted towards, e.g. commits
+	Kind   string  `json:"kind"`            // What the item is, e.g. commit, pull or review_comment
+	Repo   string  `json:"repo"`            // Repository as owner/name
+	ID     string  `json:"id"`              // Commit SHA, issue or pull request number, or item ID
+	URL    string  `json:"url,omitempty"`   // Web page of the item
+	Value  float64 `json:"value,omitempty"` // What the item added when not one, e.g. HoC lines or hours
+}
+
+// Evidence records the items behind every user's metrics so a result can be
+// traced back to what was counted. It is safe for concurrent use, and a nil
+// Evidence records nothing.
+type Evidence struct {
+	mu    sync.Mutex
+	items map[string][]EvidenceItem
+}
+
+// NewEvidence returns an empty Evidence.
+func NewEvidence() *Evidence {
+	return &Evidence{items: make(map[string][]EvidenceItem)}
+}
+
+// Add records an item counted for user.
+func (e *Evidence) Add(user string, item EvidenceItem) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.items[user] = append(e.items[user], item)
+}
+
+// Forget drops the items recorded for users, compared case-insensitively.
+func (e *Evidence) Forget(users []string) {
+	if e == nil {
+		return
+	}
+	forget := userSet(users)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for user := range e.items {
+		if forget[strings.ToLower(user)] {
+			delete(e.items, user)
+		}
+	}
+}
+
+// Save writes the recorded items to path as JSON, keyed by user and sorted by
+// metric, repository and ID.
+func (e *Evidence) Save(path string) error {
+	e.mu.Lock()
+	users := make(map[string][]EvidenceItem, len(e.items))
+	for user, items := range e.items {
+		sorted := append([]EvidenceItem(nil), items...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			a, b := sorted[i], sorted[j]
+			if a.Metric != b.Metric {
+				return a.Metric < b.Metric
+			}
+			if a.Repo != b.Repo {
+				return a.Repo < b.Repo
+			}
+			return a.ID < b.ID
+		})
+		users[user] = sorted
+	}
+	e.mu.Unlock()
+
+	data, err := json.MarshalIndent(map[string]interface{}{"users": users}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
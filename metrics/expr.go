@@ -0,0 +1,286 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// scoreVariables are the metrics a score expression can refer to.
+var scoreVariables = map[string]func(UserMetrics) float64{
+	"commits":                 func(m UserMetrics) float64 { return float64(m.Commits) },
+	"hoc":                     func(m UserMetrics) float64 { return float64(m.HoC) },
+	"deletions":               func(m UserMetrics) float64 { return float64(m.Deletions) },
+	"churn":                   func(m UserMetrics) float64 { return float64(m.Churn) },
+	"net_lines":               func(m UserMetrics) float64 { return float64(m.NetLines) },
+	"issues":                  func(m UserMetrics) float64 { return float64(m.Issues) },
+	"lcp":                     func(m UserMetrics) float64 { return m.LcP },
+	"msgs":                    func(m UserMetrics) float64 { return float64(m.Msgs) },
+	"pulls":                   func(m UserMetrics) float64 { return float64(m.Pulls) },
+	"reviews":                 func(m UserMetrics) float64 { return float64(m.Reviews) },
+	"issue_comments":          func(m UserMetrics) float64 { return float64(m.IssueComments) },
+	"pr_comments":             func(m UserMetrics) float64 { return float64(m.PRComments) },
+	"review_comments":         func(m UserMetrics) float64 { return float64(m.ReviewComments) },
+	"approvals":               func(m UserMetrics) float64 { return float64(m.Approvals) },
+	"changes_requested":       func(m UserMetrics) float64 { return float64(m.ChangesRequested) },
+	"time_to_first_review":    func(m UserMetrics) float64 { return m.TimeToFirstReview },
+	"pending_review_requests": func(m UserMetrics) float64 { return float64(m.PendingReviewRequests) },
+	"ignored_review_requests": func(m UserMetrics) float64 { return float64(m.IgnoredReviewRequests) },
+	"code_owner_pulls":        func(m UserMetrics) float64 { return float64(m.CodeOwnerPulls) },
+	"code_owner_reviews":      func(m UserMetrics) float64 { return float64(m.CodeOwnerReviews) },
+	"median_lcp":              func(m UserMetrics) float64 { return m.MedianLcP() },
+	"first_review_wait":       func(m UserMetrics) float64 { return m.MedianFirstReviewWait() },
+	"review_rounds":           func(m UserMetrics) float64 { return m.MedianReviewRounds() },
+	"merge_wait":              func(m UserMetrics) float64 { return m.MedianMergeWait() },
+	"labeled":                 func(m UserMetrics) float64 { return float64(m.Labeled) },
+	"assigned":                func(m UserMetrics) float64 { return float64(m.Assigned) },
+	"issues_closed":           func(m UserMetrics) float64 { return float64(m.IssuesClosed) },
+	"issues_closed_manually":  func(m UserMetrics) float64 { return float64(m.IssuesClosedManually) },
+	"issues_fixed":            func(m UserMetrics) float64 { return float64(m.IssuesFixed) },
+	"planned_issues":          func(m UserMetrics) float64 { return float64(m.PlannedIssues) },
+	"planned_pulls":           func(m UserMetrics) float64 { return float64(m.PlannedPulls) },
+	"time_to_triage":          func(m UserMetrics) float64 { return m.TimeToTriage },
+	"discussions_started":     func(m UserMetrics) float64 { return float64(m.DiscussionsStarted) },
+	"discussion_comments":     func(m UserMetrics) float64 { return float64(m.DiscussionComments) },
+	"discussion_answers":      func(m UserMetrics) float64 { return float64(m.DiscussionAnswers) },
+	"reverts":                 func(m UserMetrics) float64 { return float64(m.Reverts) },
+	"force_pushes":            func(m UserMetrics) float64 { return float64(m.ForcePushes) },
+	"releases_published":      func(m UserMetrics) float64 { return float64(m.ReleasesPublished) },
+	"tags_created":            func(m UserMetrics) float64 { return float64(m.TagsCreated) },
+	"shipped_in":              func(m UserMetrics) float64 { return float64(m.ShippedIn) },
+	"workflows_added":         func(m UserMetrics) float64 { return float64(m.WorkflowsAdded) },
+	"workflows_modified":      func(m UserMetrics) float64 { return float64(m.WorkflowsModified) },
+	"runs_fixed":              func(m UserMetrics) float64 { return float64(m.RunsFixed) },
+	"docs":                    func(m UserMetrics) float64 { return float64(m.Docs) },
+	"docs_commits":            func(m UserMetrics) float64 { return float64(m.DocsCommits) },
+	"alerts_fixed":            func(m UserMetrics) float64 { return float64(m.SecurityAlertsFixed) },
+	"alerts_dismissed":        func(m UserMetrics) float64 { return float64(m.SecurityAlertsDismissed) },
+	"dependabot_merged":       func(m UserMetrics) float64 { return float64(m.DependabotPullsMerged) },
+	"dependabot_reviewed":     func(m UserMetrics) float64 { return float64(m.DependabotPullsReviewed) },
+	"tone":                    func(m UserMetrics) float64 { return m.Tone },
+	"harsh_comments":          func(m UserMetrics) float64 { return float64(m.HarshComments) },
+	"active_days":             func(m UserMetrics) float64 { return float64(m.ActiveDays) },
+	"longest_streak":          func(m UserMetrics) float64 { return float64(m.LongestStreak) },
+}
+
+// ScoreVariables returns, sorted, the metric names a score expression can
+// use.
+func ScoreVariables() []string {
+	names := make([]string, 0, len(scoreVariables))
+	for name := range scoreVariables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExprScorer scores users with an arithmetic expression over their metrics,
+// such as "hoc*0.5 + pulls*300 + reviews*200 - reverts*500". Expressions
+// combine numbers and the metrics of ScoreVariables with +, -, *, / and
+// parentheses. Division by zero yields 0.
+type ExprScorer struct {
+	expr string
+	eval func(UserMetrics) float64
+}
+
+// NewExprScorer parses expr, reporting syntax errors and unknown metrics
+// with their position.
+func NewExprScorer(expr string) (ExprScorer, error) {
+	p := &exprParser{input: expr}
+	p.next()
+	eval, err := p.sum()
+	if err == nil && p.tok.kind != tokEOF {
+		err = p.unexpected()
+	}
+	if err != nil {
+		return ExprScorer{}, fmt.Errorf("score expression: %w", err)
+	}
+	return ExprScorer{expr: expr, eval: eval}, nil
+}
+
+func (s ExprScorer) Score(metrics UserMetrics) float64 {
+	if s.eval == nil {
+		return 0
+	}
+	return s.eval(metrics)
+}
+
+// String returns the expression the scorer was parsed from.
+func (s ExprScorer) String() string {
+	return s.expr
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokName
+	tokOp // One of + - * / ( )
+	tokInvalid
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	pos   int // Position in the expression, counting bytes from 1
+	value float64
+}
+
+// exprParser is a recursive descent parser over the grammar
+//
+//	sum     = product { ("+" | "-") product }
+//	product = unary { ("*" | "/") unary }
+//	unary   = "-" unary | primary
+//	primary = number | metric | "(" sum ")"
+//
+// compiling the expression into a function as it goes.
+type exprParser struct {
+	input string
+	pos   int
+	tok   token
+}
+
+// next reads the following token into p.tok.
+func (p *exprParser) next() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos == len(p.input) {
+		p.tok = token{kind: tokEOF, pos: start + 1}
+		return
+	}
+	c := p.input[p.pos]
+	switch {
+	case c >= '0' && c <= '9' || c == '.':
+		for p.pos < len(p.input) && (p.input[p.pos] >= '0' && p.input[p.pos] <= '9' || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		text := p.input[start:p.pos]
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			p.tok = token{kind: tokInvalid, text: text, pos: start + 1}
+			return
+		}
+		p.tok = token{kind: tokNumber, text: text, pos: start + 1, value: value}
+	case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		for p.pos < len(p.input) && isNameByte(p.input[p.pos]) {
+			p.pos++
+		}
+		p.tok = token{kind: tokName, text: p.input[start:p.pos], pos: start + 1}
+	case strings.IndexByte("+-*/()", c) >= 0:
+		p.pos++
+		p.tok = token{kind: tokOp, text: string(c), pos: start + 1}
+	default:
+		p.pos++
+		p.tok = token{kind: tokInvalid, text: string(c), pos: start + 1}
+	}
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// unexpected describes the current token as an error.
+func (p *exprParser) unexpected() error {
+	if p.tok.kind == tokEOF {
+		return fmt.Errorf("unexpected end of expression at position %d", p.tok.pos)
+	}
+	return fmt.Errorf("unexpected %q at position %d", p.tok.text, p.tok.pos)
+}
+
+func (p *exprParser) isOp(ops string) bool {
+	return p.tok.kind == tokOp && strings.Contains(ops, p.tok.text)
+}
+
+func (p *exprParser) sum() (func(UserMetrics) float64, error) {
+	left, err := p.product()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+-") {
+		op := p.tok.text
+		p.next()
+		right, err := p.product()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		if op == "+" {
+			left = func(m UserMetrics) float64 { return l(m) + right(m) }
+		} else {
+			left = func(m UserMetrics) float64 { return l(m) - right(m) }
+		}
+	}
+	return left, nil
+}
+
+func (p *exprParser) product() (func(UserMetrics) float64, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*/") {
+		op := p.tok.text
+		p.next()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		if op == "*" {
+			left = func(m UserMetrics) float64 { return l(m) * right(m) }
+		} else {
+			left = func(m UserMetrics) float64 {
+				divisor := right(m)
+				if divisor == 0 {
+					return 0
+				}
+				return l(m) / divisor
+			}
+		}
+	}
+	return left, nil
+}
+
+func (p *exprParser) unary() (func(UserMetrics) float64, error) {
+	if p.isOp("-") {
+		p.next()
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(m UserMetrics) float64 { return -operand(m) }, nil
+	}
+	return p.primary()
+}
+
+func (p *exprParser) primary() (func(UserMetrics) float64, error) {
+	tok := p.tok
+	switch {
+	case tok.kind == tokNumber:
+		p.next()
+		return func(UserMetrics) float64 { return tok.value }, nil
+	case tok.kind == tokName:
+		variable, ok := scoreVariables[strings.ToLower(tok.text)]
+		if !ok {
+			return nil, fmt.Errorf("unknown metric %q at position %d, expected one of %s", tok.text, tok.pos, strings.Join(ScoreVariables(), ", "))
+		}
+		p.next()
+		return variable, nil
+	case p.isOp("("):
+		p.next()
+		inner, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isOp(")") {
+			return nil, p.unexpected()
+		}
+		p.next()
+		return inner, nil
+	}
+	return nil, p.unexpected()
+}
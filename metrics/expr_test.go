@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExprScorer(t *testing.T) {
+	m := UserMetrics{HoC: 100, Pulls: 3, Reviews: 4}
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"1+2*3", 7},
+		{"(1+2)*3", 9},
+		{"10-4-3", 3},
+		{"8/4/2", 1},
+		{"-2*3", -6},
+		{"--2", 2},
+		{"1 - -hoc", 101},
+		{"hoc/0", 0},
+		{"pulls/(reviews-4)", 0},
+		{"HoC*0.5 + Pulls", 53},
+		{"hoc*0.5 + pulls*300 + reviews*200", 1750},
+	}
+	for _, tt := range tests {
+		s, err := NewExprScorer(tt.expr)
+		if err != nil {
+			t.Errorf("NewExprScorer(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := s.Score(m); got != tt.want {
+			t.Errorf("%q = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestExprScorerErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"hoc +", "unexpected end of expression at position 6"},
+		{"foo*2", `unknown metric "foo" at position 1`},
+		{"(1", "unexpected end of expression at position 3"},
+		{"1 2", `unexpected "2" at position 3`},
+		{"hoc $ 2", `unexpected "$" at position 5`},
+	}
+	for _, tt := range tests {
+		_, err := NewExprScorer(tt.expr)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("NewExprScorer(%q) = %v, want an error with %q", tt.expr, err, tt.want)
+		}
+	}
+}
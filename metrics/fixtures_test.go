@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/google/go-github/v50/github"
+)
+
+func TestFixturesRecordReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		fmt.Fprint(w, `[{"sha":"a1","commit":{"message":"Add widget sizing"}}]`)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	fixtures := Fixtures{Dir: t.TempDir()}
+	client, err := NewGitHubClient(ctx, "token", server.URL+"/", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := &github.CommitsListOptions{Author: "alice"}
+	if _, _, err := WithFixtureRecorder(client, fixtures).Repositories.ListCommits(ctx, "acme", "widgets", opts); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := os.ReadDir(fixtures.Dir); len(files) != 1 {
+		t.Fatalf("recorded %d fixtures, want 1", len(files))
+	}
+
+	replay, err := NewFixtureClient(fixtures, "https://github.example.com/", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits, resp, err := replay.Repositories.ListCommits(ctx, "acme", "widgets", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("GitHub called %d times, want once while recording", calls)
+	}
+	if len(commits) != 1 || commits[0].GetSHA() != "a1" {
+		t.Errorf("replayed %v, want commit a1", commits)
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") != "" {
+		t.Error("replayed the recorded rate limit")
+	}
+
+	_, resp, err = replay.Repositories.ListCommits(ctx, "acme", "widgets", &github.CommitsListOptions{Author: "bob"})
+	if err == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("got %v for a request without fixture, want 404", err)
+	}
+}
+
+func TestNewFixtureClientMissingDir(t *testing.T) {
+	if _, err := NewFixtureClient(Fixtures{Dir: "testdata/missing"}, "", ""); err == nil {
+		t.Error("no error for a missing fixtures directory")
+	}
+}
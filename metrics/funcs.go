@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// organizations splits the Organization of a report, which lists several
+// organizations separated by commas.
+func organizations(org string) []string {
+	var orgs []string
+	for _, name := range strings.Split(org, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			orgs = append(orgs, name)
+		}
+	}
+	return orgs
+}
+
+// formatNumber formats an integer or float with thousands separators, e.g.
+// 12,345 or 1,234.50.
+func formatNumber(v interface{}) (string, error) {
+	var s string
+	switch n := v.(type) {
+	case int:
+		s = strconv.Itoa(n)
+	case int64:
+		s = strconv.FormatInt(n, 10)
+	case float64:
+		s = strconv.FormatFloat(n, 'f', 2, 64)
+	default:
+		return "", fmt.Errorf("formatNumber: unsupported type %T", v)
+	}
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, fraction = s[:i], s[i:]
+	}
+	var b strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return sign + b.String() + fraction, nil
+}
+
+// percent formats part as a whole percentage of total, or "-" when total is
+// zero.
+func percent(part, total interface{}) (string, error) {
+	p, err := toFloat(part)
+	if err != nil {
+		return "", err
+	}
+	t, err := toFloat(total)
+	if err != nil {
+		return "", err
+	}
+	if t == 0 {
+		return "-", nil
+	}
+	return fmt.Sprintf("%.0f%%", p*100/t), nil
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	default:
+		return 0, fmt.Errorf("unsupported number type %T", v)
+	}
+}
+
+// durationHuman formats a duration given in hours, as metrics store them,
+// e.g. 2d 5h, 3h 20m or 45m.
+func durationHuman(hours float64) string {
+	d := time.Duration(hours * float64(time.Hour)).Round(time.Minute)
+	days := d / (24 * time.Hour)
+	h := d % (24 * time.Hour) / time.Hour
+	m := d % time.Hour / time.Minute
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, h)
+	case h > 0:
+		return fmt.Sprintf("%dh %dm", h, m)
+	default:
+		return fmt.Sprintf("%dm", m)
+	}
+}
+
+// rankBadge renders a leaderboard position: a medal for the first three
+// places and #N after that.
+func rankBadge(rank int) template.HTML {
+	badge := "#" + strconv.Itoa(rank)
+	switch rank {
+	case 1:
+		badge = "🥇"
+	case 2:
+		badge = "🥈"
+	case 3:
+		badge = "🥉"
+	}
+	return template.HTML(fmt.Sprintf(`<span class="rank rank-%d" title="Rank %d">%s</span>`, rank, rank, badge))
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// GeneratedPatterns are globs of files written by tools rather than people,
+// after GitHub Linguist's list: lock files, protocol buffer and other
+// generated code, minified assets and source maps.
+var GeneratedPatterns = []string{
+	"package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml", "bun.lockb",
+	"composer.lock", "Gemfile.lock", "Cargo.lock", "poetry.lock", "Pipfile.lock", "go.sum", "Gopkg.lock", "glide.lock",
+	"*.pb.go", "*.pb.gw.go", "*_pb2.py", "*_pb2_grpc.py", "*_pb2.pyi", "*.pb.cc", "*.pb.h", "*_pb.js", "*_pb.d.ts", "*.pb.swift", "*.pb.dart",
+	"zz_generated.*.go", "*_generated.go", "*.designer.cs",
+	"*.min.js", "*.min.css", "*.js.map", "*.css.map",
+}
+
+// IsGenerated reports whether filename matches GeneratedPatterns.
+func IsGenerated(filename string) bool {
+	for _, pattern := range GeneratedPatterns {
+		if MatchGlob(pattern, filename) {
+			return true
+		}
+	}
+	return false
+}
+
+// GitAttributes are the linguist-generated settings of a repository's
+// .gitattributes file, in file order.
+type GitAttributes []gitAttribute
+
+type gitAttribute struct {
+	pattern   string
+	generated bool
+}
+
+// ParseGitAttributes reads the patterns of a .gitattributes file that set
+// linguist-generated, or unset it with -linguist-generated or
+// linguist-generated=false.
+func ParseGitAttributes(data string) GitAttributes {
+	var attrs GitAttributes
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		for _, field := range fields[1:] {
+			switch field {
+			case "linguist-generated", "linguist-generated=true":
+				attrs = append(attrs, gitAttribute{pattern: fields[0], generated: true})
+			case "-linguist-generated", "linguist-generated=false":
+				attrs = append(attrs, gitAttribute{pattern: fields[0]})
+			}
+		}
+	}
+	return attrs
+}
+
+// Generated reports whether filename is generated: as the last matching
+// pattern of the attributes says, as in git, or else by GeneratedPatterns.
+func (a GitAttributes) Generated(filename string) bool {
+	for i := len(a) - 1; i >= 0; i-- {
+		if MatchGlob(a[i].pattern, filename) {
+			return a[i].generated
+		}
+	}
+	return IsGenerated(filename)
+}
+
+// gitAttributes fetches the .gitattributes file of the repository's default
+// branch, once per run. A repository without one has no attributes.
+func (c *GitHubCollector) gitAttributes(ctx context.Context, owner, repo string) GitAttributes {
+	result, err := c.shared.do("gitattributes/"+owner+"/"+repo, func() (interface{}, error) {
+		result, _, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/contents/{path}", func() (interface{}, *github.Response, error) {
+			file, resp, err := c.api().GetContents(ctx, owner, repo, ".gitattributes")
+			var respErr *github.ErrorResponse
+			if errors.As(err, &respErr) && respErr.Response != nil && respErr.Response.StatusCode == http.StatusNotFound {
+				return (*github.RepositoryContent)(nil), resp, nil
+			}
+			return file, resp, err
+		})
+		if err != nil || result.(*github.RepositoryContent) == nil {
+			return GitAttributes(nil), err
+		}
+		content, err := result.(*github.RepositoryContent).GetContent()
+		return ParseGitAttributes(content), err
+	})
+	if err != nil {
+		log.Printf("Error reading .gitattributes of %s/%s, detecting generated files by name only: %v\n", owner, repo, err)
+	}
+	return result.(GitAttributes)
+}
@@ -0,0 +1,510 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// giteaPageSize is the page size of Gitea listings, the default maximum of
+// its API.
+const giteaPageSize = 50
+
+// GiteaCollector is a Collector for Gitea and its fork Forgejo, such as a
+// self-hosted forge or Codeberg. Their API follows GitHub's closely:
+//
+//   - commits and hoc: commits on the default branch authored by the user
+//   - issues: issues the user opened, updated during the window
+//   - pulls and lcp: pull requests the user opened, merged or closed
+//   - reviews: reviews the user submitted, with their comment counts
+//   - msgs: the user's comments on issues and pull requests
+//
+// The triage, reverts and discussions metrics are not collected and stay
+// zero. Repositories are given as owner/name.
+type GiteaCollector struct {
+	BaseURL    string       // API root, e.g. https://gitea.example.com/api/v1/
+	Token      string       // Access token with read access to the repositories and issues
+	HTTPClient *http.Client // Defaults to http.DefaultClient
+
+	Since time.Time // Start of the measured window
+	Until time.Time // End of the measured window; zero means now
+
+	Repos        []string    // Fixed owner/name repositories to measure
+	Organization string      // Measure the repositories of this organization when Repos is empty
+	ExcludeRepos []string    // owner/name repositories never measured
+	Identities   Identities  // Emails and alternate logins matched to each user's commits
+	Labels       LabelFilter // Labels of the issues and pull requests counted
+	Verbose      bool
+
+	Retry     RetryPolicy // How failed requests are retried
+	ErrorMode string      // What request errors do, ErrorModePartial (the default) or ErrorModeFail
+
+	calls  atomic.Int64
+	shared shared // Listings and reviews used by several users and metrics
+
+	incomplete incompleteTasks
+}
+
+// NewGiteaCollector returns a collector for the Gitea or Forgejo API at
+// baseURL, measuring the last days days.
+func NewGiteaCollector(baseURL, token string, days int, verbose bool) *GiteaCollector {
+	return &GiteaCollector{
+		BaseURL: baseURL,
+		Token:   token,
+		Since:   time.Now().AddDate(0, 0, -days),
+		Verbose: verbose,
+		Retry:   DefaultRetryPolicy,
+	}
+}
+
+// GiteaWebURL returns the web UI root for a Gitea API root, e.g.
+// https://gitea.example.com/git for https://gitea.example.com/git/api/v1/.
+func GiteaWebURL(baseURL string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), "/api/v1")
+}
+
+// giteaUser is the part of a Gitea user the collector uses.
+type giteaUser struct {
+	Login string `json:"login"`
+}
+
+type giteaLabel struct {
+	Name string `json:"name"`
+}
+
+type giteaRepo struct {
+	FullName string `json:"full_name"`
+	Archived bool   `json:"archived"`
+}
+
+type giteaCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Author struct {
+			Email string    `json:"email"`
+			Date  time.Time `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+	Author  *giteaUser `json:"author"` // Nil when the email matches no account
+	Parents []struct {
+		SHA string `json:"sha"`
+	} `json:"parents"`
+	Stats *struct {
+		Additions int `json:"additions"`
+		Deletions int `json:"deletions"`
+	} `json:"stats"`
+}
+
+type giteaIssue struct {
+	Number    int          `json:"number"`
+	User      giteaUser    `json:"user"`
+	Labels    []giteaLabel `json:"labels"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	ClosedAt  *time.Time   `json:"closed_at"`
+	MergedAt  *time.Time   `json:"merged_at"` // Pull requests only
+	Additions int          `json:"additions"` // Pull requests only, from Gitea 1.21
+	Deletions int          `json:"deletions"` // Pull requests only, from Gitea 1.21
+}
+
+type giteaReview struct {
+	User          *giteaUser `json:"user"`
+	State         string     `json:"state"` // APPROVED, REQUEST_CHANGES, COMMENT or PENDING
+	CommentsCount int        `json:"comments_count"`
+	SubmittedAt   time.Time  `json:"submitted_at"`
+}
+
+type giteaComment struct {
+	User           giteaUser `json:"user"`
+	PullRequestURL string    `json:"pull_request_url"` // Empty for comments on issues
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// APICalls returns the number of Gitea API requests made so far.
+func (c *GiteaCollector) APICalls() int64 {
+	return c.calls.Load()
+}
+
+// EndpointFailures returns nothing: the Gitea collector has no circuit
+// breakers.
+func (c *GiteaCollector) EndpointFailures() []EndpointFailure {
+	return nil
+}
+
+// Incomplete returns the tasks whose metrics are incomplete so far, with
+// ErrorModePartial.
+func (c *GiteaCollector) Incomplete() []IncompleteTask {
+	return c.incomplete.list()
+}
+
+func (c *GiteaCollector) inWindow(t time.Time) bool {
+	return !t.Before(c.Since) && (c.Until.IsZero() || !t.After(c.Until))
+}
+
+// Repositories returns the fixed repositories, or the unarchived
+// repositories of the organization. Gitea has no cheap way to find the
+// repositories a user was active in, so one of them is required.
+func (c *GiteaCollector) Repositories(ctx context.Context, user string) ([]string, error) {
+	repos := c.Repos
+	if len(repos) == 0 {
+		if c.Organization == "" {
+			return nil, fmt.Errorf("no repositories or organization to measure for %s", user)
+		}
+		result, err := c.shared.do("org-repos", func() (interface{}, error) {
+			var list []giteaRepo
+			err := c.list(ctx, "orgs/"+url.PathEscape(c.Organization)+"/repos", nil, &list)
+			var names []string
+			for _, repo := range list {
+				if !repo.Archived {
+					names = append(names, repo.FullName)
+				}
+			}
+			return names, err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("listing repositories of organization %s: %w", c.Organization, err)
+		}
+		repos = result.([]string)
+	}
+
+	var kept []string
+	for _, repo := range repos {
+		excluded := false
+		for _, exclude := range c.ExcludeRepos {
+			if strings.EqualFold(repo, exclude) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			kept = append(kept, repo)
+		}
+	}
+	return kept, nil
+}
+
+// Collect computes a metric for the user in the owner/name repository,
+// applying the ErrorMode to the request errors logged meanwhile.
+func (c *GiteaCollector) Collect(ctx context.Context, user, repo, metric string) (UserMetrics, error) {
+	return c.incomplete.track(ctx, c.ErrorMode, user, repo, metric, func(ctx context.Context) (UserMetrics, error) {
+		return c.collect(ctx, user, repo, metric)
+	})
+}
+
+func (c *GiteaCollector) collect(ctx context.Context, user, repo, metric string) (UserMetrics, error) {
+	owner, name := ParseRepo(repo)
+	if owner == "" || name == "" {
+		return UserMetrics{}, fmt.Errorf("invalid repository %q, expected owner/name", repo)
+	}
+	path := "repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name)
+	switch metric {
+	case MetricCommits:
+		m := c.commits(ctx, user, repo, path)
+		return UserMetrics{Commits: m.Commits, DailyActivity: m.DailyActivity}, nil
+	case MetricHoC:
+		m := c.commits(ctx, user, repo, path)
+		m.Commits = 0
+		m.clearDays()
+		return m, nil
+	case MetricIssues:
+		return c.issues(ctx, user, repo, path), nil
+	case MetricLcP:
+		m := c.pulls(ctx, user, repo, path)
+		return UserMetrics{LcP: m.LcP, PullLifecycles: m.PullLifecycles}, nil
+	case MetricPulls:
+		m := c.pulls(ctx, user, repo, path)
+		m.LcP, m.PullLifecycles = 0, nil
+		return m, nil
+	case MetricReviews:
+		return c.reviews(ctx, user, repo, path), nil
+	case MetricMsgs:
+		return c.comments(ctx, user, repo, path), nil
+	case MetricTriage, MetricReverts, MetricDiscussions, MetricReleases, MetricWorkflows, MetricSecurity:
+		return UserMetrics{}, nil
+	case MetricAll:
+		m := Merge(c.commits(ctx, user, repo, path), c.issues(ctx, user, repo, path))
+		m = Merge(m, c.pulls(ctx, user, repo, path))
+		m = Merge(m, c.reviews(ctx, user, repo, path))
+		return Merge(m, c.comments(ctx, user, repo, path)), nil
+	default:
+		return UserMetrics{}, fmt.Errorf("unknown metric: %s", metric)
+	}
+}
+
+// commits counts the user's commits on the default branch and their HoC,
+// leaving out merge commits from HoC as the GitHub collector does.
+func (c *GiteaCollector) commits(ctx context.Context, user, repo, path string) UserMetrics {
+	var m UserMetrics
+	result, err := c.shared.do("commits/"+repo, func() (interface{}, error) {
+		var commits []giteaCommit
+		query := url.Values{"since": {c.Since.Format(time.RFC3339)}, "stat": {"true"}, "verification": {"false"}, "files": {"false"}}
+		if !c.Until.IsZero() {
+			query.Set("until", c.Until.Format(time.RFC3339))
+		}
+		err := c.list(ctx, path+"/commits", query, &commits)
+		return commits, err
+	})
+	if err != nil {
+		logError(ctx, err, "fetching commits in repo %s", repo)
+	}
+	for _, commit := range result.([]giteaCommit) {
+		// Gitea before 1.21 ignores since and until.
+		if !c.inWindow(commit.Commit.Author.Date) {
+			continue
+		}
+		login := ""
+		if commit.Author != nil {
+			login = commit.Author.Login
+		}
+		if !c.Identities.IsCommitAuthor(user, login, commit.Commit.Author.Email) {
+			continue
+		}
+		m.Commits++
+		m.addDay(commit.Commit.Author.Date)
+		if len(commit.Parents) <= 1 && commit.Stats != nil {
+			m.HoC += commit.Stats.Additions + commit.Stats.Deletions
+			m.addLines(commit.Stats.Additions, commit.Stats.Deletions)
+		}
+	}
+	m.Repos = map[string]int{repo: m.HoC}
+	if c.Verbose {
+		log.Printf("Total commits for user %s in repo %s: %d (HoC %d)\n", user, repo, m.Commits, m.HoC)
+	}
+	return m
+}
+
+// issues counts the issues the user opened that were updated during the
+// window.
+func (c *GiteaCollector) issues(ctx context.Context, user, repo, path string) UserMetrics {
+	var issues []giteaIssue
+	query := url.Values{"type": {"issues"}, "state": {"all"}, "created_by": {user}, "since": {c.Since.Format(time.RFC3339)}}
+	if !c.Until.IsZero() {
+		query.Set("before", c.Until.Format(time.RFC3339))
+	}
+	if err := c.list(ctx, path+"/issues", query, &issues); err != nil {
+		logError(ctx, err, "fetching issues for user %s in repo %s", user, repo)
+	}
+	var m UserMetrics
+	for _, issue := range issues {
+		// Gitea before 1.19 ignores created_by.
+		if strings.EqualFold(issue.User.Login, user) && c.Labels.MatchIssue(issue.labelNames()) {
+			m.Issues++
+		}
+	}
+	return m
+}
+
+// pulls counts the user's pull requests merged during the window, with
+// those reviewed by someone else as reviewed, and averages the hours from
+// opening to merging or closing of those updated during the window.
+func (c *GiteaCollector) pulls(ctx context.Context, user, repo, path string) UserMetrics {
+	var m UserMetrics
+	pulls, err := c.updatedPulls(ctx, repo, path)
+	if err != nil {
+		logError(ctx, err, "fetching pull requests in repo %s", repo)
+	}
+	total, closed := 0.0, 0
+	for _, pull := range pulls {
+		if !strings.EqualFold(pull.User.Login, user) || !c.Labels.MatchPull(pull.labelNames()) {
+			continue
+		}
+		end := pull.ClosedAt
+		if pull.MergedAt != nil {
+			end = pull.MergedAt
+		}
+		if end != nil {
+			total += end.Sub(pull.CreatedAt).Hours()
+			m.PullLifecycles = append(m.PullLifecycles, end.Sub(pull.CreatedAt).Hours())
+			closed++
+		}
+		if pull.MergedAt == nil || !c.inWindow(*pull.MergedAt) {
+			continue
+		}
+		m.Pulls++
+		m.addDay(*pull.MergedAt)
+		if size := pull.Additions + pull.Deletions; size > 0 {
+			m.PullSizes = append(m.PullSizes, size)
+		}
+		reviews, err := c.pullReviews(ctx, repo, path, pull.Number)
+		if err != nil {
+			logError(ctx, err, "fetching reviews of pull request #%d in repo %s", pull.Number, repo)
+		}
+		for _, review := range reviews {
+			if review.User != nil && !strings.EqualFold(review.User.Login, user) && review.State != "PENDING" {
+				m.ReviewedPulls++
+				break
+			}
+		}
+	}
+	if closed > 0 {
+		m.LcP = total / float64(closed)
+	}
+	if m.Pulls > 0 {
+		m.RepoPulls = map[string]int{repo: m.Pulls}
+	}
+	return m
+}
+
+// reviews counts the reviews the user submitted during the window on the
+// pull requests of others updated during it.
+func (c *GiteaCollector) reviews(ctx context.Context, user, repo, path string) UserMetrics {
+	var m UserMetrics
+	pulls, err := c.updatedPulls(ctx, repo, path)
+	if err != nil {
+		logError(ctx, err, "fetching pull requests in repo %s", repo)
+	}
+	for _, pull := range pulls {
+		if strings.EqualFold(pull.User.Login, user) || !c.Labels.MatchPull(pull.labelNames()) {
+			continue
+		}
+		reviews, err := c.pullReviews(ctx, repo, path, pull.Number)
+		if err != nil {
+			logError(ctx, err, "fetching reviews of pull request #%d in repo %s", pull.Number, repo)
+			continue
+		}
+		for _, review := range reviews {
+			if review.User == nil || !strings.EqualFold(review.User.Login, user) || !c.inWindow(review.SubmittedAt) {
+				continue
+			}
+			switch review.State {
+			case "APPROVED":
+				m.Approvals++
+			case "REQUEST_CHANGES":
+				m.ChangesRequested++
+			case "PENDING":
+				continue
+			}
+			m.Reviews++
+			m.ReviewComments += review.CommentsCount
+			m.addDay(review.SubmittedAt)
+		}
+	}
+	if c.Verbose {
+		log.Printf("Total reviews for user %s in repo %s: %d\n", user, repo, m.Reviews)
+	}
+	return m
+}
+
+// comments counts the user's comments on issues and pull requests during
+// the window.
+func (c *GiteaCollector) comments(ctx context.Context, user, repo, path string) UserMetrics {
+	var m UserMetrics
+	result, err := c.shared.do("comments/"+repo, func() (interface{}, error) {
+		var comments []giteaComment
+		query := url.Values{"since": {c.Since.Format(time.RFC3339)}}
+		if !c.Until.IsZero() {
+			query.Set("before", c.Until.Format(time.RFC3339))
+		}
+		err := c.list(ctx, path+"/issues/comments", query, &comments)
+		return comments, err
+	})
+	if err != nil {
+		logError(ctx, err, "fetching comments in repo %s", repo)
+	}
+	for _, comment := range result.([]giteaComment) {
+		if !strings.EqualFold(comment.User.Login, user) || !c.inWindow(comment.CreatedAt) {
+			continue
+		}
+		if comment.PullRequestURL != "" {
+			m.PRComments++
+		} else {
+			m.IssueComments++
+		}
+		m.addDay(comment.CreatedAt)
+	}
+	m.Msgs = m.IssueComments + m.PRComments
+	return m
+}
+
+// labelNames returns the names of the labels of the issue or pull request.
+func (i giteaIssue) labelNames() []string {
+	names := make([]string, 0, len(i.Labels))
+	for _, label := range i.Labels {
+		names = append(names, label.Name)
+	}
+	return names
+}
+
+// updatedPulls lists the pull requests of the repository updated during
+// the window, once per run. The listing is sorted by the last update, so it
+// stops at the first page reaching back before the window.
+func (c *GiteaCollector) updatedPulls(ctx context.Context, repo, path string) ([]giteaIssue, error) {
+	result, err := c.shared.do("pulls/"+repo, func() (interface{}, error) {
+		var pulls []giteaIssue
+		query := url.Values{"state": {"all"}, "sort": {"recentupdate"}, "limit": {strconv.Itoa(giteaPageSize)}}
+		for page := 1; ; page++ {
+			query.Set("page", strconv.Itoa(page))
+			var batch []giteaIssue
+			header, err := c.rest().get(ctx, path+"/pulls", query, &batch)
+			if err != nil {
+				return pulls, err
+			}
+			older := false
+			for _, pull := range batch {
+				if pull.UpdatedAt.Before(c.Since) {
+					older = true
+					continue
+				}
+				if c.Until.IsZero() || !pull.CreatedAt.After(c.Until) {
+					pulls = append(pulls, pull)
+				}
+			}
+			if older || !hasNextPage(header) {
+				return pulls, nil
+			}
+		}
+	})
+	return result.([]giteaIssue), err
+}
+
+// pullReviews lists the reviews of a pull request, once per run.
+func (c *GiteaCollector) pullReviews(ctx context.Context, repo, path string, number int) ([]giteaReview, error) {
+	result, err := c.shared.do(fmt.Sprintf("reviews/%s#%d", repo, number), func() (interface{}, error) {
+		var reviews []giteaReview
+		err := c.list(ctx, fmt.Sprintf("%s/pulls/%d/reviews", path, number), nil, &reviews)
+		return reviews, err
+	})
+	return result.([]giteaReview), err
+}
+
+// rest returns the client for the Gitea API.
+func (c *GiteaCollector) rest() restClient {
+	header := make(http.Header)
+	if c.Token != "" {
+		header.Set("Authorization", "token "+c.Token)
+	}
+	return restClient{baseURL: c.BaseURL, header: header, client: c.HTTPClient, retry: c.Retry, calls: &c.calls}
+}
+
+// list fetches every page of a listing into out, a pointer to a slice,
+// following the Link header.
+func (c *GiteaCollector) list(ctx context.Context, path string, query url.Values, out interface{}) error {
+	query = cloneValues(query)
+	query.Set("limit", strconv.Itoa(giteaPageSize))
+	query.Set("page", "1")
+	return c.rest().list(ctx, path, query, out, func(query url.Values, header http.Header) url.Values {
+		if !hasNextPage(header) {
+			return nil
+		}
+		page, _ := strconv.Atoi(query.Get("page"))
+		query.Set("page", strconv.Itoa(page+1))
+		return query
+	})
+}
+
+// hasNextPage reports whether the Link header of a listing links a next
+// page.
+func hasNextPage(header http.Header) bool {
+	for _, link := range header.Values("Link") {
+		if strings.Contains(link, `rel="next"`) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,782 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+	"golang.org/x/oauth2"
+)
+
+// GitHubCollector collects metrics through the GitHub REST API.
+type GitHubCollector struct {
+	Client       *github.Client
+	API          GitHubAPI // Optional replacement for the calls to Client, e.g. a fake in tests
+	Since        time.Time // Start of the measured window
+	Until        time.Time // End of the measured window; zero means now
+	Organization string    // Only repositories of this organization are considered when set
+	// Organizations are further organizations whose repositories are
+	// considered along with Organization's.
+	Organizations []string
+	Verbose       bool
+	Cache         CommitCache // Optional cache of commit details
+	// CommitConcurrency is how many commit details a HoC task fetches at
+	// once, at least 1. The requests share the rate limit pacing of every
+	// other request.
+	CommitConcurrency int
+	HoCSource         string     // Where HoC comes from, HoCSourceCommits (the default) or HoCSourceStats
+	HoCFilter         PathFilter // Files that count towards HoC
+	DocPaths          DocPaths   // Files that count towards Docs
+	Identities        Identities // Emails and alternate logins matched to each user's commits
+	Evidence          *Evidence  // Optional record of every counted item
+	// SquashAttribution counts the commits squash-merging a pull request
+	// for the pull request's author rather than whoever merged it, at the
+	// cost of listing the repository's commits and merged pull requests.
+	SquashAttribution bool
+	// WorkingHours, when set, count the commits and reviews made off hours.
+	WorkingHours WorkingHours
+	// StaleDays, when set, lists the user's pull requests open for longer
+	// with the pulls metric.
+	StaleDays int
+	// CodeOwners counts, with the reviews metric, the merged pull requests
+	// changing files each user owns by the repository's CODEOWNERS file and
+	// how many of them the user reviewed, at the cost of listing the files
+	// of every pull request merged in the repository.
+	CodeOwners bool
+	// Labels restricts the issues and pull requests counted to those with
+	// certain labels.
+	Labels LabelFilter
+	// Plan, when set, counts with the issues metric the items of a project
+	// or milestone each user completed, through the GraphQL API.
+	Plan Plan
+	// Security collects the security metric, which is opt-in as its APIs
+	// need the security_events scope or the Dependabot alerts and code
+	// scanning alerts permissions.
+	Security bool
+	// Tone rates, with the reviews metric, the tone of each user's review
+	// comments. It is experimental and never counts towards the score.
+	Tone bool
+
+	Repos      []string   // Fixed owner/name repositories to measure instead of discovering them
+	Discovery  string     // How repositories are discovered, DiscoveryActivity or DiscoveryOrg
+	RepoFilter RepoFilter // Filters for DiscoveryOrg
+
+	ExcludeRepos []string // owner/name repositories never measured
+
+	Strategy string // How API objects are fetched, StrategyUser (the default) or StrategyRepo
+
+	Retry     RetryPolicy // How failed requests are retried
+	ErrorMode string      // What request errors do, ErrorModePartial (the default) or ErrorModeFail
+
+	breakers       circuitBreakers
+	budget         rateBudget
+	rateLimitsOnce sync.Once
+	calls          atomic.Int64
+
+	orgReposOnce sync.Once
+	orgRepos     []string
+	orgReposErr  error
+
+	issueEventsMu     sync.Mutex
+	issueEventsByRepo map[string]*issueEvents
+
+	discussionsMu     sync.Mutex
+	discussionsByRepo map[string]*repoDiscussions
+
+	revertsMu     sync.Mutex
+	revertsByRepo map[string]*repoReverts
+
+	commentsMu     sync.Mutex
+	commentsByRepo map[string]*repoComments
+
+	incompleteMu      sync.Mutex
+	undiscoveredUsers map[string]bool // Users whose repository discovery failed
+	incomplete        []IncompleteTask
+
+	shared shared // Listings used by several metrics, fetched once
+}
+
+// DefaultWebURL is the web UI of github.com.
+const DefaultWebURL = "https://github.com"
+
+// DefaultCommitConcurrency is how many commit details a HoC task fetches at
+// once unless set otherwise.
+const DefaultCommitConcurrency = 8
+
+// NewGitHubClient returns a GitHub client authenticated with token. When
+// baseURL is set the client talks to that GitHub Enterprise Server API
+// instead of github.com; uploadURL defaults to baseURL.
+func NewGitHubClient(ctx context.Context, token, baseURL, uploadURL string) (*github.Client, error) {
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+	return newClient(oauth2.NewClient(ctx, ts), baseURL, uploadURL)
+}
+
+// newClient returns a client for github.com, or for the GitHub Enterprise
+// Server at baseURL when set, sending requests through hc.
+func newClient(hc *http.Client, baseURL, uploadURL string) (*github.Client, error) {
+	if baseURL == "" {
+		return github.NewClient(hc), nil
+	}
+	if uploadURL == "" {
+		uploadURL = baseURL
+	}
+	return github.NewEnterpriseClient(baseURL, uploadURL, hc)
+}
+
+// WebURL returns the web UI root for an API base URL, e.g.
+// https://ghe.example.com for https://ghe.example.com/api/v3/.
+func WebURL(baseURL string) string {
+	if baseURL == "" {
+		return DefaultWebURL
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return DefaultWebURL
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// NewGitHubCollector returns a collector measuring the last days days.
+func NewGitHubCollector(client *github.Client, days int, organization string, verbose bool) *GitHubCollector {
+	return &GitHubCollector{
+		Client:       client,
+		Since:        time.Now().AddDate(0, 0, -days),
+		Organization: organization,
+		Verbose:      verbose,
+		Retry:        DefaultRetryPolicy,
+
+		CommitConcurrency: DefaultCommitConcurrency,
+	}
+}
+
+// dateRange returns the search qualifier value matching the window, e.g.
+// ">2024-01-01" using op for an open window or "2024-01-01..2024-03-31" when
+// Until is set.
+func (c *GitHubCollector) dateRange(op string) string {
+	return DateRange(c.Since, c.Until, op)
+}
+
+// DateRange formats a search qualifier value for the window from since to
+// until. An open window, with a zero until, uses the comparison op.
+func DateRange(since, until time.Time, op string) string {
+	if until.IsZero() {
+		return op + since.Format("2006-01-02")
+	}
+	return since.Format("2006-01-02") + ".." + until.Format("2006-01-02")
+}
+
+// inWindow reports whether t falls into the measured window.
+func (c *GitHubCollector) inWindow(t time.Time) bool {
+	return !t.Before(c.Since) && (c.Until.IsZero() || !t.After(c.Until))
+}
+
+func (c *GitHubCollector) Collect(ctx context.Context, user, repoFullName, metric string) (UserMetrics, error) {
+	m, err := c.track(ctx, user, repoFullName, metric, func(ctx context.Context) (UserMetrics, error) {
+		return c.collect(ctx, user, repoFullName, metric)
+	})
+	// Collect calls nested in another task, as GraphQLCollector makes, are
+	// counted by that task.
+	if err == nil && ctx.Value(taskErrorsKey{}) == nil {
+		m.addVisibility(c.RepoVisibility(ctx, repoFullName))
+	}
+	return m, err
+}
+
+func (c *GitHubCollector) collect(ctx context.Context, user, repoFullName, metric string) (UserMetrics, error) {
+	owner, repoName := ParseRepo(repoFullName)
+	if owner == "" || repoName == "" {
+		log.Printf("Skipping invalid repo string: %s", repoFullName)
+		return UserMetrics{}, nil
+	}
+
+	switch metric {
+	case MetricCommits:
+		return c.commits(ctx, owner, repoName, user), nil
+	case MetricHoC:
+		m := c.hoc(ctx, owner, repoName, user)
+		m.Repos = map[string]int{repoFullName: m.HoC}
+		return m, nil
+	case MetricIssues:
+		// Projects (v2) are only exposed through the GraphQL API.
+		m := NewGraphQLCollector(c).planned(ctx, owner, repoName, user)
+		m.Issues = c.issues(ctx, owner, repoName, user)
+		return m, nil
+	case MetricLcP:
+		return c.lcp(ctx, owner, repoName, user), nil
+	case MetricMsgs:
+		return c.msgs(ctx, owner, repoName, user), nil
+	case MetricPulls:
+		return c.pulls(ctx, owner, repoName, user), nil
+	case MetricReviews:
+		return c.reviews(ctx, owner, repoName, user), nil
+	case MetricTriage:
+		return c.triage(ctx, owner, repoName, user), nil
+	case MetricReverts:
+		return c.reverts(ctx, owner, repoName, user), nil
+	case MetricDiscussions:
+		// Discussions are only exposed through the GraphQL API.
+		return NewGraphQLCollector(c).discussions(ctx, owner, repoName, user), nil
+	case MetricReleases:
+		return c.releases(ctx, owner, repoName, user), nil
+	case MetricWorkflows:
+		return c.workflows(ctx, owner, repoName, user), nil
+	case MetricSecurity:
+		return c.security(ctx, owner, repoName, user), nil
+	case MetricAll:
+		m := c.hoc(ctx, owner, repoName, user)
+		m = Merge(m, c.commits(ctx, owner, repoName, user))
+		m.Issues = c.issues(ctx, owner, repoName, user)
+		m = Merge(m, NewGraphQLCollector(c).planned(ctx, owner, repoName, user))
+		m = Merge(m, c.lcp(ctx, owner, repoName, user))
+		m.Repos = map[string]int{repoFullName: m.HoC}
+		m = Merge(m, c.pulls(ctx, owner, repoName, user))
+		m = Merge(m, c.msgs(ctx, owner, repoName, user))
+		m = Merge(m, c.reviews(ctx, owner, repoName, user))
+		m = Merge(m, c.triage(ctx, owner, repoName, user))
+		m = Merge(m, c.reverts(ctx, owner, repoName, user))
+		m = Merge(m, c.releases(ctx, owner, repoName, user))
+		m = Merge(m, c.workflows(ctx, owner, repoName, user))
+		m = Merge(m, c.security(ctx, owner, repoName, user))
+		return Merge(m, NewGraphQLCollector(c).discussions(ctx, owner, repoName, user)), nil
+	default:
+		return UserMetrics{}, fmt.Errorf("unknown metric: %s", metric)
+	}
+}
+
+// retryWithBackoff runs fn, a request to endpoint against the given
+// rate-limit resource, paced by the collector's budget and retried as the
+// Retry policy allows. Calls to an endpoint that keeps failing are stopped
+// by its circuit breaker.
+func (c *GitHubCollector) retryWithBackoff(ctx context.Context, resource, endpoint string, fn func() (interface{}, *github.Response, error)) (interface{}, *github.Response, error) {
+	var err error
+
+	c.rateLimitsOnce.Do(func() { c.checkRateLimits(ctx) })
+	attempts := c.Retry.MaxRetries + 1
+	// Waiting out a rate limit is no failed attempt, but the waits are
+	// bounded too, so that a limit that never lifts cannot stall the run.
+	waits := 0
+	for i := 0; i < attempts; i++ {
+		var result interface{}
+		var resp *github.Response
+
+		if err := c.breakers.allow(endpoint); err != nil {
+			return nil, nil, err
+		}
+		waited, waitErr := c.budget.wait(ctx, resource)
+		if waitErr != nil {
+			return nil, nil, waitErr
+		}
+		if c.Verbose && waited >= 5*time.Second {
+			remaining, limit, reset := c.budget.remaining(resource)
+			log.Printf("Paced %s request by %s: %d of %d remaining until %s\n", resource, waited.Round(time.Second), remaining, limit, reset.Local().Format(time.Kitchen))
+		}
+
+		c.calls.Add(1)
+		result, resp, err = fn()
+
+		if resp != nil {
+			c.budget.update(resource, resp.Rate)
+		}
+
+		if err == nil {
+			c.breakers.success(endpoint)
+			return result, resp, nil
+		}
+		if ctx.Err() != nil {
+			// Cancelled, e.g. by Ctrl-C; retrying cannot succeed.
+			return nil, resp, ctx.Err()
+		}
+
+		log.Printf("Attempt %d failed with error: %v", i+1, err)
+
+		var rateErr *github.RateLimitError
+		if errors.As(err, &rateErr) {
+			log.Printf("Rate limit exceeded. Sleeping until rate limit reset at %v", rateErr.Rate.Reset.Local())
+			c.budget.exhaust(resource, rateErr.Rate.Reset.Add(time.Second)) // Adding extra buffer time
+			if waits++; waits > maxRateLimitWaits {
+				break
+			}
+			i--
+			continue
+		}
+		if wait, ok := secondaryLimit(err); ok {
+			if wait == 0 {
+				wait = backoff(time.Minute, waits)
+			}
+			log.Printf("Secondary rate limit hit. Pausing %s requests for %s", resource, wait.Round(time.Second))
+			// Every worker pauses, since the limit applies to all of them.
+			c.budget.exhaust(resource, time.Now().Add(wait))
+			if waits++; waits > maxRateLimitWaits {
+				break
+			}
+			i--
+			continue
+		}
+		if isClientError(err) {
+			// The endpoint works; the request was refused.
+			c.breakers.success(endpoint)
+			return nil, resp, err
+		}
+		if i < attempts-1 {
+			if err := sleep(ctx, c.Retry.delay(i)); err != nil {
+				return nil, resp, err
+			}
+		}
+	}
+
+	if !IsRateLimited(err) {
+		c.breakers.failure(endpoint, err)
+	}
+	return nil, nil, err
+}
+
+// EndpointFailures returns the endpoints whose circuit breaker stopped
+// calling them during the run.
+func (c *GitHubCollector) EndpointFailures() []EndpointFailure {
+	return c.breakers.failures()
+}
+
+// APICalls returns the number of GitHub API requests made so far.
+func (c *GitHubCollector) APICalls() int64 {
+	return c.calls.Load()
+}
+
+// TeamMembers returns the logins of the members of an organization team.
+func (c *GitHubCollector) TeamMembers(ctx context.Context, org, slug string) ([]string, error) {
+	var members []string
+	opts := &github.TeamListTeamMembersOptions{
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	for {
+		result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /orgs/{org}/teams/{team_slug}/members", func() (interface{}, *github.Response, error) {
+			return c.api().ListTeamMembers(ctx, org, slug, opts)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("listing members of team %s/%s: %w", org, slug, err)
+		}
+		for _, member := range result.([]*github.User) {
+			members = append(members, member.GetLogin())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	if c.Verbose {
+		log.Printf("Team %s/%s has %d members\n", org, slug, len(members))
+	}
+	return members, nil
+}
+
+// OrgMembers returns the logins of the members of an organization. role
+// filters by membership role ("all", "admin" or "member"); empty means all.
+func (c *GitHubCollector) OrgMembers(ctx context.Context, org, role string) ([]string, error) {
+	var members []string
+	opts := &github.ListMembersOptions{
+		Role: role,
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	for {
+		result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /orgs/{org}/members", func() (interface{}, *github.Response, error) {
+			return c.api().ListOrgMembers(ctx, org, opts)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("listing members of organization %s: %w", org, err)
+		}
+		for _, member := range result.([]*github.User) {
+			members = append(members, member.GetLogin())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	if c.Verbose {
+		log.Printf("Organization %s has %d members\n", org, len(members))
+	}
+	return members, nil
+}
+
+func (c *GitHubCollector) commits(ctx context.Context, owner, repo, user string) UserMetrics {
+	commitList, err := c.authoredCommits(ctx, owner, repo, user)
+	if err != nil {
+		logError(ctx, err, "fetching commits for user %s in repo %s/%s", user, owner, repo)
+	}
+	m := UserMetrics{Commits: len(commitList)}
+	for _, commit := range commitList {
+		authored := commit.GetCommit().GetAuthor().GetDate().Time
+		if !c.WorkingHours.IsZero() && c.WorkingHours.OffHours(authored) {
+			m.OffHoursCommits++
+		}
+		m.addDay(authored)
+		c.Evidence.Add(user, EvidenceItem{Metric: MetricCommits, Kind: "commit", Repo: owner + "/" + repo, ID: commit.GetSHA(), URL: commit.GetHTMLURL()})
+		if c.Verbose {
+			log.Printf("Found commit %s by %s in repo %s/%s\n", commit.GetSHA(), user, owner, repo)
+		}
+	}
+	return m
+}
+
+// hoc counts the HoC and line metrics of the user's commits in the
+// repository.
+func (c *GitHubCollector) hoc(ctx context.Context, owner, repo, user string) UserMetrics {
+	if c.HoCSource == HoCSourceStats {
+		if m, ok := c.statsHoC(ctx, owner, repo, user); ok {
+			return m
+		}
+	}
+	var m UserMetrics
+	commitList, err := c.authoredCommits(ctx, owner, repo, user)
+	if err != nil {
+		logError(ctx, err, "fetching commits for user %s in repo %s/%s", user, owner, repo)
+	}
+	allDetails := c.allCommitDetails(ctx, owner, repo, commitList)
+	var attrs GitAttributes
+	if c.HoCFilter.SkipGenerated && len(commitList) > 0 {
+		attrs = c.gitAttributes(ctx, owner, repo)
+	}
+	for i, commit := range commitList {
+		details := allDetails[i]
+		if details == nil {
+			continue
+		}
+		lines, docs := 0, 0
+		for _, file := range details.Files {
+			if c.DocPaths.Match(file.Filename) {
+				docs += file.Additions + file.Changes
+			}
+			if !c.HoCFilter.MatchFile(file.Filename, file.NoPatch, attrs) {
+				if c.Verbose {
+					log.Printf("Commit %s: file %s left out of HoC\n", commit.GetSHA(), file.Filename)
+				}
+				continue
+			}
+			lines += file.Additions + file.Changes
+			m.addLines(file.Additions, file.Deletions)
+			if c.Verbose {
+				log.Printf("Commit %s: file %s - additions: %d, changes: %d\n", commit.GetSHA(), file.Filename, file.Additions, file.Changes)
+			}
+		}
+		m.HoC += lines
+		c.Evidence.Add(user, EvidenceItem{Metric: MetricHoC, Kind: "commit", Repo: owner + "/" + repo, ID: commit.GetSHA(), URL: commit.GetHTMLURL(), Value: float64(lines)})
+		if docs > 0 {
+			m.Docs += docs
+			m.DocsCommits++
+			c.Evidence.Add(user, EvidenceItem{Metric: MetricHoC, Kind: "docs_commit", Repo: owner + "/" + repo, ID: commit.GetSHA(), URL: commit.GetHTMLURL(), Value: float64(docs)})
+		}
+	}
+
+	return m
+}
+
+// authoredCommits lists the user's non-merge commits in the window, once
+// for both commits and HoC. Besides the login itself, every alias in
+// Identities is queried, so commits by an alternate login or by an email not
+// linked to the account are included. With SquashAttribution, squash merges
+// count for the pull request's author. The commits fetched before an error
+// are returned with it.
+func (c *GitHubCollector) authoredCommits(ctx context.Context, owner, repo, user string) ([]*github.RepositoryCommit, error) {
+	result, err := c.shared.do("commits/"+owner+"/"+repo+"/"+user, func() (interface{}, error) {
+		var commits []*github.RepositoryCommit
+		var err error
+		if c.Strategy == StrategyRepo {
+			commits, err = c.filterAuthoredCommits(ctx, owner, repo, user)
+		} else {
+			commits, err = c.listAuthoredCommits(ctx, owner, repo, user)
+		}
+		if err != nil || !c.SquashAttribution {
+			return commits, err
+		}
+		return c.attributeSquashes(ctx, owner, repo, user, commits)
+	})
+	return result.([]*github.RepositoryCommit), err
+}
+
+// filterAuthoredCommits picks the user's non-merge commits from those of
+// the repository.
+func (c *GitHubCollector) filterAuthoredCommits(ctx context.Context, owner, repo, user string) ([]*github.RepositoryCommit, error) {
+	all, err := c.repoCommits(ctx, owner, repo)
+	var commitList []*github.RepositoryCommit
+	for _, commit := range all {
+		if !isMergeCommit(commit) && c.Identities.IsCommitAuthor(user, commit.GetAuthor().GetLogin(), commit.GetCommit().GetAuthor().GetEmail()) {
+			commitList = append(commitList, commit)
+		}
+	}
+	return commitList, err
+}
+
+func (c *GitHubCollector) listAuthoredCommits(ctx context.Context, owner, repo, user string) ([]*github.RepositoryCommit, error) {
+	var commitList []*github.RepositoryCommit
+	seen := make(map[string]bool)
+	for _, author := range append([]string{user}, c.Identities.Aliases(user)...) {
+		opts := &github.CommitsListOptions{
+			Author: author,
+			Since:  c.Since,
+			Until:  c.Until,
+			ListOptions: github.ListOptions{
+				PerPage: 100,
+			},
+		}
+		for {
+			result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/commits", func() (interface{}, *github.Response, error) {
+				return c.api().ListCommits(ctx, owner, repo, opts)
+			})
+			if err != nil {
+				return commitList, err
+			}
+			for _, commit := range result.([]*github.RepositoryCommit) {
+				if seen[commit.GetSHA()] || isMergeCommit(commit) {
+					continue
+				}
+				if !c.Identities.IsCommitAuthor(user, commit.GetAuthor().GetLogin(), commit.GetCommit().GetAuthor().GetEmail()) {
+					continue
+				}
+				seen[commit.GetSHA()] = true
+				commitList = append(commitList, commit)
+			}
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+	}
+	return commitList, nil
+}
+
+// allCommitDetails fetches the details of commits, CommitConcurrency at a
+// time, paced like every request by the rate limit. The details are in the
+// order of the commits; those of commits that failed are logged and left nil.
+func (c *GitHubCollector) allCommitDetails(ctx context.Context, owner, repo string, commits []*github.RepositoryCommit) []*CommitDetails {
+	details := make([]*CommitDetails, len(commits))
+	n := c.CommitConcurrency
+	if n < 1 {
+		n = 1
+	}
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for i, commit := range commits {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, sha string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			d, err := c.commitDetails(ctx, owner, repo, sha)
+			if err != nil {
+				logError(ctx, err, "fetching commit details for commit %s", sha)
+				return
+			}
+			details[i] = d
+		}(i, commit.GetSHA())
+	}
+	wg.Wait()
+	return details
+}
+
+// commitDetails returns the file statistics of a commit, from the cache when
+// available.
+func (c *GitHubCollector) commitDetails(ctx context.Context, owner, repo, sha string) (*CommitDetails, error) {
+	if c.Cache != nil {
+		if details, ok := c.Cache.Get(sha); ok {
+			return details, nil
+		}
+	}
+
+	result, _, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/commits/{ref}", func() (interface{}, *github.Response, error) {
+		return c.api().GetCommit(ctx, owner, repo, sha)
+	})
+	if err != nil {
+		return nil, err
+	}
+	commit := result.(*github.RepositoryCommit)
+	details := &CommitDetails{SHA: sha}
+	for _, file := range commit.Files {
+		details.Files = append(details.Files, CommitFile{
+			Filename:  file.GetFilename(),
+			Status:    file.GetStatus(),
+			Additions: file.GetAdditions(),
+			Deletions: file.GetDeletions(),
+			Changes:   file.GetChanges(),
+			NoPatch:   file.GetPatch() == "",
+		})
+	}
+
+	if c.Cache != nil {
+		if err := c.Cache.Put(details); err != nil {
+			log.Printf("Error caching commit details for commit %s: %v\n", sha, err)
+		}
+	}
+	return details, nil
+}
+
+// issues counts the open issues the user opened that were updated in the
+// window. The listing is shared with lcp, which needs the closed pull
+// requests, so it holds every state and the closed issues are left out here.
+func (c *GitHubCollector) issues(ctx context.Context, owner, repo, user string) int {
+	issues := 0
+	issueList, err := c.openedIssues(ctx, owner, repo, user)
+	if err != nil {
+		logError(ctx, err, "fetching issues for user %s in repo %s/%s", user, owner, repo)
+	}
+	for _, issue := range issueList {
+		if !issue.IsPullRequest() && issue.GetState() != "closed" && c.inWindow(issue.GetUpdatedAt().Time) && c.Labels.MatchIssue(labelNames(issue.Labels)) {
+			issues++
+			c.Evidence.Add(user, EvidenceItem{Metric: MetricIssues, Kind: "issue", Repo: owner + "/" + repo, ID: strconv.Itoa(issue.GetNumber()), URL: issue.GetHTMLURL()})
+			if c.Verbose {
+				log.Printf("Found issue #%d by %s in repo %s/%s\n", issue.GetNumber(), user, owner, repo)
+			}
+		}
+	}
+
+	if c.Verbose {
+		log.Printf("Total issues for user %s in repo %s/%s: %d\n", user, owner, repo, issues)
+	}
+
+	return issues
+}
+
+// lcp averages the lifecycle of the user's closed pull requests and adds
+// how the review of their merged pull requests went.
+func (c *GitHubCollector) lcp(ctx context.Context, owner, repo, user string) UserMetrics {
+	m := c.pullFlow(ctx, owner, repo, user)
+	totalTime := 0.0
+	count := 0
+	issues, err := c.openedIssues(ctx, owner, repo, user)
+	if err != nil {
+		logError(ctx, err, "fetching issues for user %s in repo %s/%s", user, owner, repo)
+		return m
+	}
+	for _, issue := range issues {
+		if issue.IsPullRequest() && issue.CreatedAt != nil && issue.ClosedAt != nil && c.inWindow(issue.GetUpdatedAt().Time) && c.Labels.MatchPull(labelNames(issue.Labels)) {
+			duration := issue.ClosedAt.Sub(issue.CreatedAt.Time).Hours()
+			totalTime += duration
+			count++
+			m.PullLifecycles = append(m.PullLifecycles, duration)
+			c.Evidence.Add(user, EvidenceItem{Metric: MetricLcP, Kind: "pull", Repo: owner + "/" + repo, ID: strconv.Itoa(issue.GetNumber()), URL: issue.GetHTMLURL(), Value: duration})
+			if c.Verbose {
+				log.Printf("Pull request #%d by %s: created at %s, closed at %s, duration: %.2f hours\n", issue.GetNumber(), user, issue.CreatedAt.String(), issue.ClosedAt.String(), duration)
+			}
+		}
+	}
+
+	if count == 0 {
+		return m
+	}
+
+	m.LcP = totalTime / float64(count)
+	if c.Verbose {
+		log.Printf("Average lifecycle of pull requests for user %s in repo %s/%s since %s: %.2f hours\n", user, owner, repo, c.Since.Format("2006-01-02"), m.LcP)
+	}
+	return m
+}
+
+// openedIssues lists the issues and pull requests the user opened that were
+// updated since the start of the window, once for both issues and LcP. The
+// issues fetched before an error are returned with it.
+func (c *GitHubCollector) openedIssues(ctx context.Context, owner, repo, user string) ([]*github.Issue, error) {
+	result, err := c.shared.do("issues/"+owner+"/"+repo+"/"+user, func() (interface{}, error) {
+		var issues []*github.Issue
+		if c.Strategy == StrategyRepo {
+			all, err := c.repoIssues(ctx, owner, repo)
+			for _, issue := range all {
+				if strings.EqualFold(issue.GetUser().GetLogin(), user) {
+					issues = append(issues, issue)
+				}
+			}
+			return issues, err
+		}
+		opts := &github.IssueListByRepoOptions{
+			Creator: user,
+			State:   "all",
+			Since:   c.Since,
+			ListOptions: github.ListOptions{
+				PerPage: 100,
+			},
+		}
+		for {
+			if c.Verbose {
+				log.Printf("Fetching issues for user %s in repo %s/%s\n", user, owner, repo)
+			}
+			result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/issues", func() (interface{}, *github.Response, error) {
+				return c.api().ListIssues(ctx, owner, repo, opts)
+			})
+			if err != nil {
+				return issues, err
+			}
+			issues = append(issues, result.([]*github.Issue)...)
+			if resp.NextPage == 0 {
+				return issues, nil
+			}
+			opts.Page = resp.NextPage
+		}
+	})
+	return result.([]*github.Issue), err
+}
+
+// pulls counts the user's merged pull requests and records the lines each
+// one changed.
+func (c *GitHubCollector) pulls(ctx context.Context, owner, repo, user string) UserMetrics {
+	var m UserMetrics
+	pulls, err := c.authoredPulls(ctx, owner, repo, user)
+	if err != nil {
+		logError(ctx, err, "fetching pull requests for user %s in repo %s/%s", user, owner, repo)
+	}
+	for _, pull := range pulls {
+		m.Pulls++
+		m.addDay(pull.merged)
+		if c.Verbose {
+			log.Printf("Pull request #%d by %s in repo %s/%s was merged at %s\n", pull.number, user, owner, repo, pull.merged)
+		}
+		item := EvidenceItem{Metric: MetricPulls, Kind: "pull", Repo: owner + "/" + repo, ID: strconv.Itoa(pull.number), URL: pull.url}
+		if size, ok := c.pullSize(ctx, owner, repo, pull.number); ok {
+			m.PullSizes = append(m.PullSizes, size)
+			item.Value = float64(size)
+		}
+		if c.pullReviewed(ctx, owner, repo, user, pull.number) {
+			m.ReviewedPulls++
+		}
+		c.Evidence.Add(user, item)
+	}
+
+	if m.Pulls > 0 {
+		m.RepoPulls = map[string]int{owner + "/" + repo: m.Pulls}
+	}
+	m.FirstPull, m.FirstMerge = c.firstPull(ctx, owner, repo, user)
+	m.StalePulls = c.stalePulls(ctx, owner, repo, user)
+	return m
+}
+
+// pullSize returns the lines added plus deleted by a pull request.
+func (c *GitHubCollector) pullSize(ctx context.Context, owner, repo string, number int) (int, bool) {
+	result, _, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/pulls/{pull_number}", func() (interface{}, *github.Response, error) {
+		return c.api().GetPullRequest(ctx, owner, repo, number)
+	})
+	if err != nil {
+		logError(ctx, err, "fetching pull request #%d in repo %s/%s", number, owner, repo)
+		return 0, false
+	}
+	pr := result.(*github.PullRequest)
+	return pr.GetAdditions() + pr.GetDeletions(), true
+}
+
+func isMergeCommit(commit *github.RepositoryCommit) bool {
+	return commit.Parents != nil && len(commit.Parents) > 1
+}
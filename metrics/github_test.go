@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+// The fixtures in testdata/fixtures are the responses to a collection of
+// acme/widgets, on a GitHub Enterprise Server at github.example.com, from
+// 2024-01-01 to 2024-03-31 for alice and bob:
+//   - alice authored commits a1 and a2, with 81 HoC, and the merged pull
+//     requests #10 and #11, opened issue #5 and closed issue #6, and
+//     approved #12
+//   - bob authored commit b1, with 120 HoC, and the merged pull request #12,
+//     and reviewed #10 and #11, requesting changes on #11 first
+//   - each of them commented once on a pull request of the other
+
+func TestMain(m *testing.M) {
+	// Replayed responses come back at once; pacing them as GitHub's
+	// secondary limits require only slows the tests down.
+	for resource := range minInterval {
+		minInterval[resource] = 0
+	}
+	os.Exit(m.Run())
+}
+
+// replayCollector returns a collector answered from testdata/fixtures.
+func replayCollector(t *testing.T) *GitHubCollector {
+	t.Helper()
+	client, err := NewFixtureClient(Fixtures{Dir: "testdata/fixtures"}, "https://github.example.com/", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewGitHubCollector(client, 0, "", false)
+	c.Since = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.Until = time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC)
+	c.Repos = []string{"acme/widgets"}
+	return c
+}
+
+func TestGitHubCollectorCollect(t *testing.T) {
+	tests := []struct {
+		metric string
+		user   string
+		check  func(m UserMetrics) bool
+	}{
+		{MetricCommits, "alice", func(m UserMetrics) bool { return m.Commits == 2 }},
+		{MetricCommits, "bob", func(m UserMetrics) bool { return m.Commits == 1 }},
+		{MetricHoC, "alice", func(m UserMetrics) bool { return m.HoC == 81 && m.Repos["acme/widgets"] == 81 }},
+		{MetricHoC, "bob", func(m UserMetrics) bool { return m.HoC == 120 }},
+		{MetricPulls, "alice", func(m UserMetrics) bool { return m.Pulls == 2 && len(m.PullSizes) == 2 }},
+		{MetricPulls, "bob", func(m UserMetrics) bool { return m.Pulls == 1 }},
+		{MetricReviews, "alice", func(m UserMetrics) bool {
+			return m.Reviews == 1 && m.Approvals == 1 && m.TimeToFirstReview == 48
+		}},
+		{MetricReviews, "bob", func(m UserMetrics) bool {
+			return m.Reviews == 2 && m.Approvals == 2 && m.ChangesRequested == 1 && m.TimeToFirstReview == 25
+		}},
+		// Issue #6 is closed and does not count.
+		{MetricIssues, "alice", func(m UserMetrics) bool { return m.Issues == 1 }},
+		{MetricLcP, "alice", func(m UserMetrics) bool { return m.LcP == 51 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.metric+"/"+tt.user, func(t *testing.T) {
+			m, err := replayCollector(t).Collect(context.Background(), tt.user, "acme/widgets", tt.metric)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(m.Unknown) > 0 {
+				t.Fatalf("metrics %v unknown, a request has no fixture", m.Unknown)
+			}
+			if !tt.check(m) {
+				t.Errorf("unexpected metrics: %+v", m)
+			}
+		})
+	}
+}
+
+func TestGitHubCollectorMissingFixture(t *testing.T) {
+	m, err := replayCollector(t).Collect(context.Background(), "carol", "acme/widgets", MetricCommits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Commits != 0 || len(m.Unknown) != 1 || m.Unknown[0] != MetricCommits {
+		t.Errorf("got %d commits, unknown %v; want none, commits unknown", m.Commits, m.Unknown)
+	}
+}
+
+func TestCalculatorAll(t *testing.T) {
+	calc := &Calculator{Collector: replayCollector(t), Concurrency: 2}
+	got, err := calc.Calculate(context.Background(), []string{"alice", "bob"}, MetricAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]UserMetrics{
+		"alice": {Commits: 2, HoC: 81, Issues: 1, LcP: 51, Msgs: 1, Pulls: 2, Reviews: 1, Approvals: 1},
+		"bob":   {Commits: 1, HoC: 120, LcP: 72, Msgs: 1, Pulls: 1, Reviews: 2, Approvals: 2, ChangesRequested: 1},
+	}
+	for user, w := range want {
+		m, ok := got[user]
+		if !ok {
+			t.Errorf("%s missing from the results", user)
+			continue
+		}
+		if len(m.Unknown) > 0 {
+			t.Errorf("%s: metrics %v unknown, a request has no fixture", user, m.Unknown)
+		}
+		if m.Commits != w.Commits || m.HoC != w.HoC || m.Issues != w.Issues || m.LcP != w.LcP || m.Msgs != w.Msgs ||
+			m.Pulls != w.Pulls || m.Reviews != w.Reviews || m.Approvals != w.Approvals || m.ChangesRequested != w.ChangesRequested {
+			t.Errorf("%s: got commits %d, HoC %d, issues %d, LcP %.1f, msgs %d, pulls %d, reviews %d, approvals %d, changes requested %d; want %+v",
+				user, m.Commits, m.HoC, m.Issues, m.LcP, m.Msgs, m.Pulls, m.Reviews, m.Approvals, m.ChangesRequested, w)
+		}
+		if m.Repos["acme/widgets"] != w.HoC {
+			t.Errorf("%s: got repos %v, want acme/widgets with %d HoC", user, m.Repos, w.HoC)
+		}
+		if m.Score <= 0 {
+			t.Errorf("%s: got score %v, want it scored", user, m.Score)
+		}
+	}
+}
@@ -0,0 +1,513 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultGitLabURL is the API root of gitlab.com.
+const DefaultGitLabURL = "https://gitlab.com/api/v4/"
+
+// GitLabCollector is a Collector for GitLab. Merge requests, approvals,
+// notes, issues and commits stand in for their GitHub counterparts:
+//
+//   - commits and hoc: commits on the default branch authored by the user
+//   - issues: issues the user opened, updated during the window
+//   - pulls and lcp: merge requests the user opened, merged or closed
+//   - reviews: approvals by the user, and the user's diff notes as review
+//     comments
+//   - msgs: the user's notes on issues and merge requests
+//
+// The triage, reverts and discussions metrics have no equivalent and stay
+// zero. Repositories are projects named by their full path, e.g.
+// group/subgroup/project.
+type GitLabCollector struct {
+	BaseURL    string       // API root, e.g. https://gitlab.example.com/api/v4/; DefaultGitLabURL when empty
+	Token      string       // Personal, group or project access token
+	HTTPClient *http.Client // Defaults to http.DefaultClient
+
+	Since time.Time // Start of the measured window
+	Until time.Time // End of the measured window; zero means now
+
+	Projects     []string    // Fixed project paths to measure instead of discovering them
+	Group        string      // Discover the projects of this group and its subgroups instead of the user's active ones
+	ExcludeRepos []string    // Project paths never measured
+	Identities   Identities  // Emails matched to each user's commits
+	Labels       LabelFilter // Labels of the issues and merge requests counted
+	Verbose      bool
+
+	Retry     RetryPolicy // How failed requests are retried
+	ErrorMode string      // What request errors do, ErrorModePartial (the default) or ErrorModeFail
+
+	calls  atomic.Int64
+	shared shared // Users, projects, events and listings used by several metrics
+
+	incomplete incompleteTasks
+}
+
+// NewGitLabCollector returns a collector for the GitLab API at baseURL,
+// gitlab.com when empty, measuring the last days days.
+func NewGitLabCollector(baseURL, token string, days int, verbose bool) *GitLabCollector {
+	if baseURL == "" {
+		baseURL = DefaultGitLabURL
+	}
+	return &GitLabCollector{
+		BaseURL: baseURL,
+		Token:   token,
+		Since:   time.Now().AddDate(0, 0, -days),
+		Verbose: verbose,
+		Retry:   DefaultRetryPolicy,
+	}
+}
+
+// GitLabWebURL returns the web UI root for a GitLab API root, e.g.
+// https://gitlab.example.com for https://gitlab.example.com/api/v4/.
+func GitLabWebURL(baseURL string) string {
+	if baseURL == "" {
+		baseURL = DefaultGitLabURL
+	}
+	return WebURL(baseURL)
+}
+
+// gitlabUser is the part of a GitLab user the collector uses.
+type gitlabUser struct {
+	ID          int    `json:"id"`
+	Username    string `json:"username"`
+	Name        string `json:"name"`
+	PublicEmail string `json:"public_email"`
+}
+
+type gitlabProject struct {
+	ID                int    `json:"id"`
+	PathWithNamespace string `json:"path_with_namespace"`
+}
+
+type gitlabCommit struct {
+	ID          string    `json:"id"`
+	AuthorName  string    `json:"author_name"`
+	AuthorEmail string    `json:"author_email"`
+	AuthoredAt  time.Time `json:"authored_date"`
+	ParentIDs   []string  `json:"parent_ids"`
+	Stats       struct {
+		Additions int `json:"additions"`
+		Deletions int `json:"deletions"`
+	} `json:"stats"`
+	WebURL string `json:"web_url"`
+}
+
+type gitlabIssue struct {
+	IID       int        `json:"iid"`
+	State     string     `json:"state"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+	MergedAt  *time.Time `json:"merged_at"` // Merge requests only
+	WebURL    string     `json:"web_url"`
+	Labels    []string   `json:"labels"`
+}
+
+type gitlabEvent struct {
+	ProjectID  int       `json:"project_id"`
+	ActionName string    `json:"action_name"`
+	TargetType string    `json:"target_type"`
+	TargetIID  int       `json:"target_iid"`
+	CreatedAt  time.Time `json:"created_at"`
+	Note       *struct {
+		Type         string `json:"type"` // DiffNote, DiscussionNote or empty
+		NoteableType string `json:"noteable_type"`
+		System       bool   `json:"system"`
+	} `json:"note"`
+}
+
+// APICalls returns the number of GitLab API requests made so far.
+func (c *GitLabCollector) APICalls() int64 {
+	return c.calls.Load()
+}
+
+// EndpointFailures returns nothing: the GitLab collector has no circuit
+// breakers.
+func (c *GitLabCollector) EndpointFailures() []EndpointFailure {
+	return nil
+}
+
+// Incomplete returns the tasks whose metrics are incomplete so far, with
+// ErrorModePartial.
+func (c *GitLabCollector) Incomplete() []IncompleteTask {
+	return c.incomplete.list()
+}
+
+func (c *GitLabCollector) inWindow(t time.Time) bool {
+	return !t.Before(c.Since) && (c.Until.IsZero() || !t.After(c.Until))
+}
+
+// Repositories returns the fixed projects, the projects of the group, or
+// the projects the user was active in during the window.
+func (c *GitLabCollector) Repositories(ctx context.Context, user string) ([]string, error) {
+	var projects []string
+	switch {
+	case len(c.Projects) > 0:
+		projects = c.Projects
+	case c.Group != "":
+		result, err := c.shared.do("group-projects", func() (interface{}, error) {
+			var list []gitlabProject
+			query := url.Values{"include_subgroups": {"true"}, "archived": {"false"}}
+			err := c.list(ctx, "groups/"+url.PathEscape(c.Group)+"/projects", query, &list)
+			var paths []string
+			for _, project := range list {
+				paths = append(paths, project.PathWithNamespace)
+			}
+			return paths, err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("listing projects of group %s: %w", c.Group, err)
+		}
+		projects = result.([]string)
+	default:
+		events, err := c.events(ctx, user)
+		if err != nil {
+			return nil, fmt.Errorf("listing events of %s: %w", user, err)
+		}
+		seen := make(map[int]bool)
+		for _, event := range events {
+			if seen[event.ProjectID] || event.ProjectID == 0 {
+				continue
+			}
+			seen[event.ProjectID] = true
+			project, err := c.project(ctx, strconv.Itoa(event.ProjectID))
+			if err != nil {
+				return nil, err
+			}
+			projects = append(projects, project.PathWithNamespace)
+		}
+		sort.Strings(projects)
+	}
+
+	var kept []string
+	for _, project := range projects {
+		excluded := false
+		for _, exclude := range c.ExcludeRepos {
+			if strings.EqualFold(project, exclude) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			kept = append(kept, project)
+		}
+	}
+	return kept, nil
+}
+
+// Collect computes a metric for the user in the project, applying the
+// ErrorMode to the request errors logged meanwhile.
+func (c *GitLabCollector) Collect(ctx context.Context, user, project, metric string) (UserMetrics, error) {
+	return c.incomplete.track(ctx, c.ErrorMode, user, project, metric, func(ctx context.Context) (UserMetrics, error) {
+		return c.collect(ctx, user, project, metric)
+	})
+}
+
+func (c *GitLabCollector) collect(ctx context.Context, user, project, metric string) (UserMetrics, error) {
+	switch metric {
+	case MetricCommits:
+		m := c.commits(ctx, user, project)
+		return UserMetrics{Commits: m.Commits, DailyActivity: m.DailyActivity}, nil
+	case MetricHoC:
+		m := c.commits(ctx, user, project)
+		m.Commits = 0
+		m.clearDays()
+		return m, nil
+	case MetricIssues:
+		return c.issues(ctx, user, project), nil
+	case MetricLcP:
+		m := c.mergeRequests(ctx, user, project)
+		return UserMetrics{LcP: m.LcP, PullLifecycles: m.PullLifecycles}, nil
+	case MetricPulls:
+		m := c.mergeRequests(ctx, user, project)
+		m.LcP, m.PullLifecycles = 0, nil
+		return m, nil
+	case MetricMsgs, MetricReviews:
+		m := c.notes(ctx, user, project)
+		if metric == MetricMsgs {
+			return UserMetrics{Msgs: m.Msgs, IssueComments: m.IssueComments, PRComments: m.PRComments}, nil
+		}
+		return UserMetrics{Reviews: m.Reviews, Approvals: m.Approvals, ReviewComments: m.ReviewComments}, nil
+	case MetricTriage, MetricReverts, MetricDiscussions, MetricReleases, MetricWorkflows, MetricSecurity:
+		return UserMetrics{}, nil
+	case MetricAll:
+		m := Merge(c.commits(ctx, user, project), c.issues(ctx, user, project))
+		m = Merge(m, c.mergeRequests(ctx, user, project))
+		return Merge(m, c.notes(ctx, user, project)), nil
+	default:
+		return UserMetrics{}, fmt.Errorf("unknown metric: %s", metric)
+	}
+}
+
+// commits counts the user's commits on the default branch and their HoC,
+// leaving out merge commits from HoC as the GitHub collector does.
+func (c *GitLabCollector) commits(ctx context.Context, user, project string) UserMetrics {
+	var m UserMetrics
+	u, err := c.user(ctx, user)
+	if err != nil {
+		logError(ctx, err, "looking up GitLab user %s", user)
+		return m
+	}
+	result, err := c.shared.do("commits/"+project, func() (interface{}, error) {
+		var commits []gitlabCommit
+		query := url.Values{"since": {c.Since.Format(time.RFC3339)}, "with_stats": {"true"}}
+		if !c.Until.IsZero() {
+			query.Set("until", c.Until.Format(time.RFC3339))
+		}
+		err := c.list(ctx, "projects/"+url.PathEscape(project)+"/repository/commits", query, &commits)
+		return commits, err
+	})
+	if err != nil {
+		logError(ctx, err, "fetching commits in project %s", project)
+	}
+	for _, commit := range result.([]gitlabCommit) {
+		email := commit.AuthorEmail
+		if !c.Identities.IsCommitAuthor(user, "", email) && !(u.PublicEmail != "" && strings.EqualFold(email, u.PublicEmail)) && !(u.Name != "" && strings.EqualFold(commit.AuthorName, u.Name)) {
+			continue
+		}
+		m.Commits++
+		m.addDay(commit.AuthoredAt)
+		if len(commit.ParentIDs) <= 1 {
+			m.HoC += commit.Stats.Additions + commit.Stats.Deletions
+			m.addLines(commit.Stats.Additions, commit.Stats.Deletions)
+		}
+	}
+	m.Repos = map[string]int{project: m.HoC}
+	if c.Verbose {
+		log.Printf("Total commits for user %s in project %s: %d (HoC %d)\n", user, project, m.Commits, m.HoC)
+	}
+	return m
+}
+
+// issues counts the issues the user opened that were updated during the
+// window.
+func (c *GitLabCollector) issues(ctx context.Context, user, project string) UserMetrics {
+	var issues []gitlabIssue
+	err := c.list(ctx, "projects/"+url.PathEscape(project)+"/issues", c.updatedQuery(user), &issues)
+	if err != nil {
+		logError(ctx, err, "fetching issues for user %s in project %s", user, project)
+	}
+	var m UserMetrics
+	for _, issue := range issues {
+		if c.Labels.MatchIssue(issue.Labels) {
+			m.Issues++
+		}
+	}
+	return m
+}
+
+// mergeRequests counts the user's merge requests merged during the window,
+// with those approved by someone else as reviewed, and averages the hours
+// from opening to merging or closing of those updated during the window.
+func (c *GitLabCollector) mergeRequests(ctx context.Context, user, project string) UserMetrics {
+	var m UserMetrics
+	result, err := c.shared.do("merge-requests/"+project+"/"+user, func() (interface{}, error) {
+		var mrs []gitlabIssue
+		err := c.list(ctx, "projects/"+url.PathEscape(project)+"/merge_requests", c.updatedQuery(user), &mrs)
+		return mrs, err
+	})
+	if err != nil {
+		logError(ctx, err, "fetching merge requests for user %s in project %s", user, project)
+	}
+	total, closed := 0.0, 0
+	for _, mr := range result.([]gitlabIssue) {
+		if !c.Labels.MatchPull(mr.Labels) {
+			continue
+		}
+		end := mr.ClosedAt
+		if mr.MergedAt != nil {
+			end = mr.MergedAt
+		}
+		if end != nil {
+			total += end.Sub(mr.CreatedAt).Hours()
+			m.PullLifecycles = append(m.PullLifecycles, end.Sub(mr.CreatedAt).Hours())
+			closed++
+		}
+		if mr.MergedAt == nil || !c.inWindow(*mr.MergedAt) {
+			continue
+		}
+		m.Pulls++
+		m.addDay(*mr.MergedAt)
+		if c.approved(ctx, user, project, mr.IID) {
+			m.ReviewedPulls++
+		}
+	}
+	if closed > 0 {
+		m.LcP = total / float64(closed)
+	}
+	if m.Pulls > 0 {
+		m.RepoPulls = map[string]int{project: m.Pulls}
+	}
+	return m
+}
+
+// approved reports whether someone other than the author approved the
+// merge request.
+func (c *GitLabCollector) approved(ctx context.Context, author, project string, iid int) bool {
+	var approvals struct {
+		ApprovedBy []struct {
+			User gitlabUser `json:"user"`
+		} `json:"approved_by"`
+	}
+	path := fmt.Sprintf("projects/%s/merge_requests/%d/approvals", url.PathEscape(project), iid)
+	if err := c.get(ctx, path, nil, &approvals); err != nil {
+		logError(ctx, err, "fetching approvals of merge request !%d in project %s", iid, project)
+		return false
+	}
+	for _, approval := range approvals.ApprovedBy {
+		if !strings.EqualFold(approval.User.Username, author) {
+			return true
+		}
+	}
+	return false
+}
+
+// notes counts the user's approvals and notes in the project from the
+// user's events: approvals are reviews, diff notes review comments, and
+// notes on issues and merge requests messages.
+func (c *GitLabCollector) notes(ctx context.Context, user, project string) UserMetrics {
+	var m UserMetrics
+	events, err := c.events(ctx, user)
+	if err != nil {
+		logError(ctx, err, "fetching events of user %s", user)
+		return m
+	}
+	p, err := c.project(ctx, project)
+	if err != nil {
+		logError(ctx, err, "looking up project %s", project)
+		return m
+	}
+	for _, event := range events {
+		if event.ProjectID != p.ID {
+			continue
+		}
+		switch {
+		case event.ActionName == "approved" && event.TargetType == "MergeRequest":
+			m.Reviews++
+			m.Approvals++
+			m.addDay(event.CreatedAt)
+		case event.Note != nil && !event.Note.System:
+			m.addDay(event.CreatedAt)
+			if event.Note.Type == "DiffNote" {
+				m.ReviewComments++
+			}
+			switch event.Note.NoteableType {
+			case "Issue":
+				m.IssueComments++
+			case "MergeRequest":
+				m.PRComments++
+			}
+		}
+	}
+	m.Msgs = m.IssueComments + m.PRComments
+	return m
+}
+
+// updatedQuery filters issues or merge requests to those opened by the user
+// and updated during the window.
+func (c *GitLabCollector) updatedQuery(user string) url.Values {
+	query := url.Values{"author_username": {user}, "scope": {"all"}, "state": {"all"}, "updated_after": {c.Since.Format(time.RFC3339)}}
+	if !c.Until.IsZero() {
+		query.Set("updated_before", c.Until.Format(time.RFC3339))
+	}
+	return query
+}
+
+// user looks up a user by username, once per run.
+func (c *GitLabCollector) user(ctx context.Context, username string) (*gitlabUser, error) {
+	result, err := c.shared.do("user/"+strings.ToLower(username), func() (interface{}, error) {
+		var users []gitlabUser
+		if err := c.get(ctx, "users", url.Values{"username": {username}}, &users); err != nil {
+			return (*gitlabUser)(nil), err
+		}
+		if len(users) == 0 {
+			return (*gitlabUser)(nil), fmt.Errorf("no GitLab user %s", username)
+		}
+		// The user itself has the public email, which the list leaves out.
+		var u gitlabUser
+		if err := c.get(ctx, "users/"+strconv.Itoa(users[0].ID), nil, &u); err != nil {
+			return (*gitlabUser)(nil), err
+		}
+		return &u, nil
+	})
+	return result.(*gitlabUser), err
+}
+
+// project looks up a project by path or ID, once per run.
+func (c *GitLabCollector) project(ctx context.Context, project string) (*gitlabProject, error) {
+	result, err := c.shared.do("project/"+project, func() (interface{}, error) {
+		var p gitlabProject
+		if err := c.get(ctx, "projects/"+url.PathEscape(project), nil, &p); err != nil {
+			return (*gitlabProject)(nil), err
+		}
+		return &p, nil
+	})
+	return result.(*gitlabProject), err
+}
+
+// events lists the user's events during the window, once per run.
+func (c *GitLabCollector) events(ctx context.Context, username string) ([]gitlabEvent, error) {
+	result, err := c.shared.do("events/"+strings.ToLower(username), func() (interface{}, error) {
+		u, err := c.user(ctx, username)
+		if err != nil {
+			return []gitlabEvent(nil), err
+		}
+		// after and before are exclusive dates.
+		query := url.Values{"after": {c.Since.AddDate(0, 0, -1).Format("2006-01-02")}}
+		if !c.Until.IsZero() {
+			query.Set("before", c.Until.AddDate(0, 0, 1).Format("2006-01-02"))
+		}
+		var all, events []gitlabEvent
+		err = c.list(ctx, "users/"+strconv.Itoa(u.ID)+"/events", query, &all)
+		for _, event := range all {
+			if c.inWindow(event.CreatedAt) {
+				events = append(events, event)
+			}
+		}
+		return events, err
+	})
+	return result.([]gitlabEvent), err
+}
+
+// rest returns the client for the GitLab API.
+func (c *GitLabCollector) rest() restClient {
+	header := make(http.Header)
+	if c.Token != "" {
+		header.Set("Private-Token", c.Token)
+	}
+	return restClient{baseURL: c.BaseURL, header: header, client: c.HTTPClient, retry: c.Retry, calls: &c.calls}
+}
+
+// get requests path, relative to the API root, and decodes the response
+// into out.
+func (c *GitLabCollector) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	_, err := c.rest().get(ctx, path, query, out)
+	return err
+}
+
+// list fetches every page of a listing into out, a pointer to a slice,
+// following the X-Next-Page header.
+func (c *GitLabCollector) list(ctx context.Context, path string, query url.Values, out interface{}) error {
+	query = cloneValues(query)
+	query.Set("per_page", "100")
+	query.Set("page", "1")
+	return c.rest().list(ctx, path, query, out, func(query url.Values, header http.Header) url.Values {
+		page := header.Get("X-Next-Page")
+		if page == "" {
+			return nil
+		}
+		query.Set("page", page)
+		return query
+	})
+}
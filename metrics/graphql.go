@@ -0,0 +1,422 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// GraphQLCollector collects metrics through the GitHub GraphQL API. It reuses
+// repository discovery and rate-limit handling from GitHubCollector but needs
+// far fewer requests: HoC is read from the commit history in pages of 100
+// instead of fetching every commit, and counts come straight from issueCount.
+// The history only has per-commit totals, so HoC falls back to the REST
+// collector when a HoCFilter is set, including SkipGenerated. Commits and HoC
+// of users with Identities aliases also come from the REST collector, which
+// matches commit emails.
+// Comments, review quality, triage, reverts, releases, workflows, security
+// and each user's first pull request are always collected through the REST
+// collector, and so is
+// everything when Evidence is recorded, as search totals and the commit
+// history do not reference the items counted.
+type GraphQLCollector struct {
+	*GitHubCollector
+
+	mu      sync.Mutex
+	userIDs map[string]string
+}
+
+// NewGraphQLCollector returns a GraphQL collector sharing the client, window
+// and filters of rest.
+func NewGraphQLCollector(rest *GitHubCollector) *GraphQLCollector {
+	return &GraphQLCollector{GitHubCollector: rest, userIDs: make(map[string]string)}
+}
+
+func (c *GraphQLCollector) Collect(ctx context.Context, user, repoFullName, metric string) (UserMetrics, error) {
+	m, err := c.track(ctx, user, repoFullName, metric, func(ctx context.Context) (UserMetrics, error) {
+		return c.collect(ctx, user, repoFullName, metric)
+	})
+	if err == nil && ctx.Value(taskErrorsKey{}) == nil {
+		m.addVisibility(c.RepoVisibility(ctx, repoFullName))
+	}
+	return m, err
+}
+
+func (c *GraphQLCollector) collect(ctx context.Context, user, repoFullName, metric string) (UserMetrics, error) {
+	owner, repoName := ParseRepo(repoFullName)
+	if owner == "" || repoName == "" {
+		log.Printf("Skipping invalid repo string: %s", repoFullName)
+		return UserMetrics{}, nil
+	}
+	if c.Evidence != nil {
+		return c.GitHubCollector.Collect(ctx, user, repoFullName, metric)
+	}
+
+	switch metric {
+	case MetricCommits, MetricHoC:
+		if (metric == MetricHoC && !c.HoCFilter.IsZero()) || len(c.Identities.Aliases(user)) > 0 {
+			return c.GitHubCollector.Collect(ctx, user, repoFullName, metric)
+		}
+		m := c.history(ctx, owner, repoName, user)
+		if metric == MetricCommits {
+			return UserMetrics{Commits: m.Commits, OffHoursCommits: m.OffHoursCommits, DailyActivity: m.DailyActivity}, nil
+		}
+		m.Commits, m.OffHoursCommits = 0, 0
+		m.clearDays()
+		m.Repos = map[string]int{repoFullName: m.HoC}
+		return m, nil
+	case MetricIssues:
+		m := c.planned(ctx, owner, repoName, user)
+		m.Issues = c.issues(ctx, owner, repoName, user)
+		return m, nil
+	case MetricLcP:
+		return c.lcp(ctx, owner, repoName, user), nil
+	case MetricMsgs:
+		return c.GitHubCollector.msgs(ctx, owner, repoName, user), nil
+	case MetricPulls:
+		return c.pulls(ctx, owner, repoName, user), nil
+	case MetricReviews:
+		return c.GitHubCollector.reviews(ctx, owner, repoName, user), nil
+	case MetricTriage:
+		return c.GitHubCollector.triage(ctx, owner, repoName, user), nil
+	case MetricReverts:
+		return c.GitHubCollector.reverts(ctx, owner, repoName, user), nil
+	case MetricDiscussions:
+		return c.discussions(ctx, owner, repoName, user), nil
+	case MetricReleases:
+		return c.GitHubCollector.releases(ctx, owner, repoName, user), nil
+	case MetricWorkflows:
+		return c.GitHubCollector.workflows(ctx, owner, repoName, user), nil
+	case MetricSecurity:
+		return c.GitHubCollector.security(ctx, owner, repoName, user), nil
+	case MetricAll:
+		var m UserMetrics
+		switch {
+		case len(c.Identities.Aliases(user)) > 0:
+			m = c.GitHubCollector.hoc(ctx, owner, repoName, user)
+			m = Merge(m, c.GitHubCollector.commits(ctx, owner, repoName, user))
+		case !c.HoCFilter.IsZero():
+			m = c.GitHubCollector.hoc(ctx, owner, repoName, user)
+			history := c.history(ctx, owner, repoName, user)
+			m.Commits, m.OffHoursCommits = history.Commits, history.OffHoursCommits
+			m.mergeDays(history.DailyActivity)
+		default:
+			m = c.history(ctx, owner, repoName, user)
+		}
+		m.Issues = c.issues(ctx, owner, repoName, user)
+		m = Merge(m, c.planned(ctx, owner, repoName, user))
+		m = Merge(m, c.lcp(ctx, owner, repoName, user))
+		m.Repos = map[string]int{repoFullName: m.HoC}
+		m = Merge(m, c.pulls(ctx, owner, repoName, user))
+		m = Merge(m, c.GitHubCollector.msgs(ctx, owner, repoName, user))
+		m = Merge(m, c.GitHubCollector.reviews(ctx, owner, repoName, user))
+		m = Merge(m, c.GitHubCollector.triage(ctx, owner, repoName, user))
+		m = Merge(m, c.GitHubCollector.reverts(ctx, owner, repoName, user))
+		m = Merge(m, c.GitHubCollector.releases(ctx, owner, repoName, user))
+		m = Merge(m, c.GitHubCollector.workflows(ctx, owner, repoName, user))
+		m = Merge(m, c.GitHubCollector.security(ctx, owner, repoName, user))
+		return Merge(m, c.discussions(ctx, owner, repoName, user)), nil
+	default:
+		return UserMetrics{}, fmt.Errorf("unknown metric: %s", metric)
+	}
+}
+
+// graphQLError is a single entry of the errors array of a GraphQL response.
+type graphQLError struct {
+	Message string `json:"message"`
+}
+
+// query runs a GraphQL query and decodes its data into out.
+func (c *GraphQLCollector) query(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphQLError  `json:"errors"`
+	}
+	_, _, err := c.retryWithBackoff(ctx, resourceGraphQL, "POST /graphql", func() (interface{}, *github.Response, error) {
+		resp, err := c.api().GraphQL(ctx, map[string]interface{}{
+			"query":     query,
+			"variables": variables,
+		}, &envelope)
+		return nil, resp, err
+	})
+	if err != nil {
+		return err
+	}
+	if len(envelope.Errors) > 0 {
+		var msgs []string
+		for _, e := range envelope.Errors {
+			msgs = append(msgs, e.Message)
+		}
+		return fmt.Errorf("graphql: %s", strings.Join(msgs, "; "))
+	}
+	if len(envelope.Data) == 0 {
+		return errors.New("graphql: empty response")
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
+
+const userIDQuery = `query($login: String!) {
+  user(login: $login) { id }
+}`
+
+// userID resolves and caches the node ID of a login, which the commit
+// history author filter requires.
+func (c *GraphQLCollector) userID(ctx context.Context, login string) (string, error) {
+	c.mu.Lock()
+	id, ok := c.userIDs[login]
+	c.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	var data struct {
+		User *struct {
+			ID string `json:"id"`
+		} `json:"user"`
+	}
+	if err := c.query(ctx, userIDQuery, map[string]interface{}{"login": login}, &data); err != nil {
+		return "", err
+	}
+	if data.User == nil {
+		return "", fmt.Errorf("user %s not found", login)
+	}
+
+	c.mu.Lock()
+	c.userIDs[login] = data.User.ID
+	c.mu.Unlock()
+	return data.User.ID, nil
+}
+
+const historyQuery = `query($owner: String!, $name: String!, $since: GitTimestamp!, $until: GitTimestamp, $author: ID!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    defaultBranchRef {
+      target {
+        ... on Commit {
+          history(first: 100, after: $cursor, since: $since, until: $until, author: {id: $author}) {
+            pageInfo { hasNextPage endCursor }
+            nodes { oid authoredDate additions deletions parents { totalCount } }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// history walks the user's non-merge commits on the default branch and
+// returns the commit count, hits of code and line metrics. HoC matches the
+// REST collector, which sums additions and changes (additions+deletions) per
+// file. The history is walked once for both commits and HoC.
+func (c *GraphQLCollector) history(ctx context.Context, owner, repo, user string) UserMetrics {
+	result, _ := c.shared.do("history/"+owner+"/"+repo+"/"+user, func() (interface{}, error) {
+		return c.walkHistory(ctx, owner, repo, user), nil
+	})
+	return result.(UserMetrics)
+}
+
+func (c *GraphQLCollector) walkHistory(ctx context.Context, owner, repo, user string) UserMetrics {
+	var m UserMetrics
+	authorID, err := c.userID(ctx, user)
+	if err != nil {
+		logError(ctx, err, "resolving user %s", user)
+		return m
+	}
+
+	variables := map[string]interface{}{
+		"owner":  owner,
+		"name":   repo,
+		"since":  c.Since.Format(time.RFC3339),
+		"until":  nil,
+		"author": authorID,
+		"cursor": nil,
+	}
+	if !c.Until.IsZero() {
+		variables["until"] = c.Until.Format(time.RFC3339)
+	}
+	for {
+		var data struct {
+			Repository *struct {
+				DefaultBranchRef *struct {
+					Target struct {
+						History struct {
+							PageInfo pageInfo `json:"pageInfo"`
+							Nodes    []struct {
+								OID          string    `json:"oid"`
+								AuthoredDate time.Time `json:"authoredDate"`
+								Additions    int       `json:"additions"`
+								Deletions    int       `json:"deletions"`
+								Parents      struct {
+									TotalCount int `json:"totalCount"`
+								} `json:"parents"`
+							} `json:"nodes"`
+						} `json:"history"`
+					} `json:"target"`
+				} `json:"defaultBranchRef"`
+			} `json:"repository"`
+		}
+		if err := c.query(ctx, historyQuery, variables, &data); err != nil {
+			logError(ctx, err, "fetching commits for user %s in repo %s/%s", user, owner, repo)
+			return m
+		}
+		if data.Repository == nil || data.Repository.DefaultBranchRef == nil {
+			return m
+		}
+		history := data.Repository.DefaultBranchRef.Target.History
+		for _, commit := range history.Nodes {
+			if commit.Parents.TotalCount > 1 {
+				continue
+			}
+			m.Commits++
+			if !c.WorkingHours.IsZero() && c.WorkingHours.OffHours(commit.AuthoredDate) {
+				m.OffHoursCommits++
+			}
+			m.addDay(commit.AuthoredDate)
+			m.HoC += 2*commit.Additions + commit.Deletions
+			m.addLines(commit.Additions, commit.Deletions)
+			if c.Verbose {
+				log.Printf("Commit %s by %s in repo %s/%s - additions: %d, deletions: %d\n", commit.OID, user, owner, repo, commit.Additions, commit.Deletions)
+			}
+		}
+		if !history.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = history.PageInfo.EndCursor
+	}
+
+	return m
+}
+
+type pageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+// searchNode is the subset of pull request fields read from search results.
+type searchNode struct {
+	Number    int        `json:"number"`
+	CreatedAt *time.Time `json:"createdAt"`
+	ClosedAt  *time.Time `json:"closedAt"`
+	Additions int        `json:"additions"`
+	Deletions int        `json:"deletions"`
+	Reviews   struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"reviews"`
+}
+
+const searchQuery = `query($q: String!, $first: Int!, $cursor: String) {
+  search(type: ISSUE, query: $q, first: $first, after: $cursor) {
+    issueCount
+    pageInfo { hasNextPage endCursor }
+    nodes {
+      ... on PullRequest { number createdAt closedAt additions deletions reviews { totalCount } }
+    }
+  }
+}`
+
+// search runs an issue search. With all set every page of nodes is fetched,
+// otherwise only the total count is requested.
+func (c *GraphQLCollector) search(ctx context.Context, q string, all bool) (int, []searchNode, error) {
+	first := 1
+	if all {
+		first = 100
+	}
+	variables := map[string]interface{}{"q": q, "first": first, "cursor": nil}
+
+	var nodes []searchNode
+	for {
+		var data struct {
+			Search struct {
+				IssueCount int          `json:"issueCount"`
+				PageInfo   pageInfo     `json:"pageInfo"`
+				Nodes      []searchNode `json:"nodes"`
+			} `json:"search"`
+		}
+		if err := c.query(ctx, searchQuery, variables, &data); err != nil {
+			return 0, nodes, err
+		}
+		if !all {
+			return data.Search.IssueCount, nil, nil
+		}
+		nodes = append(nodes, data.Search.Nodes...)
+		if !data.Search.PageInfo.HasNextPage {
+			return data.Search.IssueCount, nodes, nil
+		}
+		variables["cursor"] = data.Search.PageInfo.EndCursor
+	}
+}
+
+func (c *GraphQLCollector) issues(ctx context.Context, owner, repo, user string) int {
+	query := fmt.Sprintf("repo:%s/%s is:issue author:%s updated:%s", owner, repo, user, c.dateRange(">=")) + labelQualifier(c.Labels.Issues)
+	count, _, err := c.search(ctx, query, false)
+	if err != nil {
+		logError(ctx, err, "fetching issues for user %s in repo %s/%s", user, owner, repo)
+		return 0
+	}
+	if c.Verbose {
+		log.Printf("Total issues for user %s in repo %s/%s: %d\n", user, owner, repo, count)
+	}
+	return count
+}
+
+// lcp averages the lifecycle of the user's closed pull requests and adds
+// how the review of their merged pull requests went, through the REST API.
+func (c *GraphQLCollector) lcp(ctx context.Context, owner, repo, user string) UserMetrics {
+	m := c.GitHubCollector.pullFlow(ctx, owner, repo, user)
+	query := fmt.Sprintf("repo:%s/%s is:pr is:closed author:%s updated:%s", owner, repo, user, c.dateRange(">=")) + labelQualifier(c.Labels.Pulls)
+	_, nodes, err := c.search(ctx, query, true)
+	if err != nil {
+		logError(ctx, err, "fetching pull requests for user %s in repo %s/%s", user, owner, repo)
+		return m
+	}
+
+	totalTime := 0.0
+	count := 0
+	for _, pr := range nodes {
+		if pr.CreatedAt == nil || pr.ClosedAt == nil {
+			continue
+		}
+		duration := pr.ClosedAt.Sub(*pr.CreatedAt).Hours()
+		totalTime += duration
+		count++
+		m.PullLifecycles = append(m.PullLifecycles, duration)
+		if c.Verbose {
+			log.Printf("Pull request #%d by %s: created at %s, closed at %s, duration: %.2f hours\n", pr.Number, user, pr.CreatedAt, pr.ClosedAt, duration)
+		}
+	}
+	if count > 0 {
+		m.LcP = totalTime / float64(count)
+	}
+	return m
+}
+
+func (c *GraphQLCollector) pulls(ctx context.Context, owner, repo, user string) UserMetrics {
+	query := fmt.Sprintf("repo:%s/%s is:pr author:%s merged:%s", owner, repo, user, c.dateRange(">")) + labelQualifier(c.Labels.Pulls)
+	count, nodes, err := c.search(ctx, query, true)
+	if err != nil {
+		logError(ctx, err, "fetching pull requests for user %s in repo %s/%s", user, owner, repo)
+		return UserMetrics{}
+	}
+
+	m := UserMetrics{Pulls: count}
+	for _, pr := range nodes {
+		m.PullSizes = append(m.PullSizes, pr.Additions+pr.Deletions)
+		if pr.ClosedAt != nil {
+			m.addDay(*pr.ClosedAt) // When merged
+		}
+		if pr.Reviews.TotalCount > 0 {
+			m.ReviewedPulls++
+		}
+	}
+	if count > 0 {
+		m.RepoPulls = map[string]int{owner + "/" + repo: count}
+	}
+	m.FirstPull, m.FirstMerge = c.firstPull(ctx, owner, repo, user)
+	m.StalePulls = c.stalePulls(ctx, owner, repo, user)
+	return m
+}
@@ -0,0 +1,239 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"handshake/stats/metricspb"
+)
+
+// GRPCService answers the queries of the REST API over gRPC, with the
+// messages of metricspb, for platforms preferring typed RPC to JSON. It
+// queries the same store with the same defaults as API.
+type GRPCService struct {
+	metricspb.UnimplementedMetricsServiceServer
+	API *StoreAPI
+}
+
+// ListRuns lists the stored runs of the metric taken in the range.
+func (s *GRPCService) ListRuns(ctx context.Context, req *metricspb.ListRunsRequest) (*metricspb.ListRunsResponse, error) {
+	metric := s.metric(req.GetMetric())
+	from, to := grpcTime(req.GetFrom()), grpcTime(req.GetTo())
+	infos, err := s.API.Store.Snapshots(ctx)
+	if err != nil {
+		log.Printf("Error listing snapshots: %v", err)
+		return nil, status.Error(codes.Internal, "snapshots not available")
+	}
+	resp := &metricspb.ListRunsResponse{}
+	for _, info := range infos {
+		if info.Metric != metric || (!from.IsZero() && info.TakenAt.Before(from)) || (!to.IsZero() && !info.TakenAt.Before(to)) {
+			continue
+		}
+		resp.Runs = append(resp.Runs, &metricspb.Run{
+			Id:      info.ID,
+			TakenAt: timestamppb.New(info.TakenAt),
+			Since:   timestamppb.New(info.Since),
+			Metric:  info.Metric,
+			Users:   int32(info.Users),
+		})
+	}
+	return resp, nil
+}
+
+// GetUserMetrics returns the user's metrics in every run of the metric taken
+// in the range.
+func (s *GRPCService) GetUserMetrics(ctx context.Context, req *metricspb.GetUserMetricsRequest) (*metricspb.GetUserMetricsResponse, error) {
+	if req.GetLogin() == "" {
+		return nil, status.Error(codes.InvalidArgument, "login is required")
+	}
+	metric := s.metric(req.GetMetric())
+	snapshots, err := s.API.Store.History(ctx, metric, grpcTime(req.GetFrom()), grpcTime(req.GetTo()))
+	if err != nil {
+		log.Printf("Error loading snapshots: %v", err)
+		return nil, status.Error(codes.Internal, "snapshots not available")
+	}
+	resp := &metricspb.GetUserMetricsResponse{Metric: metric}
+	for _, snapshot := range snapshots {
+		for _, view := range s.API.report(snapshot).Users {
+			if strings.EqualFold(view.User, req.GetLogin()) {
+				resp.User = view.User
+				resp.Snapshots = append(resp.Snapshots, &metricspb.UserSnapshot{
+					Run:     newProtoRun(snapshot),
+					Rank:    int32(view.Rank),
+					Metrics: newProtoMetrics(view.Metrics),
+				})
+			}
+		}
+	}
+	if resp.User == "" {
+		return nil, status.Errorf(codes.NotFound, "no metrics of %s", req.GetLogin())
+	}
+	return resp, nil
+}
+
+// GetLeaderboard returns the ranked users of the newest run of the metric
+// taken during the period.
+func (s *GRPCService) GetLeaderboard(ctx context.Context, req *metricspb.GetLeaderboardRequest) (*metricspb.GetLeaderboardResponse, error) {
+	from, to, err := apiPeriod(req.GetPeriod())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid period: "+err.Error())
+	}
+	snapshots, err := s.API.Store.History(ctx, s.metric(req.GetMetric()), from, to)
+	if err != nil {
+		log.Printf("Error loading snapshots: %v", err)
+		return nil, status.Error(codes.Internal, "snapshots not available")
+	}
+	if len(snapshots) == 0 {
+		return nil, status.Error(codes.NotFound, "no snapshot in the period")
+	}
+	snapshot := snapshots[len(snapshots)-1]
+	resp := &metricspb.GetLeaderboardResponse{Run: newProtoRun(snapshot)}
+	for _, view := range s.API.report(snapshot).Users {
+		resp.Users = append(resp.Users, &metricspb.RankedUser{
+			Rank:     int32(view.Rank),
+			User:     view.User,
+			Metrics:  newProtoMetrics(view.Metrics),
+			Newcomer: view.Newcomer,
+		})
+	}
+	return resp, nil
+}
+
+// metric returns the requested metric, or the API's default.
+func (s *GRPCService) metric(metric string) string {
+	if metric == "" {
+		return s.API.Metric
+	}
+	return metric
+}
+
+// grpcTime returns the time of a timestamp; unset is the zero time.
+func grpcTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func newProtoRun(snapshot *Snapshot) *metricspb.Run {
+	return &metricspb.Run{
+		Id:      snapshot.ID,
+		TakenAt: timestamppb.New(snapshot.TakenAt),
+		Since:   timestamppb.New(snapshot.Since),
+		Metric:  snapshot.Metric,
+		Users:   int32(len(snapshot.Users)),
+	}
+}
+
+// newProtoMetrics converts the metrics as the JSON output does, with the
+// same derived medians and shares.
+func newProtoMetrics(m UserMetrics) *metricspb.UserMetrics {
+	j := newJSONMetrics(m)
+	out := &metricspb.UserMetrics{
+		Commits: int64(j.Commits),
+		Hoc:     int64(j.HoC),
+		Issues:  int64(j.Issues),
+		Lcp:     j.LcP,
+		Msgs:    int64(j.Msgs),
+		Pulls:   int64(j.Pulls),
+		Reviews: int64(j.Reviews),
+		Score:   j.Score,
+
+		Deletions: int64(j.Deletions),
+		Churn:     int64(j.Churn),
+		NetLines:  int64(j.NetLines),
+
+		Docs:        int64(j.Docs),
+		DocsCommits: int64(j.DocsCommits),
+
+		IssueComments: int64(j.IssueComments),
+		PrComments:    int64(j.PRComments),
+
+		ReviewComments:    int64(j.ReviewComments),
+		Approvals:         int64(j.Approvals),
+		ChangesRequested:  int64(j.ChangesRequested),
+		TimeToFirstReview: j.TimeToFirstReview,
+
+		PendingReviewRequests: int64(j.PendingReviewRequests),
+		IgnoredReviewRequests: int64(j.IgnoredReviewRequests),
+
+		CodeOwnerPulls:   int64(j.CodeOwnerPulls),
+		CodeOwnerReviews: int64(j.CodeOwnerReviews),
+
+		MedianPullSize: int64(j.MedianPullSize),
+		ReviewedPulls:  int64(j.ReviewedPulls),
+
+		MedianLcp:             j.MedianLcP,
+		FirstReviewWait:       j.FirstReviewWait,
+		MedianFirstReviewWait: j.MedianFirstReviewWait,
+		ReviewRounds:          j.ReviewRounds,
+		MedianReviewRounds:    j.MedianReviewRounds,
+		MergeWait:             j.MergeWait,
+		MedianMergeWait:       j.MedianMergeWait,
+
+		Labeled:      int64(j.Labeled),
+		Assigned:     int64(j.Assigned),
+		IssuesClosed: int64(j.IssuesClosed),
+		TimeToTriage: j.TimeToTriage,
+
+		IssuesClosedManually: int64(j.IssuesClosedManually),
+		IssuesFixed:          int64(j.IssuesFixed),
+
+		PlannedIssues: int64(j.PlannedIssues),
+		PlannedPulls:  int64(j.PlannedPulls),
+
+		DiscussionsStarted: int64(j.DiscussionsStarted),
+		DiscussionComments: int64(j.DiscussionComments),
+		DiscussionAnswers:  int64(j.DiscussionAnswers),
+
+		Reverts:     int64(j.Reverts),
+		ForcePushes: int64(j.ForcePushes),
+
+		ReleasesPublished: int64(j.ReleasesPublished),
+		TagsCreated:       int64(j.TagsCreated),
+		ShippedIn:         int64(j.ShippedIn),
+
+		WorkflowsAdded:    int64(j.WorkflowsAdded),
+		WorkflowsModified: int64(j.WorkflowsModified),
+		RunsFixed:         int64(j.RunsFixed),
+
+		SecurityAlertsFixed:     int64(j.SecurityAlertsFixed),
+		SecurityAlertsDismissed: int64(j.SecurityAlertsDismissed),
+		DependabotPullsMerged:   int64(j.DependabotPullsMerged),
+		DependabotPullsReviewed: int64(j.DependabotPullsReviewed),
+
+		PublicActivity:   int64(j.PublicActivity),
+		PrivateActivity:  int64(j.PrivateActivity),
+		InternalActivity: int64(j.InternalActivity),
+
+		OffHoursCommits:  int64(j.OffHoursCommits),
+		OffHoursReviews:  int64(j.OffHoursReviews),
+		SubmittedReviews: int64(j.SubmittedReviews),
+		OffHoursShare:    j.OffHoursShare,
+
+		Tone:          j.Tone,
+		ToneComments:  int64(j.ToneComments),
+		HarshComments: int64(j.HarshComments),
+
+		ActiveDays:    int64(j.ActiveDays),
+		LongestStreak: int64(j.LongestStreak),
+
+		Unknown: j.Unknown,
+	}
+	if len(j.Repos) > 0 {
+		out.Repos = make(map[string]int64, len(j.Repos))
+		for repo, lines := range j.Repos {
+			out.Repos[repo] = int64(lines)
+		}
+	}
+	for _, size := range j.PullSizes {
+		out.PullSizes = append(out.PullSizes, &metricspb.PullSizeCount{Label: size.Label, Count: int64(size.Count), Percent: int64(size.Percent)})
+	}
+	return out
+}
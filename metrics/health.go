@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"time"
+)
+
+// OrgHealth is the one-glance overview of all measured users shown above
+// the leaderboard.
+type OrgHealth struct {
+	MergedPulls    int     `json:"mergedPulls"`
+	ReviewedPulls  int     `json:"reviewedPulls"`  // Merged pull requests reviewed by someone other than the author
+	PullsPerWeek   float64 `json:"pullsPerWeek"`   // Merged pull requests per week of the window
+	PullLifecycle  float64 `json:"pullLifecycle"`  // Average hours from opening to closing a pull request, over users who closed any
+	ReviewCoverage float64 `json:"reviewCoverage"` // Percentage of merged pull requests reviewed by someone other than the author
+	Contributors   int     `json:"contributors"`   // Users with any activity in the window
+	Users          int     `json:"users"`
+}
+
+// NewOrgHealth summarizes the users over the window from since to until.
+// A zero until means the window ends now.
+func NewOrgHealth(views []UserMetricsView, since, until time.Time) OrgHealth {
+	h := OrgHealth{Users: len(views)}
+	lifecycles := 0
+	for _, view := range views {
+		m := view.Metrics
+		h.MergedPulls += m.Pulls
+		h.ReviewedPulls += m.ReviewedPulls
+		if m.LcP > 0 {
+			h.PullLifecycle += m.LcP
+			lifecycles++
+		}
+		if active(m) {
+			h.Contributors++
+		}
+	}
+	if lifecycles > 0 {
+		h.PullLifecycle /= float64(lifecycles)
+	}
+	if h.MergedPulls > 0 {
+		h.ReviewCoverage = float64(h.ReviewedPulls) / float64(h.MergedPulls) * 100
+	}
+	if until.IsZero() {
+		until = time.Now()
+	}
+	if weeks := until.Sub(since).Hours() / (24 * 7); weeks > 0 {
+		h.PullsPerWeek = float64(h.MergedPulls) / weeks
+	}
+	return h
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// Identities maps a login to the commit emails and alternate logins that
+// belong to the same person, so commits whose author is not linked to the
+// GitHub account still count towards it.
+type Identities map[string][]string
+
+// LoadIdentities reads an identity map from a JSON file of the form
+// {"login": ["email@example.com", "alt-login"]}.
+func LoadIdentities(path string) (Identities, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ids Identities
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Aliases returns the emails and alternate logins recorded for user.
+func (ids Identities) Aliases(user string) []string {
+	for login, aliases := range ids {
+		if strings.EqualFold(login, user) {
+			return aliases
+		}
+	}
+	return nil
+}
+
+// IsCommitAuthor reports whether a commit belongs to user. login is the
+// GitHub account linked to the commit, empty when the author email is not
+// linked to one; the commit then matches on the user's recorded emails.
+func (ids Identities) IsCommitAuthor(user, login, email string) bool {
+	if login != "" {
+		if strings.EqualFold(login, user) {
+			return true
+		}
+		for _, alias := range ids.Aliases(user) {
+			if !strings.Contains(alias, "@") && strings.EqualFold(alias, login) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, alias := range ids.Aliases(user) {
+		if strings.Contains(alias, "@") && strings.EqualFold(alias, email) {
+			return true
+		}
+	}
+	return false
+}
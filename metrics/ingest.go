@@ -0,0 +1,404 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// pendingReviewAge is how long a review of a pull request that is still open
+// is kept for its merge; reviews of pull requests that are left open for
+// longer are dropped, so that they do not pile up.
+const pendingReviewAge = 90 * 24 * time.Hour
+
+// ingestedEvents are the webhook events a WebhookIngester counts; others are
+// acknowledged and ignored.
+var ingestedEvents = []string{"push", "pull_request", "pull_request_review", "issue_comment"}
+
+// WebhookIngester is an http.Handler receiving GitHub webhook events and
+// adding them to live results as they happen, instead of collecting the
+// whole window again. It counts distinct commits pushed to the default
+// branch, merged pull requests with their lifecycle and size, a review for
+// every user who reviewed a pull request by someone else once it is merged,
+// approvals and requested changes as they are submitted, and comments on
+// issues and pull requests. Line counts and the other metrics no event
+// carries keep the values of the results it starts from.
+type WebhookIngester struct {
+	Secret        []byte   // Secret the payloads are signed with
+	Users         []string // Measured users; empty measures every sender but bots
+	ExcludeUsers  []string
+	Repos         []string // Measured repositories as owner/name
+	Organizations []string // Organizations whose repositories are all measured
+	ExcludeRepos  []string
+	Identities    Identities
+	Scorer        Scorer
+	Leaderboards  []Leaderboard
+	Store         Store // Receives the users each event changed when set
+	SnapshotID    int64 // Stored snapshot the results are kept in
+	Verbose       bool
+
+	mu      sync.Mutex
+	results *Results
+	// reviews of the open pull requests keyed by owner/repo#number, whose
+	// reviewers are credited with a review once the pull request is merged;
+	// they are stored with the snapshot to outlive restarts
+	reviews map[string][]PendingReview
+	// lifecycles holds the index in its author's PullLifecycles of each
+	// closed pull request, keyed like reviews, so that closing it again
+	// after reopening it replaces its lifecycle rather than adding one
+	lifecycles map[string]int
+}
+
+// NewWebhookIngester returns an ingester adding events to results, which it
+// takes over, and crediting the pending reviews of the snapshot the results
+// come from once their pull requests are merged.
+func NewWebhookIngester(results *Results, pending []PendingReview) *WebhookIngester {
+	if results.Users == nil {
+		results.Users = make(map[string]UserMetrics)
+	}
+	w := &WebhookIngester{results: results, reviews: make(map[string][]PendingReview), lifecycles: make(map[string]int)}
+	for _, review := range pending {
+		w.reviews[review.Pull] = append(w.reviews[review.Pull], review)
+	}
+	return w
+}
+
+// Results returns the live results. They are replaced rather than changed
+// by later events, so they can be read while events come in.
+func (w *WebhookIngester) Results() (*Results, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.results, nil
+}
+
+func (w *WebhookIngester) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	payload, err := github.ValidatePayload(r, w.Secret)
+	if err != nil {
+		log.Printf("Rejecting webhook delivery %s: %v", github.DeliveryID(r), err)
+		http.Error(rw, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	kind := github.WebHookType(r)
+	if !containsFold(ingestedEvents, kind) {
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+	event, err := github.ParseWebHook(kind, payload)
+	if err != nil {
+		http.Error(rw, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if err := w.ingest(r.Context(), event); err != nil {
+		log.Printf("Error storing %s event %s: %v", kind, github.DeliveryID(r), err)
+		http.Error(rw, "storing metrics failed", http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// ingest adds the event to a copy of the results, scores the users it
+// changed again and stores them.
+func (w *WebhookIngester) ingest(ctx context.Context, event interface{}) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	results := *w.results
+	results.Users = make(map[string]UserMetrics, len(w.results.Users))
+	for user, m := range w.results.Users {
+		results.Users[user] = m
+	}
+	pending := w.pendingReviews()
+	changed := w.apply(results.Users, event)
+	w.prune(time.Now().Add(-pendingReviewAge))
+	if len(changed) == 0 && len(w.pendingReviews()) == len(pending) {
+		return nil
+	}
+	if cohort, ok := w.Scorer.(CohortScorer); ok {
+		cohort.ScoreAll(results.Users)
+		changed = changed[:0]
+		for user := range results.Users {
+			changed = append(changed, user)
+		}
+	} else if w.Scorer != nil {
+		for _, user := range changed {
+			m := results.Users[user]
+			m.Score = w.Scorer.Score(m)
+			results.Users[user] = m
+		}
+	}
+	results.Leaderboards = ScoreLeaderboards(results.Users, w.Leaderboards)
+	results.CollectedAt = time.Now()
+	w.results = &results
+	if w.Store == nil {
+		return nil
+	}
+	snapshot := &Snapshot{ID: w.SnapshotID, TakenAt: results.CollectedAt, Since: results.Since, Metric: results.Metric, Users: results.Users, PendingReviews: w.pendingReviews()}
+	return w.Store.Update(ctx, snapshot, changed)
+}
+
+// pendingReviews returns the reviews of the open pull requests, sorted by
+// pull request.
+func (w *WebhookIngester) pendingReviews() []PendingReview {
+	pulls := make([]string, 0, len(w.reviews))
+	for pull := range w.reviews {
+		pulls = append(pulls, pull)
+	}
+	sort.Strings(pulls)
+	var reviews []PendingReview
+	for _, pull := range pulls {
+		reviews = append(reviews, w.reviews[pull]...)
+	}
+	return reviews
+}
+
+// prune drops the pending reviews of pull requests nobody reviewed since
+// before.
+func (w *WebhookIngester) prune(before time.Time) {
+	for pull, reviews := range w.reviews {
+		last := reviews[0].ReviewedAt
+		for _, review := range reviews[1:] {
+			if review.ReviewedAt.After(last) {
+				last = review.ReviewedAt
+			}
+		}
+		if last.Before(before) {
+			delete(w.reviews, pull)
+		}
+	}
+}
+
+// apply counts the event towards users and returns the users it changed.
+func (w *WebhookIngester) apply(users map[string]UserMetrics, event interface{}) []string {
+	var changed []string
+	update := func(user string, f func(m *UserMetrics)) {
+		m := users[user]
+		f(&m)
+		users[user] = m
+		if !containsFold(changed, user) {
+			changed = append(changed, user)
+		}
+	}
+
+	switch e := event.(type) {
+	case *github.PushEvent:
+		repo := e.GetRepo().GetFullName()
+		if !w.measured(repo) || e.GetRef() != "refs/heads/"+e.GetRepo().GetDefaultBranch() {
+			return nil
+		}
+		for _, commit := range e.Commits {
+			if !commit.GetDistinct() || isMergeMessage(commit.GetMessage()) {
+				continue
+			}
+			user, ok := w.commitAuthor(commit.GetAuthor().GetLogin(), commit.GetAuthor().GetEmail())
+			if !ok {
+				continue
+			}
+			update(user, func(m *UserMetrics) {
+				m.Commits++
+				m.Repos = withCount(m.Repos, repo, 0)
+				m.addDay(commit.GetTimestamp().Time)
+			})
+			if w.Verbose {
+				log.Printf("Commit %s by %s pushed to %s\n", commit.GetID(), user, repo)
+			}
+		}
+
+	case *github.PullRequestEvent:
+		repo := e.GetRepo().GetFullName()
+		pull := e.GetPullRequest()
+		if !w.measured(repo) || e.GetAction() != "closed" {
+			return nil
+		}
+		key := repo + "#" + strconv.Itoa(pull.GetNumber())
+		var reviewers []string
+		for _, review := range w.reviews[key] {
+			reviewers = append(reviewers, review.User)
+		}
+		delete(w.reviews, key)
+		author := pull.GetUser().GetLogin()
+		if user, ok := w.user(author); ok && pull.CreatedAt != nil && pull.ClosedAt != nil {
+			update(user, func(m *UserMetrics) {
+				lifecycle := pull.ClosedAt.Sub(pull.CreatedAt.Time).Hours()
+				if i, ok := w.lifecycles[key]; ok && i < len(m.PullLifecycles) {
+					m.PullLifecycles = append([]float64(nil), m.PullLifecycles...)
+					m.PullLifecycles[i] = lifecycle
+				} else {
+					w.lifecycles[key] = len(m.PullLifecycles)
+					m.PullLifecycles = append(m.PullLifecycles[:len(m.PullLifecycles):len(m.PullLifecycles)], lifecycle)
+				}
+				m.LcP = mean(m.PullLifecycles)
+				if !pull.GetMerged() {
+					return
+				}
+				m.Pulls++
+				m.RepoPulls = withCount(m.RepoPulls, repo, 1)
+				m.PullSizes = append(m.PullSizes[:len(m.PullSizes):len(m.PullSizes)], pull.GetAdditions()+pull.GetDeletions())
+				if len(reviewers) > 0 {
+					m.ReviewedPulls++
+				}
+				m.addDay(pull.GetMergedAt().Time)
+			})
+		}
+		if !pull.GetMerged() {
+			return changed
+		}
+		for _, reviewer := range reviewers {
+			update(reviewer, func(m *UserMetrics) { m.Reviews++ })
+		}
+		if w.Verbose {
+			log.Printf("Pull request %s by %s merged, reviewed by %v\n", key, author, reviewers)
+		}
+
+	case *github.PullRequestReviewEvent:
+		repo := e.GetRepo().GetFullName()
+		review := e.GetReview()
+		if !w.measured(repo) || e.GetAction() != "submitted" || strings.EqualFold(review.GetUser().GetLogin(), e.GetPullRequest().GetUser().GetLogin()) {
+			return nil
+		}
+		user, ok := w.user(review.GetUser().GetLogin())
+		if !ok {
+			return nil
+		}
+		key := repo + "#" + strconv.Itoa(e.GetPullRequest().GetNumber())
+		w.addReview(key, user, review.GetSubmittedAt().Time)
+		update(user, func(m *UserMetrics) {
+			switch strings.ToLower(review.GetState()) {
+			case "approved":
+				m.Approvals++
+			case "changes_requested":
+				m.ChangesRequested++
+			}
+			m.addDay(review.GetSubmittedAt().Time)
+		})
+
+	case *github.IssueCommentEvent:
+		repo := e.GetRepo().GetFullName()
+		comment := e.GetComment()
+		if !w.measured(repo) || e.GetAction() != "created" {
+			return nil
+		}
+		user, ok := w.user(comment.GetUser().GetLogin())
+		if !ok {
+			return nil
+		}
+		update(user, func(m *UserMetrics) {
+			m.Msgs++
+			if e.GetIssue().IsPullRequest() {
+				m.PRComments++
+			} else {
+				m.IssueComments++
+			}
+			m.addDay(comment.GetCreatedAt().Time)
+		})
+	}
+	return changed
+}
+
+// addReview records a user's review of an open pull request, once per user.
+func (w *WebhookIngester) addReview(pull, user string, at time.Time) {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	reviews := w.reviews[pull]
+	for i := range reviews {
+		if strings.EqualFold(reviews[i].User, user) {
+			if at.After(reviews[i].ReviewedAt) {
+				reviews[i].ReviewedAt = at
+			}
+			return
+		}
+	}
+	w.reviews[pull] = append(reviews, PendingReview{Pull: pull, User: user, ReviewedAt: at})
+}
+
+// user returns the measured user login belongs to.
+func (w *WebhookIngester) user(login string) (string, bool) {
+	if login == "" {
+		return "", false
+	}
+	for _, excluded := range w.ExcludeUsers {
+		if strings.EqualFold(excluded, login) {
+			return "", false
+		}
+	}
+	if len(w.Users) == 0 {
+		return login, !IsBot(login)
+	}
+	for _, user := range w.Users {
+		if strings.EqualFold(user, login) {
+			return user, true
+		}
+	}
+	return "", false
+}
+
+// commitAuthor returns the measured user who authored a commit, by the
+// GitHub account the push linked to it or the identities.
+func (w *WebhookIngester) commitAuthor(login, email string) (string, bool) {
+	if user, ok := w.user(login); ok {
+		return user, true
+	}
+	for _, user := range w.Users {
+		if w.Identities.IsCommitAuthor(user, "", email) {
+			return w.user(user)
+		}
+	}
+	return "", false
+}
+
+// measured reports whether events of the repository, given as owner/name,
+// count. Without repositories or organizations every repository counts.
+func (w *WebhookIngester) measured(repo string) bool {
+	for _, excluded := range w.ExcludeRepos {
+		if strings.EqualFold(excluded, repo) {
+			return false
+		}
+	}
+	if len(w.Repos) == 0 && len(w.Organizations) == 0 {
+		return true
+	}
+	for _, measured := range w.Repos {
+		if strings.EqualFold(measured, repo) {
+			return true
+		}
+	}
+	owner, _, _ := strings.Cut(repo, "/")
+	for _, org := range w.Organizations {
+		if strings.EqualFold(org, owner) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMergeMessage reports whether a pushed commit is a merge commit by its
+// message, as push events do not list parents.
+func isMergeMessage(message string) bool {
+	for _, prefix := range []string{"Merge pull request ", "Merge branch ", "Merge remote-tracking branch "} {
+		if strings.HasPrefix(message, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// withCount returns a copy of counts with n added to key, as the metrics of
+// earlier results may share the map.
+func withCount(counts map[string]int, key string, n int) map[string]int {
+	copied := make(map[string]int, len(counts)+1)
+	for k, v := range counts {
+		copied[k] = v
+	}
+	copied[key] += n
+	return copied
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// deliver sends a webhook event to w signed with secret and returns the
+// status it answered with.
+func deliver(t *testing.T, w *WebhookIngester, secret, kind, payload string) int {
+	t.Helper()
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", kind)
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	rec := httptest.NewRecorder()
+	w.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func pullRequestPayload(action string, merged bool, closedAt string) string {
+	return fmt.Sprintf(`{"action":%q,"repository":{"full_name":"acme/widgets"},"pull_request":{"number":7,"user":{"login":"alice"},"merged":%t,"additions":30,"deletions":10,"created_at":"2024-01-01T00:00:00Z","closed_at":%q,"merged_at":%q}}`,
+		action, merged, closedAt, closedAt)
+}
+
+func newTestIngester() *WebhookIngester {
+	w := NewWebhookIngester(&Results{}, nil)
+	w.Secret = []byte("secret")
+	w.Users = []string{"alice", "bob"}
+	return w
+}
+
+func TestWebhookIngesterRejectsBadSignature(t *testing.T) {
+	w := newTestIngester()
+	if code := deliver(t, w, "wrong", "pull_request", pullRequestPayload("closed", true, "2024-01-02T00:00:00Z")); code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want %d", code, http.StatusUnauthorized)
+	}
+	if results, _ := w.Results(); len(results.Users) != 0 {
+		t.Errorf("counted an unsigned event: %v", results.Users)
+	}
+}
+
+func TestWebhookIngesterPullRequestClosedAgain(t *testing.T) {
+	w := newTestIngester()
+	// Reviews are kept for the merge as long as they are recent.
+	review := fmt.Sprintf(`{"action":"submitted","repository":{"full_name":"acme/widgets"},"pull_request":{"number":7,"user":{"login":"alice"}},"review":{"state":"approved","user":{"login":"bob"},"submitted_at":%q}}`,
+		time.Now().UTC().Format(time.RFC3339))
+	for _, event := range []struct{ kind, payload string }{
+		{"pull_request_review", review},
+		{"pull_request", pullRequestPayload("closed", false, "2024-01-01T10:00:00Z")},
+	} {
+		if code := deliver(t, w, "secret", event.kind, event.payload); code != http.StatusNoContent {
+			t.Fatalf("%s: got status %d, want %d", event.kind, code, http.StatusNoContent)
+		}
+	}
+	closed, _ := w.Results()
+	if got := closed.Users["alice"].LcP; got != 10 {
+		t.Fatalf("got LcP %v after the first close, want 10", got)
+	}
+
+	deliver(t, w, "secret", "pull_request", pullRequestPayload("reopened", false, ""))
+	deliver(t, w, "secret", "pull_request_review", review)
+	deliver(t, w, "secret", "pull_request", pullRequestPayload("closed", true, "2024-01-02T00:00:00Z"))
+	results, _ := w.Results()
+	alice := results.Users["alice"]
+	if len(alice.PullLifecycles) != 1 || alice.LcP != 24 {
+		t.Errorf("got lifecycles %v and LcP %v, want the second close alone, 24", alice.PullLifecycles, alice.LcP)
+	}
+	if alice.Pulls != 1 || alice.ReviewedPulls != 1 || alice.RepoPulls["acme/widgets"] != 1 {
+		t.Errorf("got %d pulls, %d reviewed, by repo %v; want the merged pull request once", alice.Pulls, alice.ReviewedPulls, alice.RepoPulls)
+	}
+	if bob := results.Users["bob"]; bob.Reviews != 1 || bob.Approvals != 2 {
+		t.Errorf("got bob %d reviews and %d approvals, want 1 and 2", bob.Reviews, bob.Approvals)
+	}
+
+	// Results handed out earlier are replaced, not changed.
+	if m := closed.Users["alice"]; m.LcP != 10 || m.Pulls != 0 || len(m.PullLifecycles) != 1 || m.PullLifecycles[0] != 10 {
+		t.Errorf("earlier results changed to %+v", m)
+	}
+}
@@ -0,0 +1,505 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// JSONRenderer writes the report as an indented JSON document following
+// JSONSchema. The report is validated before anything is written.
+type JSONRenderer struct{}
+
+type jsonReport struct {
+	SchemaVersion string              `json:"schemaVersion"`
+	Run           jsonRun             `json:"run"`
+	Health        OrgHealth           `json:"health"`
+	Concentration ConcentrationReport `json:"concentration"`
+	ReviewLoad    ReviewLoad          `json:"reviewLoad"`
+	Newcomers     []jsonNewcomer      `json:"newcomers"`
+	StalePulls    []jsonStalePulls    `json:"stalePulls,omitempty"`
+	CodeOwners    []jsonPathCoverage  `json:"codeOwnerCoverage,omitempty"`
+	PlannedWork   []jsonPlanProgress  `json:"plannedWork,omitempty"`
+	Inactive      []string            `json:"inactive"`
+	Users         []jsonUser          `json:"users"`
+	Repos         []jsonRepo          `json:"repos"`
+	Teams         []jsonTeam          `json:"teams,omitempty"`
+	Periods       []jsonPeriod        `json:"periods,omitempty"`
+	Leaderboards  []jsonLeaderboard   `json:"leaderboards,omitempty"`
+}
+
+type jsonLeaderboard struct {
+	Name  string      `json:"name"`
+	Users []jsonScore `json:"users"`
+}
+
+type jsonScore struct {
+	Rank  int     `json:"rank"`
+	User  string  `json:"user"`
+	Score float64 `json:"score"`
+}
+
+type jsonRun struct {
+	Generator    string            `json:"generator"`
+	GeneratedAt  time.Time         `json:"generatedAt"`
+	CollectedAt  *time.Time        `json:"collectedAt,omitempty"`
+	Since        time.Time         `json:"since"`
+	Until        *time.Time        `json:"until,omitempty"`
+	Organization string            `json:"organization,omitempty"`
+	Failures     []EndpointFailure `json:"failures,omitempty"`
+	WorkingHours string            `json:"workingHours,omitempty"`
+	Aggregation  string            `json:"aggregation,omitempty"`
+	StaleDays    int               `json:"staleDays,omitempty"`
+	Tone         bool              `json:"tone,omitempty"`
+}
+
+type jsonRepo struct {
+	Repo         string   `json:"repo"`
+	Organization string   `json:"organization,omitempty"` // Measured organization the repository belongs to
+	HoC          int      `json:"hoc"`                    // Hits of code over all users
+	Users        []string `json:"users"`                  // Users with hits of code in the repository, or any activity with --group-by repo
+
+	// Set with --group-by repo
+	Commits          *int     `json:"commits,omitempty"`
+	Pulls            *int     `json:"pulls,omitempty"`            // Merged pull requests
+	MedianReviewTime *float64 `json:"medianReviewTime,omitempty"` // Hours from review request to first review
+}
+
+type jsonMetrics struct {
+	Commits int            `json:"commits"`
+	HoC     int            `json:"hoc"`
+	Issues  int            `json:"issues"`
+	LcP     float64        `json:"lcp"`
+	Msgs    int            `json:"msgs"`
+	Pulls   int            `json:"pulls"`
+	Reviews int            `json:"reviews"`
+	Score   float64        `json:"score"`
+	Repos   map[string]int `json:"repos,omitempty"`
+
+	Deletions int `json:"deletions"`
+	Churn     int `json:"churn"`
+	NetLines  int `json:"netLines"`
+
+	Docs        int `json:"docs"`
+	DocsCommits int `json:"docsCommits"`
+
+	IssueComments int `json:"issueComments"`
+	PRComments    int `json:"prComments"`
+
+	ReviewComments    int     `json:"reviewComments"`
+	Approvals         int     `json:"approvals"`
+	ChangesRequested  int     `json:"changesRequested"`
+	TimeToFirstReview float64 `json:"timeToFirstReview"`
+
+	PendingReviewRequests int `json:"pendingReviewRequests"`
+	IgnoredReviewRequests int `json:"ignoredReviewRequests"`
+
+	CodeOwnerPulls   int `json:"codeOwnerPulls"`
+	CodeOwnerReviews int `json:"codeOwnerReviews"`
+
+	PullSizes      []PullSizeCount `json:"pullSizes"`
+	MedianPullSize int             `json:"medianPullSize"`
+	ReviewedPulls  int             `json:"reviewedPulls"`
+
+	MedianLcP             float64 `json:"medianLcp"`
+	FirstReviewWait       float64 `json:"firstReviewWait"`
+	MedianFirstReviewWait float64 `json:"medianFirstReviewWait"`
+	ReviewRounds          float64 `json:"reviewRounds"`
+	MedianReviewRounds    float64 `json:"medianReviewRounds"`
+	MergeWait             float64 `json:"mergeWait"`
+	MedianMergeWait       float64 `json:"medianMergeWait"`
+
+	Labeled      int     `json:"labeled"`
+	Assigned     int     `json:"assigned"`
+	IssuesClosed int     `json:"issuesClosed"`
+	TimeToTriage float64 `json:"timeToTriage"`
+
+	IssuesClosedManually int `json:"issuesClosedManually"`
+	IssuesFixed          int `json:"issuesFixed"`
+
+	PlannedIssues int `json:"plannedIssues"`
+	PlannedPulls  int `json:"plannedPulls"`
+
+	DiscussionsStarted int `json:"discussionsStarted"`
+	DiscussionComments int `json:"discussionComments"`
+	DiscussionAnswers  int `json:"discussionAnswers"`
+
+	Reverts     int `json:"reverts"`
+	ForcePushes int `json:"forcePushes"`
+
+	ReleasesPublished int `json:"releasesPublished"`
+	TagsCreated       int `json:"tagsCreated"`
+	ShippedIn         int `json:"shippedIn"`
+
+	WorkflowsAdded    int `json:"workflowsAdded"`
+	WorkflowsModified int `json:"workflowsModified"`
+	RunsFixed         int `json:"runsFixed"`
+
+	SecurityAlertsFixed     int `json:"securityAlertsFixed"`
+	SecurityAlertsDismissed int `json:"securityAlertsDismissed"`
+	DependabotPullsMerged   int `json:"dependabotPullsMerged"`
+	DependabotPullsReviewed int `json:"dependabotPullsReviewed"`
+
+	PublicActivity   int `json:"publicActivity"`
+	PrivateActivity  int `json:"privateActivity"`
+	InternalActivity int `json:"internalActivity"`
+
+	OffHoursCommits  int     `json:"offHoursCommits"`
+	OffHoursReviews  int     `json:"offHoursReviews"`
+	SubmittedReviews int     `json:"submittedReviews"`
+	OffHoursShare    float64 `json:"offHoursShare"`
+
+	Tone          float64 `json:"tone"`
+	ToneComments  int     `json:"toneComments"`
+	HarshComments int     `json:"harshComments"`
+
+	ActiveDays    int `json:"activeDays"`
+	LongestStreak int `json:"longestStreak"`
+
+	Unknown []string `json:"unknown,omitempty"`
+}
+
+type jsonUser struct {
+	User    string      `json:"user"`
+	Metrics jsonMetrics `json:"metrics"`
+	Delta   *UserDeltas `json:"delta,omitempty"`
+
+	Newcomer bool `json:"newcomer,omitempty"` // First pull request falls into the window
+
+	Lifecycles *jsonLifecycles `json:"lifecycles,omitempty"`
+}
+
+type jsonLifecycles struct {
+	LcP             *Distribution `json:"lcp,omitempty"`
+	FirstReviewWait *Distribution `json:"firstReviewWait,omitempty"`
+	MergeWait       *Distribution `json:"mergeWait,omitempty"`
+}
+
+type jsonNewcomer struct {
+	User        string     `json:"user"`
+	FirstPull   FirstPull  `json:"firstPull"`
+	FirstMerge  *time.Time `json:"firstMerge,omitempty"`
+	TimeToMerge *float64   `json:"timeToMerge,omitempty"` // Hours from opening the first pull request to the first merge
+}
+
+type jsonStalePulls struct {
+	User   string   `json:"user"`
+	Repo   string   `json:"repo"`
+	Count  int      `json:"count"`
+	Oldest OpenPull `json:"oldest"`
+	Age    float64  `json:"age"` // Hours the oldest pull request has been open
+}
+
+type jsonPathCoverage struct {
+	Repo     string   `json:"repo"`
+	Path     string   `json:"path"`
+	Pulls    int      `json:"pulls"`
+	Reviewed int      `json:"reviewed"`
+	Coverage float64  `json:"coverage"` // Percentage of Pulls a code owner reviewed
+	Owners   []string `json:"owners"`
+}
+
+type jsonPlanProgress struct {
+	Plan   string   `json:"plan"`
+	Issues int      `json:"issues"`
+	Pulls  int      `json:"pulls"`
+	Users  []string `json:"users"`
+}
+
+type jsonTeam struct {
+	Team    string          `json:"team"`
+	Members int             `json:"members"`
+	Total   jsonMetrics     `json:"total"`
+	Average MetricsAverages `json:"average"`
+
+	Lifecycles *jsonLifecycles `json:"lifecycles,omitempty"`
+}
+
+type jsonPeriod struct {
+	Since time.Time  `json:"since"`
+	Until time.Time  `json:"until"`
+	Users []jsonUser `json:"users"`
+}
+
+func newJSONMetrics(m UserMetrics) jsonMetrics {
+	return jsonMetrics{
+		Commits: m.Commits,
+		HoC:     m.HoC,
+		Issues:  m.Issues,
+		LcP:     m.LcP,
+		Msgs:    m.Msgs,
+		Pulls:   m.Pulls,
+		Reviews: m.Reviews,
+		Score:   m.Score,
+		Repos:   m.Repos,
+
+		Deletions: m.Deletions,
+		Churn:     m.Churn,
+		NetLines:  m.NetLines,
+
+		Docs:        m.Docs,
+		DocsCommits: m.DocsCommits,
+
+		IssueComments: m.IssueComments,
+		PRComments:    m.PRComments,
+
+		ReviewComments:    m.ReviewComments,
+		Approvals:         m.Approvals,
+		ChangesRequested:  m.ChangesRequested,
+		TimeToFirstReview: m.TimeToFirstReview,
+
+		PendingReviewRequests: m.PendingReviewRequests,
+		IgnoredReviewRequests: m.IgnoredReviewRequests,
+
+		CodeOwnerPulls:   m.CodeOwnerPulls,
+		CodeOwnerReviews: m.CodeOwnerReviews,
+
+		PullSizes:      m.PullSizeDistribution(),
+		MedianPullSize: m.MedianPullSize(),
+		ReviewedPulls:  m.ReviewedPulls,
+
+		MedianLcP:             m.MedianLcP(),
+		FirstReviewWait:       m.FirstReviewWait(),
+		MedianFirstReviewWait: m.MedianFirstReviewWait(),
+		ReviewRounds:          m.AverageReviewRounds(),
+		MedianReviewRounds:    m.MedianReviewRounds(),
+		MergeWait:             m.MergeWait(),
+		MedianMergeWait:       m.MedianMergeWait(),
+
+		Labeled:      m.Labeled,
+		Assigned:     m.Assigned,
+		IssuesClosed: m.IssuesClosed,
+		TimeToTriage: m.TimeToTriage,
+
+		IssuesClosedManually: m.IssuesClosedManually,
+		IssuesFixed:          m.IssuesFixed,
+
+		PlannedIssues: m.PlannedIssues,
+		PlannedPulls:  m.PlannedPulls,
+
+		DiscussionsStarted: m.DiscussionsStarted,
+		DiscussionComments: m.DiscussionComments,
+		DiscussionAnswers:  m.DiscussionAnswers,
+
+		Reverts:     m.Reverts,
+		ForcePushes: m.ForcePushes,
+
+		ReleasesPublished: m.ReleasesPublished,
+		TagsCreated:       m.TagsCreated,
+		ShippedIn:         m.ShippedIn,
+
+		WorkflowsAdded:    m.WorkflowsAdded,
+		WorkflowsModified: m.WorkflowsModified,
+		RunsFixed:         m.RunsFixed,
+
+		SecurityAlertsFixed:     m.SecurityAlertsFixed,
+		SecurityAlertsDismissed: m.SecurityAlertsDismissed,
+		DependabotPullsMerged:   m.DependabotPullsMerged,
+		DependabotPullsReviewed: m.DependabotPullsReviewed,
+
+		PublicActivity:   m.PublicActivity,
+		PrivateActivity:  m.PrivateActivity,
+		InternalActivity: m.InternalActivity,
+
+		OffHoursCommits:  m.OffHoursCommits,
+		OffHoursReviews:  m.OffHoursReviews,
+		SubmittedReviews: m.SubmittedReviews,
+		OffHoursShare:    m.OffHoursShare(),
+
+		Tone:          m.Tone,
+		ToneComments:  m.ToneComments,
+		HarshComments: m.HarshComments,
+
+		ActiveDays:    m.ActiveDays,
+		LongestStreak: m.LongestStreak,
+
+		Unknown: m.Unknown,
+	}
+}
+
+func newJSONUser(view UserMetricsView) jsonUser {
+	return jsonUser{
+		User:    view.User,
+		Metrics: newJSONMetrics(view.Metrics),
+		Delta:   view.Delta,
+
+		Newcomer: view.Newcomer,
+
+		Lifecycles: newJSONLifecycles(view.Lifecycles),
+	}
+}
+
+// newJSONLifecycles leaves out the distributions without pull requests, and
+// returns nil without any.
+func newJSONLifecycles(l Lifecycles) *jsonLifecycles {
+	var out jsonLifecycles
+	if l.LcP.Count > 0 {
+		out.LcP = &l.LcP
+	}
+	if l.FirstReview.Count > 0 {
+		out.FirstReviewWait = &l.FirstReview
+	}
+	if l.Merge.Count > 0 {
+		out.MergeWait = &l.Merge
+	}
+	if out == (jsonLifecycles{}) {
+		return nil
+	}
+	return &out
+}
+
+// newJSONRepo converts a row of the per-repository table.
+func newJSONRepo(view RepoMetricsView, org string) jsonRepo {
+	repo := jsonRepo{
+		Repo:         view.Repo,
+		Organization: repoOrganization(view.Repo, org),
+		HoC:          view.Metrics.HoC,
+		Users:        view.Contributors,
+		Commits:      &view.Metrics.Commits,
+		Pulls:        &view.Metrics.Pulls,
+	}
+	if repo.Users == nil {
+		repo.Users = []string{}
+	}
+	if len(view.Metrics.ReviewTimes) > 0 {
+		repo.MedianReviewTime = &view.MedianReviewTime
+	}
+	return repo
+}
+
+// repoOrganization returns the owner of the repository when it is one of the
+// report's organizations.
+func repoOrganization(repo, org string) string {
+	owner, _ := ParseRepo(repo)
+	for _, name := range organizations(org) {
+		if strings.EqualFold(name, owner) {
+			return name
+		}
+	}
+	return ""
+}
+
+// jsonRepos totals the hits of code of every repository over all users,
+// most first.
+func jsonRepos(views []UserMetricsView, org string) []jsonRepo {
+	byRepo := make(map[string]*jsonRepo)
+	repos := []jsonRepo{}
+	for _, view := range views {
+		for name, hoc := range view.Metrics.Repos {
+			repo, ok := byRepo[name]
+			if !ok {
+				repo = &jsonRepo{Repo: name, Organization: repoOrganization(name, org)}
+				byRepo[name] = repo
+			}
+			repo.HoC += hoc
+			repo.Users = append(repo.Users, view.User)
+		}
+	}
+	for _, repo := range byRepo {
+		sort.Strings(repo.Users)
+		repos = append(repos, *repo)
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		if repos[i].HoC != repos[j].HoC {
+			return repos[i].HoC > repos[j].HoC
+		}
+		return repos[i].Repo < repos[j].Repo
+	})
+	return repos
+}
+
+func (JSONRenderer) Render(_ context.Context, w io.Writer, report Report) error {
+	out := jsonReport{
+		SchemaVersion: JSONSchemaVersion,
+		Run: jsonRun{
+			Generator:    "github-metrics",
+			GeneratedAt:  time.Now().UTC(),
+			Since:        report.Since,
+			Organization: report.Organization,
+			Failures:     report.Failures,
+			WorkingHours: report.WorkingHours,
+			Aggregation:  report.Aggregation,
+			StaleDays:    report.StaleDays,
+			Tone:         report.Tone,
+		},
+		Health:        report.Health,
+		Concentration: report.Ownership,
+		ReviewLoad:    report.ReviewLoad,
+		Newcomers:     []jsonNewcomer{},
+		Inactive:      []string{},
+		Users:         []jsonUser{},
+		Repos:         jsonRepos(report.Users, report.Organization),
+	}
+	if report.Inactive != nil {
+		out.Inactive = report.Inactive
+	}
+	if out.Concentration.Repos == nil {
+		out.Concentration.Repos = []RepoConcentration{}
+	}
+	if len(report.Repos) > 0 {
+		out.Repos = nil
+		for _, view := range report.Repos {
+			out.Repos = append(out.Repos, newJSONRepo(view, report.Organization))
+		}
+	}
+	if !report.CollectedAt.IsZero() {
+		out.Run.CollectedAt = &report.CollectedAt
+	}
+	if !report.Until.IsZero() {
+		out.Run.Until = &report.Until
+	}
+	for _, view := range report.Users {
+		out.Users = append(out.Users, newJSONUser(view))
+	}
+	for _, n := range report.Newcomers {
+		newcomer := jsonNewcomer{User: n.User, FirstPull: n.FirstPull, FirstMerge: n.FirstMerge}
+		if n.FirstMerge != nil {
+			hours := n.TimeToMerge
+			newcomer.TimeToMerge = &hours
+		}
+		out.Newcomers = append(out.Newcomers, newcomer)
+	}
+	for _, stale := range report.StalePulls {
+		out.StalePulls = append(out.StalePulls, jsonStalePulls{User: stale.User, Repo: stale.Repo, Count: stale.Count, Oldest: stale.Oldest, Age: stale.Age})
+	}
+	for _, path := range report.CodeOwners {
+		out.CodeOwners = append(out.CodeOwners, jsonPathCoverage{Repo: path.Repo, Path: path.Path, Pulls: path.Pulls, Reviewed: path.Reviewed, Coverage: path.Coverage(), Owners: path.Owners})
+	}
+	for _, plan := range report.PlannedWork {
+		out.PlannedWork = append(out.PlannedWork, jsonPlanProgress{Plan: plan.Plan, Issues: plan.Issues, Pulls: plan.Pulls, Users: plan.Users})
+	}
+	for _, team := range report.Teams {
+		out.Teams = append(out.Teams, jsonTeam{
+			Team:    team.Team,
+			Members: team.Members,
+			Total:   newJSONMetrics(team.Total),
+			Average: team.Average,
+
+			Lifecycles: newJSONLifecycles(team.Lifecycles),
+		})
+	}
+	for i, period := range report.Periods {
+		out.Periods = append(out.Periods, jsonPeriod{Since: period.Since, Until: period.Until, Users: []jsonUser{}})
+		for _, view := range report.PeriodUsers {
+			out.Periods[i].Users = append(out.Periods[i].Users, jsonUser{User: view.User, Metrics: newJSONMetrics(view.Metrics[i])})
+		}
+	}
+	for _, board := range report.Leaderboards {
+		leaderboard := jsonLeaderboard{Name: board.Name, Users: []jsonScore{}}
+		for _, view := range board.Users {
+			leaderboard.Users = append(leaderboard.Users, jsonScore{Rank: view.Rank, User: view.User, Score: view.Metrics.Score})
+		}
+		out.Leaderboards = append(out.Leaderboards, leaderboard)
+	}
+	if err := out.validate(); err != nil {
+		return fmt.Errorf("invalid JSON report: %w", err)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// LabelFilter restricts the issues and pull requests that count towards
+// the metrics to those carrying one of the labels given for their kind, so
+// that e.g. only bug reports count as issues. Labels match regardless of
+// case; without labels every issue or pull request counts.
+type LabelFilter struct {
+	Issues []string // Labels of the issues counted, e.g. bug
+	Pulls  []string // Labels of the pull requests counted, e.g. release
+}
+
+// IsZero reports whether the filter lets every issue and pull request
+// through.
+func (f LabelFilter) IsZero() bool {
+	return len(f.Issues) == 0 && len(f.Pulls) == 0
+}
+
+// MatchIssue reports whether an issue with the labels counts.
+func (f LabelFilter) MatchIssue(labels []string) bool {
+	return matchLabels(f.Issues, labels)
+}
+
+// MatchPull reports whether a pull request with the labels counts.
+func (f LabelFilter) MatchPull(labels []string) bool {
+	return matchLabels(f.Pulls, labels)
+}
+
+func matchLabels(want, labels []string) bool {
+	if len(want) == 0 {
+		return true
+	}
+	for _, label := range labels {
+		for _, w := range want {
+			if strings.EqualFold(label, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// labelQualifier returns the search qualifier matching any of the labels,
+// e.g. ` label:"bug","regression"`, or "" without labels.
+func labelQualifier(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(labels))
+	for i, label := range labels {
+		quoted[i] = strconv.Quote(label)
+	}
+	return " label:" + strings.Join(quoted, ",")
+}
+
+// labelNames returns the names of GitHub labels.
+func labelNames(labels []*github.Label) []string {
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		names = append(names, label.GetName())
+	}
+	return names
+}
+
+// labeledPulls returns the pulls that Labels lets through.
+func (c *GitHubCollector) labeledPulls(pulls []mergedPull) []mergedPull {
+	if len(c.Labels.Pulls) == 0 {
+		return pulls
+	}
+	var labeled []mergedPull
+	for _, pull := range pulls {
+		if c.Labels.MatchPull(pull.labels) {
+			labeled = append(labeled, pull)
+		}
+	}
+	return labeled
+}
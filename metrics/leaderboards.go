@@ -0,0 +1,76 @@
+package metrics
+
+import "sort"
+
+// Leaderboard ranks the users of a run by a Scorer of its own, next to the
+// main leaderboard, e.g. reviewers by reviews and comments.
+type Leaderboard struct {
+	Name   string
+	Scorer Scorer
+}
+
+// LeaderboardScores holds every user's score on a named leaderboard.
+type LeaderboardScores struct {
+	Name   string             `json:"name"`
+	Scores map[string]float64 `json:"scores"`
+}
+
+// LeaderboardView is a named leaderboard of a report.
+type LeaderboardView struct {
+	Name  string
+	Users []UserMetricsView // Ranked by the leaderboard's score, which replaces Metrics.Score
+}
+
+// ScoreLeaderboards scores the metrics for each leaderboard.
+func ScoreLeaderboards(metrics map[string]UserMetrics, boards []Leaderboard) []LeaderboardScores {
+	var scored []LeaderboardScores
+	for _, board := range boards {
+		scores := make(map[string]float64, len(metrics))
+		if cohort, ok := board.Scorer.(CohortScorer); ok {
+			ranked := make(map[string]UserMetrics, len(metrics))
+			for user, m := range metrics {
+				ranked[user] = m
+			}
+			cohort.ScoreAll(ranked)
+			for user, m := range ranked {
+				scores[user] = m.Score
+			}
+		} else {
+			for user, m := range metrics {
+				scores[user] = board.Scorer.Score(m)
+			}
+		}
+		scored = append(scored, LeaderboardScores{Name: board.Name, Scores: scores})
+	}
+	return scored
+}
+
+// LeaderboardViews ranks the leaderboard rows by each set of scores. Users
+// without a score are left out. Deltas are dropped, as they compare main
+// scores.
+func LeaderboardViews(views []UserMetricsView, boards []LeaderboardScores) []LeaderboardView {
+	var result []LeaderboardView
+	for _, board := range boards {
+		var users []UserMetricsView
+		for _, view := range views {
+			score, ok := board.Scores[view.User]
+			if !ok {
+				continue
+			}
+			view.Metrics.Score = score
+			view.Delta = nil
+			users = append(users, view)
+		}
+		sort.SliceStable(users, func(i, j int) bool {
+			if users[i].Metrics.Score != users[j].Metrics.Score {
+				return users[i].Metrics.Score > users[j].Metrics.Score
+			}
+			return users[i].User < users[j].User
+		})
+		for i := range users {
+			users[i].Rank = i + 1
+		}
+		result = append(result, LeaderboardView{Name: board.Name, Users: users})
+	}
+	return result
+}
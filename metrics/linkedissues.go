@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// closingKeyword matches a closing keyword followed by the issue it closes
+// in a pull request description: #N, owner/repo#N or the issue's URL.
+var closingKeyword = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+(?:https?://[^\s/]+/([\w.-]+/[\w.-]+)/issues/|([\w.-]+/[\w.-]+)?#)(\d+)\b`)
+
+// closingReferences returns the numbers of the issues of owner/repo that a
+// pull request description closes with a keyword, as "Fixes #12", in
+// order and without repeats.
+func closingReferences(body, owner, repo string) []int {
+	var numbers []int
+	seen := make(map[int]bool)
+	for _, match := range closingKeyword.FindAllStringSubmatch(body, -1) {
+		if ref := match[1] + match[2]; ref != "" && !strings.EqualFold(ref, owner+"/"+repo) {
+			continue
+		}
+		number, err := strconv.Atoi(match[3])
+		if err != nil || seen[number] {
+			continue
+		}
+		seen[number] = true
+		numbers = append(numbers, number)
+	}
+	return numbers
+}
+
+// issuesFixed counts the issues closed during the window by merging the
+// user's pull requests that reference them with a closing keyword. GitHub
+// records the commit that closed an issue on its closed event, which tells
+// these apart from issues closed by hand.
+func (c *GitHubCollector) issuesFixed(ctx context.Context, owner, repo, user string, events []*github.IssueEvent) int {
+	closed := make(map[int]*github.IssueEvent)
+	for _, event := range events {
+		issue := event.GetIssue()
+		if event.GetEvent() == "closed" && event.GetCommitID() != "" && !issue.IsPullRequest() && c.inWindow(event.GetCreatedAt().Time) && c.Labels.MatchIssue(labelNames(issue.Labels)) {
+			closed[issue.GetNumber()] = event
+		}
+	}
+	if len(closed) == 0 {
+		return 0
+	}
+	pulls, err := c.authoredPulls(ctx, owner, repo, user)
+	if err != nil {
+		logError(ctx, err, "fetching pull requests for user %s in repo %s/%s", user, owner, repo)
+	}
+	fixed := 0
+	for _, pull := range pulls {
+		for _, number := range closingReferences(pull.body, owner, repo) {
+			event, ok := closed[number]
+			if !ok {
+				continue
+			}
+			delete(closed, number)
+			fixed++
+			c.Evidence.Add(user, EvidenceItem{Metric: MetricTriage, Kind: "issue_fixed", Repo: owner + "/" + repo, ID: strconv.Itoa(number), URL: event.GetIssue().GetHTMLURL()})
+			if c.Verbose {
+				log.Printf("Issue #%d in repo %s/%s was closed by pull request #%d by %s\n", number, owner, repo, pull.number, user)
+			}
+		}
+	}
+	return fixed
+}
@@ -0,0 +1,237 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalGitCollector answers the commits and hoc metrics from local clones
+// with git log instead of the API, so HoC needs no request per commit, and
+// leaves every other metric, and finding the repositories, to Collector.
+// Repositories without a clone under Dir are measured by Collector alone.
+//
+// Local commits carry no account, so they are attributed to users through
+// Identities: a commit counts for a user when its author email is one of the
+// user's recorded emails, or is a GitHub noreply address of the user or one
+// of their alternate logins.
+type LocalGitCollector struct {
+	Collector  Collector  // Collects the other metrics
+	Dir        string     // Holds the clones as <Dir>/<owner>/<name> or <Dir>/<name>
+	Since      time.Time  // Start of the measured window
+	Until      time.Time  // End of the measured window; zero means now
+	Identities Identities // Commit emails of each user
+	HoCFilter  PathFilter // Files counted towards HoC
+	DocPaths   DocPaths   // Files counted towards Docs
+	// WorkingHours, when set, count the commits made off hours.
+	WorkingHours WorkingHours
+	Evidence     *Evidence // Records the commits counted when set
+	Verbose      bool
+
+	shared shared // Log of each clone, used by every user
+}
+
+// localCommit is a commit read from git log.
+type localCommit struct {
+	sha       string
+	email     string
+	date      time.Time // Author date
+	lines     int       // HoC of the files matching the filter
+	additions int       // Lines added to the files matching the filter
+	deletions int       // Lines deleted from the files matching the filter
+	docs      int       // HoC of the documentation files
+}
+
+// noreplyEmail matches GitHub noreply addresses, login@ or id+login@
+// users.noreply.github.com.
+var noreplyEmail = regexp.MustCompile(`(?i)^(?:\d+\+)?([^@+]+)@users\.noreply\.github\.com$`)
+
+// Repositories returns the repositories found by Collector.
+func (c *LocalGitCollector) Repositories(ctx context.Context, user string) ([]string, error) {
+	return c.Collector.Repositories(ctx, user)
+}
+
+// Collect computes commits and hoc from the repository's clone when there
+// is one, and every other metric with Collector.
+func (c *LocalGitCollector) Collect(ctx context.Context, user, repo, metric string) (UserMetrics, error) {
+	dir := c.clone(repo)
+	if dir == "" {
+		return c.Collector.Collect(ctx, user, repo, metric)
+	}
+	switch metric {
+	case MetricCommits, MetricHoC:
+		m, err := c.commits(ctx, user, repo, dir, metric)
+		if err != nil {
+			log.Printf("Error reading git log of %s, collecting %s through the API: %v\n", dir, metric, err)
+			return c.Collector.Collect(ctx, user, repo, metric)
+		}
+		c.addVisibility(ctx, repo, &m)
+		return m, nil
+	case MetricAll:
+		m, err := c.commits(ctx, user, repo, dir, metric)
+		if err != nil {
+			log.Printf("Error reading git log of %s, collecting through the API: %v\n", dir, err)
+			return c.Collector.Collect(ctx, user, repo, metric)
+		}
+		c.addVisibility(ctx, repo, &m)
+		for _, other := range AllMetrics {
+			if other == MetricCommits || other == MetricHoC {
+				continue
+			}
+			update, err := c.Collector.Collect(ctx, user, repo, other)
+			if err != nil {
+				return m, err
+			}
+			m = Merge(m, update)
+		}
+		return m, nil
+	default:
+		return c.Collector.Collect(ctx, user, repo, metric)
+	}
+}
+
+// addVisibility counts the local commits of m towards the visibility of the
+// repository, when Collector knows it.
+func (c *LocalGitCollector) addVisibility(ctx context.Context, repo string, m *UserMetrics) {
+	if collector, ok := c.Collector.(interface {
+		RepoVisibility(ctx context.Context, repo string) string
+	}); ok {
+		m.addVisibility(collector.RepoVisibility(ctx, repo))
+	}
+}
+
+// clone returns the directory of the repository's clone, or "" when there
+// is none.
+func (c *LocalGitCollector) clone(repo string) string {
+	owner, name := ParseRepo(repo)
+	for _, dir := range []string{filepath.Join(c.Dir, owner, name), filepath.Join(c.Dir, name)} {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return ""
+}
+
+// commits counts the user's commits in the clone and their HoC. Like the
+// API collectors, merge commits are left out.
+func (c *LocalGitCollector) commits(ctx context.Context, user, repo, dir, metric string) (UserMetrics, error) {
+	result, err := c.shared.do("log/"+dir, func() (interface{}, error) {
+		return c.log(ctx, dir)
+	})
+	if err != nil {
+		return UserMetrics{}, err
+	}
+	var m UserMetrics
+	for _, commit := range result.([]localCommit) {
+		if !c.isAuthor(user, commit.email) {
+			continue
+		}
+		m.Commits++
+		if !c.WorkingHours.IsZero() && c.WorkingHours.OffHours(commit.date) {
+			m.OffHoursCommits++
+		}
+		m.addDay(commit.date)
+		m.HoC += commit.lines
+		m.addLines(commit.additions, commit.deletions)
+		if commit.docs > 0 {
+			m.Docs += commit.docs
+			m.DocsCommits++
+		}
+		if metric != MetricHoC {
+			c.Evidence.Add(user, EvidenceItem{Metric: MetricCommits, Kind: "commit", Repo: repo, ID: commit.sha})
+		}
+		if metric != MetricCommits {
+			c.Evidence.Add(user, EvidenceItem{Metric: MetricHoC, Kind: "commit", Repo: repo, ID: commit.sha, Value: float64(commit.lines)})
+		}
+	}
+	if c.Verbose {
+		log.Printf("Total local commits for user %s in repo %s: %d (HoC %d)\n", user, repo, m.Commits, m.HoC)
+	}
+	switch metric {
+	case MetricCommits:
+		return UserMetrics{Commits: m.Commits, OffHoursCommits: m.OffHoursCommits, DailyActivity: m.DailyActivity}, nil
+	case MetricHoC:
+		m.Commits, m.OffHoursCommits = 0, 0
+		m.clearDays()
+	}
+	m.Repos = map[string]int{repo: m.HoC}
+	return m, nil
+}
+
+// isAuthor reports whether a commit by email belongs to user.
+func (c *LocalGitCollector) isAuthor(user, email string) bool {
+	if match := noreplyEmail.FindStringSubmatch(email); match != nil {
+		return c.Identities.IsCommitAuthor(user, match[1], email)
+	}
+	return c.Identities.IsCommitAuthor(user, "", email)
+}
+
+// log reads the non-merge commits of the window on the clone's checked out
+// branch. A file counts its additions plus its changes towards HoC, as
+// GitHubCollector counts the files of a commit. Generated files are found
+// with the .gitattributes file of the working tree.
+func (c *LocalGitCollector) log(ctx context.Context, dir string) ([]localCommit, error) {
+	var attrs GitAttributes
+	if c.HoCFilter.SkipGenerated {
+		data, err := os.ReadFile(filepath.Join(dir, ".gitattributes"))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		attrs = ParseGitAttributes(string(data))
+	}
+
+	args := []string{"-C", dir, "log", "HEAD", "--no-merges", "--no-renames", "--numstat",
+		"--format=%x00%H%x09%at%x09%ae", "--since=" + c.Since.Format(time.RFC3339)}
+	if !c.Until.IsZero() {
+		args = append(args, "--until="+c.Until.Format(time.RFC3339))
+	}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var commits []localCommit
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "\x00") {
+			sha, rest, _ := strings.Cut(line[1:], "\t")
+			timestamp, email, _ := strings.Cut(rest, "\t")
+			seconds, _ := strconv.ParseInt(timestamp, 10, 64)
+			commits = append(commits, localCommit{sha: sha, email: email, date: time.Unix(seconds, 0)})
+			continue
+		}
+		// Files are listed as additions, deletions and path; binary files
+		// have - for both.
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 || len(commits) == 0 {
+			continue
+		}
+		additions, _ := strconv.Atoi(fields[0])
+		deletions, _ := strconv.Atoi(fields[1])
+		commit := &commits[len(commits)-1]
+		if c.DocPaths.Match(fields[2]) {
+			commit.docs += additions + additions + deletions
+		}
+		if !c.HoCFilter.MatchFile(fields[2], fields[0] == "-", attrs) {
+			continue
+		}
+		commit.lines += additions + additions + deletions
+		commit.additions += additions
+		commit.deletions += deletions
+	}
+	return commits, scanner.Err()
+}
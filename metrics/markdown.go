@@ -0,0 +1,232 @@
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MarkdownRenderer renders views as a GitHub-flavored Markdown table suitable
+// for issue comments and wiki pages. Each named leaderboard follows as a
+// table headed by its name.
+type MarkdownRenderer struct{}
+
+func (MarkdownRenderer) Render(_ context.Context, w io.Writer, report Report) error {
+	views := report.Users
+	bw := bufio.NewWriter(w)
+	if len(report.Failures) > 0 {
+		fmt.Fprintln(bw, "> **Incomplete collection:** these endpoints kept failing and were no longer called, so the metrics relying on them are undercounted.")
+		fmt.Fprintln(bw, ">")
+		for _, f := range report.Failures {
+			fmt.Fprintf(bw, "> - `%s`: %d calls skipped, last error: %s\n", f.Endpoint, f.Skipped, markdownEscape(f.Error))
+		}
+		fmt.Fprintln(bw)
+	}
+	if len(views) > 0 {
+		h := report.Health
+		lifecycle, coverage := "-", "-"
+		if h.PullLifecycle > 0 {
+			lifecycle = durationHuman(h.PullLifecycle)
+		}
+		if h.MergedPulls > 0 {
+			coverage = fmt.Sprintf("%.0f%%", h.ReviewCoverage)
+		}
+		fmt.Fprintln(bw, "| Merged PRs | PRs a Week | Average PR Lifecycle | Review Coverage | Active Contributors |")
+		fmt.Fprintln(bw, "|-----------:|-----------:|---------------------:|----------------:|--------------------:|")
+		fmt.Fprintf(bw, "| %d | %.1f | %s | %s | %d of %d |\n\n", h.MergedPulls, h.PullsPerWeek, lifecycle, coverage, h.Contributors, h.Users)
+	}
+	lcp := "LcP"
+	if report.Aggregation != "" {
+		lcp += " (" + report.Aggregation + ")"
+	}
+	fmt.Fprintf(bw, "| # | User | Commits | HoC | Issues | %s | Msgs | Pulls | Reviews | Score | Top Repositories |\n", lcp)
+	fmt.Fprintln(bw, "|--:|------|--------:|----:|-------:|----:|-----:|------:|--------:|------:|------------------|")
+	for i, view := range views {
+		m := view.Metrics
+		// Counts that request errors left incomplete read "unknown".
+		cell := func(metric string, value interface{}) string {
+			if m.IsUnknown(metric) {
+				return "unknown"
+			}
+			if f, ok := value.(float64); ok {
+				return fmt.Sprintf("%.2f", f)
+			}
+			return fmt.Sprint(value)
+		}
+		fmt.Fprintf(bw, "| %d | @%s | %s | %s | %s | %s | %s | %s | %s | %.2f | %s |\n",
+			i+1, markdownEscape(view.User), cell(MetricCommits, m.Commits), cell(MetricHoC, m.HoC), cell(MetricIssues, m.Issues), cell(MetricLcP, m.LcP),
+			cell(MetricMsgs, m.Msgs), cell(MetricPulls, m.Pulls), cell(MetricReviews, m.Reviews), m.Score, markdownEscape(view.TopRepos))
+	}
+	for _, board := range report.Leaderboards {
+		fmt.Fprintf(bw, "\n| # | %s | Commits | HoC | Issues | Msgs | Pulls | Reviews | Score |\n", markdownEscape(board.Name))
+		fmt.Fprintln(bw, "|--:|------|--------:|----:|-------:|-----:|------:|--------:|------:|")
+		for _, view := range board.Users {
+			m := view.Metrics
+			fmt.Fprintf(bw, "| %d | @%s | %d | %d | %d | %d | %d | %d | %.2f |\n",
+				view.Rank, markdownEscape(view.User), m.Commits, m.HoC, m.Issues, m.Msgs, m.Pulls, m.Reviews, m.Score)
+		}
+	}
+	if len(report.Teams) > 0 {
+		fmt.Fprintf(bw, "\n| Team | Members | Commits | HoC | Issues | %s | Msgs | Pulls | Reviews | Score |\n", lcp)
+		fmt.Fprintln(bw, "|------|--------:|--------:|----:|-------:|----:|-----:|------:|--------:|------:|")
+		for _, team := range report.Teams {
+			t, a := team.Total, team.Average
+			fmt.Fprintf(bw, "| %s | %d | %d (%.1f) | %d (%.1f) | %d (%.1f) | %.2f | %d (%.1f) | %d (%.1f) | %d (%.1f) | %.2f (%.2f) |\n",
+				markdownEscape(team.Team), team.Members, t.Commits, a.Commits, t.HoC, a.HoC, t.Issues, a.Issues, a.LcP,
+				t.Msgs, a.Msgs, t.Pulls, a.Pulls, t.Reviews, a.Reviews, t.Score, a.Score)
+		}
+		fmt.Fprintln(bw, "\n_Team cells show the total with the per-member average in parentheses._")
+	}
+	if len(report.Repos) > 0 {
+		fmt.Fprintln(bw, "\n| Repository | Commits | HoC | Merged PRs | Median Review Time | Contributors |")
+		fmt.Fprintln(bw, "|------------|--------:|----:|-----------:|-------------------:|-------------:|")
+		for _, repo := range report.Repos {
+			m := repo.Metrics
+			reviewTime := "-"
+			if len(m.ReviewTimes) > 0 {
+				reviewTime = durationHuman(repo.MedianReviewTime)
+			}
+			fmt.Fprintf(bw, "| %s | %d | %d | %d | %s | %d |\n",
+				markdownEscape(repo.Repo), m.Commits, m.HoC, m.Pulls, reviewTime, len(repo.Contributors))
+		}
+	}
+	if len(report.Inactive) > 0 {
+		var users []string
+		for _, user := range report.Inactive {
+			users = append(users, "@"+markdownEscape(user))
+		}
+		fmt.Fprintf(bw, "\n_No activity in the window: %s._\n", strings.Join(users, ", "))
+	}
+	if report.WorkingHours != "" {
+		fmt.Fprintf(bw, "\n_Off hours: weekends and outside %s._\n\n", markdownEscape(report.WorkingHours))
+		fmt.Fprintln(bw, "| User | Off-Hours Share | Off-Hours Commits | Off-Hours Reviews |")
+		fmt.Fprintln(bw, "|------|----------------:|------------------:|------------------:|")
+		for _, view := range report.Users {
+			m := view.Metrics
+			fmt.Fprintf(bw, "| @%s | %.0f%% | %d | %d |\n", markdownEscape(view.User), m.OffHoursShare(), m.OffHoursCommits, m.OffHoursReviews)
+		}
+	}
+	if report.Tone {
+		fmt.Fprint(bw, "\n_Collaboration tone, experimental: review comments rated from -1, harsh, to 1, polite._\n\n")
+		fmt.Fprintln(bw, "| User | Tone | Review Comments | Harsh Comments |")
+		fmt.Fprintln(bw, "|------|-----:|----------------:|---------------:|")
+		for _, view := range report.Users {
+			m := view.Metrics
+			fmt.Fprintf(bw, "| @%s | %+.2f | %d | %d |\n", markdownEscape(view.User), m.Tone, m.ToneComments, m.HarshComments)
+		}
+	}
+	if len(report.Newcomers) > 0 {
+		fmt.Fprintln(bw, "\n| Newcomer | First Pull Request | Opened | Time to First Merge |")
+		fmt.Fprintln(bw, "|----------|--------------------|--------|--------------------:|")
+		for _, n := range report.Newcomers {
+			merge := "not merged yet"
+			if n.FirstMerge != nil {
+				merge = durationHuman(n.TimeToMerge)
+			}
+			fmt.Fprintf(bw, "| @%s | [%s#%d](%s) %s | %s | %s |\n", markdownEscape(n.User), n.FirstPull.Repo, n.FirstPull.Number, n.FirstPull.URL,
+				markdownEscape(n.FirstPull.Title), n.FirstPull.CreatedAt.Format("2006-01-02"), merge)
+		}
+	}
+	if len(report.StalePulls) > 0 {
+		fmt.Fprintf(bw, "\n_Pull requests open for more than %d days:_\n", report.StaleDays)
+		fmt.Fprintln(bw, "\n| User | Repository | Open PRs | Oldest | Age |")
+		fmt.Fprintln(bw, "|------|------------|---------:|--------|----:|")
+		for _, stale := range report.StalePulls {
+			fmt.Fprintf(bw, "| @%s | %s | %d | [#%d](%s) %s | %s |\n", markdownEscape(stale.User), stale.Repo, stale.Count, stale.Oldest.Number, stale.Oldest.URL,
+				markdownEscape(stale.Oldest.Title), durationHuman(stale.Age))
+		}
+	}
+	if len(report.CodeOwners) > 0 {
+		fmt.Fprintln(bw, "\n| User | Code Owner PRs | Reviewed | Share |")
+		fmt.Fprintln(bw, "|------|---------------:|---------:|------:|")
+		for _, view := range report.Users {
+			if m := view.Metrics; m.CodeOwnerPulls > 0 {
+				fmt.Fprintf(bw, "| @%s | %d | %d | %.0f%% |\n", markdownEscape(view.User), m.CodeOwnerPulls, m.CodeOwnerReviews, m.CodeOwnerReviewShare())
+			}
+		}
+		fmt.Fprintln(bw, "\n| Repository | Path | PRs | Owner Reviewed | Coverage | Owners |")
+		fmt.Fprintln(bw, "|------------|------|----:|---------------:|---------:|--------|")
+		for _, path := range report.CodeOwners {
+			fmt.Fprintf(bw, "| %s | `%s` | %d | %d | %.0f%% | %s |\n", path.Repo, path.Path, path.Pulls, path.Reviewed, path.Coverage(), markdownEscape(strings.Join(path.Owners, ", ")))
+		}
+	}
+	if len(report.PlannedWork) > 0 {
+		fmt.Fprintln(bw, "\n| User | Planned Issues | Planned PRs |")
+		fmt.Fprintln(bw, "|------|---------------:|------------:|")
+		for _, view := range report.Users {
+			if m := view.Metrics; m.PlannedIssues+m.PlannedPulls > 0 {
+				fmt.Fprintf(bw, "| @%s | %d | %d |\n", markdownEscape(view.User), m.PlannedIssues, m.PlannedPulls)
+			}
+		}
+		fmt.Fprintln(bw, "\n| Plan | Issues | PRs | Users |")
+		fmt.Fprintln(bw, "|------|-------:|----:|-------|")
+		for _, plan := range report.PlannedWork {
+			fmt.Fprintf(bw, "| %s | %d | %d | %s |\n", markdownEscape(plan.Plan), plan.Issues, plan.Pulls, markdownEscape(strings.Join(plan.Users, ", ")))
+		}
+	}
+	if load := report.ReviewLoad; len(load.Users) > 0 {
+		fmt.Fprintf(bw, "\n_Review load balance: %.0f of 100._\n", load.Balance)
+		fmt.Fprintln(bw, "\n| User | Reviews Given | Merged PRs | Reviews per PR | Heavy Author |")
+		fmt.Fprintln(bw, "|------|--------------:|-----------:|---------------:|:------------:|")
+		for _, user := range load.Users {
+			heavy := ""
+			if user.HeavyAuthor {
+				heavy = "yes"
+			}
+			fmt.Fprintf(bw, "| @%s | %d | %d | %.2f | %s |\n", markdownEscape(user.User), user.Reviews, user.Pulls, user.Ratio, heavy)
+		}
+		if len(load.Teams) > 0 {
+			fmt.Fprintln(bw, "\n| Team | Reviews Given | Merged PRs | Reviews per PR | Balance |")
+			fmt.Fprintln(bw, "|------|--------------:|-----------:|---------------:|--------:|")
+			for _, team := range load.Teams {
+				fmt.Fprintf(bw, "| %s | %d | %d | %.2f | %.0f |\n", markdownEscape(team.Team), team.Reviews, team.Pulls, team.Ratio, team.Balance)
+			}
+		}
+	}
+	if own := report.Ownership; len(own.Repos) > 0 {
+		fmt.Fprintf(bw, "\n_Across all users, HoC has a Gini coefficient of %.2f and a bus factor of %d; pull requests have %.2f and %d._\n",
+			own.HoC.Gini, own.HoC.BusFactor, own.Pulls.Gini, own.Pulls.BusFactor)
+		fmt.Fprintln(bw, "\n| Repository | HoC Top Contributor | HoC Bus Factor | HoC Gini | PR Top Contributor | PR Bus Factor | PR Gini | Single Person |")
+		fmt.Fprintln(bw, "|------------|---------------------|---------------:|---------:|--------------------|--------------:|--------:|:-------------:|")
+		for _, repo := range own.Repos {
+			single := ""
+			if repo.SinglePerson() {
+				single = "yes"
+			}
+			fmt.Fprintf(bw, "| %s | %s | %d | %.2f | %s | %d | %.2f | %s |\n",
+				markdownEscape(repo.Repo), topContributor(repo.HoC), repo.HoC.BusFactor, repo.HoC.Gini,
+				topContributor(repo.Pulls), repo.Pulls.BusFactor, repo.Pulls.Gini, single)
+		}
+	}
+	if len(report.PeriodUsers) > 0 {
+		header, align := "\n| User | Metric |", "|------|--------|"
+		for _, period := range report.Periods {
+			header += " " + period.Label() + " |"
+			align += "--:|"
+		}
+		fmt.Fprintln(bw, header)
+		fmt.Fprintln(bw, align)
+		for _, view := range report.PeriodUsers {
+			for _, row := range view.Rows() {
+				fmt.Fprintf(bw, "| @%s | %s | %s |\n", markdownEscape(view.User), row.Metric, strings.Join(row.Values, " | "))
+			}
+		}
+	}
+	fmt.Fprintf(bw, "\n_Activity %s._\n", report.Window())
+	return bw.Flush()
+}
+
+// markdownEscape escapes characters that would break a table cell.
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
+
+// topContributor formats the user with the largest share, or "-" for none.
+func topContributor(c Concentration) string {
+	if c.TopUser == "" {
+		return "-"
+	}
+	return fmt.Sprintf("@%s (%.0f%%)", markdownEscape(c.TopUser), c.TopShare)
+}
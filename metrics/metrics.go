@@ -0,0 +1,518 @@
+// Package metrics collects GitHub user metrics, scores them and renders the
+// results. The collectors, scorer and renderers are exposed through small
+// interfaces so other programs can compute UserMetrics without the CLI.
+package metrics
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+type UserMetrics struct {
+	Commits int
+	HoC     int
+	Issues  int
+	LcP     float64
+	Msgs    int
+	Pulls   int
+	Reviews int
+	Score   float64
+	Repos   map[string]int // Repositories touched and lines changed
+
+	// Lines, collected with the hoc metric from the same commits and files
+	Deletions int // Lines deleted
+	Churn     int // Lines added plus lines deleted
+	NetLines  int // Lines added minus lines deleted
+
+	// Documentation, collected with the hoc metric from the same files
+	Docs        int // HoC of the files matching DocPaths, whatever HoCFilter leaves out
+	DocsCommits int // Commits changing such files
+
+	// Comments, collected with the msgs metric; Msgs is their sum
+	IssueComments int // Comments on issues
+	PRComments    int // Comments on pull requests, in the conversation and on the diff
+
+	// Review quality, collected with the reviews metric
+	ReviewComments    int     // Pull request review comments authored
+	Approvals         int     // Reviews that approved the pull request
+	ChangesRequested  int     // Reviews that requested changes
+	TimeToFirstReview float64 // Average hours from review request to the user's first review
+	FirstReviews      int     // Requested reviews TimeToFirstReview is averaged over
+
+	PendingReviewRequests int // Reviews requested in the window on open pull requests the user has not reviewed
+	IgnoredReviewRequests int // Reviews requested in the window on pull requests closed or merged without the user's review
+
+	// Code ownership, collected with the reviews metric when CODEOWNERS files are read
+	CodeOwnerPulls   int         // Merged pull requests by others changing files the user owns
+	CodeOwnerReviews int         // CodeOwnerPulls the user reviewed
+	OwnedPulls       []OwnedPull // Each owned path CodeOwnerPulls changed
+
+	// Pull requests, collected with the pulls metric
+	PullSizes     []int          // Lines changed by each merged pull request
+	ReviewedPulls int            // Merged pull requests reviewed by someone other than the author
+	RepoPulls     map[string]int // Merged pull requests by repository
+	FirstPull     *FirstPull     // Earliest pull request opened in the measured repositories, at any time
+	FirstMerge    *time.Time     // First merge of a pull request, looked up when FirstPull falls into the window
+	StalePulls    []OpenPull     // Pull requests still open at collection after the stale threshold
+
+	ReviewTimes []float64 // Hours from review request to first review of each pull request, collected with the reviews metric
+
+	// Pull request flow, collected with the lcp metric
+	PullLifecycles   []float64 // Hours from opening to closing each pull request LcP averages
+	FirstReviewWaits []float64 // Hours from opening each merged pull request to its first review by someone else
+	ReviewRounds     []int     // Distinct commits reviewed by others in each reviewed merged pull request
+	MergeWaits       []float64 // Hours from the last approval to the merge of each approved pull request
+
+	// Issue triage, collected with the triage metric
+	Labeled      int     // Label events on issues by the user
+	Assigned     int     // Assignment events on issues by the user
+	IssuesClosed int     // Issues closed by the user
+	TimeToTriage float64 // Average hours from an issue being opened to the user labeling it first
+	Triaged      int     // Issues TimeToTriage is averaged over
+
+	IssuesClosedManually int // IssuesClosed without a commit or pull request closing them
+	IssuesFixed          int // Issues closed by merging the user's pull requests that reference them with a closing keyword
+
+	// Planned work, collected with the issues metric when a project or milestone is set
+	PlannedIssues int           // Issues of the plan assigned to or, unassigned, closed by the user and closed as completed during the window
+	PlannedPulls  int           // Pull requests of the plan by the user merged during the window
+	PlannedItems  []PlannedItem // Each item PlannedIssues and PlannedPulls count, once per plan it belongs to
+
+	// Discussions participation, collected with the discussions metric
+	DiscussionsStarted int // Discussions opened by the user
+	DiscussionComments int // Comments and replies on discussions
+	DiscussionAnswers  int // Comments by the user marked as the answer
+
+	// Reverts, collected with the reverts metric
+	Reverts     int // Commits and pull requests by the user reverted during the window
+	ForcePushes int // Force pushes by the user to pull request branches
+
+	// Releases, collected with the releases metric
+	ReleasesPublished int // Releases the user published during the window, drafts left out
+	TagsCreated       int // Annotated tags the user created during the window
+	ShippedIn         int // Releases published during the window that shipped commits by the user
+
+	// CI work, collected with the workflows metric
+	WorkflowsAdded    int // GitHub Actions workflow files the user added during the window
+	WorkflowsModified int // Existing workflow files the user changed during the window
+	RunsFixed         int // Workflow runs that passed on the user's commit after the run before failed
+
+	// Security work, collected with the opt-in security metric
+	SecurityAlertsFixed     int // Code scanning alerts fixed by the user's commits during the window
+	SecurityAlertsDismissed int // Dependabot and code scanning alerts the user dismissed during the window
+	DependabotPullsMerged   int // Dependabot pull requests the user merged during the window
+	DependabotPullsReviewed int // Dependabot pull requests merged during the window that the user reviewed
+
+	// Commits, issues, pull requests and reviews by the visibility of their
+	// repository, for GitHub repositories
+	PublicActivity   int
+	PrivateActivity  int
+	InternalActivity int // Internal repositories of GitHub Enterprise
+
+	// Wellbeing, collected with the commits and reviews metrics when working
+	// hours are set
+	OffHoursCommits  int // Commits authored on weekends or outside working hours
+	OffHoursReviews  int // Reviews submitted on weekends or outside working hours
+	SubmittedReviews int // Reviews submitted, which OffHoursReviews are part of
+
+	// Collaboration tone, experimental, collected with the reviews metric
+	// when Tone is set
+	Tone          float64 // Average tone of the review comments, from -1 harsh to 1 polite
+	ToneComments  int     // Review comments Tone is averaged over
+	HarshComments int     // Review comments rated harsh
+
+	// Active days, from the dates of the user's commits, merged pull
+	// requests, submitted reviews and comments in the window
+	ActiveDays    int            // Distinct days with activity
+	LongestStreak int            // Most consecutive active days
+	DailyActivity map[string]int // Contributions per UTC day as YYYY-MM-DD
+
+	Unknown []string // Metrics left incomplete by request errors with ErrorModePartial, sorted
+}
+
+// Metric names accepted by Collector.Collect and Calculator.Calculate.
+const (
+	MetricAll     = "all"
+	MetricCommits = "commits"
+	MetricHoC     = "hoc"
+	MetricIssues  = "issues"
+	MetricLcP     = "lcp"
+	MetricMsgs    = "msgs"
+	MetricPulls   = "pulls"
+	MetricReviews = "reviews"
+	MetricTriage  = "triage"
+	MetricReverts = "reverts"
+
+	MetricDiscussions = "discussions"
+	MetricReleases    = "releases"
+	MetricWorkflows   = "workflows"
+	MetricSecurity    = "security"
+)
+
+// AllMetrics lists the individual metrics that MetricAll expands to.
+var AllMetrics = []string{MetricCommits, MetricHoC, MetricIssues, MetricLcP, MetricMsgs, MetricPulls, MetricReviews, MetricTriage, MetricReverts, MetricDiscussions, MetricReleases, MetricWorkflows, MetricSecurity}
+
+// Collector gathers metrics for a user from a source such as the GitHub API.
+type Collector interface {
+	// Repositories returns the repositories (as owner/name) the user was
+	// active in during the measured window.
+	Repositories(ctx context.Context, user string) ([]string, error)
+	// Collect computes a single metric, or all of them, for the user in
+	// the given owner/name repository.
+	Collect(ctx context.Context, user, repo, metric string) (UserMetrics, error)
+}
+
+// Providers collecting from a code hosting platform.
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+	ProviderGitea  = "gitea" // Also Forgejo
+)
+
+// Provider is a Collector calling the API of a code hosting platform, such
+// as GitHubCollector, GitLabCollector or GiteaCollector, which also reports how the calls
+// went for the run status.
+type Provider interface {
+	Collector
+	// APICalls returns the number of API requests made so far.
+	APICalls() int64
+	// Incomplete returns the tasks left incomplete by API errors.
+	Incomplete() []IncompleteTask
+	// EndpointFailures returns the endpoints given up on after repeated
+	// failures.
+	EndpointFailures() []EndpointFailure
+}
+
+// Calculator drives a Collector over a set of users and scores the results.
+type Calculator struct {
+	Collector   Collector
+	Scorer      Scorer
+	Verbose     bool
+	Concurrency int         // Number of collection tasks run in parallel, at least 1
+	Checkpoint  *Checkpoint // Optional progress record; finished tasks found in it are not collected again
+	Progress    *Progress   // Optional progress reporting
+	Repos       RepoTotals  // Optional totals by repository, filled in when not nil
+
+	// OnUser, when set, is called with the metrics collected so far each
+	// time a user has been fully processed.
+	OnUser func(metrics map[string]UserMetrics) error
+}
+
+// task is a single unit of collection work handed to the worker pool.
+type task struct {
+	user, repo, metric string
+}
+
+// Calculate collects the requested metric for every user across the
+// repositories reported by the collector. Collection is fanned out into
+// (user, repo, metric) tasks processed by Concurrency workers. On error or
+// cancellation the metrics of the tasks that finished are returned with the
+// error.
+func (c *Calculator) Calculate(ctx context.Context, users []string, metric string) (map[string]UserMetrics, error) {
+	if c.Verbose {
+		log.Printf("Calculating %s metric for %d users with %d workers\n", metric, len(users), c.workers())
+	}
+	scorer := c.Scorer
+	if scorer == nil {
+		scorer = DefaultScorer{}
+	}
+	metricNames := []string{metric}
+	if metric == MetricAll {
+		metricNames = AllMetrics
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		metrics  = make(map[string]UserMetrics)
+		pending  = make(map[string]int)
+	)
+	fail := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+	// rescore ranks the users against each other when the scorer needs
+	// the whole cohort. It must be called with mu held.
+	rescore := func() {
+		if cohort, ok := scorer.(CohortScorer); ok {
+			cohort.ScoreAll(metrics)
+		}
+	}
+	// done must be called with mu held.
+	done := func(user string) {
+		c.Progress.userDone(user)
+		if c.OnUser == nil || firstErr != nil {
+			return
+		}
+		rescore()
+		if err := c.OnUser(metrics); err != nil {
+			fail(err)
+		}
+	}
+
+	c.Progress.begin(len(users))
+	defer c.Progress.finish()
+
+	tasks := make(chan task)
+	var wg sync.WaitGroup
+	for i := 0; i < c.workers(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range tasks {
+				update, err := c.collect(ctx, t)
+
+				mu.Lock()
+				if err != nil {
+					fail(err)
+				} else {
+					m := Merge(metrics[t.user], update)
+					m.Score = scorer.Score(m)
+					metrics[t.user] = m
+					c.Repos.add(t.repo, t.user, update)
+				}
+				c.Progress.taskDone(t.user, t.repo)
+				pending[t.user]--
+				if pending[t.user] == 0 {
+					done(t.user)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+produce:
+	for _, user := range users {
+		repos, err := c.repositories(ctx, user)
+		if err != nil {
+			mu.Lock()
+			fail(err)
+			mu.Unlock()
+			break
+		}
+		if c.Verbose {
+			log.Printf("User %s has %d repositories\n", user, len(repos))
+		}
+
+		mu.Lock()
+		c.Progress.addUser(user, repos, len(metricNames))
+		pending[user] = len(repos) * len(metricNames)
+		if pending[user] == 0 {
+			done(user)
+		}
+		mu.Unlock()
+
+		for _, repo := range repos {
+			for _, name := range metricNames {
+				select {
+				case tasks <- task{user: user, repo: repo, metric: name}:
+				case <-ctx.Done():
+					break produce
+				}
+			}
+		}
+	}
+	close(tasks)
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	rescore()
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	return metrics, firstErr
+}
+
+// repositories discovers the user's repositories unless the checkpoint
+// already holds them.
+func (c *Calculator) repositories(ctx context.Context, user string) ([]string, error) {
+	if c.Checkpoint != nil {
+		if repos, ok := c.Checkpoint.repositories(user); ok {
+			return repos, nil
+		}
+	}
+	repos, err := c.Collector.Repositories(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if c.Checkpoint != nil {
+		if err := c.Checkpoint.setRepositories(user, repos); err != nil {
+			return nil, err
+		}
+	}
+	return repos, nil
+}
+
+// collect runs a task unless its result is already in the checkpoint.
+func (c *Calculator) collect(ctx context.Context, t task) (UserMetrics, error) {
+	if c.Checkpoint != nil {
+		if m, ok := c.Checkpoint.result(t); ok {
+			return m, nil
+		}
+	}
+	m, err := c.Collector.Collect(ctx, t.user, t.repo, t.metric)
+	if err != nil {
+		return m, err
+	}
+	// Collectors log request errors and return what they have, so a task
+	// interrupted by cancellation is incomplete and must not be recorded.
+	if err := ctx.Err(); err != nil {
+		return m, err
+	}
+	// Incomplete results are left out of the checkpoint, so resuming
+	// collects them again.
+	if c.Checkpoint != nil && len(m.Unknown) == 0 {
+		if err := c.Checkpoint.record(t, m); err != nil {
+			return m, err
+		}
+	}
+	return m, nil
+}
+
+func (c *Calculator) workers() int {
+	if c.Concurrency < 1 {
+		return 1
+	}
+	return c.Concurrency
+}
+
+// addLines counts additions and deletions towards the line metrics.
+func (m *UserMetrics) addLines(additions, deletions int) {
+	m.Deletions += deletions
+	m.Churn += additions + deletions
+	m.NetLines += additions - deletions
+}
+
+// addVisibility counts the commits, issues, pull requests and reviews of m
+// towards the activity of the visibility of their repository: public,
+// private or internal.
+func (m *UserMetrics) addVisibility(visibility string) {
+	activity := m.Commits + m.Issues + m.Pulls + m.Reviews
+	switch visibility {
+	case "public":
+		m.PublicActivity += activity
+	case "private":
+		m.PrivateActivity += activity
+	case "internal":
+		m.InternalActivity += activity
+	}
+}
+
+// Merge adds the counters of update to metrics. The score is left untouched
+// and must be recomputed by the caller.
+func Merge(metrics, update UserMetrics) UserMetrics {
+	metrics.Commits += update.Commits
+	metrics.HoC += update.HoC
+	metrics.Deletions += update.Deletions
+	metrics.Churn += update.Churn
+	metrics.NetLines += update.NetLines
+	metrics.Docs += update.Docs
+	metrics.DocsCommits += update.DocsCommits
+	metrics.Issues += update.Issues
+	metrics.LcP += update.LcP
+	metrics.Msgs += update.Msgs
+	metrics.IssueComments += update.IssueComments
+	metrics.PRComments += update.PRComments
+	metrics.Pulls += update.Pulls
+	metrics.Reviews += update.Reviews
+	metrics.ReviewComments += update.ReviewComments
+	metrics.Approvals += update.Approvals
+	metrics.ChangesRequested += update.ChangesRequested
+	metrics.PendingReviewRequests += update.PendingReviewRequests
+	metrics.IgnoredReviewRequests += update.IgnoredReviewRequests
+	metrics.CodeOwnerPulls += update.CodeOwnerPulls
+	metrics.CodeOwnerReviews += update.CodeOwnerReviews
+	metrics.OwnedPulls = append(metrics.OwnedPulls, update.OwnedPulls...)
+	if n := metrics.FirstReviews + update.FirstReviews; n > 0 {
+		metrics.TimeToFirstReview = (metrics.TimeToFirstReview*float64(metrics.FirstReviews) + update.TimeToFirstReview*float64(update.FirstReviews)) / float64(n)
+		metrics.FirstReviews = n
+	}
+	metrics.PullSizes = append(metrics.PullSizes, update.PullSizes...)
+	metrics.ReviewedPulls += update.ReviewedPulls
+	metrics.StalePulls = append(metrics.StalePulls, update.StalePulls...)
+	if update.FirstPull != nil && (metrics.FirstPull == nil || update.FirstPull.CreatedAt.Before(metrics.FirstPull.CreatedAt)) {
+		metrics.FirstPull = update.FirstPull
+	}
+	if update.FirstMerge != nil && (metrics.FirstMerge == nil || update.FirstMerge.Before(*metrics.FirstMerge)) {
+		metrics.FirstMerge = update.FirstMerge
+	}
+	metrics.ReviewTimes = append(metrics.ReviewTimes, update.ReviewTimes...)
+	metrics.PullLifecycles = append(metrics.PullLifecycles, update.PullLifecycles...)
+	metrics.FirstReviewWaits = append(metrics.FirstReviewWaits, update.FirstReviewWaits...)
+	metrics.ReviewRounds = append(metrics.ReviewRounds, update.ReviewRounds...)
+	metrics.MergeWaits = append(metrics.MergeWaits, update.MergeWaits...)
+	metrics.Labeled += update.Labeled
+	metrics.Assigned += update.Assigned
+	metrics.IssuesClosed += update.IssuesClosed
+	metrics.IssuesClosedManually += update.IssuesClosedManually
+	metrics.IssuesFixed += update.IssuesFixed
+	metrics.PlannedIssues += update.PlannedIssues
+	metrics.PlannedPulls += update.PlannedPulls
+	metrics.PlannedItems = append(metrics.PlannedItems, update.PlannedItems...)
+	if n := metrics.Triaged + update.Triaged; n > 0 {
+		metrics.TimeToTriage = (metrics.TimeToTriage*float64(metrics.Triaged) + update.TimeToTriage*float64(update.Triaged)) / float64(n)
+		metrics.Triaged = n
+	}
+	metrics.DiscussionsStarted += update.DiscussionsStarted
+	metrics.DiscussionComments += update.DiscussionComments
+	metrics.DiscussionAnswers += update.DiscussionAnswers
+	metrics.Reverts += update.Reverts
+	metrics.ForcePushes += update.ForcePushes
+	metrics.ReleasesPublished += update.ReleasesPublished
+	metrics.TagsCreated += update.TagsCreated
+	metrics.ShippedIn += update.ShippedIn
+	metrics.WorkflowsAdded += update.WorkflowsAdded
+	metrics.WorkflowsModified += update.WorkflowsModified
+	metrics.RunsFixed += update.RunsFixed
+	metrics.SecurityAlertsFixed += update.SecurityAlertsFixed
+	metrics.SecurityAlertsDismissed += update.SecurityAlertsDismissed
+	metrics.DependabotPullsMerged += update.DependabotPullsMerged
+	metrics.DependabotPullsReviewed += update.DependabotPullsReviewed
+	metrics.PublicActivity += update.PublicActivity
+	metrics.PrivateActivity += update.PrivateActivity
+	metrics.InternalActivity += update.InternalActivity
+	metrics.OffHoursCommits += update.OffHoursCommits
+	metrics.OffHoursReviews += update.OffHoursReviews
+	metrics.SubmittedReviews += update.SubmittedReviews
+	if n := metrics.ToneComments + update.ToneComments; n > 0 {
+		metrics.Tone = (metrics.Tone*float64(metrics.ToneComments) + update.Tone*float64(update.ToneComments)) / float64(n)
+		metrics.ToneComments = n
+	}
+	metrics.HarshComments += update.HarshComments
+	metrics.mergeDays(update.DailyActivity)
+	metrics.Unknown = mergeUnknown(metrics.Unknown, update.Unknown)
+
+	if metrics.Repos == nil {
+		metrics.Repos = make(map[string]int)
+	}
+	for repo, hoc := range update.Repos {
+		metrics.Repos[repo] += hoc
+	}
+	for repo, pulls := range update.RepoPulls {
+		if metrics.RepoPulls == nil {
+			metrics.RepoPulls = make(map[string]int)
+		}
+		metrics.RepoPulls[repo] += pulls
+	}
+
+	return metrics
+}
+
+// ParseRepo splits an owner/name string. Both values are empty when the
+// string is not in that form.
+func ParseRepo(repo string) (string, string) {
+	parts := strings.Split(repo, "/")
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
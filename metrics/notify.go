@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Notifier delivers the summary of a completed run to a chat or automation
+// system. SlackNotifier, TeamsNotifier and WebhookNotifier are the built-in
+// drivers.
+type Notifier interface {
+	Notify(ctx context.Context, summary Summary) error
+}
+
+// postJSON posts v as JSON to endpoint and fails unless the response is a
+// 2xx.
+func postJSON(ctx context.Context, client *http.Client, endpoint string, v interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("invalid webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		// Leave out the URL, as webhook URLs embed their secret.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RawSnapshot is the unscored data of a collection run: the repositories of
+// every user and the metrics of every (user, repo, metric) task. Scoring,
+// grouping by repository and rendering need nothing else, so a saved
+// snapshot lets a report be computed again offline, with other weights or
+// another template, without calling GitHub.
+type RawSnapshot struct {
+	Metric       string              `json:"metric"`
+	Since        time.Time           `json:"since"`
+	Until        time.Time           `json:"until"` // Zero for windows ending at CollectedAt
+	CollectedAt  time.Time           `json:"collectedAt"`
+	Organization string              `json:"organization,omitempty"`
+	WebURL       string              `json:"webURL,omitempty"`
+	Teams        map[string][]string `json:"teams,omitempty"`
+	Users        []string            `json:"users"` // Measured users, in the order given
+
+	Repositories map[string][]string    `json:"repositories"` // Discovered repositories per user
+	Tasks        map[string]UserMetrics `json:"tasks"`        // Results keyed by user|repo|metric
+	Failures     []EndpointFailure      `json:"failures,omitempty"`
+	WorkingHours string                 `json:"workingHours,omitempty"`
+	StaleDays    int                    `json:"staleDays,omitempty"`
+	Tone         bool                   `json:"tone,omitempty"`
+
+	mu sync.Mutex
+}
+
+// NewRawSnapshot returns an empty snapshot.
+func NewRawSnapshot() *RawSnapshot {
+	return &RawSnapshot{
+		Repositories: make(map[string][]string),
+		Tasks:        make(map[string]UserMetrics),
+	}
+}
+
+// LoadRawSnapshot reads a snapshot saved with Save.
+func LoadRawSnapshot(path string) (*RawSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := NewRawSnapshot()
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Repositories == nil || s.Tasks == nil {
+		return nil, fmt.Errorf("%s is not a raw data snapshot", path)
+	}
+	return s, nil
+}
+
+// Save writes the snapshot to path as JSON.
+func (s *RawSnapshot) Save(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// AddCheckpoint adds the repositories and tasks recorded in cp, which a
+// resumed Calculator takes from the checkpoint instead of its collector.
+func (s *RawSnapshot) AddCheckpoint(cp *Checkpoint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for user, repos := range cp.Repositories {
+		s.Repositories[user] = repos
+	}
+	for key, m := range cp.Tasks {
+		s.Tasks[key] = m
+	}
+}
+
+// Record returns a collector that adds everything collector returns to the
+// snapshot.
+func (s *RawSnapshot) Record(collector Collector) Collector {
+	return snapshotRecorder{collector: collector, snapshot: s}
+}
+
+type snapshotRecorder struct {
+	collector Collector
+	snapshot  *RawSnapshot
+}
+
+func (r snapshotRecorder) Repositories(ctx context.Context, user string) ([]string, error) {
+	repos, err := r.collector.Repositories(ctx, user)
+	if err != nil {
+		return repos, err
+	}
+	r.snapshot.mu.Lock()
+	defer r.snapshot.mu.Unlock()
+	if repos == nil {
+		repos = []string{}
+	}
+	r.snapshot.Repositories[user] = repos
+	return repos, nil
+}
+
+func (r snapshotRecorder) Collect(ctx context.Context, user, repo, metric string) (UserMetrics, error) {
+	m, err := r.collector.Collect(ctx, user, repo, metric)
+	// Like the checkpoint, a task cut short by cancellation is not recorded.
+	if err != nil || ctx.Err() != nil {
+		return m, err
+	}
+	r.snapshot.mu.Lock()
+	defer r.snapshot.mu.Unlock()
+	r.snapshot.Tasks[taskKey(task{user: user, repo: repo, metric: metric})] = m
+	return m, nil
+}
+
+// SnapshotCollector is a Collector answering from a RawSnapshot instead of
+// GitHub. Users, repositories and metrics missing from the snapshot are
+// errors.
+type SnapshotCollector struct {
+	Snapshot     *RawSnapshot
+	ExcludeRepos []string // owner/name repositories of the snapshot left out
+}
+
+// Repositories returns the repositories recorded for the user.
+func (c *SnapshotCollector) Repositories(ctx context.Context, user string) ([]string, error) {
+	repos, ok := c.Snapshot.Repositories[user]
+	if !ok {
+		return nil, fmt.Errorf("user %s is not in the snapshot", user)
+	}
+	var kept []string
+	for _, repo := range repos {
+		excluded := false
+		for _, exclude := range c.ExcludeRepos {
+			if strings.EqualFold(repo, exclude) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			kept = append(kept, repo)
+		}
+	}
+	return kept, nil
+}
+
+// Collect returns the recorded metrics of the task.
+func (c *SnapshotCollector) Collect(ctx context.Context, user, repo, metric string) (UserMetrics, error) {
+	m, ok := c.Snapshot.Tasks[taskKey(task{user: user, repo: repo, metric: metric})]
+	if !ok {
+		return UserMetrics{}, fmt.Errorf("the %s metric of %s in %s is not in the snapshot", metric, user, repo)
+	}
+	return m, nil
+}
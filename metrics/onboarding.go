@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// FirstPull is the earliest pull request a user opened in the measured
+// repositories.
+type FirstPull struct {
+	Repo      string    `json:"repo"`
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Newcomer is a user whose first pull request falls into the window.
+type Newcomer struct {
+	User        string
+	FirstPull   FirstPull
+	FirstMerge  *time.Time // When the user's first pull request was merged, nil until then
+	TimeToMerge float64    // Hours from opening the first pull request to the first merge
+}
+
+// firstPull looks up the earliest pull request the user opened in the
+// repository and, when it falls into the window, when the user first had a
+// pull request merged there.
+func (c *GitHubCollector) firstPull(ctx context.Context, owner, repo, user string) (*FirstPull, *time.Time) {
+	query := fmt.Sprintf("repo:%s/%s is:pr author:%s", owner, repo, user)
+	opts := &github.SearchOptions{
+		Sort:  "created",
+		Order: "asc",
+		ListOptions: github.ListOptions{
+			PerPage: 1,
+		},
+	}
+	result, _, err := c.retryWithBackoff(ctx, resourceSearch, "GET /search/issues", func() (interface{}, *github.Response, error) {
+		return c.api().SearchIssues(ctx, query, opts)
+	})
+	if err != nil {
+		logError(ctx, err, "fetching the first pull request of user %s in repo %s/%s", user, owner, repo)
+		return nil, nil
+	}
+	issues := result.(*github.IssuesSearchResult).Issues
+	if len(issues) == 0 {
+		return nil, nil
+	}
+	first := &FirstPull{
+		Repo:      owner + "/" + repo,
+		Number:    issues[0].GetNumber(),
+		Title:     issues[0].GetTitle(),
+		URL:       issues[0].GetHTMLURL(),
+		CreatedAt: issues[0].GetCreatedAt().Time,
+	}
+	if c.Verbose {
+		log.Printf("First pull request of user %s in repo %s/%s is #%d, opened at %s\n", user, owner, repo, first.Number, first.CreatedAt)
+	}
+	if first.CreatedAt.Before(c.Since) {
+		return first, nil
+	}
+
+	// Merged pull requests are closed when merged, so the earliest closing
+	// among the oldest ones is the first merge.
+	opts.PerPage = 100
+	result, _, err = c.retryWithBackoff(ctx, resourceSearch, "GET /search/issues", func() (interface{}, *github.Response, error) {
+		return c.api().SearchIssues(ctx, query+" is:merged", opts)
+	})
+	if err != nil {
+		logError(ctx, err, "fetching the first merged pull request of user %s in repo %s/%s", user, owner, repo)
+		return first, nil
+	}
+	var merged *time.Time
+	for _, issue := range result.(*github.IssuesSearchResult).Issues {
+		if closed := issue.ClosedAt; closed != nil && (merged == nil || closed.Before(*merged)) {
+			t := closed.Time
+			merged = &t
+		}
+	}
+	return first, merged
+}
+
+// newcomer reports whether the user's first pull request falls into the
+// window from since to until. A zero until means the window ends now.
+func newcomer(m UserMetrics, since, until time.Time) bool {
+	if m.FirstPull == nil {
+		return false
+	}
+	created := m.FirstPull.CreatedAt
+	return !created.Before(since) && (until.IsZero() || !created.After(until))
+}
+
+// Newcomers lists the users whose first pull request in the measured
+// repositories falls into the window, in the order they joined.
+func Newcomers(views []UserMetricsView) []Newcomer {
+	var newcomers []Newcomer
+	for _, view := range views {
+		if !view.Newcomer {
+			continue
+		}
+		n := Newcomer{User: view.User, FirstPull: *view.Metrics.FirstPull, FirstMerge: view.Metrics.FirstMerge}
+		if n.FirstMerge != nil {
+			n.TimeToMerge = n.FirstMerge.Sub(n.FirstPull.CreatedAt).Hours()
+		}
+		newcomers = append(newcomers, n)
+	}
+	sort.Slice(newcomers, func(i, j int) bool {
+		return newcomers[i].FirstPull.CreatedAt.Before(newcomers[j].FirstPull.CreatedAt)
+	})
+	return newcomers
+}
@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"path"
+	"strings"
+)
+
+// PathFilter decides which changed files count towards hits of code.
+type PathFilter struct {
+	Exclude       []string // Glob patterns of paths to skip, e.g. vendor/** or *.lock
+	Languages     []string // When set, only files in these languages count
+	SkipGenerated bool     // Skip binary files and generated ones, see GitAttributes.Generated
+}
+
+// IsZero reports whether the filter lets every file through.
+func (f PathFilter) IsZero() bool {
+	return len(f.Exclude) == 0 && len(f.Languages) == 0 && !f.SkipGenerated
+}
+
+// MatchFile reports whether a changed file counts towards HoC, binary when
+// it has no line diff, in a repository with the given attributes.
+func (f PathFilter) MatchFile(filename string, binary bool, attrs GitAttributes) bool {
+	if f.SkipGenerated && (binary || attrs.Generated(filename)) {
+		return false
+	}
+	return f.Match(filename)
+}
+
+// Match reports whether filename counts towards HoC by Exclude and
+// Languages.
+func (f PathFilter) Match(filename string) bool {
+	for _, pattern := range f.Exclude {
+		if MatchGlob(pattern, filename) {
+			return false
+		}
+	}
+	if len(f.Languages) == 0 {
+		return true
+	}
+	lang := Language(filename)
+	for _, want := range f.Languages {
+		if strings.EqualFold(want, lang) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultDocPaths are the files counted as documentation unless other
+// patterns are given: docs directories and Markdown, reStructuredText and
+// AsciiDoc files anywhere in the tree.
+var DefaultDocPaths = DocPaths{"docs/**", "doc/**", "*.md", "*.mdx", "*.rst", "*.adoc"}
+
+// DocPaths are glob patterns of documentation files, matched like
+// PathFilter.Exclude.
+type DocPaths []string
+
+// Match reports whether filename is documentation.
+func (d DocPaths) Match(filename string) bool {
+	for _, pattern := range d {
+		if MatchGlob(pattern, filename) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchGlob matches a slash-separated path against a glob pattern. "**"
+// matches any number of directories and a pattern without a slash matches
+// the base name anywhere in the tree, as in .gitignore.
+func MatchGlob(pattern, name string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// languages maps file extensions to language names.
+var languages = map[string]string{
+	".go":    "Go",
+	".py":    "Python",
+	".js":    "JavaScript",
+	".jsx":   "JavaScript",
+	".mjs":   "JavaScript",
+	".ts":    "TypeScript",
+	".tsx":   "TypeScript",
+	".java":  "Java",
+	".kt":    "Kotlin",
+	".scala": "Scala",
+	".rb":    "Ruby",
+	".php":   "PHP",
+	".rs":    "Rust",
+	".c":     "C",
+	".h":     "C",
+	".cc":    "C++",
+	".cpp":   "C++",
+	".hpp":   "C++",
+	".cs":    "C#",
+	".swift": "Swift",
+	".m":     "Objective-C",
+	".sh":    "Shell",
+	".sql":   "SQL",
+	".html":  "HTML",
+	".css":   "CSS",
+	".scss":  "SCSS",
+	".vue":   "Vue",
+	".dart":  "Dart",
+	".ex":    "Elixir",
+	".exs":   "Elixir",
+	".tf":    "HCL",
+	".yml":   "YAML",
+	".yaml":  "YAML",
+	".json":  "JSON",
+	".md":    "Markdown",
+	".proto": "Protocol Buffers",
+}
+
+// Language guesses the programming language of a file from its extension.
+// It returns an empty string for unknown extensions.
+func Language(filename string) string {
+	return languages[strings.ToLower(path.Ext(filename))]
+}
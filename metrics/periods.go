@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Period holds the metrics collected for one of several consecutive windows
+// compared side by side.
+type Period struct {
+	Since time.Time              `json:"since"`
+	Until time.Time              `json:"until"`
+	Users map[string]UserMetrics `json:"users"`
+}
+
+// Label names the period by its first and last day, e.g.
+// 2024-01-01..2024-01-30.
+func (p Period) Label() string {
+	return p.Since.Format("2006-01-02") + ".." + p.Until.Format("2006-01-02")
+}
+
+// PeriodMetrics are the metrics shown in a period comparison, in row order.
+var PeriodMetrics = []string{"Commits", "HoC", "Issues", "LcP", "Msgs", "Pulls", "Reviews", "Score"}
+
+// UserPeriodsView is a user's metrics in every compared period.
+type UserPeriodsView struct {
+	User    string
+	Metrics []UserMetrics // One per period, oldest first
+}
+
+// PeriodRow is one metric of a user formatted for every period.
+type PeriodRow struct {
+	Metric string
+	Values []string
+}
+
+// Rows returns a row per entry of PeriodMetrics.
+func (v UserPeriodsView) Rows() []PeriodRow {
+	rows := make([]PeriodRow, len(PeriodMetrics))
+	for i, name := range PeriodMetrics {
+		rows[i].Metric = name
+	}
+	for _, m := range v.Metrics {
+		values := []string{
+			strconv.Itoa(m.Commits),
+			strconv.Itoa(m.HoC),
+			strconv.Itoa(m.Issues),
+			strconv.FormatFloat(m.LcP, 'f', 2, 64),
+			strconv.Itoa(m.Msgs),
+			strconv.Itoa(m.Pulls),
+			strconv.Itoa(m.Reviews),
+			strconv.FormatFloat(m.Score, 'f', 2, 64),
+		}
+		for i := range rows {
+			rows[i].Values = append(rows[i].Values, values[i])
+		}
+	}
+	return rows
+}
+
+// PeriodViews returns every user measured in any of the periods, sorted by
+// descending score in the latest period. Users without activity in a period
+// have zero metrics for it.
+func PeriodViews(periods []Period) []UserPeriodsView {
+	if len(periods) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var views []UserPeriodsView
+	for _, period := range periods {
+		for user := range period.Users {
+			if !seen[user] {
+				seen[user] = true
+				views = append(views, UserPeriodsView{User: user})
+			}
+		}
+	}
+	for i := range views {
+		for _, period := range periods {
+			views[i].Metrics = append(views[i].Metrics, period.Users[views[i].User])
+		}
+	}
+
+	latest := len(periods) - 1
+	sort.Slice(views, func(i, j int) bool {
+		si, sj := views[i].Metrics[latest].Score, views[j].Metrics[latest].Score
+		if si != sj {
+			return si > sj
+		}
+		return views[i].User < views[j].User
+	})
+
+	return views
+}
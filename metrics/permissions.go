@@ -0,0 +1,308 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// Access is what a collection is about to read, for CheckAccess to verify
+// the credentials against before it starts.
+type Access struct {
+	Repos    []string // owner/name repositories given explicitly
+	Orgs     []string // Organizations whose repositories are measured
+	Members  []string // Organizations whose members are measured
+	Teams    []string // org/team-slug teams whose members are measured
+	Metric   string   // Metric collected, MetricAll for every one
+	Security bool     // Whether the security metric is collected, see GitHubCollector.Security
+	Plan     Plan
+}
+
+// MissingPermission is a permission the credentials lack for a collection.
+// Without it the requests of the metrics needing it fail or, worse, answer
+// with less than there is, and the metrics count zero.
+type MissingPermission struct {
+	Permission string   // As named in the settings of fine-grained tokens and GitHub Apps, e.g. "Pull requests: read"
+	Scope      string   // Classic token scope granting it, empty when it comes with access to the repository
+	Target     string   // Repository or organization it is missing on
+	For        []string // Metrics and options needing it
+}
+
+func (m MissingPermission) String() string {
+	s := m.Permission + " on " + m.Target
+	if m.Scope != "" {
+		s += " (classic token scope " + m.Scope + ")"
+	}
+	return s + ", for " + strings.Join(m.For, ", ")
+}
+
+// repoPermission is a repository permission of fine-grained tokens and
+// GitHub Apps, with a request GitHub refuses without it.
+type repoPermission struct {
+	permission string
+	scope      string   // Classic scope, beyond repo for private repositories
+	metrics    []string // Metrics needing it
+	probe      func(ctx context.Context, api GitHubAPI, owner, repo string) error
+}
+
+var repoPermissions = []repoPermission{
+	{"Contents: read", "", []string{MetricCommits, MetricHoC, MetricReverts, MetricReleases, MetricWorkflows}, func(ctx context.Context, api GitHubAPI, owner, repo string) error {
+		_, _, err := api.ListCommits(ctx, owner, repo, &github.CommitsListOptions{ListOptions: github.ListOptions{PerPage: 1}})
+		return err
+	}},
+	{"Issues: read", "", []string{MetricIssues, MetricLcP, MetricMsgs, MetricTriage, MetricSecurity}, func(ctx context.Context, api GitHubAPI, owner, repo string) error {
+		_, _, err := api.ListIssues(ctx, owner, repo, &github.IssueListByRepoOptions{ListOptions: github.ListOptions{PerPage: 1}})
+		return err
+	}},
+	{"Pull requests: read", "", []string{MetricLcP, MetricMsgs, MetricPulls, MetricReviews}, func(ctx context.Context, api GitHubAPI, owner, repo string) error {
+		_, _, err := api.ListPullRequests(ctx, owner, repo, &github.PullRequestListOptions{ListOptions: github.ListOptions{PerPage: 1}})
+		return err
+	}},
+	{"Actions: read", "", []string{MetricWorkflows}, func(ctx context.Context, api GitHubAPI, owner, repo string) error {
+		_, _, err := api.ListWorkflowRuns(ctx, owner, repo, &github.ListWorkflowRunsOptions{ListOptions: github.ListOptions{PerPage: 1}})
+		return err
+	}},
+	{"Discussions: read", "", []string{MetricDiscussions}, func(ctx context.Context, api GitHubAPI, owner, repo string) error {
+		return graphQLProbe(ctx, api, `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) { discussions(first: 1) { totalCount } }
+}`, map[string]interface{}{"owner": owner, "name": repo})
+	}},
+	{"Code scanning alerts: read", "security_events", []string{MetricSecurity}, func(ctx context.Context, api GitHubAPI, owner, repo string) error {
+		_, _, err := api.ListCodeScanningAlerts(ctx, owner, repo, &github.AlertListOptions{ListOptions: github.ListOptions{PerPage: 1}})
+		return err
+	}},
+	{"Dependabot alerts: read", "security_events", []string{MetricSecurity}, func(ctx context.Context, api GitHubAPI, owner, repo string) error {
+		_, _, err := api.ListDependabotAlerts(ctx, owner, repo, &github.ListAlertsOptions{ListCursorOptions: github.ListCursorOptions{PerPage: 1}})
+		return err
+	}},
+}
+
+// impliedScopes are the classic scopes that grant another.
+var impliedScopes = map[string][]string{
+	"read:org":        {"read:org", "write:org", "admin:org"},
+	"read:project":    {"read:project", "project"},
+	"security_events": {"security_events", "repo"},
+}
+
+// accessCheck holds what CheckAccess learned about the credentials.
+type accessCheck struct {
+	classic bool            // A classic token, whose scopes GitHub lists with every response
+	scopes  map[string]bool // Scopes of a classic token
+	missing []MissingPermission
+}
+
+// CheckAccess verifies that the credentials of api can read what a
+// collection needs, so that a run missing permissions fails before it
+// starts rather than counting zeros. Every repository given explicitly
+// must be visible. Classic tokens are checked by their scopes, which
+// GitHub reports with every response; fine-grained tokens and GitHub Apps
+// have no such list, so a request needing each permission is sent for the
+// first repository, standing in for the rest, and for the organizations and
+// teams whose members are measured. The check costs a few requests, none
+// per user.
+//
+// It returns the missing permissions, and an error when a request failed
+// for another reason, such as bad credentials.
+func CheckAccess(ctx context.Context, api GitHubAPI, access Access) ([]MissingPermission, error) {
+	c := &accessCheck{}
+	var sample string
+	for _, repo := range access.Repos {
+		owner, name := ParseRepo(repo)
+		_, resp, err := api.GetRepository(ctx, owner, name)
+		if refused(err, true) {
+			c.miss("Repository access", "repo", repo, "every metric")
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		c.readScopes(resp)
+		if sample == "" {
+			sample = repo
+		}
+	}
+	for _, org := range access.Orgs {
+		repos, resp, err := api.ListOrgRepositories(ctx, org, &github.RepositoryListByOrgOptions{ListOptions: github.ListOptions{PerPage: 1}})
+		if err != nil && !refused(err, true) {
+			return nil, err
+		}
+		if err != nil || len(repos) == 0 {
+			c.miss("Repository access", "repo", org, "--organization")
+			continue
+		}
+		c.readScopes(resp)
+		if sample == "" {
+			sample = repos[0].GetFullName()
+		}
+	}
+
+	for _, org := range access.Members {
+		err := c.orgPermission(func() error {
+			_, _, err := api.ListOrgMembers(ctx, org, &github.ListMembersOptions{ListOptions: github.ListOptions{PerPage: 1}})
+			return err
+		}, "Members: read", "read:org", org, "--all-org-members")
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, team := range access.Teams {
+		org, slug := ParseRepo(team)
+		err := c.orgPermission(func() error {
+			_, _, err := api.ListTeamMembers(ctx, org, slug, &github.TeamListTeamMembersOptions{ListOptions: github.ListOptions{PerPage: 1}})
+			return err
+		}, "Members: read", "read:org", team, "--team")
+		if err != nil {
+			return nil, err
+		}
+	}
+	if plan := access.Plan; plan.ProjectOwner != "" {
+		err := c.orgPermission(func() error {
+			return graphQLProbe(ctx, api, `query($owner: String!, $number: Int!) {
+  repositoryOwner(login: $owner) { ... on ProjectV2Owner { projectV2(number: $number) { id } } }
+}`, map[string]interface{}{"owner": plan.ProjectOwner, "number": plan.ProjectNumber})
+		}, "Projects: read", "read:project", plan.ProjectOwner, "--project")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if sample == "" {
+		return c.missing, nil
+	}
+	owner, name := ParseRepo(sample)
+	for _, p := range repoPermissions {
+		var needed []string
+		for _, metric := range p.metrics {
+			if metric == MetricSecurity && !access.Security {
+				continue
+			}
+			if access.Metric == metric || !containsFold(AllMetrics, access.Metric) {
+				needed = append(needed, metric)
+			}
+		}
+		if len(needed) == 0 {
+			continue
+		}
+		if c.classic {
+			if p.scope != "" && !c.hasScope(p.scope) {
+				c.miss(p.permission, p.scope, sample, needed...)
+			}
+			continue
+		}
+		err := p.probe(ctx, api, owner, name)
+		if refused(err, false) {
+			c.miss(p.permission, p.scope, sample, needed...)
+		} else if err != nil && (IsAuthError(err) || !isClientError(err)) {
+			// Other client errors, such as alerts not enabled for the
+			// repository or an empty repository, say nothing of the
+			// permissions.
+			return nil, err
+		}
+	}
+	return c.missing, nil
+}
+
+// orgPermission checks an organization permission: by its scope for a
+// classic token, which GitHub answers without otherwise, leaving out what
+// is private, and by the probe request otherwise.
+func (c *accessCheck) orgPermission(probe func() error, permission, scope, target, option string) error {
+	if c.classic {
+		if !c.hasScope(scope) {
+			c.miss(permission, scope, target, option)
+		}
+		return nil
+	}
+	err := probe()
+	if refused(err, true) {
+		c.miss(permission, scope, target, option)
+		return nil
+	}
+	return err
+}
+
+func (c *accessCheck) miss(permission, scope, target string, needs ...string) {
+	c.missing = append(c.missing, MissingPermission{Permission: permission, Scope: scope, Target: target, For: needs})
+}
+
+// readScopes records the scopes of a classic token from the X-OAuth-Scopes
+// header, which responses to other credentials lack.
+func (c *accessCheck) readScopes(resp *github.Response) {
+	if c.scopes != nil || resp == nil || resp.Response == nil {
+		return
+	}
+	header, ok := resp.Header[http.CanonicalHeaderKey("X-OAuth-Scopes")]
+	if !ok {
+		return
+	}
+	c.classic = true
+	c.scopes = make(map[string]bool)
+	for _, value := range header {
+		for _, scope := range strings.Split(value, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				c.scopes[scope] = true
+			}
+		}
+	}
+}
+
+// hasScope reports whether the classic token has the scope, or one that
+// grants it.
+func (c *accessCheck) hasScope(scope string) bool {
+	if c.scopes[scope] {
+		return true
+	}
+	for _, implied := range impliedScopes[scope] {
+		if c.scopes[implied] {
+			return true
+		}
+	}
+	return false
+}
+
+// refused reports whether GitHub refused a request for lack of permission:
+// a 403 naming the credentials as unable to access the resource or, when
+// notFound is set, a 404, which is how GitHub hides what the credentials
+// cannot see. Other 403s, such as alerts that are not enabled for the
+// repository, are no permission issue.
+func refused(err error, notFound bool) bool {
+	if errors.Is(err, errNotAccessible) {
+		return true
+	}
+	var respErr *github.ErrorResponse
+	if !errors.As(err, &respErr) || respErr.Response == nil {
+		return false
+	}
+	switch respErr.Response.StatusCode {
+	case http.StatusNotFound:
+		return notFound
+	case http.StatusForbidden:
+		return strings.Contains(respErr.Message, "not accessible by") && !IsRateLimited(err)
+	}
+	return false
+}
+
+// errNotAccessible is a GraphQL query refused for lack of permission.
+var errNotAccessible = errors.New("resource not accessible")
+
+// graphQLProbe runs a GraphQL query, returning errNotAccessible when it was
+// refused for lack of permission.
+func graphQLProbe(ctx context.Context, api GitHubAPI, query string, variables map[string]interface{}) error {
+	var envelope struct {
+		Errors []struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if _, err := api.GraphQL(ctx, map[string]interface{}{"query": query, "variables": variables}, &envelope); err != nil {
+		return err
+	}
+	for _, e := range envelope.Errors {
+		if e.Type == "FORBIDDEN" || strings.Contains(e.Message, "not accessible by") {
+			return errNotAccessible
+		}
+	}
+	return nil
+}
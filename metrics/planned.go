@@ -0,0 +1,356 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Plan selects the planned work whose completed items are credited to the
+// users: a GitHub Project (v2) of an organization or user, a milestone
+// looked up by title in every repository measured, or both.
+type Plan struct {
+	ProjectOwner  string // Login of the organization or user owning the project
+	ProjectNumber int    // Number of the project, as in its URL
+	Milestone     string // Title of the milestone, e.g. v2.0
+}
+
+// IsZero reports whether no project or milestone is set.
+func (p Plan) IsZero() bool {
+	return p.ProjectOwner == "" && p.Milestone == ""
+}
+
+// ParseProject reads a project given as owner/number, e.g. acme/5 for
+// github.com/orgs/acme/projects/5.
+func ParseProject(value string) (owner string, number int, err error) {
+	owner, num, ok := strings.Cut(value, "/")
+	if ok {
+		number, err = strconv.Atoi(num)
+	}
+	if !ok || owner == "" || err != nil || number < 1 {
+		return "", 0, fmt.Errorf("project %q is not owner/number", value)
+	}
+	return owner, number, nil
+}
+
+// PlannedItem is an issue or pull request of a project or milestone that
+// was completed during the window and credited to the user.
+type PlannedItem struct {
+	Plan   string `json:"plan"` // Title of the project or milestone
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+	Pull   bool   `json:"pull"`
+	URL    string `json:"url"`
+}
+
+type plannedNode struct {
+	Typename    string            `json:"__typename"`
+	Number      int               `json:"number"`
+	URL         string            `json:"url"`
+	StateReason string            `json:"stateReason"`
+	ClosedAt    *time.Time        `json:"closedAt"`
+	MergedAt    *time.Time        `json:"mergedAt"`
+	Author      *discussionAuthor `json:"author"`
+	Repository  struct {
+		NameWithOwner string `json:"nameWithOwner"`
+	} `json:"repository"`
+	Assignees struct {
+		Nodes []discussionAuthor `json:"nodes"`
+	} `json:"assignees"`
+	Labels struct {
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	} `json:"labels"`
+	TimelineItems struct {
+		Nodes []struct {
+			Actor *discussionAuthor `json:"actor"`
+		} `json:"nodes"`
+	} `json:"timelineItems"`
+}
+
+// plannedFields selects the issues and pull requests of a project or
+// milestone; draft issues of a project have no fields and are skipped. The
+// last closed event tells who closed an issue without assignees.
+const plannedFields = `
+        ... on Issue {
+          __typename number url stateReason closedAt
+          author { login }
+          repository { nameWithOwner }
+          assignees(first: 10) { nodes { login } }
+          labels(first: 20) { nodes { name } }
+          timelineItems(last: 1, itemTypes: [CLOSED_EVENT]) { nodes { ... on ClosedEvent { actor { login } } } }
+        }
+        ... on PullRequest {
+          __typename number url closedAt mergedAt
+          author { login }
+          repository { nameWithOwner }
+          labels(first: 20) { nodes { name } }
+        }`
+
+const projectItemsQuery = `query($owner: String!, $number: Int!, $cursor: String) {
+  repositoryOwner(login: $owner) {
+    ... on ProjectV2Owner {
+      projectV2(number: $number) {
+        title
+        items(first: 50, after: $cursor) {
+          pageInfo { hasNextPage endCursor }
+          nodes {
+            content {` + plannedFields + `
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+const milestoneItemsQuery = `query($q: String!, $cursor: String) {
+  search(type: ISSUE, query: $q, first: 50, after: $cursor) {
+    pageInfo { hasNextPage endCursor }
+    nodes {` + plannedFields + `
+    }
+  }
+}`
+
+// completed returns when the item was completed: when a pull request was
+// merged, or an issue closed other than as not planned. ok is false for
+// items still open, closed pull requests that were not merged and draft
+// issues.
+func (n plannedNode) completed() (at time.Time, ok bool) {
+	switch {
+	case n.Typename == "PullRequest" && n.MergedAt != nil:
+		return *n.MergedAt, true
+	case n.Typename == "Issue" && n.ClosedAt != nil && n.StateReason != "NOT_PLANNED":
+		return *n.ClosedAt, true
+	}
+	return time.Time{}, false
+}
+
+// credits reports whether the item counts for user: a pull request for its
+// author, an issue for its assignees or, without any, for whoever closed
+// it.
+func (n plannedNode) credits(user string) bool {
+	is := func(author *discussionAuthor) bool {
+		return author != nil && strings.EqualFold(author.Login, user)
+	}
+	if n.Typename == "PullRequest" {
+		return is(n.Author)
+	}
+	for i := range n.Assignees.Nodes {
+		if is(&n.Assignees.Nodes[i]) {
+			return true
+		}
+	}
+	if len(n.Assignees.Nodes) > 0 {
+		return false
+	}
+	for _, event := range n.TimelineItems.Nodes {
+		if is(event.Actor) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n plannedNode) labelNames() []string {
+	names := make([]string, 0, len(n.Labels.Nodes))
+	for _, label := range n.Labels.Nodes {
+		names = append(names, label.Name)
+	}
+	return names
+}
+
+// planned counts the issues and pull requests of the repository in the
+// Plan's project or milestone that were completed during the window and
+// credit the user. An item in both the project and the milestone counts
+// once.
+func (c *GraphQLCollector) planned(ctx context.Context, owner, repo, user string) UserMetrics {
+	var m UserMetrics
+	if c.Plan.IsZero() {
+		return m
+	}
+	counted := make(map[int]bool)
+	add := func(plan string, nodes []plannedNode) {
+		for _, n := range nodes {
+			pull := n.Typename == "PullRequest"
+			if !strings.EqualFold(n.Repository.NameWithOwner, owner+"/"+repo) || !n.credits(user) {
+				continue
+			}
+			if (pull && !c.Labels.MatchPull(n.labelNames())) || (!pull && !c.Labels.MatchIssue(n.labelNames())) {
+				continue
+			}
+			m.PlannedItems = append(m.PlannedItems, PlannedItem{Plan: plan, Repo: owner + "/" + repo, Number: n.Number, Pull: pull, URL: n.URL})
+			if counted[n.Number] {
+				continue
+			}
+			counted[n.Number] = true
+			kind := "planned_issue"
+			if pull {
+				m.PlannedPulls++
+				kind = "planned_pull"
+			} else {
+				m.PlannedIssues++
+			}
+			c.Evidence.Add(user, EvidenceItem{Metric: MetricIssues, Kind: kind, Repo: owner + "/" + repo, ID: strconv.Itoa(n.Number), URL: n.URL})
+			if c.Verbose {
+				log.Printf("Planned item #%d of %s in repo %s/%s completed by %s\n", n.Number, plan, owner, repo, user)
+			}
+		}
+	}
+	if c.Plan.ProjectOwner != "" {
+		title, nodes, err := c.projectItems(ctx)
+		if err != nil {
+			logError(ctx, err, "fetching items of project %s/%d", c.Plan.ProjectOwner, c.Plan.ProjectNumber)
+		}
+		add(title, nodes)
+	}
+	if c.Plan.Milestone != "" {
+		nodes, err := c.milestoneItems(ctx, owner, repo)
+		if err != nil {
+			logError(ctx, err, "fetching items of milestone %s in repo %s/%s", c.Plan.Milestone, owner, repo)
+		}
+		add(c.Plan.Milestone, nodes)
+	}
+	return m
+}
+
+type projectItemList struct {
+	title string
+	nodes []plannedNode
+}
+
+// projectItems lists the items of the Plan's project completed during the
+// window, in every repository, once per run, with the project's title.
+func (c *GraphQLCollector) projectItems(ctx context.Context) (string, []plannedNode, error) {
+	key := fmt.Sprintf("project/%s/%d", c.Plan.ProjectOwner, c.Plan.ProjectNumber)
+	result, err := c.shared.do(key, func() (interface{}, error) {
+		items := projectItemList{title: fmt.Sprintf("%s/%d", c.Plan.ProjectOwner, c.Plan.ProjectNumber)}
+		variables := map[string]interface{}{"owner": c.Plan.ProjectOwner, "number": c.Plan.ProjectNumber, "cursor": nil}
+		for {
+			var data struct {
+				RepositoryOwner *struct {
+					ProjectV2 *struct {
+						Title string `json:"title"`
+						Items struct {
+							PageInfo pageInfo `json:"pageInfo"`
+							Nodes    []struct {
+								Content *plannedNode `json:"content"`
+							} `json:"nodes"`
+						} `json:"items"`
+					} `json:"projectV2"`
+				} `json:"repositoryOwner"`
+			}
+			if err := c.query(ctx, projectItemsQuery, variables, &data); err != nil {
+				return items, err
+			}
+			if data.RepositoryOwner == nil || data.RepositoryOwner.ProjectV2 == nil {
+				return items, fmt.Errorf("project %s not found", items.title)
+			}
+			project := data.RepositoryOwner.ProjectV2
+			if project.Title != "" {
+				items.title = project.Title
+			}
+			for _, item := range project.Items.Nodes {
+				if item.Content == nil {
+					continue
+				}
+				if at, ok := item.Content.completed(); ok && c.inWindow(at) {
+					items.nodes = append(items.nodes, *item.Content)
+				}
+			}
+			if !project.Items.PageInfo.HasNextPage {
+				return items, nil
+			}
+			variables["cursor"] = project.Items.PageInfo.EndCursor
+		}
+	})
+	items := result.(projectItemList)
+	return items.title, items.nodes, err
+}
+
+// milestoneItems searches the issues and pull requests of the repository's
+// milestone titled Plan.Milestone completed during the window, once for
+// every user.
+func (c *GraphQLCollector) milestoneItems(ctx context.Context, owner, repo string) ([]plannedNode, error) {
+	result, err := c.shared.do("milestone/"+owner+"/"+repo, func() (interface{}, error) {
+		var nodes []plannedNode
+		query := fmt.Sprintf("repo:%s/%s milestone:%s is:closed closed:%s", owner, repo, strconv.Quote(c.Plan.Milestone), c.dateRange(">="))
+		variables := map[string]interface{}{"q": query, "cursor": nil}
+		for {
+			var data struct {
+				Search struct {
+					PageInfo pageInfo      `json:"pageInfo"`
+					Nodes    []plannedNode `json:"nodes"`
+				} `json:"search"`
+			}
+			if err := c.query(ctx, milestoneItemsQuery, variables, &data); err != nil {
+				return nodes, err
+			}
+			for _, n := range data.Search.Nodes {
+				if at, ok := n.completed(); ok && c.inWindow(at) {
+					nodes = append(nodes, n)
+				}
+			}
+			if !data.Search.PageInfo.HasNextPage {
+				return nodes, nil
+			}
+			variables["cursor"] = data.Search.PageInfo.EndCursor
+		}
+	})
+	return result.([]plannedNode), err
+}
+
+// PlanProgress is a row of the planned work report: the items of a project
+// or milestone the users completed during the window.
+type PlanProgress struct {
+	Plan   string
+	Issues int
+	Pulls  int
+	Users  []string // Users credited with any of the items, sorted
+}
+
+// PlannedWork builds the progress of every plan from the users' completed
+// items, most completed first. An item credited to several users is counted
+// once.
+func PlannedWork(views []UserMetricsView) []PlanProgress {
+	var rows []PlanProgress
+	byPlan := make(map[string]int)
+	counted := make(map[string]bool)
+	for _, view := range views {
+		for _, item := range view.Metrics.PlannedItems {
+			i, ok := byPlan[item.Plan]
+			if !ok {
+				i = len(rows)
+				byPlan[item.Plan] = i
+				rows = append(rows, PlanProgress{Plan: item.Plan})
+			}
+			row := &rows[i]
+			if n := len(row.Users); n == 0 || row.Users[n-1] != view.User {
+				row.Users = append(row.Users, view.User)
+			}
+			if id := fmt.Sprintf("%s %s#%d", item.Plan, item.Repo, item.Number); !counted[id] {
+				counted[id] = true
+				if item.Pull {
+					row.Pulls++
+				} else {
+					row.Issues++
+				}
+			}
+		}
+	}
+	for i := range rows {
+		sort.Strings(rows[i].Users)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if ni, nj := rows[i].Issues+rows[i].Pulls, rows[j].Issues+rows[j].Pulls; ni != nj {
+			return ni > nj
+		}
+		return rows[i].Plan < rows[j].Plan
+	})
+	return rows
+}
@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Progress reports how far a Calculate run has got: users and repositories
+// processed, API calls made and the estimated time remaining. Interactive
+// progress redraws a single line; otherwise a line is written each time a
+// user finishes, which suits CI logs.
+type Progress struct {
+	Out         io.Writer
+	Interactive bool
+	Calls       func() int64 // Optional count of API calls made so far
+
+	mu         sync.Mutex
+	start      time.Time
+	lastDraw   time.Time
+	users      int
+	discovered int // Users whose repositories are known
+	usersDone  int
+	repos      int
+	reposDone  int
+	tasks      int
+	tasksDone  int
+	pending    map[string]int // Unfinished tasks per user/repo
+	width      int            // Length of the last interactive line
+}
+
+// NewProgress returns a progress reporter writing to out. A nil *Progress
+// reports nothing.
+func NewProgress(out io.Writer, interactive bool, calls func() int64) *Progress {
+	return &Progress{Out: out, Interactive: interactive, Calls: calls}
+}
+
+func (p *Progress) begin(users int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.start = time.Now()
+	p.users = users
+	p.pending = make(map[string]int)
+}
+
+// addUser records the repositories found for a user, each collected with
+// metrics tasks.
+func (p *Progress) addUser(user string, repos []string, metrics int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.discovered++
+	p.repos += len(repos)
+	p.tasks += len(repos) * metrics
+	for _, repo := range repos {
+		p.pending[user+"/"+repo] += metrics
+	}
+	p.draw(false)
+}
+
+func (p *Progress) taskDone(user, repo string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tasksDone++
+	key := user + "/" + repo
+	p.pending[key]--
+	if p.pending[key] == 0 {
+		delete(p.pending, key)
+		p.reposDone++
+	}
+	p.draw(false)
+}
+
+func (p *Progress) userDone(user string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.usersDone++
+	if p.Interactive {
+		p.draw(false)
+		return
+	}
+	fmt.Fprintf(p.Out, "Finished %s: %s\n", user, p.status())
+}
+
+func (p *Progress) finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Interactive {
+		p.draw(true)
+		fmt.Fprintln(p.Out)
+	}
+}
+
+// draw redraws the interactive line, at most a few times a second unless
+// force is set. It must be called with mu held.
+func (p *Progress) draw(force bool) {
+	if !p.Interactive {
+		return
+	}
+	now := time.Now()
+	if !force && now.Sub(p.lastDraw) < 200*time.Millisecond {
+		return
+	}
+	p.lastDraw = now
+	line := p.status()
+	pad := ""
+	if len(line) < p.width {
+		pad = strings.Repeat(" ", p.width-len(line))
+	}
+	p.width = len(line)
+	fmt.Fprintf(p.Out, "\r%s%s", line, pad)
+}
+
+// status formats the counters. It must be called with mu held.
+func (p *Progress) status() string {
+	s := fmt.Sprintf("users %d/%d, repos %d/%d", p.usersDone, p.users, p.reposDone, p.repos)
+	if p.Calls != nil {
+		s += fmt.Sprintf(", %d API calls", p.Calls())
+	}
+	if eta, ok := p.eta(); ok {
+		s += fmt.Sprintf(", ETA %s", eta.Round(time.Second))
+	}
+	return s
+}
+
+// eta estimates the time remaining from the pace so far. Users whose
+// repositories are not known yet are assumed to have as many tasks as the
+// average user so far. It must be called with mu held.
+func (p *Progress) eta() (time.Duration, bool) {
+	if p.tasksDone == 0 || p.discovered == 0 {
+		return 0, false
+	}
+	total := float64(p.tasks) * float64(p.users) / float64(p.discovered)
+	perTask := float64(time.Since(p.start)) / float64(p.tasksDone)
+	remaining := total - float64(p.tasksDone)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return time.Duration(perTask * remaining), true
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// pullFlow measures how the review of each of the user's pull requests
+// merged in the window went: the hours until someone else first reviewed
+// it, the review rounds, as the distinct commits reviewed by others, and the
+// hours from the last approval to the merge. Pull requests nobody else
+// reviewed are left out.
+func (c *GitHubCollector) pullFlow(ctx context.Context, owner, repo, user string) UserMetrics {
+	var m UserMetrics
+	pulls, err := c.authoredPulls(ctx, owner, repo, user)
+	if err != nil {
+		logError(ctx, err, "fetching pull requests for user %s in repo %s/%s", user, owner, repo)
+	}
+	for _, pull := range pulls {
+		reviews, err := c.pullReviews(ctx, owner, repo, pull.number)
+		if err != nil {
+			logError(ctx, err, "fetching reviews of pull request #%d in repo %s/%s", pull.number, owner, repo)
+			continue
+		}
+		var first, approved time.Time
+		rounds := make(map[string]bool)
+		for _, review := range reviews {
+			submitted := review.GetSubmittedAt().Time
+			if submitted.IsZero() || review.GetState() == "PENDING" || strings.EqualFold(review.GetUser().GetLogin(), user) {
+				continue
+			}
+			if first.IsZero() || submitted.Before(first) {
+				first = submitted
+			}
+			rounds[review.GetCommitID()] = true
+			if review.GetState() == "APPROVED" && !submitted.After(pull.merged) && submitted.After(approved) {
+				approved = submitted
+			}
+		}
+		if first.IsZero() {
+			continue
+		}
+		if !pull.created.IsZero() {
+			m.FirstReviewWaits = append(m.FirstReviewWaits, first.Sub(pull.created).Hours())
+		}
+		m.ReviewRounds = append(m.ReviewRounds, len(rounds))
+		if !approved.IsZero() {
+			m.MergeWaits = append(m.MergeWaits, pull.merged.Sub(approved).Hours())
+		}
+	}
+	return m
+}
+
+// MedianLcP returns the median hours from opening to closing the user's
+// pull requests, or 0 without any.
+func (m UserMetrics) MedianLcP() float64 {
+	return median(m.PullLifecycles)
+}
+
+// FirstReviewWait returns the average hours the user's pull requests waited
+// for their first review, or 0 without any.
+func (m UserMetrics) FirstReviewWait() float64 {
+	return mean(m.FirstReviewWaits)
+}
+
+// MedianFirstReviewWait returns the median hours the user's pull requests
+// waited for their first review, or 0 without any.
+func (m UserMetrics) MedianFirstReviewWait() float64 {
+	return median(m.FirstReviewWaits)
+}
+
+// AverageReviewRounds returns the average review rounds of the user's
+// reviewed pull requests, or 0 without any.
+func (m UserMetrics) AverageReviewRounds() float64 {
+	return mean(roundValues(m.ReviewRounds))
+}
+
+// MedianReviewRounds returns the median review rounds of the user's
+// reviewed pull requests, or 0 without any.
+func (m UserMetrics) MedianReviewRounds() float64 {
+	return median(roundValues(m.ReviewRounds))
+}
+
+// MergeWait returns the average hours from the last approval to the merge
+// of the user's approved pull requests, or 0 without any.
+func (m UserMetrics) MergeWait() float64 {
+	return mean(m.MergeWaits)
+}
+
+// MedianMergeWait returns the median hours from the last approval to the
+// merge of the user's approved pull requests, or 0 without any.
+func (m UserMetrics) MedianMergeWait() float64 {
+	return median(m.MergeWaits)
+}
+
+// roundValues converts review rounds to numbers for median and mean.
+func roundValues(rounds []int) []float64 {
+	values := make([]float64, len(rounds))
+	for i, r := range rounds {
+		values[i] = float64(r)
+	}
+	return values
+}
+
+// mean returns the average of values, or 0 when there are none.
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total / float64(len(values))
+}
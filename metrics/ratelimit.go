@@ -0,0 +1,249 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// API resources with separate rate limits.
+const (
+	resourceCore    = "core"
+	resourceSearch  = "search"
+	resourceGraphQL = "graphql"
+)
+
+// minInterval is the least time between two requests to a resource. It keeps
+// bursts from all workers under GitHub's secondary rate limits, which are not
+// reported in advance: about 900 REST requests a minute and 30 searches.
+var minInterval = map[string]time.Duration{
+	resourceCore:    70 * time.Millisecond,
+	resourceSearch:  2 * time.Second,
+	resourceGraphQL: 70 * time.Millisecond,
+}
+
+// reserveFraction is the share of a quota below which the remaining requests
+// are spread evenly until the reset instead of being spent as fast as the
+// minimum interval allows.
+const reserveFraction = 5
+
+// rateBudget is shared by every request of a collector and paces them per
+// resource, so concurrent workers neither run the quota dry nor trip the
+// secondary limits, and once a quota is exhausted all of them wait for the
+// same reset instead of each discovering it with a failed request.
+type rateBudget struct {
+	mu        sync.Mutex
+	resources map[string]*rateResource
+}
+
+// rateResource is the last known quota of a resource.
+type rateResource struct {
+	limit     int // 0 until known, e.g. on GitHub Enterprise Server without rate limiting
+	remaining int
+	reset     time.Time
+	next      time.Time // Earliest start of the next request
+}
+
+func (b *rateBudget) resource(name string) *rateResource {
+	if b.resources == nil {
+		b.resources = make(map[string]*rateResource)
+	}
+	r, ok := b.resources[name]
+	if !ok {
+		r = &rateResource{}
+		b.resources[name] = r
+	}
+	return r
+}
+
+// wait reserves the next request slot of the resource and blocks until it
+// starts or ctx is done. It returns how long it waited.
+func (b *rateBudget) wait(ctx context.Context, name string) (time.Duration, error) {
+	b.mu.Lock()
+	r := b.resource(name)
+	now := time.Now()
+	start := now
+	if r.next.After(start) {
+		start = r.next
+	}
+	if r.limit > 0 && r.remaining <= 0 && r.reset.After(start) {
+		start = r.reset
+	}
+	interval := minInterval[name]
+	if r.limit > 0 && r.remaining > 0 && r.remaining < r.limit/reserveFraction {
+		if spread := r.reset.Sub(start) / time.Duration(r.remaining); spread > interval {
+			interval = spread
+		}
+	}
+	r.next = start.Add(interval)
+	if r.remaining > 0 {
+		// Count the request now so concurrent workers see it before the
+		// response arrives.
+		r.remaining--
+	}
+	b.mu.Unlock()
+
+	d := start.Sub(now)
+	if d <= 0 {
+		return 0, nil
+	}
+	return d, sleep(ctx, d)
+}
+
+// update records the quota reported with a response.
+func (b *rateBudget) update(name string, rate github.Rate) {
+	if rate.Limit == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	r := b.resource(name)
+	// Responses of concurrent requests arrive out of order; within one
+	// window the lowest remaining count is the latest.
+	if rate.Reset.Time.Equal(r.reset) && rate.Remaining > r.remaining {
+		return
+	}
+	r.limit = rate.Limit
+	r.remaining = rate.Remaining
+	r.reset = rate.Reset.Time
+}
+
+// exhaust marks the resource as spent until the given time.
+func (b *rateBudget) exhaust(name string, until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	r := b.resource(name)
+	r.remaining = 0
+	if r.limit == 0 {
+		r.limit = 1
+	}
+	if until.After(r.reset) {
+		r.reset = until
+	}
+}
+
+// remaining returns the last known quota of the resource.
+func (b *rateBudget) remaining(name string) (remaining, limit int, reset time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	r := b.resource(name)
+	return r.remaining, r.limit, r.reset
+}
+
+// checkRateLimits seeds the budget from /rate_limit, which does not count
+// against the quota, so pacing starts with the first request instead of
+// after the first responses.
+func (c *GitHubCollector) checkRateLimits(ctx context.Context) {
+	limits, _, err := c.api().RateLimits(ctx)
+	if err != nil {
+		// GitHub Enterprise Server answers 404 when rate limiting is
+		// disabled; pacing then relies on the minimum intervals alone.
+		if c.Verbose {
+			log.Printf("Could not check rate limits: %v\n", err)
+		}
+		return
+	}
+	for name, rate := range map[string]*github.Rate{
+		resourceCore:    limits.GetCore(),
+		resourceSearch:  limits.GetSearch(),
+		resourceGraphQL: limits.GetGraphQL(),
+	} {
+		if rate == nil {
+			continue
+		}
+		c.budget.update(name, *rate)
+		if c.Verbose {
+			log.Printf("Rate limit %s: %d of %d remaining, resets at %s\n", name, rate.Remaining, rate.Limit, rate.Reset.Local().Format(time.Kitchen))
+		}
+	}
+}
+
+// secondaryLimit reports whether err is a secondary rate limit and how long
+// GitHub asked to wait before retrying, zero when it did not say. Besides
+// the errors go-github recognizes, 403 and 429 responses with a Retry-After
+// header or a secondary rate limit message count, since GitHub has changed
+// the documentation URL go-github matches on.
+func secondaryLimit(err error) (time.Duration, bool) {
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		if abuseErr.RetryAfter != nil {
+			return *abuseErr.RetryAfter, true
+		}
+		return 0, true
+	}
+
+	var respErr *github.ErrorResponse
+	if !errors.As(err, &respErr) || respErr.Response == nil {
+		return 0, false
+	}
+	status := respErr.Response.StatusCode
+	if status != http.StatusForbidden && status != http.StatusTooManyRequests {
+		return 0, false
+	}
+	retryAfter := retryAfter(respErr.Response)
+	if retryAfter > 0 || status == http.StatusTooManyRequests || strings.Contains(strings.ToLower(respErr.Message), "secondary rate limit") {
+		return retryAfter, true
+	}
+	return 0, false
+}
+
+// retryAfter parses the Retry-After header given in seconds.
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// isClientError reports whether err is a 4xx response other than a rate
+// limit, which retrying cannot fix.
+func isClientError(err error) bool {
+	var respErr *github.ErrorResponse
+	if !errors.As(err, &respErr) || respErr.Response == nil {
+		return false
+	}
+	status := respErr.Response.StatusCode
+	return status >= 400 && status < 500 && status != http.StatusTooManyRequests
+}
+
+// maxBackoff bounds the delay backoff doubles to, before jitter, as an
+// hour is as long as any GitHub rate limit takes to reset.
+const maxBackoff = time.Hour
+
+// backoff returns the delay before retry attempt n, counted from 0: base
+// doubled for each attempt up to maxBackoff, jittered by up to half either
+// way so workers that failed together do not retry together.
+func backoff(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	d := base
+	for i := 0; i < attempt && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d)))
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
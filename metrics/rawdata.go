@@ -0,0 +1,428 @@
+package metrics
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// RawDataVersion is the version of the RawData format. It is raised when a
+// field is removed or changes meaning; fields may be added at any time.
+const RawDataVersion = "1"
+
+// RawData is every commit, pull request, issue and review fetched by a
+// collection, normalized for analysis outside github-metrics.
+type RawData struct {
+	Version      string           `json:"version"`
+	Since        time.Time        `json:"since"`
+	Until        time.Time        `json:"until"` // Zero for windows ending at ExportedAt
+	ExportedAt   time.Time        `json:"exportedAt"`
+	Commits      []RawCommit      `json:"commits"`
+	PullRequests []RawPullRequest `json:"pullRequests"`
+	Issues       []RawIssue       `json:"issues"`
+	Reviews      []RawReview      `json:"reviews"`
+}
+
+// RawStats is the size of a commit or pull request.
+type RawStats struct {
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
+	Files     int `json:"files"`
+}
+
+// RawCommit is a commit.
+type RawCommit struct {
+	Repo        string    `json:"repo"` // Repository as owner/name
+	SHA         string    `json:"sha"`
+	Author      string    `json:"author,omitempty"` // Login; empty when the email is not linked to an account
+	AuthorName  string    `json:"authorName"`
+	AuthorEmail string    `json:"authorEmail"`
+	AuthoredAt  time.Time `json:"authoredAt"`
+	Committer   string    `json:"committer,omitempty"`
+	CommittedAt time.Time `json:"committedAt"`
+	Message     string    `json:"message"`
+	Parents     int       `json:"parents"`
+	Stats       *RawStats `json:"stats,omitempty"` // Only when the commit's details were fetched, e.g. for HoC
+	URL         string    `json:"url"`
+}
+
+// RawPullRequest is a pull request.
+type RawPullRequest struct {
+	Repo      string     `json:"repo"`
+	Number    int        `json:"number"`
+	Author    string     `json:"author"`
+	Title     string     `json:"title"`
+	State     string     `json:"state"` // open or closed
+	Draft     bool       `json:"draft"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	ClosedAt  *time.Time `json:"closedAt,omitempty"`
+	MergedAt  *time.Time `json:"mergedAt,omitempty"` // Unknown for pull requests only seen in searches and issue listings
+	Stats     *RawStats  `json:"stats,omitempty"`    // Only when the pull request itself was fetched
+	URL       string     `json:"url"`
+
+	detailed bool // Fetched from the pull request API rather than as an issue
+}
+
+// RawIssue is an issue.
+type RawIssue struct {
+	Repo      string     `json:"repo"`
+	Number    int        `json:"number"`
+	Author    string     `json:"author"`
+	Title     string     `json:"title"`
+	State     string     `json:"state"`
+	Labels    []string   `json:"labels"`
+	Assignees []string   `json:"assignees"`
+	Comments  int        `json:"comments"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	ClosedAt  *time.Time `json:"closedAt,omitempty"`
+	URL       string     `json:"url"`
+}
+
+// RawReview is a pull request review.
+type RawReview struct {
+	Repo        string     `json:"repo"`
+	Pull        int        `json:"pull"` // Number of the reviewed pull request
+	ID          int64      `json:"id"`
+	Reviewer    string     `json:"reviewer"`
+	State       string     `json:"state"` // APPROVED, CHANGES_REQUESTED, COMMENTED or DISMISSED
+	SubmittedAt *time.Time `json:"submittedAt,omitempty"`
+	URL         string     `json:"url"`
+}
+
+// Save writes the raw data to path as JSON, compressed with gzip when path
+// ends in .gz.
+func (d *RawData) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	var w io.Writer = f
+	var zw *gzip.Writer
+	if strings.HasSuffix(path, ".gz") {
+		zw = gzip.NewWriter(f)
+		w = zw
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(d)
+	if zw != nil {
+		if closeErr := zw.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// RawRecorder collects the raw data of the responses passing through the
+// GitHubAPI and CommitCache it wraps. It is safe for concurrent use.
+type RawRecorder struct {
+	mu      sync.Mutex
+	commits map[string]RawCommit // Keyed by repo@sha
+	stats   map[string]RawStats  // Commit sizes by SHA
+	pulls   map[string]RawPullRequest
+	issues  map[string]RawIssue
+	reviews map[int64]RawReview
+}
+
+// NewRawRecorder returns an empty recorder.
+func NewRawRecorder() *RawRecorder {
+	return &RawRecorder{
+		commits: make(map[string]RawCommit),
+		stats:   make(map[string]RawStats),
+		pulls:   make(map[string]RawPullRequest),
+		issues:  make(map[string]RawIssue),
+		reviews: make(map[int64]RawReview),
+	}
+}
+
+// API returns api recording what it returns.
+func (r *RawRecorder) API(api GitHubAPI) GitHubAPI {
+	return rawAPI{GitHubAPI: api, recorder: r}
+}
+
+// Cache returns cache recording the commit details found in it, which
+// spare the collector fetching them.
+func (r *RawRecorder) Cache(cache CommitCache) CommitCache {
+	return rawCache{CommitCache: cache, recorder: r}
+}
+
+// Data returns the raw data recorded so far, sorted by repository and then
+// by date, number or ID.
+func (r *RawRecorder) Data() *RawData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d := &RawData{
+		Version:      RawDataVersion,
+		ExportedAt:   time.Now(),
+		Commits:      []RawCommit{},
+		PullRequests: []RawPullRequest{},
+		Issues:       []RawIssue{},
+		Reviews:      []RawReview{},
+	}
+	for _, commit := range r.commits {
+		if stats, ok := r.stats[commit.SHA]; ok && commit.Stats == nil {
+			commit.Stats = &stats
+		}
+		d.Commits = append(d.Commits, commit)
+	}
+	sort.Slice(d.Commits, func(i, j int) bool {
+		a, b := d.Commits[i], d.Commits[j]
+		if a.Repo != b.Repo {
+			return a.Repo < b.Repo
+		}
+		if !a.AuthoredAt.Equal(b.AuthoredAt) {
+			return a.AuthoredAt.Before(b.AuthoredAt)
+		}
+		return a.SHA < b.SHA
+	})
+	for _, pull := range r.pulls {
+		d.PullRequests = append(d.PullRequests, pull)
+	}
+	sort.Slice(d.PullRequests, func(i, j int) bool {
+		a, b := d.PullRequests[i], d.PullRequests[j]
+		return a.Repo < b.Repo || a.Repo == b.Repo && a.Number < b.Number
+	})
+	for _, issue := range r.issues {
+		d.Issues = append(d.Issues, issue)
+	}
+	sort.Slice(d.Issues, func(i, j int) bool {
+		a, b := d.Issues[i], d.Issues[j]
+		return a.Repo < b.Repo || a.Repo == b.Repo && a.Number < b.Number
+	})
+	for _, review := range r.reviews {
+		d.Reviews = append(d.Reviews, review)
+	}
+	sort.Slice(d.Reviews, func(i, j int) bool {
+		a, b := d.Reviews[i], d.Reviews[j]
+		if a.Repo != b.Repo {
+			return a.Repo < b.Repo
+		}
+		if a.Pull != b.Pull {
+			return a.Pull < b.Pull
+		}
+		return a.ID < b.ID
+	})
+	return d
+}
+
+func rawTime(ts *github.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.Time
+	return &t
+}
+
+func (r *RawRecorder) addCommit(repo string, commit *github.RepositoryCommit) {
+	raw := RawCommit{
+		Repo:        repo,
+		SHA:         commit.GetSHA(),
+		Author:      commit.GetAuthor().GetLogin(),
+		AuthorName:  commit.GetCommit().GetAuthor().GetName(),
+		AuthorEmail: commit.GetCommit().GetAuthor().GetEmail(),
+		AuthoredAt:  commit.GetCommit().GetAuthor().GetDate().Time,
+		Committer:   commit.GetCommitter().GetLogin(),
+		CommittedAt: commit.GetCommit().GetCommitter().GetDate().Time,
+		Message:     commit.GetCommit().GetMessage(),
+		Parents:     len(commit.Parents),
+		URL:         commit.GetHTMLURL(),
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if commit.Files != nil {
+		r.stats[raw.SHA] = RawStats{Additions: commit.GetStats().GetAdditions(), Deletions: commit.GetStats().GetDeletions(), Files: len(commit.Files)}
+	}
+	r.commits[repo+"@"+raw.SHA] = raw
+}
+
+// addIssue records an issue, or a pull request listed as an issue unless
+// the pull request itself was recorded.
+func (r *RawRecorder) addIssue(repo string, issue *github.Issue) {
+	if repo == "" {
+		repo = parseRepoURL(issue.GetRepositoryURL())
+	}
+	key := repo + "#" + strconv.Itoa(issue.GetNumber())
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if issue.IsPullRequest() {
+		if r.pulls[key].detailed {
+			return
+		}
+		r.pulls[key] = RawPullRequest{
+			Repo:      repo,
+			Number:    issue.GetNumber(),
+			Author:    issue.GetUser().GetLogin(),
+			Title:     issue.GetTitle(),
+			State:     issue.GetState(),
+			CreatedAt: issue.GetCreatedAt().Time,
+			UpdatedAt: issue.GetUpdatedAt().Time,
+			ClosedAt:  rawTime(issue.ClosedAt),
+			URL:       issue.GetHTMLURL(),
+		}
+		return
+	}
+	raw := RawIssue{
+		Repo:      repo,
+		Number:    issue.GetNumber(),
+		Author:    issue.GetUser().GetLogin(),
+		Title:     issue.GetTitle(),
+		State:     issue.GetState(),
+		Labels:    []string{},
+		Assignees: []string{},
+		Comments:  issue.GetComments(),
+		CreatedAt: issue.GetCreatedAt().Time,
+		UpdatedAt: issue.GetUpdatedAt().Time,
+		ClosedAt:  rawTime(issue.ClosedAt),
+		URL:       issue.GetHTMLURL(),
+	}
+	for _, label := range issue.Labels {
+		raw.Labels = append(raw.Labels, label.GetName())
+	}
+	for _, assignee := range issue.Assignees {
+		raw.Assignees = append(raw.Assignees, assignee.GetLogin())
+	}
+	r.issues[key] = raw
+}
+
+// addPull records a pull request. Listings lack the size, which is kept
+// from an earlier fetch of the pull request.
+func (r *RawRecorder) addPull(repo string, pull *github.PullRequest) {
+	key := repo + "#" + strconv.Itoa(pull.GetNumber())
+	raw := RawPullRequest{
+		Repo:      repo,
+		Number:    pull.GetNumber(),
+		Author:    pull.GetUser().GetLogin(),
+		Title:     pull.GetTitle(),
+		State:     pull.GetState(),
+		Draft:     pull.GetDraft(),
+		CreatedAt: pull.GetCreatedAt().Time,
+		UpdatedAt: pull.GetUpdatedAt().Time,
+		ClosedAt:  rawTime(pull.ClosedAt),
+		MergedAt:  rawTime(pull.MergedAt),
+		URL:       pull.GetHTMLURL(),
+		detailed:  true,
+	}
+	if pull.ChangedFiles != nil {
+		raw.Stats = &RawStats{Additions: pull.GetAdditions(), Deletions: pull.GetDeletions(), Files: pull.GetChangedFiles()}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if raw.Stats == nil {
+		raw.Stats = r.pulls[key].Stats
+	}
+	r.pulls[key] = raw
+}
+
+func (r *RawRecorder) addReview(repo string, number int, review *github.PullRequestReview) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reviews[review.GetID()] = RawReview{
+		Repo:        repo,
+		Pull:        number,
+		ID:          review.GetID(),
+		Reviewer:    review.GetUser().GetLogin(),
+		State:       review.GetState(),
+		SubmittedAt: rawTime(review.SubmittedAt),
+		URL:         review.GetHTMLURL(),
+	}
+}
+
+// rawAPI is a GitHubAPI recording the commits, pull requests, issues and
+// reviews it returns.
+type rawAPI struct {
+	GitHubAPI
+	recorder *RawRecorder
+}
+
+func (a rawAPI) ListCommits(ctx context.Context, owner, repo string, opts *github.CommitsListOptions) ([]*github.RepositoryCommit, *github.Response, error) {
+	commits, resp, err := a.GitHubAPI.ListCommits(ctx, owner, repo, opts)
+	for _, commit := range commits {
+		a.recorder.addCommit(owner+"/"+repo, commit)
+	}
+	return commits, resp, err
+}
+
+func (a rawAPI) GetCommit(ctx context.Context, owner, repo, sha string) (*github.RepositoryCommit, *github.Response, error) {
+	commit, resp, err := a.GitHubAPI.GetCommit(ctx, owner, repo, sha)
+	if commit != nil {
+		a.recorder.addCommit(owner+"/"+repo, commit)
+	}
+	return commit, resp, err
+}
+
+func (a rawAPI) ListIssues(ctx context.Context, owner, repo string, opts *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error) {
+	issues, resp, err := a.GitHubAPI.ListIssues(ctx, owner, repo, opts)
+	for _, issue := range issues {
+		a.recorder.addIssue(owner+"/"+repo, issue)
+	}
+	return issues, resp, err
+}
+
+func (a rawAPI) SearchIssues(ctx context.Context, query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+	result, resp, err := a.GitHubAPI.SearchIssues(ctx, query, opts)
+	if result != nil {
+		for _, issue := range result.Issues {
+			a.recorder.addIssue("", issue)
+		}
+	}
+	return result, resp, err
+}
+
+func (a rawAPI) ListPullRequests(ctx context.Context, owner, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error) {
+	pulls, resp, err := a.GitHubAPI.ListPullRequests(ctx, owner, repo, opts)
+	for _, pull := range pulls {
+		a.recorder.addPull(owner+"/"+repo, pull)
+	}
+	return pulls, resp, err
+}
+
+func (a rawAPI) GetPullRequest(ctx context.Context, owner, repo string, number int) (*github.PullRequest, *github.Response, error) {
+	pull, resp, err := a.GitHubAPI.GetPullRequest(ctx, owner, repo, number)
+	if pull != nil {
+		a.recorder.addPull(owner+"/"+repo, pull)
+	}
+	return pull, resp, err
+}
+
+func (a rawAPI) ListReviews(ctx context.Context, owner, repo string, number int, opts *github.ListOptions) ([]*github.PullRequestReview, *github.Response, error) {
+	reviews, resp, err := a.GitHubAPI.ListReviews(ctx, owner, repo, number, opts)
+	for _, review := range reviews {
+		a.recorder.addReview(owner+"/"+repo, number, review)
+	}
+	return reviews, resp, err
+}
+
+// rawCache is a CommitCache recording the sizes of the commits found in it.
+type rawCache struct {
+	CommitCache
+	recorder *RawRecorder
+}
+
+func (c rawCache) Get(sha string) (*CommitDetails, bool) {
+	details, ok := c.CommitCache.Get(sha)
+	if ok {
+		stats := RawStats{Files: len(details.Files)}
+		for _, file := range details.Files {
+			stats.Additions += file.Additions
+			stats.Deletions += file.Deletions
+		}
+		c.recorder.mu.Lock()
+		c.recorder.stats[sha] = stats
+		c.recorder.mu.Unlock()
+	}
+	return details, ok
+}
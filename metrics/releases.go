@@ -0,0 +1,231 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// shippedRelease is a release published during the window with the commits
+// it shipped, those since the release published before it.
+type shippedRelease struct {
+	release *github.RepositoryRelease
+	commits []*github.RepositoryCommit
+}
+
+// releases counts the releases the user published and the annotated tags
+// the user created during the window, and the releases published during
+// the window that shipped the user's commits.
+func (c *GitHubCollector) releases(ctx context.Context, owner, repo, user string) UserMetrics {
+	var m UserMetrics
+	releases, err := c.repoReleases(ctx, owner, repo)
+	if err != nil {
+		logError(ctx, err, "fetching releases in repo %s/%s", owner, repo)
+	}
+	for _, shipped := range releases {
+		release := shipped.release
+		if c.Identities.IsCommitAuthor(user, release.GetAuthor().GetLogin(), "") {
+			m.ReleasesPublished++
+			c.Evidence.Add(user, EvidenceItem{Metric: MetricReleases, Kind: "release", Repo: owner + "/" + repo, ID: release.GetTagName(), URL: release.GetHTMLURL()})
+		}
+		for _, commit := range shipped.commits {
+			if !isMergeCommit(commit) && c.Identities.IsCommitAuthor(user, commit.GetAuthor().GetLogin(), commit.GetCommit().GetAuthor().GetEmail()) {
+				m.ShippedIn++
+				c.Evidence.Add(user, EvidenceItem{Metric: MetricReleases, Kind: "shipped", Repo: owner + "/" + repo, ID: release.GetTagName(), URL: release.GetHTMLURL()})
+				break
+			}
+		}
+	}
+	// Who created a tag is only exposed through the GraphQL API.
+	m.TagsCreated = NewGraphQLCollector(c).tagsCreated(ctx, owner, repo, user)
+	if c.Verbose && (m.ReleasesPublished > 0 || m.ShippedIn > 0 || m.TagsCreated > 0) {
+		log.Printf("User %s in repo %s/%s: %d releases published, %d tags created, shipped in %d releases\n", user, owner, repo, m.ReleasesPublished, m.TagsCreated, m.ShippedIn)
+	}
+	return m
+}
+
+// repoReleases lists the releases published during the window, oldest
+// first, with the commits each shipped, once for every user. The first
+// release of a repository has no release to compare with and ships no
+// commits. The releases found before an error are returned with it.
+func (c *GitHubCollector) repoReleases(ctx context.Context, owner, repo string) ([]shippedRelease, error) {
+	result, err := c.shared.do("releases/"+owner+"/"+repo, func() (interface{}, error) {
+		var published []*github.RepositoryRelease
+		opts := &github.ListOptions{PerPage: 100}
+		for {
+			result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/releases", func() (interface{}, *github.Response, error) {
+				return c.api().ListReleases(ctx, owner, repo, opts)
+			})
+			if err != nil {
+				return []shippedRelease(nil), err
+			}
+			// Releases come newest first, so the pages can stop at the
+			// first release published before the window, which the
+			// oldest release of the window is compared with.
+			before := false
+			for _, release := range result.([]*github.RepositoryRelease) {
+				if release.GetDraft() || release.PublishedAt == nil {
+					continue
+				}
+				published = append(published, release)
+				before = before || release.GetPublishedAt().Before(c.Since)
+			}
+			if before || resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+		sort.SliceStable(published, func(i, j int) bool {
+			return published[i].GetPublishedAt().Before(published[j].GetPublishedAt().Time)
+		})
+
+		var releases []shippedRelease
+		for i, release := range published {
+			if !c.inWindow(release.GetPublishedAt().Time) {
+				continue
+			}
+			shipped := shippedRelease{release: release}
+			if i > 0 {
+				commits, err := c.compareCommits(ctx, owner, repo, published[i-1].GetTagName(), release.GetTagName())
+				shipped.commits = commits
+				if err != nil {
+					return append(releases, shipped), err
+				}
+			}
+			releases = append(releases, shipped)
+		}
+		return releases, nil
+	})
+	return result.([]shippedRelease), err
+}
+
+// compareCommits lists the commits reachable from head but not from base.
+func (c *GitHubCollector) compareCommits(ctx context.Context, owner, repo, base, head string) ([]*github.RepositoryCommit, error) {
+	var commits []*github.RepositoryCommit
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/compare/{basehead}", func() (interface{}, *github.Response, error) {
+			return c.api().CompareCommits(ctx, owner, repo, base, head, opts)
+		})
+		if err != nil {
+			return commits, err
+		}
+		commits = append(commits, result.(*github.CommitsComparison).Commits...)
+		if resp.NextPage == 0 {
+			return commits, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+type tagNode struct {
+	Name   string `json:"name"`
+	Target struct {
+		Tagger *struct {
+			Date  time.Time         `json:"date"`
+			Email string            `json:"email"`
+			User  *discussionAuthor `json:"user"`
+		} `json:"tagger"`
+		CommittedDate *time.Time `json:"committedDate"`
+		Target        *struct {
+			CommittedDate *time.Time `json:"committedDate"`
+		} `json:"target"`
+	} `json:"target"`
+}
+
+// Tags are listed by the date of the commit they point at, newest first,
+// so paging can stop at the start of the window. Tags created during the
+// window on older commits are not found.
+const tagsQuery = `query($owner: String!, $name: String!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    url
+    refs(refPrefix: "refs/tags/", first: 100, after: $cursor, orderBy: {field: TAG_COMMIT_DATE, direction: DESC}) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        name
+        target {
+          ... on Tag {
+            tagger { date email user { login } }
+            target { ... on Commit { committedDate } }
+          }
+          ... on Commit { committedDate }
+        }
+      }
+    }
+  }
+}`
+
+// tagsCreated counts the annotated tags the user created during the
+// window. Lightweight tags do not record who created them.
+func (c *GraphQLCollector) tagsCreated(ctx context.Context, owner, repo, user string) int {
+	url, tags, err := c.repoTags(ctx, owner, repo)
+	if err != nil {
+		logError(ctx, err, "fetching tags in repo %s/%s", owner, repo)
+	}
+	created := 0
+	for _, tag := range tags {
+		tagger := tag.Target.Tagger
+		login := ""
+		if tagger.User != nil {
+			login = tagger.User.Login
+		}
+		if c.Identities.IsCommitAuthor(user, login, tagger.Email) {
+			created++
+			c.Evidence.Add(user, EvidenceItem{Metric: MetricReleases, Kind: "tag", Repo: owner + "/" + repo, ID: tag.Name, URL: url + "/tree/" + tag.Name})
+		}
+	}
+	return created
+}
+
+type tagList struct {
+	url  string
+	tags []tagNode
+}
+
+// repoTags lists the annotated tags of the repository created during the
+// window, with the repository's web URL, once for every user.
+func (c *GraphQLCollector) repoTags(ctx context.Context, owner, repo string) (string, []tagNode, error) {
+	result, err := c.shared.do("tags/"+owner+"/"+repo, func() (interface{}, error) {
+		var found tagList
+		variables := map[string]interface{}{"owner": owner, "name": repo, "cursor": nil}
+		for {
+			var data struct {
+				Repository *struct {
+					URL  string `json:"url"`
+					Refs struct {
+						PageInfo pageInfo  `json:"pageInfo"`
+						Nodes    []tagNode `json:"nodes"`
+					} `json:"refs"`
+				} `json:"repository"`
+			}
+			if err := c.query(ctx, tagsQuery, variables, &data); err != nil {
+				return found, err
+			}
+			if data.Repository == nil {
+				return found, nil
+			}
+			found.url = data.Repository.URL
+			for _, tag := range data.Repository.Refs.Nodes {
+				committed := tag.Target.CommittedDate
+				if tag.Target.Target != nil {
+					committed = tag.Target.Target.CommittedDate
+				}
+				if committed != nil && committed.Before(c.Since) {
+					return found, nil
+				}
+				if tag.Target.Tagger != nil && c.inWindow(tag.Target.Tagger.Date) {
+					found.tags = append(found.tags, tag)
+				}
+			}
+			if !data.Repository.Refs.PageInfo.HasNextPage {
+				return found, nil
+			}
+			variables["cursor"] = data.Repository.Refs.PageInfo.EndCursor
+		}
+	})
+	found := result.(tagList)
+	return found.url, found.tags, err
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"context"
+	_ "embed"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Renderer writes a report.
+type Renderer interface {
+	Render(ctx context.Context, w io.Writer, report Report) error
+}
+
+// defaultTemplate is the built-in HTML report, with its styles inlined so
+// the output is a single self-contained file.
+//
+//go:embed template.html
+var defaultTemplate string
+
+// HTMLRenderer renders the report with an html/template. The built-in
+// template is used unless TemplatePath names a custom template file.
+type HTMLRenderer struct {
+	TemplatePath string
+	Charts       bool // Draw bar charts and the score history instead of the leaderboard table
+}
+
+func (r HTMLRenderer) Render(_ context.Context, w io.Writer, report Report) error {
+	var tmpl *template.Template
+	var err error
+	if r.TemplatePath == "" {
+		tmpl, err = template.New("template.html").Funcs(templateFuncs).Parse(defaultTemplate)
+	} else {
+		tmpl, err = template.New(filepath.Base(r.TemplatePath)).Funcs(templateFuncs).ParseFiles(r.TemplatePath)
+	}
+	if err != nil {
+		return err
+	}
+	report.Charts = r.Charts
+	return tmpl.Execute(w, report)
+}
+
+// templateFuncs are the helper functions available to HTML templates,
+// including custom ones given with TemplatePath.
+var templateFuncs = template.FuncMap{
+	"trend":         trendHTML,
+	"formatNumber":  formatNumber,
+	"percent":       percent,
+	"durationHuman": durationHuman,
+	"rankBadge":     rankBadge,
+	"barChart":      barChart,
+	"lineChart":     lineChart,
+	"organizations": organizations,
+	"heatmap":       heatmap,
+}
+
+// RenderFile renders the report into the file at path, replacing its contents.
+func RenderFile(ctx context.Context, r Renderer, path string, report Report) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return r.Render(ctx, file, report)
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"sort"
+)
+
+// RepoMetrics are the metrics of every measured user in one repository.
+type RepoMetrics struct {
+	Metrics      UserMetrics `json:"metrics"`      // Summed over users; the score is not set
+	Contributors []string    `json:"contributors"` // Users with any activity in the repository, sorted
+}
+
+// RepoTotals accumulates the result of every collection task by repository
+// for --group-by repo. A nil RepoTotals records nothing.
+type RepoTotals map[string]RepoMetrics
+
+func (r RepoTotals) add(repo, user string, update UserMetrics) {
+	if r == nil {
+		return
+	}
+	totals := r[repo]
+	totals.Metrics = Merge(totals.Metrics, update)
+	if active(update) {
+		i := sort.SearchStrings(totals.Contributors, user)
+		if i == len(totals.Contributors) || totals.Contributors[i] != user {
+			totals.Contributors = append(totals.Contributors, "")
+			copy(totals.Contributors[i+1:], totals.Contributors[i:])
+			totals.Contributors[i] = user
+		}
+	}
+	r[repo] = totals
+}
+
+// active reports whether m counts any activity.
+func active(m UserMetrics) bool {
+	return m.Commits > 0 || m.HoC > 0 || m.Issues > 0 || m.Msgs > 0 || m.Pulls > 0 || m.Reviews > 0 ||
+		m.ReviewComments > 0 || m.Labeled > 0 || m.Assigned > 0 || m.IssuesClosed > 0 ||
+		m.DiscussionsStarted > 0 || m.DiscussionComments > 0 || m.DiscussionAnswers > 0
+}
+
+// RepoMetricsView is a row of the per-repository table.
+type RepoMetricsView struct {
+	Repo             string
+	Metrics          UserMetrics
+	MedianReviewTime float64 // Median hours from review request to first review; 0 without reviews
+	Contributors     []string
+}
+
+// RepoViews builds the per-repository rows, most hits of code first.
+func RepoViews(repos map[string]RepoMetrics) []RepoMetricsView {
+	var views []RepoMetricsView
+	for repo, totals := range repos {
+		views = append(views, RepoMetricsView{
+			Repo:             repo,
+			Metrics:          totals.Metrics,
+			MedianReviewTime: median(totals.Metrics.ReviewTimes),
+			Contributors:     totals.Contributors,
+		})
+	}
+	sort.Slice(views, func(i, j int) bool {
+		if views[i].Metrics.HoC != views[j].Metrics.HoC {
+			return views[i].Metrics.HoC > views[j].Metrics.HoC
+		}
+		return views[i].Repo < views[j].Repo
+	})
+	return views
+}
+
+// median returns the median of values, or 0 when there are none.
+func median(values []float64) float64 {
+	n := len(values)
+	if n == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -0,0 +1,326 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// UserMetricsView is a row of the leaderboard.
+type UserMetricsView struct {
+	Rank         int // Position by descending score, starting at 1
+	User         string
+	Metrics      UserMetrics
+	CreatedSince string
+	CreatedRange string // Search qualifier value for the window, e.g. >2024-01-01
+	Organization string
+	TopRepos     string      // Top 3 repositories formatted as org/repo(LoC)
+	WebURL       string      // Root of the GitHub web UI used for search links
+	Delta        *UserDeltas // Change against the previous run, nil without history
+	Newcomer     bool        // The user's first pull request falls into the window
+	Lifecycles   Lifecycles  // Distributions of the lifecycle metrics
+}
+
+// ViewOptions describes the run that views are built for.
+type ViewOptions struct {
+	Since        time.Time
+	Until        time.Time // End of the window; zero when unknown
+	CollectedAt  time.Time // When the results were collected; zero when unknown
+	Organization string
+	WebURL       string                 // Defaults to https://github.com
+	Teams        map[string][]string    // Team members keyed by org/team-slug
+	Previous     map[string]UserMetrics // Metrics of the previous run used for deltas
+	Periods      []Period               // Consecutive windows compared side by side, oldest first
+	History      []ScoreSeries          // Scores of the stored runs per user
+	Repos        map[string]RepoMetrics // Totals by repository, for the per-repository table
+	Inactive     []string               // Users without activity, listed apart from the leaderboard
+	Leaderboards []LeaderboardScores    // Scores of the named leaderboards
+	Failures     []EndpointFailure      // Endpoints the collection stopped calling, leaving metrics incomplete
+	WorkingHours string                 // Working hours of the off-hours activity, empty when not measured
+	Aggregation  string                 // Aggregation of LcP over pull requests; empty or mean keeps the average
+	StaleDays    int                    // Days after which open pull requests are stale, 0 when not listed
+	Tone         bool                   // Whether the tone of review comments was rated
+	OptOut       []string               // Users left out of every individual row, still counted in team totals
+}
+
+// TeamMetricsView is a row of the team roll-up table.
+type TeamMetricsView struct {
+	Team    string // Team as org/team-slug
+	Members int
+	Total   UserMetrics     // Sum over all members; LcP is the member average
+	Average MetricsAverages // Per-member averages
+
+	Lifecycles Lifecycles // Distributions of the lifecycle metrics over all members' pull requests
+}
+
+// MetricsAverages holds the per-member average of each metric.
+type MetricsAverages struct {
+	Commits float64 `json:"commits"`
+	HoC     float64 `json:"hoc"`
+	Issues  float64 `json:"issues"`
+	LcP     float64 `json:"lcp"`
+	Msgs    float64 `json:"msgs"`
+	Pulls   float64 `json:"pulls"`
+	Reviews float64 `json:"reviews"`
+	Score   float64 `json:"score"`
+}
+
+// Report is the data handed to a Renderer.
+type Report struct {
+	Since        time.Time
+	Until        time.Time // End of the window; zero when unknown
+	CollectedAt  time.Time // When the results were collected; zero when unknown
+	Organization string
+	Ownership    ConcentrationReport // How concentrated HoC and pull requests are among users
+	ReviewLoad   ReviewLoad          // Reviews given against pull requests authored
+	Users        []UserMetricsView
+	Leaderboards []LeaderboardView // Named leaderboards ranked by their own scores
+	Health       OrgHealth         // Overview of all users
+	Teams        []TeamMetricsView // Empty unless teams were requested
+	Periods      []Period          // Empty unless periods are compared
+	PeriodUsers  []UserPeriodsView // Per-user rows of the period comparison
+	ScoreHistory []ScoreSeries     // Score over time of users with more than one run
+	Repos        []RepoMetricsView // Empty unless grouped by repository
+	Newcomers    []Newcomer        // Users whose first pull request falls into the window
+	Inactive     []string          // Users without activity, left out of Users
+	Failures     []EndpointFailure // Endpoints the collection stopped calling
+	WorkingHours string            // Working hours of the off-hours activity, empty when not measured
+	Aggregation  string            // Aggregation of LcP, median, p90 or p95; empty for the mean
+	StaleDays    int               // Days after which open pull requests are stale, 0 when not listed
+	StalePulls   []StalePulls      // Users' pull requests open for longer than StaleDays, oldest first
+	CodeOwners   []PathCoverage    // Code owner review coverage of the paths owned by users, least covered first
+	PlannedWork  []PlanProgress    // Items of the project or milestone completed by users, most completed first
+	Tone         bool              // Whether the tone of review comments was rated, an experimental indicator
+	Charts       bool              // Set by HTMLRenderer to draw charts instead of the leaderboard table
+}
+
+// NewReport builds the per-user rows and, when opts.Teams or opts.Periods
+// are set, the team roll-ups and the period comparison. Users in
+// opts.Inactive are left out of the leaderboard and listed apart. Users in
+// opts.OptOut appear in no row, list or chart naming users, but still count
+// towards the team roll-ups, the organization health and the review load
+// balance.
+func NewReport(metrics map[string]UserMetrics, opts ViewOptions) Report {
+	all := withoutUsers(Views(metrics, opts), opts.Inactive)
+	users := withoutUsers(all, opts.OptOut)
+	report := Report{
+		Since:        opts.Since,
+		Until:        opts.Until,
+		CollectedAt:  opts.CollectedAt,
+		Organization: opts.Organization,
+		Users:        users,
+		Leaderboards: LeaderboardViews(users, opts.Leaderboards),
+		Health:       NewOrgHealth(all, opts.Since, opts.Until),
+		Ownership:    Concentrations(users),
+		ReviewLoad:   NewReviewLoad(all, opts.Teams),
+		Teams:        TeamViews(metrics, opts.Teams),
+		Periods:      opts.Periods,
+		PeriodUsers:  PeriodViews(opts.Periods),
+		ScoreHistory: trends(opts.History),
+		Repos:        RepoViews(opts.Repos),
+		Newcomers:    Newcomers(users),
+		CodeOwners:   CodeOwnerCoverage(users),
+		PlannedWork:  PlannedWork(users),
+		Inactive:     opts.Inactive,
+		Failures:     opts.Failures,
+		WorkingHours: opts.WorkingHours,
+		Tone:         opts.Tone,
+	}
+	if opts.Aggregation != AggregationMean {
+		report.Aggregation = opts.Aggregation
+	}
+	if opts.StaleDays > 0 {
+		at := opts.CollectedAt
+		if at.IsZero() {
+			at = time.Now()
+		}
+		report.StaleDays = opts.StaleDays
+		report.StalePulls = StalePullViews(users, at)
+	}
+	for i := range report.Teams {
+		team := &report.Teams[i]
+		team.Lifecycles = NewLifecycles(team.Total)
+		if report.Aggregation != "" && team.Lifecycles.LcP.Count > 0 {
+			team.Total.LcP = team.Lifecycles.LcP.Value(report.Aggregation)
+			team.Average.LcP = team.Total.LcP
+		}
+	}
+	report.Health.Users += len(opts.Inactive)
+	if len(opts.OptOut) > 0 {
+		report.withoutOptedOut(opts.OptOut)
+	}
+	return report
+}
+
+// withoutOptedOut drops the opted-out users from the parts of the report
+// that name users. The leaderboard and the lists built from it are already
+// without them.
+func (r *Report) withoutOptedOut(optOut []string) {
+	optedOut := userSet(optOut)
+	var load []ReviewBalance
+	for _, balance := range r.ReviewLoad.Users {
+		if !optedOut[strings.ToLower(balance.User)] {
+			load = append(load, balance)
+		}
+	}
+	r.ReviewLoad.Users = load
+	var periods []UserPeriodsView
+	for _, view := range r.PeriodUsers {
+		if !optedOut[strings.ToLower(view.User)] {
+			periods = append(periods, view)
+		}
+	}
+	r.PeriodUsers = periods
+	var history []ScoreSeries
+	for _, series := range r.ScoreHistory {
+		if !optedOut[strings.ToLower(series.User)] {
+			history = append(history, series)
+		}
+	}
+	r.ScoreHistory = history
+	r.Inactive = withoutLogins(r.Inactive, optedOut)
+	for i := range r.Repos {
+		r.Repos[i].Contributors = withoutLogins(r.Repos[i].Contributors, optedOut)
+	}
+}
+
+// withoutLogins returns the logins not in the set of lower-case logins.
+func withoutLogins(logins []string, exclude map[string]bool) []string {
+	var kept []string
+	for _, login := range logins {
+		if !exclude[strings.ToLower(login)] {
+			kept = append(kept, login)
+		}
+	}
+	return kept
+}
+
+// userSet returns the lower-case logins as a set.
+func userSet(users []string) map[string]bool {
+	set := make(map[string]bool, len(users))
+	for _, user := range users {
+		set[strings.ToLower(user)] = true
+	}
+	return set
+}
+
+// withoutUsers drops the excluded users from views and ranks the rest again.
+// Logins are compared case-insensitively.
+func withoutUsers(views []UserMetricsView, exclude []string) []UserMetricsView {
+	if len(exclude) == 0 {
+		return views
+	}
+	excluded := userSet(exclude)
+	var kept []UserMetricsView
+	for _, view := range views {
+		if !excluded[strings.ToLower(view.User)] {
+			view.Rank = len(kept) + 1
+			kept = append(kept, view)
+		}
+	}
+	return kept
+}
+
+// Window describes the measured period, e.g. "from 2024-01-01 to
+// 2024-03-31".
+func (r Report) Window() string {
+	if r.Until.IsZero() {
+		return "since " + r.Since.Format("2006-01-02")
+	}
+	return "from " + r.Since.Format("2006-01-02") + " to " + r.Until.Format("2006-01-02")
+}
+
+// Views converts collected metrics into view rows sorted by descending score.
+func Views(metrics map[string]UserMetrics, opts ViewOptions) []UserMetricsView {
+	webURL := opts.WebURL
+	if webURL == "" {
+		webURL = DefaultWebURL
+	}
+	var sortedMetrics []UserMetricsView
+	for user, metric := range metrics {
+		view := UserMetricsView{
+			User:         user,
+			Metrics:      metric,
+			CreatedSince: opts.Since.Format("2006-01-02"),
+			CreatedRange: DateRange(opts.Since, opts.Until, ">"),
+			Organization: opts.Organization,
+			TopRepos:     TopRepos(metric.Repos),
+			WebURL:       webURL,
+			Newcomer:     newcomer(metric, opts.Since, opts.Until),
+			Lifecycles:   NewLifecycles(metric),
+		}
+		if opts.Aggregation != "" && opts.Aggregation != AggregationMean && view.Lifecycles.LcP.Count > 0 {
+			view.Metrics.LcP = view.Lifecycles.LcP.Value(opts.Aggregation)
+		}
+		if opts.Previous != nil {
+			view.Delta = Deltas(metric, opts.Previous[user])
+		}
+		sortedMetrics = append(sortedMetrics, view)
+	}
+
+	sort.Slice(sortedMetrics, func(i, j int) bool {
+		return sortedMetrics[i].Metrics.Score > sortedMetrics[j].Metrics.Score
+	})
+	for i := range sortedMetrics {
+		sortedMetrics[i].Rank = i + 1
+	}
+
+	return sortedMetrics
+}
+
+// TopRepos formats the three repositories with the most hits of code.
+func TopRepos(repos map[string]int) string {
+	type repo struct {
+		Name string
+		HoC  int
+	}
+	var repoList []repo
+	for name, hoc := range repos {
+		repoList = append(repoList, repo{Name: name, HoC: hoc})
+	}
+	sort.Slice(repoList, func(i, j int) bool {
+		return repoList[i].HoC > repoList[j].HoC
+	})
+	var topRepos []string
+	for i := 0; i < len(repoList) && i < 3; i++ {
+		topRepos = append(topRepos, fmt.Sprintf("%s(%d)", repoList[i].Name, repoList[i].HoC))
+	}
+	return strings.Join(topRepos, ", ")
+}
+
+// TeamViews aggregates member metrics per team, sorted by descending total
+// score. Members without any collected activity count towards the averages
+// with zero values.
+func TeamViews(metrics map[string]UserMetrics, teams map[string][]string) []TeamMetricsView {
+	var views []TeamMetricsView
+	for team, members := range teams {
+		view := TeamMetricsView{Team: team, Members: len(members)}
+		for _, member := range members {
+			m := metrics[member]
+			view.Total = Merge(view.Total, m)
+			view.Total.Score += m.Score
+		}
+		if view.Members > 0 {
+			n := float64(view.Members)
+			view.Average = MetricsAverages{
+				Commits: float64(view.Total.Commits) / n,
+				HoC:     float64(view.Total.HoC) / n,
+				Issues:  float64(view.Total.Issues) / n,
+				LcP:     view.Total.LcP / n,
+				Msgs:    float64(view.Total.Msgs) / n,
+				Pulls:   float64(view.Total.Pulls) / n,
+				Reviews: float64(view.Total.Reviews) / n,
+				Score:   view.Total.Score / n,
+			}
+			view.Total.LcP = view.Average.LcP
+		}
+		views = append(views, view)
+	}
+
+	sort.Slice(views, func(i, j int) bool {
+		return views[i].Total.Score > views[j].Total.Score
+	})
+
+	return views
+}
@@ -0,0 +1,441 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// Repository discovery modes of GitHubCollector.
+const (
+	// DiscoveryActivity derives repositories from each user's pull request
+	// activity during the window.
+	DiscoveryActivity = "activity"
+	// DiscoveryOrg measures every repository of the organization.
+	DiscoveryOrg = "org"
+)
+
+// RepoFilter selects which discovered repositories are measured.
+type RepoFilter struct {
+	IncludeArchived bool // Organization repositories only
+	// IncludeForks measures forks. Otherwise forks of the organization are
+	// skipped, and forks found through activity are replaced by their
+	// upstream repository.
+	IncludeForks bool
+	Visibility   string   // all, public, private or internal; empty means all
+	Topics       []string // Keeps the repositories with any of the topics; empty keeps all
+	Match        []string // Keeps the repositories whose name matches any of the globs; empty keeps all
+	ExcludeMatch []string // Drops the repositories whose name matches any of the globs
+}
+
+// IsZero reports whether the filter keeps every repository by its name,
+// topics and visibility.
+func (f RepoFilter) IsZero() bool {
+	return len(f.Topics) == 0 && len(f.Match) == 0 && len(f.ExcludeMatch) == 0 && f.MatchVisibility("")
+}
+
+// MatchVisibility reports whether a repository with the visibility passes
+// Visibility.
+func (f RepoFilter) MatchVisibility(visibility string) bool {
+	return f.Visibility == "" || f.Visibility == "all" || f.Visibility == visibility
+}
+
+// MatchName reports whether the owner/name repository passes Match and
+// ExcludeMatch. Globs without a slash match the name, others the full name,
+// ignoring case.
+func (f RepoFilter) MatchName(repo string) bool {
+	if len(f.Match) > 0 && !matchRepoGlob(f.Match, repo) {
+		return false
+	}
+	return !matchRepoGlob(f.ExcludeMatch, repo)
+}
+
+// MatchTopics reports whether a repository with the topics passes Topics.
+func (f RepoFilter) MatchTopics(topics []string) bool {
+	if len(f.Topics) == 0 {
+		return true
+	}
+	for _, topic := range topics {
+		if containsFold(f.Topics, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchRepoGlob(patterns []string, repo string) bool {
+	for _, pattern := range patterns {
+		if MatchGlob(strings.ToLower(pattern), strings.ToLower(repo)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Repositories returns the repositories to measure for the user: the fixed
+// Repos when set, otherwise the organizations' repositories with
+// DiscoveryOrg, otherwise those the user was active in. ExcludeRepos are
+// removed in every case. Failed searches for the user's activity are an
+// error with ErrorModeFail; otherwise every metric of the user is marked
+// unknown.
+func (c *GitHubCollector) Repositories(ctx context.Context, user string) ([]string, error) {
+	var repos []string
+	var err error
+	errs := &taskErrors{}
+	ctx = context.WithValue(ctx, taskErrorsKey{}, errs)
+	switch {
+	case len(c.Repos) > 0:
+		repos = c.Repos
+	case c.Discovery == DiscoveryOrg && len(c.organizations()) > 0:
+		c.orgReposOnce.Do(func() {
+			for _, org := range c.organizations() {
+				orgRepos, err := c.OrgRepositories(ctx, org)
+				if err != nil {
+					c.orgReposErr = err
+					return
+				}
+				c.orgRepos = append(c.orgRepos, orgRepos...)
+			}
+		})
+		repos, err = c.orgRepos, c.orgReposErr
+	default:
+		repos, err = c.activityRepositories(ctx, user)
+		if err == nil && !c.RepoFilter.IncludeForks {
+			repos = c.upstreamRepositories(ctx, repos)
+		}
+		if err == nil && !c.RepoFilter.IsZero() {
+			repos = c.filterRepositories(ctx, repos)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := errs.first(); err != nil {
+		if c.ErrorMode == ErrorModeFail {
+			return nil, err
+		}
+		c.addIncomplete(IncompleteTask{User: user, Error: err.Error(), Err: err})
+		c.incompleteMu.Lock()
+		if c.undiscoveredUsers == nil {
+			c.undiscoveredUsers = make(map[string]bool)
+		}
+		c.undiscoveredUsers[user] = true
+		c.incompleteMu.Unlock()
+	}
+	return c.excludeRepos(repos), nil
+}
+
+// organizations returns Organization and Organizations.
+func (c *GitHubCollector) organizations() []string {
+	var orgs []string
+	if c.Organization != "" {
+		orgs = append(orgs, c.Organization)
+	}
+	return append(orgs, c.Organizations...)
+}
+
+// inOrganizations reports whether the repository belongs to one of the
+// organizations, or whether none is set.
+func (c *GitHubCollector) inOrganizations(repo string) bool {
+	orgs := c.organizations()
+	owner, _ := ParseRepo(repo)
+	return len(orgs) == 0 || containsFold(orgs, owner)
+}
+
+// excludeRepos drops the ExcludeRepos from repos.
+func (c *GitHubCollector) excludeRepos(repos []string) []string {
+	if len(c.ExcludeRepos) == 0 {
+		return repos
+	}
+	var kept []string
+	for _, repo := range repos {
+		excluded := false
+		for _, exclude := range c.ExcludeRepos {
+			if strings.EqualFold(repo, exclude) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			kept = append(kept, repo)
+		} else if c.Verbose {
+			log.Printf("Skipping excluded repository %s\n", repo)
+		}
+	}
+	return kept
+}
+
+// OrgRepositories lists the repositories of an organization that pass the
+// collector's RepoFilter.
+func (c *GitHubCollector) OrgRepositories(ctx context.Context, org string) ([]string, error) {
+	visibility := c.RepoFilter.Visibility
+	if visibility == "" {
+		visibility = "all"
+	}
+	opts := &github.RepositoryListByOrgOptions{
+		Type: visibility,
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	var repos []string
+	for {
+		result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /orgs/{org}/repos", func() (interface{}, *github.Response, error) {
+			return c.api().ListOrgRepositories(ctx, org, opts)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("listing repositories of organization %s: %w", org, err)
+		}
+		for _, repo := range result.([]*github.Repository) {
+			if repo.GetArchived() && !c.RepoFilter.IncludeArchived {
+				continue
+			}
+			if repo.GetFork() && !c.RepoFilter.IncludeForks {
+				continue
+			}
+			if !c.RepoFilter.MatchName(repo.GetFullName()) || !c.RepoFilter.MatchTopics(repo.Topics) {
+				continue
+			}
+			// The listing holds the details looked up for the visibility
+			// of the repository's activity.
+			listed := repo
+			c.shared.do("repo/"+strings.ToLower(repo.GetFullName()), func() (interface{}, error) {
+				return listed, nil
+			})
+			repos = append(repos, repo.GetFullName())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	if c.Verbose {
+		log.Printf("Organization %s has %d repositories to measure\n", org, len(repos))
+	}
+	return repos, nil
+}
+
+// activityRepositories returns the repositories in which the user created,
+// commented on or reviewed pull requests during the window.
+func (c *GitHubCollector) activityRepositories(ctx context.Context, user string) ([]string, error) {
+	reposMap := make(map[string]bool)
+
+	// Get repositories where the user created pull requests
+	query := fmt.Sprintf("author:%s created:%s", user, c.dateRange(">"))
+	searchOpts := &github.SearchOptions{
+		Sort:  "created",
+		Order: "desc",
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	for {
+		result, resp, err := c.retryWithBackoff(ctx, resourceSearch, "GET /search/issues", func() (interface{}, *github.Response, error) {
+			return c.api().SearchIssues(ctx, query, searchOpts)
+		})
+		if err != nil {
+			logError(ctx, err, "fetching pull requests commented by user %s", user)
+			break
+		}
+		issues := result.(*github.IssuesSearchResult)
+		for _, issue := range issues.Issues {
+			if issue.IsPullRequest() {
+				repoFullName := parseRepoURL(issue.GetRepositoryURL())
+				if repoFullName != "" && c.inOrganizations(repoFullName) {
+					reposMap[repoFullName] = true
+					if c.Verbose {
+						log.Printf("User %s created pull request in repository %s\n", user, repoFullName)
+					}
+				}
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		searchOpts.Page = resp.NextPage
+	}
+
+	// Get repositories where the user commented on pull requests
+	query = fmt.Sprintf("commenter:%s created:%s", user, c.dateRange(">"))
+	searchOpts = &github.SearchOptions{
+		Sort:  "created",
+		Order: "desc",
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	for {
+		result, resp, err := c.retryWithBackoff(ctx, resourceSearch, "GET /search/issues", func() (interface{}, *github.Response, error) {
+			return c.api().SearchIssues(ctx, query, searchOpts)
+		})
+		if err != nil {
+			logError(ctx, err, "fetching pull requests commented by user %s", user)
+			break
+		}
+		issues := result.(*github.IssuesSearchResult)
+		for _, issue := range issues.Issues {
+			if issue.IsPullRequest() {
+				repoFullName := parseRepoURL(issue.GetRepositoryURL())
+				if repoFullName != "" && c.inOrganizations(repoFullName) {
+					reposMap[repoFullName] = true
+					if c.Verbose {
+						log.Printf("User %s commented on pull request in repository %s\n", user, repoFullName)
+					}
+				}
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		searchOpts.Page = resp.NextPage
+	}
+
+	// Get repositories where the user reviewed pull requests
+	query = fmt.Sprintf("reviewed-by:%s created:%s", user, c.dateRange(">"))
+	for {
+		result, resp, err := c.retryWithBackoff(ctx, resourceSearch, "GET /search/issues", func() (interface{}, *github.Response, error) {
+			return c.api().SearchIssues(ctx, query, searchOpts)
+		})
+		if err != nil {
+			logError(ctx, err, "fetching pull requests reviewed by user %s", user)
+			break
+		}
+		issues := result.(*github.IssuesSearchResult)
+		for _, issue := range issues.Issues {
+			if issue.IsPullRequest() {
+				repoFullName := parseRepoURL(issue.GetRepositoryURL())
+				if repoFullName != "" && c.inOrganizations(repoFullName) {
+					reposMap[repoFullName] = true
+					if c.Verbose {
+						log.Printf("User %s reviewed pull request in repository %s\n", user, repoFullName)
+					}
+				}
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		searchOpts.Page = resp.NextPage
+	}
+
+	// Convert map keys to slice
+	var reposList []string
+	for repo := range reposMap {
+		reposList = append(reposList, repo)
+	}
+
+	return reposList, nil
+}
+
+// upstreamRepositories replaces the forks among repos with the repositories
+// they were forked from, where their pull requests usually end up, or drops
+// them when the upstream is outside the organizations. Repositories whose
+// details cannot be fetched are kept.
+func (c *GitHubCollector) upstreamRepositories(ctx context.Context, repos []string) []string {
+	seen := make(map[string]bool, len(repos))
+	var kept []string
+	for _, repo := range repos {
+		owner, name := ParseRepo(repo)
+		info, err := c.repository(ctx, owner, name)
+		if err != nil {
+			log.Printf("Error fetching repository %s, keeping it even if it is a fork: %v\n", repo, err)
+		} else if info.GetFork() {
+			upstream := info.GetParent().GetFullName()
+			if c.Verbose {
+				log.Printf("Repository %s is a fork of %s\n", repo, upstream)
+			}
+			repo = upstream
+			if repo == "" || !c.inOrganizations(repo) {
+				continue
+			}
+		}
+		if !seen[strings.ToLower(repo)] {
+			seen[strings.ToLower(repo)] = true
+			kept = append(kept, repo)
+		}
+	}
+	return kept
+}
+
+// filterRepositories drops the repositories that do not pass the name,
+// topic and visibility filters of RepoFilter. Topics and visibility are
+// looked up once per repository; repositories whose details cannot be
+// fetched are kept.
+func (c *GitHubCollector) filterRepositories(ctx context.Context, repos []string) []string {
+	var kept []string
+	for _, repo := range repos {
+		if !c.RepoFilter.MatchName(repo) {
+			if c.Verbose {
+				log.Printf("Skipping repository %s not matching the name filters\n", repo)
+			}
+			continue
+		}
+		if len(c.RepoFilter.Topics) > 0 {
+			owner, name := ParseRepo(repo)
+			info, err := c.repository(ctx, owner, name)
+			if err != nil {
+				log.Printf("Error fetching repository %s, keeping it whatever its topics: %v\n", repo, err)
+			} else if !c.RepoFilter.MatchTopics(info.Topics) {
+				if c.Verbose {
+					log.Printf("Skipping repository %s without the topics %s\n", repo, strings.Join(c.RepoFilter.Topics, ", "))
+				}
+				continue
+			}
+		}
+		if visibility := c.RepoVisibility(ctx, repo); visibility != "" && !c.RepoFilter.MatchVisibility(visibility) {
+			if c.Verbose {
+				log.Printf("Skipping %s repository %s\n", visibility, repo)
+			}
+			continue
+		}
+		kept = append(kept, repo)
+	}
+	return kept
+}
+
+// RepoVisibility returns the visibility of the owner/name repository, public,
+// private or internal, or "" when its details cannot be fetched.
+func (c *GitHubCollector) RepoVisibility(ctx context.Context, repo string) string {
+	result, _ := c.shared.do("visibility/"+strings.ToLower(repo), func() (interface{}, error) {
+		owner, name := ParseRepo(repo)
+		info, err := c.repository(ctx, owner, name)
+		switch {
+		case err != nil:
+			log.Printf("Error fetching repository %s, leaving its activity out of the visibility split: %v\n", repo, err)
+			return "", nil
+		case info.GetVisibility() != "":
+			return info.GetVisibility(), nil
+		case info.GetPrivate():
+			return "private", nil
+		}
+		return "public", nil
+	})
+	return result.(string)
+}
+
+// repository fetches a repository's details, once per run.
+func (c *GitHubCollector) repository(ctx context.Context, owner, name string) (*github.Repository, error) {
+	result, err := c.shared.do("repo/"+strings.ToLower(owner+"/"+name), func() (interface{}, error) {
+		result, _, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}", func() (interface{}, *github.Response, error) {
+			return c.api().GetRepository(ctx, owner, name)
+		})
+		if err != nil {
+			return (*github.Repository)(nil), err
+		}
+		return result.(*github.Repository), nil
+	})
+	return result.(*github.Repository), err
+}
+
+func parseRepoURL(repoURL string) string {
+	parts := strings.Split(repoURL, "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s", parts[len(parts)-2], parts[len(parts)-1])
+}
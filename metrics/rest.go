@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// HTTPError is an error response of the GitLab or Gitea API.
+type HTTPError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration // From the Retry-After header of a 429
+}
+
+func (e *HTTPError) Error() string {
+	// GitLab messages usually start with the status, e.g. 404 Project Not Found.
+	if strings.HasPrefix(e.Message, strconv.Itoa(e.StatusCode)+" ") {
+		return fmt.Sprintf("%s %s: %s", e.Method, e.URL, e.Message)
+	}
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, e.Message)
+}
+
+// restClient makes the JSON requests of the collectors for forges without a
+// client library, GitLab and Gitea.
+type restClient struct {
+	baseURL string       // API root, ending in a slash or not
+	header  http.Header  // Sent with every request, such as the token
+	client  *http.Client // http.DefaultClient when nil
+	retry   RetryPolicy
+	calls   *atomic.Int64 // Counts every request sent
+}
+
+// get requests path, relative to the API root, decodes the response into out
+// and returns its header for the caller's pagination. Server errors, network
+// errors and rate limits are retried as the retry policy allows.
+func (r restClient) get(ctx context.Context, path string, query url.Values, out interface{}) (http.Header, error) {
+	endpoint := strings.TrimSuffix(r.baseURL, "/") + "/" + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	var err error
+	attempts := r.retry.MaxRetries + 1
+	for i := 0; i < attempts; i++ {
+		var header http.Header
+		r.calls.Add(1)
+		header, err = r.do(ctx, endpoint, out)
+		if err == nil {
+			return header, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Printf("Attempt %d failed with error: %v", i+1, err)
+
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && httpErr.StatusCode != http.StatusTooManyRequests && httpErr.StatusCode < 500 {
+			return nil, err
+		}
+		if i < attempts-1 {
+			delay := r.retry.delay(i)
+			if httpErr != nil && httpErr.RetryAfter > delay {
+				delay = httpErr.RetryAfter
+			}
+			if err := sleep(ctx, delay); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nil, err
+}
+
+func (r restClient) do(ctx context.Context, endpoint string, out interface{}) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	for name, values := range r.header {
+		req.Header[name] = values
+	}
+	client := r.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		var message struct {
+			Message interface{} `json:"message"`
+			Error   string      `json:"error"`
+		}
+		json.Unmarshal(body, &message)
+		text := message.Error
+		if message.Message != nil {
+			text = fmt.Sprint(message.Message)
+		}
+		return nil, &HTTPError{Method: req.Method, URL: endpoint, StatusCode: resp.StatusCode, Message: text, RetryAfter: retryAfter(resp)}
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", endpoint, err)
+	}
+	return resp.Header, nil
+}
+
+// list fetches every page of a listing into out, a pointer to a slice. next
+// returns the query of the page after the one with the given query and
+// response header, or nil after the last. The items fetched before an error
+// are stored with it.
+func (r restClient) list(ctx context.Context, path string, query url.Values, out interface{}, next func(query url.Values, header http.Header) url.Values) error {
+	var items []json.RawMessage
+	var err error
+	for query != nil {
+		var batch []json.RawMessage
+		var header http.Header
+		header, err = r.get(ctx, path, query, &batch)
+		items = append(items, batch...)
+		if err != nil {
+			break
+		}
+		query = next(query, header)
+	}
+	if items == nil {
+		items = []json.RawMessage{}
+	}
+	joined, marshalErr := json.Marshal(items)
+	if marshalErr == nil {
+		marshalErr = json.Unmarshal(joined, out)
+	}
+	if err == nil {
+		err = marshalErr
+	}
+	return err
+}
+
+func cloneValues(v url.Values) url.Values {
+	clone := make(url.Values, len(v))
+	for key, values := range v {
+		clone[key] = append([]string(nil), values...)
+	}
+	return clone
+}
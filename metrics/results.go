@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// Results is the outcome of a collection run. It is saved by the collect
+// subcommand so reports can be rendered later without collecting again.
+type Results struct {
+	Metric       string                 `json:"metric"`
+	Since        time.Time              `json:"since"`
+	Until        time.Time              `json:"until"` // Explicit end of the window; zero for windows ending at CollectedAt
+	CollectedAt  time.Time              `json:"collectedAt"`
+	Organization string                 `json:"organization,omitempty"`
+	WebURL       string                 `json:"webURL,omitempty"`
+	Teams        map[string][]string    `json:"teams,omitempty"`
+	Users        map[string]UserMetrics `json:"users"`
+	Previous     map[string]UserMetrics `json:"previous,omitempty"` // Metrics of the previous stored run, for deltas
+	Periods      []Period               `json:"periods,omitempty"`  // Consecutive windows of a period comparison, oldest first
+	History      []ScoreSeries          `json:"history,omitempty"`  // Scores of every stored run including this one
+	Repos        map[string]RepoMetrics `json:"repos,omitempty"`    // Totals by repository with --group-by repo
+	Inactive     []string               `json:"inactive,omitempty"` // Users without activity, listed apart with --include-inactive
+	Leaderboards []LeaderboardScores    `json:"leaderboards,omitempty"`
+	Failures     []EndpointFailure      `json:"failures,omitempty"`     // Endpoints whose circuit breaker opened
+	WorkingHours string                 `json:"workingHours,omitempty"` // Working hours the off-hours activity was measured against
+	Aggregation  string                 `json:"aggregation,omitempty"`  // Aggregation of LcP in reports, empty for the mean
+	StaleDays    int                    `json:"staleDays,omitempty"`    // Days after which open pull requests were listed as stale
+	Tone         bool                   `json:"tone,omitempty"`         // Whether the tone of review comments was rated
+	OptOut       []string               `json:"optOut,omitempty"`       // Users left out of individual reporting, still counted in team totals
+}
+
+// LoadResults reads results saved with Save.
+func LoadResults(path string) (*Results, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var results Results
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
+
+// Save writes the results to path as JSON.
+func (r *Results) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// ViewOptions returns the options for building a report of the results.
+func (r *Results) ViewOptions() ViewOptions {
+	until := r.Until
+	if until.IsZero() {
+		until = r.CollectedAt
+	}
+	return ViewOptions{
+		Since:        r.Since,
+		Until:        until,
+		CollectedAt:  r.CollectedAt,
+		Organization: r.Organization,
+		WebURL:       r.WebURL,
+		Teams:        r.Teams,
+		Previous:     r.Previous,
+		Periods:      r.Periods,
+		History:      r.History,
+		Repos:        r.Repos,
+		Inactive:     r.Inactive,
+		Leaderboards: r.Leaderboards,
+		Failures:     r.Failures,
+		WorkingHours: r.WorkingHours,
+		Aggregation:  r.Aggregation,
+		StaleDays:    r.StaleDays,
+		Tone:         r.Tone,
+		OptOut:       r.OptOut,
+	}
+}
@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// repoReverts holds the reverts made in one repository during the window,
+// keyed by the lowercase login of the author of the reverted change, or
+// their email when the commit is not linked to an account. They are found
+// once and shared by every user measured in the repository.
+type repoReverts struct {
+	once   sync.Once
+	byUser map[string][]EvidenceItem
+	err    error
+}
+
+var (
+	// revertedCommit matches the line git revert adds to the message.
+	revertedCommit = regexp.MustCompile(`(?i)this reverts commit ([0-9a-f]{7,40})`)
+	// revertedPull matches the body of pull requests made with the Revert
+	// button, e.g. "Reverts owner/name#123".
+	revertedPull = regexp.MustCompile(`(?i)\breverts (?:([\w.-]+/[\w.-]+))?#(\d+)`)
+)
+
+// reverts counts the user's commits and pull requests that were reverted
+// during the window, and the user's force pushes to pull request branches.
+func (c *GitHubCollector) reverts(ctx context.Context, owner, repo, user string) UserMetrics {
+	var m UserMetrics
+	reverted, err := c.repoReverts(ctx, owner, repo)
+	if err != nil {
+		logError(ctx, err, "fetching reverts in repo %s/%s", owner, repo)
+	}
+	for _, author := range append([]string{user}, c.Identities.Aliases(user)...) {
+		for _, item := range reverted[strings.ToLower(author)] {
+			m.Reverts++
+			c.Evidence.Add(user, item)
+		}
+	}
+
+	events, err := c.issueEvents(ctx, owner, repo)
+	if err != nil {
+		logError(ctx, err, "fetching issue events in repo %s/%s", owner, repo)
+		return m
+	}
+	for _, event := range events {
+		if event.GetEvent() != "head_ref_force_pushed" || !c.inWindow(event.GetCreatedAt().Time) || !strings.EqualFold(event.GetActor().GetLogin(), user) {
+			continue
+		}
+		m.ForcePushes++
+		c.Evidence.Add(user, EvidenceItem{Metric: MetricReverts, Kind: "force_push", Repo: owner + "/" + repo, ID: strconv.FormatInt(event.GetID(), 10), URL: event.GetIssue().GetHTMLURL()})
+	}
+	if c.Verbose && (m.Reverts > 0 || m.ForcePushes > 0) {
+		log.Printf("User %s in repo %s/%s: %d changes reverted, %d force pushes\n", user, owner, repo, m.Reverts, m.ForcePushes)
+	}
+	return m
+}
+
+// repoReverts returns the repository's reverts by the author of the
+// reverted change, finding them on first use.
+func (c *GitHubCollector) repoReverts(ctx context.Context, owner, repo string) (map[string][]EvidenceItem, error) {
+	c.revertsMu.Lock()
+	if c.revertsByRepo == nil {
+		c.revertsByRepo = make(map[string]*repoReverts)
+	}
+	entry, ok := c.revertsByRepo[owner+"/"+repo]
+	if !ok {
+		entry = &repoReverts{}
+		c.revertsByRepo[owner+"/"+repo] = entry
+	}
+	c.revertsMu.Unlock()
+
+	entry.once.Do(func() {
+		entry.byUser, entry.err = c.findReverts(ctx, owner, repo)
+	})
+	return entry.byUser, entry.err
+}
+
+// findReverts goes through the commits of the window and attributes every
+// revert among them to the author of the commit or pull request it reverts.
+func (c *GitHubCollector) findReverts(ctx context.Context, owner, repo string) (map[string][]EvidenceItem, error) {
+	byUser := make(map[string][]EvidenceItem)
+	commits, err := c.repoCommits(ctx, owner, repo)
+	for _, commit := range commits {
+		message := commit.GetCommit().GetMessage()
+		if !strings.HasPrefix(strings.ToLower(message), "revert") {
+			continue
+		}
+		for _, author := range c.revertedAuthors(ctx, owner, repo, message) {
+			item := EvidenceItem{Metric: MetricReverts, Kind: "revert", Repo: owner + "/" + repo, ID: commit.GetSHA(), URL: commit.GetHTMLURL()}
+			byUser[strings.ToLower(author)] = append(byUser[strings.ToLower(author)], item)
+		}
+	}
+	return byUser, err
+}
+
+// revertedAuthors returns the authors of the commits and pull requests of
+// the repository that a revert commit message names, each once.
+func (c *GitHubCollector) revertedAuthors(ctx context.Context, owner, repo, message string) []string {
+	var authors []string
+	add := func(author string) {
+		for _, a := range authors {
+			if strings.EqualFold(a, author) {
+				return
+			}
+		}
+		authors = append(authors, author)
+	}
+	for _, match := range revertedCommit.FindAllStringSubmatch(message, -1) {
+		result, _, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/commits/{ref}", func() (interface{}, *github.Response, error) {
+			return c.api().GetCommit(ctx, owner, repo, match[1])
+		})
+		if err != nil {
+			logError(ctx, err, "fetching reverted commit %s in repo %s/%s", match[1], owner, repo)
+			continue
+		}
+		commit := result.(*github.RepositoryCommit)
+		if login := commit.GetAuthor().GetLogin(); login != "" {
+			add(login)
+		} else if email := commit.GetCommit().GetAuthor().GetEmail(); email != "" {
+			add(email)
+		}
+	}
+	for _, match := range revertedPull.FindAllStringSubmatch(message, -1) {
+		if match[1] != "" && !strings.EqualFold(match[1], owner+"/"+repo) {
+			continue
+		}
+		number, _ := strconv.Atoi(match[2])
+		result, _, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/pulls/{pull_number}", func() (interface{}, *github.Response, error) {
+			return c.api().GetPullRequest(ctx, owner, repo, number)
+		})
+		if err != nil {
+			logError(ctx, err, "fetching reverted pull request #%d in repo %s/%s", number, owner, repo)
+			continue
+		}
+		if login := result.(*github.PullRequest).GetUser().GetLogin(); login != "" {
+			add(login)
+		}
+	}
+	return authors
+}
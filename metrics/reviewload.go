@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+)
+
+// heavyAuthorPulls is the fewest merged pull requests for a user to count as
+// a heavy author.
+const heavyAuthorPulls = 3
+
+// ReviewBalance compares the reviews a user gave with the pull requests
+// they authored.
+type ReviewBalance struct {
+	User        string  `json:"user"`
+	Reviews     int     `json:"reviews"`
+	Pulls       int     `json:"pulls"`
+	Ratio       float64 `json:"ratio"`       // Reviews given per merged pull request, counting at least one pull request
+	HeavyAuthor bool    `json:"heavyAuthor"` // Authors heavily but reviews less than half as often
+}
+
+// TeamBalance is the review load balance among the members of a team.
+type TeamBalance struct {
+	Team    string  `json:"team"`
+	Reviews int     `json:"reviews"`
+	Pulls   int     `json:"pulls"`
+	Ratio   float64 `json:"ratio"`
+	Balance float64 `json:"balance"` // 0-100, see ReviewLoad.Balance
+}
+
+// ReviewLoad shows how the review load is shared compared to authorship.
+type ReviewLoad struct {
+	// Balance is 100 when every user's share of the reviews matches their
+	// share of the merged pull requests, and 0 when the users reviewing are
+	// entirely different from the ones authoring. It is 0 without any
+	// reviews or pull requests.
+	Balance float64         `json:"balance"`
+	Users   []ReviewBalance `json:"users"` // Heavy authors first, then by ascending ratio
+	Teams   []TeamBalance   `json:"teams"`
+}
+
+// NewReviewLoad compares reviews given with pull requests authored for every
+// user with either, and per team for teams keyed by org/team-slug.
+func NewReviewLoad(views []UserMetricsView, teams map[string][]string) ReviewLoad {
+	metrics := make(map[string]UserMetrics, len(views))
+	var authored []int
+	for _, view := range views {
+		metrics[view.User] = view.Metrics
+		if view.Metrics.Pulls > 0 {
+			authored = append(authored, view.Metrics.Pulls)
+		}
+	}
+	sort.Ints(authored)
+	median := 0
+	if len(authored) > 0 {
+		median = authored[len(authored)/2]
+	}
+
+	load := ReviewLoad{Users: []ReviewBalance{}, Teams: []TeamBalance{}}
+	users := make([]string, 0, len(views))
+	for _, view := range views {
+		m := view.Metrics
+		users = append(users, view.User)
+		if m.Reviews == 0 && m.Pulls == 0 {
+			continue
+		}
+		load.Users = append(load.Users, ReviewBalance{
+			User:        view.User,
+			Reviews:     m.Reviews,
+			Pulls:       m.Pulls,
+			Ratio:       reviewRatio(m.Reviews, m.Pulls),
+			HeavyAuthor: m.Pulls >= heavyAuthorPulls && m.Pulls >= median && 2*m.Reviews < m.Pulls,
+		})
+	}
+	sort.Slice(load.Users, func(i, j int) bool {
+		a, b := load.Users[i], load.Users[j]
+		if a.HeavyAuthor != b.HeavyAuthor {
+			return a.HeavyAuthor
+		}
+		if a.Ratio != b.Ratio {
+			return a.Ratio < b.Ratio
+		}
+		return a.User < b.User
+	})
+	load.Balance = reviewBalance(metrics, users)
+
+	for team, members := range teams {
+		t := TeamBalance{Team: team}
+		for _, member := range members {
+			t.Reviews += metrics[member].Reviews
+			t.Pulls += metrics[member].Pulls
+		}
+		t.Ratio = reviewRatio(t.Reviews, t.Pulls)
+		t.Balance = reviewBalance(metrics, members)
+		load.Teams = append(load.Teams, t)
+	}
+	sort.Slice(load.Teams, func(i, j int) bool {
+		if load.Teams[i].Balance != load.Teams[j].Balance {
+			return load.Teams[i].Balance < load.Teams[j].Balance
+		}
+		return load.Teams[i].Team < load.Teams[j].Team
+	})
+	return load
+}
+
+func reviewRatio(reviews, pulls int) float64 {
+	if pulls < 1 {
+		pulls = 1
+	}
+	return float64(reviews) / float64(pulls)
+}
+
+// reviewBalance is 100 minus the total variation distance, in percent,
+// between the users' shares of reviews and of merged pull requests.
+func reviewBalance(metrics map[string]UserMetrics, users []string) float64 {
+	reviews, pulls := 0, 0
+	for _, user := range users {
+		reviews += metrics[user].Reviews
+		pulls += metrics[user].Pulls
+	}
+	if reviews == 0 || pulls == 0 {
+		return 0
+	}
+	distance := 0.0
+	for _, user := range users {
+		m := metrics[user]
+		distance += math.Abs(float64(m.Reviews)/float64(reviews) - float64(m.Pulls)/float64(pulls))
+	}
+	return math.Max(0, 100*(1-distance/2))
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// reviewRequests counts the pull requests whose review was requested from
+// the user during the window and that the user never reviewed: pending
+// while the pull request is open, ignored once it was merged or closed
+// without their review. GitHub keeps a request listed until the reviewer
+// submits a review, so the pull requests still requesting the user are
+// searched and checked against their reviews and timeline.
+func (c *GitHubCollector) reviewRequests(ctx context.Context, owner, repo, user string) (pending, ignored int) {
+	query := fmt.Sprintf("repo:%s/%s is:pr user-review-requested:%s updated:%s", owner, repo, user, c.dateRange(">="))
+	opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		result, resp, err := c.retryWithBackoff(ctx, resourceSearch, "GET /search/issues", func() (interface{}, *github.Response, error) {
+			return c.api().SearchIssues(ctx, query, opts)
+		})
+		if err != nil {
+			logError(ctx, err, "fetching review requests for user %s in repo %s/%s", user, owner, repo)
+			return pending, ignored
+		}
+		for _, issue := range result.(*github.IssuesSearchResult).Issues {
+			if !issue.IsPullRequest() || !c.Labels.MatchPull(labelNames(issue.Labels)) || c.reviewedBy(ctx, owner, repo, user, issue.GetNumber()) {
+				continue
+			}
+			requested := c.reviewRequestedAt(ctx, owner, repo, user, issue.GetNumber())
+			if requested.IsZero() || !c.inWindow(requested) {
+				continue
+			}
+			kind := "review_request_pending"
+			if issue.GetState() == "open" {
+				pending++
+			} else {
+				ignored++
+				kind = "review_request_ignored"
+			}
+			c.Evidence.Add(user, EvidenceItem{Metric: MetricReviews, Kind: kind, Repo: owner + "/" + repo, ID: strconv.Itoa(issue.GetNumber()), URL: issue.GetHTMLURL()})
+			if c.Verbose {
+				log.Printf("Review of pull request #%d in repo %s/%s requested from %s at %s is still %s\n", issue.GetNumber(), owner, repo, user, requested, strings.TrimPrefix(kind, "review_request_"))
+			}
+		}
+		if resp.NextPage == 0 {
+			return pending, ignored
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// reviewedBy reports whether the user submitted any review of the pull
+// request.
+func (c *GitHubCollector) reviewedBy(ctx context.Context, owner, repo, user string, number int) bool {
+	reviews, err := c.pullReviews(ctx, owner, repo, number)
+	if err != nil {
+		logError(ctx, err, "fetching reviews of pull request #%d in repo %s/%s", number, owner, repo)
+	}
+	for _, review := range reviews {
+		if strings.EqualFold(review.GetUser().GetLogin(), user) && review.GetState() != "PENDING" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// reviews counts the merged pull requests the user reviewed and measures the
+// quality of those reviews: approvals versus change requests, review
+// comments authored, the time from review request to first review, the
+// review requests left unanswered and, with CodeOwners, the reviews of the
+// pull requests changing code the user owns.
+func (c *GitHubCollector) reviews(ctx context.Context, owner, repo, user string) UserMetrics {
+	var m UserMetrics
+	pulls, err := c.reviewedPulls(ctx, owner, repo, user)
+	if err != nil {
+		logError(ctx, err, "fetching reviewed pull requests for user %s in repo %s/%s", user, owner, repo)
+	}
+	var numbers []int
+	for _, pull := range pulls {
+		m.Reviews++
+		numbers = append(numbers, pull.number)
+		c.Evidence.Add(user, EvidenceItem{Metric: MetricReviews, Kind: "pull", Repo: owner + "/" + repo, ID: strconv.Itoa(pull.number), URL: pull.url})
+		if c.Verbose {
+			log.Printf("Pull request #%d reviewed by %s in repo %s/%s was merged at %s\n", pull.number, user, owner, repo, pull.merged)
+		}
+	}
+
+	totalLatency := 0.0
+	for _, number := range numbers {
+		first := c.reviewVerdicts(ctx, owner, repo, user, number, &m)
+		if first.IsZero() {
+			continue
+		}
+		requested := c.reviewRequestedAt(ctx, owner, repo, user, number)
+		if requested.IsZero() || first.Before(requested) {
+			continue
+		}
+		latency := first.Sub(requested).Hours()
+		totalLatency += latency
+		m.FirstReviews++
+		m.ReviewTimes = append(m.ReviewTimes, latency)
+		if c.Verbose {
+			log.Printf("Pull request #%d in repo %s/%s: review requested from %s at %s, first review after %.2f hours\n", number, owner, repo, user, requested, latency)
+		}
+	}
+	if m.FirstReviews > 0 {
+		m.TimeToFirstReview = totalLatency / float64(m.FirstReviews)
+	}
+
+	m.ReviewComments = c.reviewComments(ctx, owner, repo, user)
+	if c.Tone {
+		c.tone(ctx, owner, repo, user, &m)
+	}
+	m.PendingReviewRequests, m.IgnoredReviewRequests = c.reviewRequests(ctx, owner, repo, user)
+	c.ownership(ctx, owner, repo, user, &m)
+	return m
+}
+
+// reviewVerdicts adds the user's approvals and change requests on a pull
+// request, and with working hours their submitted reviews, to m and returns when the user first submitted a review.
+func (c *GitHubCollector) reviewVerdicts(ctx context.Context, owner, repo, user string, number int, m *UserMetrics) time.Time {
+	var first time.Time
+	reviews, err := c.pullReviews(ctx, owner, repo, number)
+	if err != nil {
+		logError(ctx, err, "fetching reviews of pull request #%d in repo %s/%s", number, owner, repo)
+		return first
+	}
+	for _, review := range reviews {
+		if !strings.EqualFold(review.GetUser().GetLogin(), user) {
+			continue
+		}
+		switch review.GetState() {
+		case "APPROVED":
+			m.Approvals++
+		case "CHANGES_REQUESTED":
+			m.ChangesRequested++
+		}
+		c.Evidence.Add(user, EvidenceItem{Metric: MetricReviews, Kind: "review_" + strings.ToLower(review.GetState()), Repo: owner + "/" + repo, ID: strconv.FormatInt(review.GetID(), 10), URL: review.GetHTMLURL()})
+		submitted := review.GetSubmittedAt().Time
+		if c.inWindow(submitted) {
+			m.addDay(submitted)
+		}
+		if !c.WorkingHours.IsZero() && !submitted.IsZero() {
+			m.SubmittedReviews++
+			if c.WorkingHours.OffHours(submitted) {
+				m.OffHoursReviews++
+			}
+		}
+		if !submitted.IsZero() && (first.IsZero() || submitted.Before(first)) {
+			first = submitted
+		}
+	}
+	return first
+}
+
+// reviewRequestedAt returns when a review of the pull request was first
+// requested from the user, or the zero time if it never was.
+func (c *GitHubCollector) reviewRequestedAt(ctx context.Context, owner, repo, user string, number int) time.Time {
+	var requested time.Time
+	events, err := c.pullTimeline(ctx, owner, repo, number)
+	if err != nil {
+		logError(ctx, err, "fetching timeline of pull request #%d in repo %s/%s", number, owner, repo)
+		return requested
+	}
+	for _, event := range events {
+		if event.GetEvent() != "review_requested" || !strings.EqualFold(event.GetReviewer().GetLogin(), user) {
+			continue
+		}
+		created := event.GetCreatedAt().Time
+		if requested.IsZero() || created.Before(requested) {
+			requested = created
+		}
+	}
+	return requested
+}
+
+// reviewComments counts the pull request review comments the user authored
+// in the repository during the window, from the comments listed for msgs.
+func (c *GitHubCollector) reviewComments(ctx context.Context, owner, repo, user string) int {
+	count := 0
+	comments, err := c.comments(ctx, owner, repo)
+	if err != nil {
+		logError(ctx, err, "fetching review comments for user %s in repo %s/%s", user, owner, repo)
+	}
+	for _, comment := range comments {
+		if comment.item.Kind != "review_comment" || !strings.EqualFold(comment.author, user) || !c.inWindow(comment.created) {
+			continue
+		}
+		count++
+		item := comment.item
+		item.Metric = MetricReviews
+		c.Evidence.Add(user, item)
+	}
+	return count
+}
+
+// pullReviewed reports whether anyone but the author reviewed the pull
+// request.
+func (c *GitHubCollector) pullReviewed(ctx context.Context, owner, repo, author string, number int) bool {
+	reviews, err := c.pullReviews(ctx, owner, repo, number)
+	if err != nil {
+		logError(ctx, err, "fetching reviews of pull request #%d in repo %s/%s", number, owner, repo)
+	}
+	for _, review := range reviews {
+		if !strings.EqualFold(review.GetUser().GetLogin(), author) {
+			return true
+		}
+	}
+	return false
+}
+
+// pullReviews lists the reviews of a pull request, once for its author and
+// every reviewer measured. The reviews fetched before an error are returned
+// with it.
+func (c *GitHubCollector) pullReviews(ctx context.Context, owner, repo string, number int) ([]*github.PullRequestReview, error) {
+	result, err := c.shared.do(fmt.Sprintf("reviews/%s/%s#%d", owner, repo, number), func() (interface{}, error) {
+		var reviews []*github.PullRequestReview
+		opts := &github.ListOptions{PerPage: 100}
+		for {
+			result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/pulls/{pull_number}/reviews", func() (interface{}, *github.Response, error) {
+				return c.api().ListReviews(ctx, owner, repo, number, opts)
+			})
+			if err != nil {
+				return reviews, err
+			}
+			reviews = append(reviews, result.([]*github.PullRequestReview)...)
+			if resp.NextPage == 0 {
+				return reviews, nil
+			}
+			opts.Page = resp.NextPage
+		}
+	})
+	return result.([]*github.PullRequestReview), err
+}
+
+// pullTimeline lists the timeline events of a pull request, once for every
+// reviewer measured. The events fetched before an error are returned with
+// it.
+func (c *GitHubCollector) pullTimeline(ctx context.Context, owner, repo string, number int) ([]*github.Timeline, error) {
+	result, err := c.shared.do(fmt.Sprintf("timeline/%s/%s#%d", owner, repo, number), func() (interface{}, error) {
+		var events []*github.Timeline
+		opts := &github.ListOptions{PerPage: 100}
+		for {
+			result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/issues/{issue_number}/timeline", func() (interface{}, *github.Response, error) {
+				return c.api().ListIssueTimeline(ctx, owner, repo, number, opts)
+			})
+			if err != nil {
+				return events, err
+			}
+			events = append(events, result.([]*github.Timeline)...)
+			if resp.NextPage == 0 {
+				return events, nil
+			}
+			opts.Page = resp.NextPage
+		}
+	})
+	return result.([]*github.Timeline), err
+}
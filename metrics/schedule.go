@@ -0,0 +1,155 @@
+package metrics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheduleMacros are the shorthands accepted for common schedules.
+var scheduleMacros = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+var (
+	monthNames   = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	weekdayNames = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+)
+
+// Schedule is a cron expression with the five fields minute, hour, day of
+// month, month and day of week, e.g. "0 6 * * MON" for Mondays at 6:00.
+// Fields take *, numbers, ranges (1-5), lists (1,15), steps (*/15 or 0-30/10)
+// and for months and days of the week also names (JAN, MON), with Sunday as
+// 0 or 7. As in cron, a day matches either day field when both are
+// restricted, and a day field starting with *, such as */2, is not
+// restricted: the day has to match both.
+type Schedule struct {
+	minute, hour, day, month, weekday uint64 // Bit sets of the values each field matches
+	anyDay, anyWeekday                bool   // The day fields start with *
+}
+
+// ParseSchedule parses a cron expression, or one of @hourly, @daily,
+// @weekly and @monthly.
+func ParseSchedule(expr string) (Schedule, error) {
+	if macro, ok := scheduleMacros[strings.ToLower(strings.TrimSpace(expr))]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return Schedule{}, fmt.Errorf("invalid schedule %q, expected five fields: minute, hour, day of month, month and day of week", expr)
+	}
+	var s Schedule
+	var err error
+	if s.minute, err = parseScheduleField(fields[0], 0, 59, nil); err != nil {
+		return Schedule{}, fmt.Errorf("invalid minute: %w", err)
+	}
+	if s.hour, err = parseScheduleField(fields[1], 0, 23, nil); err != nil {
+		return Schedule{}, fmt.Errorf("invalid hour: %w", err)
+	}
+	if s.day, err = parseScheduleField(fields[2], 1, 31, nil); err != nil {
+		return Schedule{}, fmt.Errorf("invalid day of month: %w", err)
+	}
+	if s.month, err = parseScheduleField(fields[3], 1, 12, monthNames); err != nil {
+		return Schedule{}, fmt.Errorf("invalid month: %w", err)
+	}
+	if s.weekday, err = parseScheduleField(fields[4], 0, 7, weekdayNames); err != nil {
+		return Schedule{}, fmt.Errorf("invalid day of week: %w", err)
+	}
+	if s.weekday&(1<<7) != 0 {
+		s.weekday |= 1 // 7 is Sunday too
+	}
+	s.anyDay, s.anyWeekday = strings.HasPrefix(fields[2], "*"), strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+// parseScheduleField returns the set of values from min to max a field
+// matches. names, when set, name the values from min on.
+func parseScheduleField(field string, min, max int, names []string) (uint64, error) {
+	value := func(s string) (int, error) {
+		for i, name := range names {
+			if strings.EqualFold(s, name) {
+				return min + i, nil
+			}
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < min || n > max {
+			return 0, fmt.Errorf("%q is not between %d and %d", s, min, max)
+		}
+		return n, nil
+	}
+
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		span, stepText, stepped := strings.Cut(part, "/")
+		step := 1
+		if stepped {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepText)
+			}
+		}
+		low, high := min, max
+		switch from, to, ranged := strings.Cut(span, "-"); {
+		case span == "*":
+		case ranged:
+			var err error
+			if low, err = value(from); err != nil {
+				return 0, err
+			}
+			if high, err = value(to); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("range %q ends before it starts", span)
+			}
+		default:
+			var err error
+			if low, err = value(span); err != nil {
+				return 0, err
+			}
+			if !stepped {
+				high = low
+			}
+		}
+		for v := low; v <= high; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// Next returns the first time after t the schedule matches, in t's location,
+// or the zero time when it never does, e.g. for February 30.
+func (s Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// matchesDay reports whether the day fields match t's date.
+func (s Schedule) matchesDay(t time.Time) bool {
+	day := s.day&(1<<uint(t.Day())) != 0
+	weekday := s.weekday&(1<<uint(t.Weekday())) != 0
+	if s.anyDay || s.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
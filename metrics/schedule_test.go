@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	at := func(s string) time.Time {
+		t, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"0 6 * * MON", at("2024-01-03 10:00"), at("2024-01-08 06:00")},
+		{"0 6 * * MON", at("2024-01-08 05:59"), at("2024-01-08 06:00")},
+		{"*/15 * * * *", at("2024-01-03 10:07"), at("2024-01-03 10:15")},
+		{"*/15 * * * *", at("2024-01-03 10:45"), at("2024-01-03 11:00")},
+		// */2 leaves the day of month unrestricted: Mondays on odd days.
+		{"0 6 */2 * MON", at("2024-01-01 07:00"), at("2024-01-15 06:00")},
+		// Both day fields restricted: the 1st or any Monday.
+		{"0 6 1 * MON", at("2024-01-02 00:00"), at("2024-01-08 06:00")},
+		{"0 6 1 * MON", at("2024-01-29 07:00"), at("2024-02-01 06:00")},
+		{"@monthly", at("2024-01-15 12:00"), at("2024-02-01 00:00")},
+		{"0 0 30 2 *", at("2024-01-01 00:00"), time.Time{}},
+	}
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.expr)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := s.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%q after %s: got %s, want %s", tt.expr, tt.from, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package metrics
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// JSONSchemaVersion is the version of the JSON output format. The minor
+// version goes up when fields are added, the major version when fields are
+// removed or change meaning.
+const JSONSchemaVersion = "1.28"
+
+// JSONSchema is the JSON Schema describing the output of JSONRenderer.
+//
+//go:embed schema.json
+var JSONSchema []byte
+
+// validate checks the report against the guarantees of the schema, so a
+// broken report is never written for downstream consumers.
+func (r jsonReport) validate() error {
+	if r.SchemaVersion != JSONSchemaVersion {
+		return fmt.Errorf("schema version %q, want %q", r.SchemaVersion, JSONSchemaVersion)
+	}
+	if r.Run.Since.IsZero() {
+		return errors.New("run.since is not set")
+	}
+	if r.Run.Until != nil && !r.Run.Until.After(r.Run.Since) {
+		return errors.New("run.until is not after run.since")
+	}
+	for i, failure := range r.Run.Failures {
+		if failure.Endpoint == "" || failure.Skipped < 0 {
+			return fmt.Errorf("run.failures[%d]: endpoint is empty or skipped is negative", i)
+		}
+	}
+	if h := r.Health; h.MergedPulls < 0 || h.ReviewedPulls < 0 || h.ReviewedPulls > h.MergedPulls || h.Contributors > h.Users {
+		return errors.New("health totals are inconsistent")
+	}
+	if err := r.Concentration.validate(); err != nil {
+		return err
+	}
+	if err := r.ReviewLoad.validate(); err != nil {
+		return err
+	}
+	for i, n := range r.Newcomers {
+		if n.User == "" || n.FirstPull.Repo == "" || n.FirstPull.CreatedAt.IsZero() {
+			return fmt.Errorf("newcomers[%d]: first pull request is not set", i)
+		}
+		if n.TimeToMerge != nil && *n.TimeToMerge < 0 {
+			return fmt.Errorf("newcomers[%d] (%s): first merge before the first pull request", i, n.User)
+		}
+	}
+	for i, path := range r.CodeOwners {
+		if path.Repo == "" || path.Path == "" || path.Reviewed < 0 || path.Reviewed > path.Pulls {
+			return fmt.Errorf("codeOwnerCoverage[%d]: empty path or inconsistent counts", i)
+		}
+	}
+	for i, plan := range r.PlannedWork {
+		if plan.Plan == "" || plan.Issues < 0 || plan.Pulls < 0 || len(plan.Users) == 0 {
+			return fmt.Errorf("plannedWork[%d]: empty plan, negative counts or no users", i)
+		}
+	}
+	if err := validateUsers("users", r.Users); err != nil {
+		return err
+	}
+	listed := make(map[string]bool, len(r.Users))
+	for _, user := range r.Users {
+		listed[user.User] = true
+	}
+	for i, user := range r.Inactive {
+		if user == "" || listed[user] {
+			return fmt.Errorf("inactive[%d]: %q is empty or also in users", i, user)
+		}
+	}
+	for i, repo := range r.Repos {
+		if owner, name, ok := strings.Cut(repo.Repo, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return fmt.Errorf("repos[%d]: %q is not owner/name", i, repo.Repo)
+		}
+		if repo.HoC < 0 || (repo.Commits != nil && *repo.Commits < 0) || (repo.Pulls != nil && *repo.Pulls < 0) {
+			return fmt.Errorf("repos[%d]: negative count", i)
+		}
+	}
+	for i, team := range r.Teams {
+		if err := validateMetrics(team.Total); err != nil {
+			return fmt.Errorf("teams[%d] (%s): %w", i, team.Team, err)
+		}
+	}
+	for i, period := range r.Periods {
+		if err := validateUsers(fmt.Sprintf("periods[%d].users", i), period.Users); err != nil {
+			return err
+		}
+	}
+	names := make(map[string]bool, len(r.Leaderboards))
+	for i, board := range r.Leaderboards {
+		if board.Name == "" || names[board.Name] {
+			return fmt.Errorf("leaderboards[%d]: name %q is empty or repeated", i, board.Name)
+		}
+		names[board.Name] = true
+		for j, user := range board.Users {
+			if user.Rank != j+1 || user.User == "" || math.IsNaN(user.Score) || math.IsInf(user.Score, 0) {
+				return fmt.Errorf("leaderboards[%d] (%s): users[%d] has an invalid rank, user or score", i, board.Name, j)
+			}
+		}
+	}
+	return nil
+}
+
+func validateUsers(path string, users []jsonUser) error {
+	seen := make(map[string]bool)
+	for i, user := range users {
+		if user.User == "" {
+			return fmt.Errorf("%s[%d]: empty user", path, i)
+		}
+		if seen[user.User] {
+			return fmt.Errorf("%s[%d]: duplicate user %s", path, i, user.User)
+		}
+		seen[user.User] = true
+		if err := validateMetrics(user.Metrics); err != nil {
+			return fmt.Errorf("%s[%d] (%s): %w", path, i, user.User, err)
+		}
+	}
+	return nil
+}
+
+func validateMetrics(m jsonMetrics) error {
+	counts := []struct {
+		name  string
+		value int
+	}{
+		{"commits", m.Commits}, {"hoc", m.HoC}, {"deletions", m.Deletions}, {"churn", m.Churn}, {"issues", m.Issues}, {"msgs", m.Msgs}, {"pulls", m.Pulls},
+		{"reviews", m.Reviews}, {"reviewComments", m.ReviewComments}, {"approvals", m.Approvals},
+		{"changesRequested", m.ChangesRequested}, {"medianPullSize", m.MedianPullSize}, {"reviewedPulls", m.ReviewedPulls}, {"labeled", m.Labeled},
+		{"assigned", m.Assigned}, {"issuesClosed", m.IssuesClosed}, {"discussionsStarted", m.DiscussionsStarted},
+		{"discussionComments", m.DiscussionComments}, {"discussionAnswers", m.DiscussionAnswers},
+		{"reverts", m.Reverts}, {"forcePushes", m.ForcePushes}, {"issueComments", m.IssueComments}, {"prComments", m.PRComments},
+		{"publicActivity", m.PublicActivity}, {"privateActivity", m.PrivateActivity}, {"internalActivity", m.InternalActivity},
+		{"offHoursCommits", m.OffHoursCommits}, {"offHoursReviews", m.OffHoursReviews}, {"submittedReviews", m.SubmittedReviews},
+		{"activeDays", m.ActiveDays}, {"longestStreak", m.LongestStreak},
+		{"pendingReviewRequests", m.PendingReviewRequests}, {"ignoredReviewRequests", m.IgnoredReviewRequests},
+		{"codeOwnerPulls", m.CodeOwnerPulls}, {"codeOwnerReviews", m.CodeOwnerReviews},
+		{"issuesClosedManually", m.IssuesClosedManually}, {"issuesFixed", m.IssuesFixed},
+		{"plannedIssues", m.PlannedIssues}, {"plannedPulls", m.PlannedPulls},
+		{"releasesPublished", m.ReleasesPublished}, {"tagsCreated", m.TagsCreated}, {"shippedIn", m.ShippedIn},
+		{"workflowsAdded", m.WorkflowsAdded}, {"workflowsModified", m.WorkflowsModified}, {"runsFixed", m.RunsFixed},
+		{"securityAlertsFixed", m.SecurityAlertsFixed}, {"securityAlertsDismissed", m.SecurityAlertsDismissed},
+		{"dependabotPullsMerged", m.DependabotPullsMerged}, {"dependabotPullsReviewed", m.DependabotPullsReviewed},
+		{"docs", m.Docs}, {"docsCommits", m.DocsCommits}, {"toneComments", m.ToneComments}, {"harshComments", m.HarshComments},
+	}
+	for _, count := range counts {
+		if count.value < 0 {
+			return fmt.Errorf("negative %s", count.name)
+		}
+	}
+	for repo, hoc := range m.Repos {
+		if hoc < 0 {
+			return fmt.Errorf("negative hoc in %s", repo)
+		}
+	}
+
+	numbers := []struct {
+		name  string
+		value float64
+	}{
+		{"lcp", m.LcP}, {"timeToFirstReview", m.TimeToFirstReview}, {"timeToTriage", m.TimeToTriage},
+		{"medianLcp", m.MedianLcP}, {"firstReviewWait", m.FirstReviewWait}, {"medianFirstReviewWait", m.MedianFirstReviewWait},
+		{"reviewRounds", m.ReviewRounds}, {"medianReviewRounds", m.MedianReviewRounds}, {"mergeWait", m.MergeWait}, {"medianMergeWait", m.MedianMergeWait},
+	}
+	for _, number := range numbers {
+		if number.value < 0 || math.IsNaN(number.value) || math.IsInf(number.value, 0) {
+			return fmt.Errorf("%s is %v", number.name, number.value)
+		}
+	}
+	if math.IsNaN(m.Score) || math.IsInf(m.Score, 0) {
+		return fmt.Errorf("score is %v", m.Score)
+	}
+	if m.Tone < -1 || m.Tone > 1 || math.IsNaN(m.Tone) {
+		return fmt.Errorf("tone is %v", m.Tone)
+	}
+	for _, metric := range m.Unknown {
+		known := false
+		for _, name := range AllMetrics {
+			known = known || name == metric
+		}
+		if !known {
+			return fmt.Errorf("unknown lists %q, which is not a metric", metric)
+		}
+	}
+	return nil
+}
+
+func (r ConcentrationReport) validate() error {
+	check := func(path string, c Concentration) error {
+		if c.Total < 0 || c.BusFactor < 0 || c.Gini < 0 || c.Gini > 1 || c.TopShare < 0 || c.TopShare > 100 {
+			return fmt.Errorf("%s: concentration out of range", path)
+		}
+		return nil
+	}
+	if err := check("concentration.hoc", r.HoC); err != nil {
+		return err
+	}
+	if err := check("concentration.pulls", r.Pulls); err != nil {
+		return err
+	}
+	for i, repo := range r.Repos {
+		if err := check(fmt.Sprintf("concentration.repos[%d].hoc", i), repo.HoC); err != nil {
+			return err
+		}
+		if err := check(fmt.Sprintf("concentration.repos[%d].pulls", i), repo.Pulls); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l ReviewLoad) validate() error {
+	if l.Balance < 0 || l.Balance > 100 {
+		return errors.New("reviewLoad.balance out of range")
+	}
+	for i, user := range l.Users {
+		if user.User == "" || user.Reviews < 0 || user.Pulls < 0 || user.Ratio < 0 {
+			return fmt.Errorf("reviewLoad.users[%d]: invalid balance", i)
+		}
+	}
+	for i, team := range l.Teams {
+		if team.Reviews < 0 || team.Pulls < 0 || team.Ratio < 0 || team.Balance < 0 || team.Balance > 100 {
+			return fmt.Errorf("reviewLoad.teams[%d] (%s): invalid balance", i, team.Team)
+		}
+	}
+	return nil
+}
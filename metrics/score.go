@@ -0,0 +1,153 @@
+package metrics
+
+import "math"
+
+// Scorer turns a user's metrics into a single comparable score.
+type Scorer interface {
+	Score(metrics UserMetrics) float64
+}
+
+// CohortScorer is a Scorer whose scores depend on the other users measured.
+// Calculate rescores every user with ScoreAll as their metrics come in.
+type CohortScorer interface {
+	Scorer
+	ScoreAll(metrics map[string]UserMetrics)
+}
+
+// Scoring modes, selecting WeightedScorer or PercentileScorer.
+const (
+	ScoringRaw        = "raw"
+	ScoringPercentile = "percentile"
+)
+
+// Weights are the multipliers WeightedScorer applies to each metric.
+type Weights struct {
+	HoC     float64
+	Pulls   float64
+	Issues  float64
+	Commits float64
+	Reviews float64
+	Msgs    float64
+	Reverts float64 // Usually negative, to penalize changes that had to be reverted
+
+	// Line metrics, not weighted by default
+	Deletions float64
+	Churn     float64
+	NetLines  float64
+
+	ActiveDays  float64 // Not weighted by default
+	IssuesFixed float64 // Not weighted by default
+	Planned     float64 // Not weighted by default; multiplies PlannedIssues plus PlannedPulls
+	Docs        float64 // Not weighted by default; multiplies the HoC of documentation
+}
+
+// DefaultWeights are the multipliers of DefaultScorer.
+var DefaultWeights = Weights{HoC: 1, Pulls: 250, Issues: 50, Commits: 5, Reviews: 150, Msgs: 5}
+
+// WeightedScorer sums the metrics multiplied by their weights.
+type WeightedScorer struct {
+	Weights Weights
+}
+
+func (s WeightedScorer) Score(metrics UserMetrics) float64 {
+	w := s.Weights
+	return float64(metrics.HoC)*w.HoC + float64(metrics.Pulls)*w.Pulls + float64(metrics.Issues)*w.Issues + float64(metrics.Commits)*w.Commits + float64(metrics.Reviews)*w.Reviews + float64(metrics.Msgs)*w.Msgs + float64(metrics.Reverts)*w.Reverts +
+		float64(metrics.Deletions)*w.Deletions + float64(metrics.Churn)*w.Churn + float64(metrics.NetLines)*w.NetLines +
+		float64(metrics.ActiveDays)*w.ActiveDays + float64(metrics.IssuesFixed)*w.IssuesFixed +
+		float64(metrics.PlannedIssues+metrics.PlannedPulls)*w.Planned + float64(metrics.Docs)*w.Docs
+}
+
+// DefaultScorer is the arithmetic summary of all metrics:
+// 1×HoC + 250×Pulls + 50×Issues + 5×Commits + 150×Reviews + 5×Msgs.
+type DefaultScorer struct{}
+
+func (DefaultScorer) Score(metrics UserMetrics) float64 {
+	return WeightedScorer{Weights: DefaultWeights}.Score(metrics)
+}
+
+// PercentileWeights are the multipliers of PercentileScorer by default:
+// every metric counts the same, except reverts, which do not count.
+var PercentileWeights = Weights{HoC: 1, Pulls: 1, Issues: 1, Commits: 1, Reviews: 1, Msgs: 1}
+
+// PercentileScorer ranks each metric across the users measured, from 0 for
+// the lowest value to 100 for the highest, and scores the weighted average
+// of the ranks. An outlier in one metric, such as a vendored import in HoC,
+// then counts no more than being first in any other metric.
+type PercentileScorer struct {
+	Weights Weights
+}
+
+// Score is the weighted sum of the metrics, standing in until ScoreAll has
+// ranked the user against the others.
+func (s PercentileScorer) Score(metrics UserMetrics) float64 {
+	return WeightedScorer{Weights: s.Weights}.Score(metrics)
+}
+
+// ScoreAll sets the score of every user to the weighted average of their
+// percentile ranks.
+func (s PercentileScorer) ScoreAll(metrics map[string]UserMetrics) {
+	w := s.Weights
+	fields := []struct {
+		weight float64
+		value  func(UserMetrics) int
+	}{
+		{w.HoC, func(m UserMetrics) int { return m.HoC }},
+		{w.Pulls, func(m UserMetrics) int { return m.Pulls }},
+		{w.Issues, func(m UserMetrics) int { return m.Issues }},
+		{w.Commits, func(m UserMetrics) int { return m.Commits }},
+		{w.Reviews, func(m UserMetrics) int { return m.Reviews }},
+		{w.Msgs, func(m UserMetrics) int { return m.Msgs }},
+		{w.Reverts, func(m UserMetrics) int { return m.Reverts }},
+		{w.Deletions, func(m UserMetrics) int { return m.Deletions }},
+		{w.Churn, func(m UserMetrics) int { return m.Churn }},
+		{w.NetLines, func(m UserMetrics) int { return m.NetLines }},
+		{w.ActiveDays, func(m UserMetrics) int { return m.ActiveDays }},
+		{w.IssuesFixed, func(m UserMetrics) int { return m.IssuesFixed }},
+		{w.Planned, func(m UserMetrics) int { return m.PlannedIssues + m.PlannedPulls }},
+		{w.Docs, func(m UserMetrics) int { return m.Docs }},
+	}
+
+	var total float64
+	scores := make(map[string]float64, len(metrics))
+	for _, f := range fields {
+		if f.weight == 0 {
+			continue
+		}
+		total += math.Abs(f.weight)
+		values := make([]int, 0, len(metrics))
+		for _, m := range metrics {
+			values = append(values, f.value(m))
+		}
+		for user, m := range metrics {
+			scores[user] += f.weight * percentile(values, f.value(m))
+		}
+	}
+	for user, m := range metrics {
+		m.Score = 0
+		if total > 0 {
+			m.Score = scores[user] / total
+		}
+		metrics[user] = m
+	}
+}
+
+// percentile ranks v among values, which include it, from 0 to 100. Tied
+// users share the middle of their ranks, and a value of zero ranks 0 so
+// that no activity earns nothing.
+func percentile(values []int, v int) float64 {
+	if v <= 0 {
+		return 0
+	}
+	if len(values) < 2 {
+		return 100
+	}
+	below, equal := 0, 0
+	for _, x := range values {
+		if x < v {
+			below++
+		} else if x == v {
+			equal++
+		}
+	}
+	return 100 * (float64(below) + float64(equal-1)/2) / float64(len(values)-1)
+}
@@ -0,0 +1,178 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// DependabotLogin is the account Dependabot opens its pull requests as.
+const DependabotLogin = "dependabot[bot]"
+
+// securityAlert is a Dependabot or code scanning alert closed during the
+// window, with who closed it.
+type securityAlert struct {
+	id    string // Kind and number, e.g. dependabot#12
+	url   string
+	fixed bool   // Fixed by a commit rather than dismissed
+	login string // Who dismissed the alert, or authored the commit that fixed it
+	email string // Email of the commit that fixed the alert
+}
+
+// security counts the security alerts the user dismissed or fixed during
+// the window and the Dependabot pull requests the user merged or reviewed.
+// It is opt-in with Security, as the security APIs need permissions most
+// tokens lack, and returns nothing otherwise.
+func (c *GitHubCollector) security(ctx context.Context, owner, repo, user string) UserMetrics {
+	var m UserMetrics
+	if !c.Security {
+		return m
+	}
+	alerts, err := c.securityAlerts(ctx, owner, repo)
+	if err != nil {
+		logError(ctx, err, "fetching security alerts in repo %s/%s", owner, repo)
+	}
+	for _, alert := range alerts {
+		if !c.Identities.IsCommitAuthor(user, alert.login, alert.email) {
+			continue
+		}
+		kind := "alert_dismissed"
+		if alert.fixed {
+			m.SecurityAlertsFixed++
+			kind = "alert_fixed"
+		} else {
+			m.SecurityAlertsDismissed++
+		}
+		c.Evidence.Add(user, EvidenceItem{Metric: MetricSecurity, Kind: kind, Repo: owner + "/" + repo, ID: alert.id, URL: alert.url})
+	}
+
+	events, err := c.issueEvents(ctx, owner, repo)
+	if err != nil {
+		logError(ctx, err, "fetching issue events in repo %s/%s", owner, repo)
+	}
+	for _, event := range events {
+		issue := event.GetIssue()
+		if event.GetEvent() == "merged" && issue.IsPullRequest() && issue.GetUser().GetLogin() == DependabotLogin && strings.EqualFold(event.GetActor().GetLogin(), user) && c.inWindow(event.GetCreatedAt().Time) {
+			m.DependabotPullsMerged++
+			c.Evidence.Add(user, EvidenceItem{Metric: MetricSecurity, Kind: "dependabot_merged", Repo: owner + "/" + repo, ID: strconv.Itoa(issue.GetNumber()), URL: issue.GetHTMLURL()})
+		}
+	}
+	pulls, err := c.reviewedPulls(ctx, owner, repo, user)
+	if err != nil {
+		logError(ctx, err, "fetching reviewed pull requests for user %s in repo %s/%s", user, owner, repo)
+	}
+	for _, pull := range pulls {
+		if pull.author == DependabotLogin {
+			m.DependabotPullsReviewed++
+			c.Evidence.Add(user, EvidenceItem{Metric: MetricSecurity, Kind: "dependabot_reviewed", Repo: owner + "/" + repo, ID: strconv.Itoa(pull.number), URL: pull.url})
+		}
+	}
+	if c.Verbose && (m.SecurityAlertsFixed > 0 || m.SecurityAlertsDismissed > 0 || m.DependabotPullsMerged > 0 || m.DependabotPullsReviewed > 0) {
+		log.Printf("User %s in repo %s/%s: %d security alerts fixed, %d dismissed, %d Dependabot pull requests merged, %d reviewed\n", user, owner, repo, m.SecurityAlertsFixed, m.SecurityAlertsDismissed, m.DependabotPullsMerged, m.DependabotPullsReviewed)
+	}
+	return m
+}
+
+// securityAlerts lists the Dependabot and code scanning alerts of the
+// repository dismissed or fixed during the window, once for every user.
+// Dependabot does not record what fixed an alert, so only its dismissed
+// alerts are listed; a fixed code scanning alert is credited to the author
+// of the commit in which it was last seen fixed. Either kind of alert may be
+// disabled for the repository, so both are listed and the alerts found are
+// returned with the first error.
+func (c *GitHubCollector) securityAlerts(ctx context.Context, owner, repo string) ([]securityAlert, error) {
+	result, err := c.shared.do("security-alerts/"+owner+"/"+repo, func() (interface{}, error) {
+		alerts, firstErr := c.dependabotAlerts(ctx, owner, repo)
+		for _, state := range []string{"dismissed", "fixed"} {
+			found, err := c.codeScanningAlerts(ctx, owner, repo, state)
+			alerts = append(alerts, found...)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				// Fixed alerts are as unavailable as dismissed ones.
+				break
+			}
+		}
+		return alerts, firstErr
+	})
+	return result.([]securityAlert), err
+}
+
+// dependabotAlerts lists the Dependabot alerts dismissed during the window.
+// Alerts are listed by last update, newest first, until they were last
+// updated before the window started.
+func (c *GitHubCollector) dependabotAlerts(ctx context.Context, owner, repo string) ([]securityAlert, error) {
+	var alerts []securityAlert
+	state, sort, direction := "dismissed", "updated", "desc"
+	opts := &github.ListAlertsOptions{State: &state, Sort: &sort, Direction: &direction, ListCursorOptions: github.ListCursorOptions{PerPage: 100}}
+	for {
+		result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/dependabot/alerts", func() (interface{}, *github.Response, error) {
+			return c.api().ListDependabotAlerts(ctx, owner, repo, opts)
+		})
+		if err != nil {
+			return alerts, err
+		}
+		for _, alert := range result.([]*github.DependabotAlert) {
+			if alert.GetUpdatedAt().Before(c.Since) {
+				return alerts, nil
+			}
+			if alert.DismissedAt != nil && c.inWindow(alert.DismissedAt.Time) {
+				alerts = append(alerts, securityAlert{id: "dependabot#" + strconv.Itoa(alert.GetNumber()), url: alert.GetHTMLURL(), login: alert.GetDismissedBy().GetLogin()})
+			}
+		}
+		if resp.After == "" {
+			return alerts, nil
+		}
+		opts.After = resp.After
+	}
+}
+
+// codeScanningAlerts lists the code scanning alerts in state, dismissed or
+// fixed, that were closed during the window.
+func (c *GitHubCollector) codeScanningAlerts(ctx context.Context, owner, repo, state string) ([]securityAlert, error) {
+	var alerts []securityAlert
+	opts := &github.AlertListOptions{State: state, ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/code-scanning/alerts", func() (interface{}, *github.Response, error) {
+			return c.api().ListCodeScanningAlerts(ctx, owner, repo, opts)
+		})
+		if err != nil {
+			return alerts, err
+		}
+		for _, alert := range result.([]*github.Alert) {
+			closed := func(at *github.Timestamp) bool { return at != nil && c.inWindow(at.Time) }
+			switch {
+			case state == "dismissed" && closed(alert.DismissedAt):
+				alerts = append(alerts, securityAlert{id: "code-scanning#" + strconv.Itoa(alert.GetNumber()), url: alert.GetHTMLURL(), login: alert.GetDismissedBy().GetLogin()})
+			case state == "fixed" && closed(alert.FixedAt):
+				found := securityAlert{id: "code-scanning#" + strconv.Itoa(alert.GetNumber()), url: alert.GetHTMLURL(), fixed: true}
+				if sha := alert.GetMostRecentInstance().GetCommitSHA(); sha != "" {
+					found.login, found.email = c.commitAuthor(ctx, owner, repo, sha)
+				}
+				alerts = append(alerts, found)
+			}
+		}
+		if resp.NextPage == 0 {
+			return alerts, nil
+		}
+		opts.ListOptions.Page = resp.NextPage
+	}
+}
+
+// commitAuthor returns the login and email of a commit's author, or nothing
+// when the commit cannot be fetched.
+func (c *GitHubCollector) commitAuthor(ctx context.Context, owner, repo, sha string) (login, email string) {
+	result, _, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/commits/{ref}", func() (interface{}, *github.Response, error) {
+		return c.api().GetCommit(ctx, owner, repo, sha)
+	})
+	if err != nil {
+		logError(ctx, err, "fetching commit %s in repo %s/%s", sha, owner, repo)
+		return "", ""
+	}
+	commit := result.(*github.RepositoryCommit)
+	return commit.GetAuthor().GetLogin(), commit.GetCommit().GetAuthor().GetEmail()
+}
@@ -0,0 +1,39 @@
+package metrics
+
+import "sync"
+
+// shared runs each keyed request once per run and hands its result to every
+// collection task that needs it. Metrics derived from the same API objects,
+// such as commits and HoC from a user's commits, or the issues and LcP from
+// the issues a user opened, then fetch them once however they are split
+// into tasks.
+type shared struct {
+	mu      sync.Mutex
+	entries map[string]*sharedEntry
+}
+
+type sharedEntry struct {
+	once  sync.Once
+	value interface{}
+	err   error
+}
+
+// do returns the result of fetch for key, calling it on first use only.
+// Concurrent callers of the same key wait for the first one to finish.
+func (s *shared) do(key string, fetch func() (interface{}, error)) (interface{}, error) {
+	s.mu.Lock()
+	if s.entries == nil {
+		s.entries = make(map[string]*sharedEntry)
+	}
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &sharedEntry{}
+		s.entries[key] = entry
+	}
+	s.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.value, entry.err = fetch()
+	})
+	return entry.value, entry.err
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import "sort"
+
+// PullSizeBucket is a range of lines changed (additions plus deletions) that
+// a merged pull request falls into. Max is exclusive; 0 means unbounded.
+type PullSizeBucket struct {
+	Label string
+	Max   int
+}
+
+// PullSizeBuckets are the size classes pull requests are grouped into,
+// smallest first.
+var PullSizeBuckets = []PullSizeBucket{
+	{Label: "XS", Max: 10},
+	{Label: "S", Max: 50},
+	{Label: "M", Max: 250},
+	{Label: "L", Max: 1000},
+	{Label: "XL"},
+}
+
+// PullSizeCount is the number of pull requests in a bucket and their share
+// of the total, as a whole percentage.
+type PullSizeCount struct {
+	Label   string `json:"label"`
+	Count   int    `json:"count"`
+	Percent int    `json:"percent"`
+}
+
+// PullSizeLabel returns the label of the bucket a pull request changing the
+// given number of lines falls into.
+func PullSizeLabel(lines int) string {
+	for _, bucket := range PullSizeBuckets {
+		if bucket.Max == 0 || lines < bucket.Max {
+			return bucket.Label
+		}
+	}
+	return PullSizeBuckets[len(PullSizeBuckets)-1].Label
+}
+
+// PullSizeDistribution counts the user's merged pull requests per size
+// bucket, in PullSizeBuckets order.
+func (m UserMetrics) PullSizeDistribution() []PullSizeCount {
+	counts := make([]PullSizeCount, len(PullSizeBuckets))
+	index := make(map[string]int, len(PullSizeBuckets))
+	for i, bucket := range PullSizeBuckets {
+		counts[i].Label = bucket.Label
+		index[bucket.Label] = i
+	}
+	for _, lines := range m.PullSizes {
+		counts[index[PullSizeLabel(lines)]].Count++
+	}
+	if n := len(m.PullSizes); n > 0 {
+		for i := range counts {
+			counts[i].Percent = counts[i].Count * 100 / n
+		}
+	}
+	return counts
+}
+
+// MedianPullSize returns the median lines changed across the user's merged
+// pull requests, or 0 when there are none.
+func (m UserMetrics) MedianPullSize() int {
+	n := len(m.PullSizes)
+	if n == 0 {
+		return 0
+	}
+	sizes := append([]int(nil), m.PullSizes...)
+	sort.Ints(sizes)
+	if n%2 == 1 {
+		return sizes[n/2]
+	}
+	return (sizes[n/2-1] + sizes[n/2]) / 2
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SlackNotifier posts a run summary to a Slack incoming webhook.
+type SlackNotifier struct {
+	WebhookURL string
+	Client     *http.Client // Defaults to http.DefaultClient
+}
+
+// Notify posts the summary as a message with the totals, the biggest movers
+// and the leaderboard.
+func (n SlackNotifier) Notify(ctx context.Context, summary Summary) error {
+	return postJSON(ctx, n.Client, n.WebhookURL, slackMessage(summary))
+}
+
+func slackMessage(s Summary) map[string]interface{} {
+	title := "GitHub Metrics"
+	if s.Organization != "" {
+		title += " for " + s.Organization
+	}
+	section := func(text string) map[string]interface{} {
+		return map[string]interface{}{
+			"type": "section",
+			"text": map[string]string{"type": "mrkdwn", "text": text},
+		}
+	}
+
+	blocks := []map[string]interface{}{
+		{"type": "header", "text": map[string]string{"type": "plain_text", "text": title}},
+		section(fmt.Sprintf("Activity %s · %d users\n*Totals:* %s", s.Window, s.Users, summaryTotals(s.Totals))),
+	}
+	if len(s.Inactive) > 0 {
+		users := slackEscape(strings.Join(s.Inactive, ", "))
+		text := "*No activity:* " + users
+		if s.Alert {
+			text = fmt.Sprintf(":warning: *%d users had no activity:* %s", len(s.Inactive), users)
+		}
+		blocks = append(blocks, section(text))
+	}
+	if len(s.Movers) > 0 {
+		var b strings.Builder
+		b.WriteString("*Top movers*")
+		for _, view := range s.Movers {
+			score, _ := formatNumber(view.Metrics.Score)
+			fmt.Fprintf(&b, "\n• *%s* %s (%s)", slackEscape(view.User), score, view.Delta.Score)
+		}
+		blocks = append(blocks, section(b.String()))
+	}
+	if len(s.Leaders) > 0 {
+		var b strings.Builder
+		b.WriteString("*Leaderboard*")
+		for _, view := range s.Leaders {
+			score, _ := formatNumber(view.Metrics.Score)
+			fmt.Fprintf(&b, "\n%d. *%s* %s", view.Rank, slackEscape(view.User), score)
+		}
+		blocks = append(blocks, section(b.String()))
+	}
+
+	return map[string]interface{}{
+		"text":   fmt.Sprintf("%s: activity %s", title, s.Window),
+		"blocks": blocks,
+	}
+}
+
+// summaryTotals formats the headline totals of a summary.
+func summaryTotals(m UserMetrics) string {
+	hoc, _ := formatNumber(m.HoC)
+	return fmt.Sprintf("%d commits · %s HoC · %d pull requests · %d reviews · %d issues", m.Commits, hoc, m.Pulls, m.Reviews, m.Issues)
+}
+
+// slackEscape escapes the characters Slack treats as markup.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,388 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id       INTEGER PRIMARY KEY AUTOINCREMENT,
+	taken_at TIMESTAMP NOT NULL,
+	since    TIMESTAMP NOT NULL,
+	metric   TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS user_metrics (
+	run_id  INTEGER NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+	user    TEXT NOT NULL,
+	commits INTEGER NOT NULL,
+	hoc     INTEGER NOT NULL,
+	issues  INTEGER NOT NULL,
+	lcp     REAL NOT NULL,
+	msgs    INTEGER NOT NULL,
+	pulls   INTEGER NOT NULL,
+	reviews INTEGER NOT NULL,
+	score   REAL NOT NULL,
+	repos   TEXT NOT NULL,
+	metrics TEXT NOT NULL DEFAULT '{}',
+	PRIMARY KEY (run_id, user)
+);
+CREATE TABLE IF NOT EXISTS pending_reviews (
+	run_id      INTEGER NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+	pull        TEXT NOT NULL,
+	user        TEXT NOT NULL,
+	reviewed_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (run_id, pull, user)
+);
+`
+
+// SQLiteStore is a Store backed by a SQLite database file.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLiteStore opens or creates the database at path.
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite3", path+"?_foreign_keys=on")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := migrateSQLite(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// migrateSQLite upgrades databases created before the metrics column, which
+// holds the complete UserMetrics as JSON next to the core columns.
+func migrateSQLite(db *sql.DB) error {
+	rows, err := db.Query(`PRAGMA table_info(user_metrics)`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, typ string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
+			return err
+		}
+		if name == "metrics" {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	_, err = db.Exec(`ALTER TABLE user_metrics ADD COLUMN metrics TEXT NOT NULL DEFAULT '{}'`)
+	return err
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) Save(ctx context.Context, snapshot *Snapshot) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO runs (taken_at, since, metric) VALUES (?, ?, ?)`,
+		snapshot.TakenAt.UTC(), snapshot.Since.UTC(), snapshot.Metric)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO user_metrics
+		(run_id, user, commits, hoc, issues, lcp, msgs, pulls, reviews, score, repos, metrics)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for user, m := range snapshot.Users {
+		repos, err := json.Marshal(m.Repos)
+		if err != nil {
+			return err
+		}
+		all, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.ExecContext(ctx, id, user, m.Commits, m.HoC, m.Issues, m.LcP, m.Msgs, m.Pulls, m.Reviews, m.Score, string(repos), string(all)); err != nil {
+			return err
+		}
+	}
+	if err := savePendingReviews(ctx, tx, id, snapshot.PendingReviews); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	snapshot.ID = id
+	return nil
+}
+
+func (s *SQLiteStore) Update(ctx context.Context, snapshot *Snapshot, users []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE runs SET taken_at = ? WHERE id = ?`, snapshot.TakenAt.UTC(), snapshot.ID); err != nil {
+		return err
+	}
+	stmt, err := tx.PrepareContext(ctx, `INSERT OR REPLACE INTO user_metrics
+		(run_id, user, commits, hoc, issues, lcp, msgs, pulls, reviews, score, repos, metrics)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, user := range users {
+		m := snapshot.Users[user]
+		repos, err := json.Marshal(m.Repos)
+		if err != nil {
+			return err
+		}
+		all, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.ExecContext(ctx, snapshot.ID, user, m.Commits, m.HoC, m.Issues, m.LcP, m.Msgs, m.Pulls, m.Reviews, m.Score, string(repos), string(all)); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM pending_reviews WHERE run_id = ?`, snapshot.ID); err != nil {
+		return err
+	}
+	if err := savePendingReviews(ctx, tx, snapshot.ID, snapshot.PendingReviews); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// savePendingReviews inserts the pending reviews of a snapshot.
+func savePendingReviews(ctx context.Context, tx *sql.Tx, id int64, reviews []PendingReview) error {
+	if len(reviews) == 0 {
+		return nil
+	}
+	stmt, err := tx.PrepareContext(ctx, `INSERT OR REPLACE INTO pending_reviews (run_id, pull, user, reviewed_at) VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, review := range reviews {
+		if _, err := stmt.ExecContext(ctx, id, review.Pull, review.User, review.ReviewedAt.UTC()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Latest(ctx context.Context, metric string) (*Snapshot, error) {
+	snapshot := &Snapshot{Metric: metric, Users: make(map[string]UserMetrics)}
+	err := s.db.QueryRowContext(ctx, `SELECT id, taken_at, since FROM runs
+		WHERE metric = ? ORDER BY taken_at DESC, id DESC LIMIT 1`, metric).
+		Scan(&snapshot.ID, &snapshot.TakenAt, &snapshot.Since)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, s.loadUsers(ctx, snapshot)
+}
+
+func (s *SQLiteStore) History(ctx context.Context, metric string, from, to time.Time) ([]*Snapshot, error) {
+	query, args := `SELECT id, taken_at, since FROM runs WHERE metric = ?`, []interface{}{metric}
+	if !from.IsZero() {
+		query, args = query+` AND taken_at >= ?`, append(args, from.UTC())
+	}
+	if !to.IsZero() {
+		query, args = query+` AND taken_at < ?`, append(args, to.UTC())
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY taken_at, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []*Snapshot
+	for rows.Next() {
+		snapshot := &Snapshot{Metric: metric, Users: make(map[string]UserMetrics)}
+		if err := rows.Scan(&snapshot.ID, &snapshot.TakenAt, &snapshot.Since); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots {
+		if err := s.loadUsers(ctx, snapshot); err != nil {
+			return nil, err
+		}
+	}
+	return snapshots, nil
+}
+
+// loadUsers reads the metrics of the snapshot's users and its pending
+// reviews.
+func (s *SQLiteStore) loadUsers(ctx context.Context, snapshot *Snapshot) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT user, commits, hoc, issues, lcp, msgs, pulls, reviews, score, repos, metrics
+		FROM user_metrics WHERE run_id = ?`, snapshot.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var user, repos, all string
+		var m UserMetrics
+		if err := rows.Scan(&user, &m.Commits, &m.HoC, &m.Issues, &m.LcP, &m.Msgs, &m.Pulls, &m.Reviews, &m.Score, &repos, &all); err != nil {
+			return err
+		}
+		// The metrics column holds every field; rows written before it
+		// existed keep "{}" and load from the core columns alone.
+		if err := json.Unmarshal([]byte(all), &m); err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(repos), &m.Repos); err != nil {
+			return err
+		}
+		snapshot.Users[user] = m
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return s.loadPendingReviews(ctx, snapshot)
+}
+
+// loadPendingReviews reads the pending reviews of the snapshot.
+func (s *SQLiteStore) loadPendingReviews(ctx context.Context, snapshot *Snapshot) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT pull, user, reviewed_at FROM pending_reviews
+		WHERE run_id = ? ORDER BY pull, user`, snapshot.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var review PendingReview
+		if err := rows.Scan(&review.Pull, &review.User, &review.ReviewedAt); err != nil {
+			return err
+		}
+		snapshot.PendingReviews = append(snapshot.PendingReviews, review)
+	}
+	return rows.Err()
+}
+
+func (s *SQLiteStore) Scores(ctx context.Context, metric string) ([]ScoreSeries, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT u.user, r.taken_at, u.score
+		FROM runs r JOIN user_metrics u ON u.run_id = r.id
+		WHERE r.metric = ? ORDER BY u.user, r.taken_at, r.id`, metric)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scores []ScoreSeries
+	for rows.Next() {
+		var user string
+		var point ScorePoint
+		if err := rows.Scan(&user, &point.TakenAt, &point.Score); err != nil {
+			return nil, err
+		}
+		if len(scores) == 0 || scores[len(scores)-1].User != user {
+			scores = append(scores, ScoreSeries{User: user})
+		}
+		scores[len(scores)-1].Points = append(scores[len(scores)-1].Points, point)
+	}
+	return scores, rows.Err()
+}
+
+func (s *SQLiteStore) Snapshots(ctx context.Context) ([]SnapshotInfo, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT r.id, r.taken_at, r.since, r.metric, COUNT(u.user)
+		FROM runs r LEFT JOIN user_metrics u ON u.run_id = r.id
+		GROUP BY r.id ORDER BY r.taken_at, r.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var infos []SnapshotInfo
+	for rows.Next() {
+		var info SnapshotInfo
+		if err := rows.Scan(&info.ID, &info.TakenAt, &info.Since, &info.Metric, &info.Users); err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, rows.Err()
+}
+
+func (s *SQLiteStore) Prune(ctx context.Context, keep int) (int, error) {
+	if keep < 0 {
+		keep = 0
+	}
+	res, err := s.db.ExecContext(ctx, `DELETE FROM runs WHERE id NOT IN (
+		SELECT id FROM runs ORDER BY taken_at DESC, id DESC LIMIT ?)`, keep)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (s *SQLiteStore) Purge(ctx context.Context, before time.Time) (int, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM runs WHERE taken_at < ?`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return s.erased(ctx, res)
+}
+
+func (s *SQLiteStore) DeleteUser(ctx context.Context, user string) (int, error) {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM pending_reviews WHERE user = ? COLLATE NOCASE`, user); err != nil {
+		return 0, err
+	}
+	res, err := s.db.ExecContext(ctx, `DELETE FROM user_metrics WHERE user = ? COLLATE NOCASE`, user)
+	if err != nil {
+		return 0, err
+	}
+	return s.erased(ctx, res)
+}
+
+// erased returns the rows a deletion affected after vacuuming the database,
+// as SQLite otherwise keeps deleted rows in free pages of the file until
+// they are reused.
+func (s *SQLiteStore) erased(ctx context.Context, res sql.Result) (int, error) {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		if _, err := s.db.ExecContext(ctx, `VACUUM`); err != nil {
+			return int(n), err
+		}
+	}
+	return int(n), nil
+}
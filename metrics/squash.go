@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// squashedCommit is a commit on the default branch that squash-merged a pull
+// request but is authored by someone else than the pull request's author,
+// usually whoever merged it.
+type squashedCommit struct {
+	commit *github.RepositoryCommit
+	author string // Author of the pull request
+	number int    // Number of the pull request
+}
+
+// attributeSquashes moves squash-merged commits from the users who merged
+// them to the authors of their pull requests: commits is the list of the
+// user's commits, without those squashing someone else's pull request and
+// with those squashing the user's own.
+func (c *GitHubCollector) attributeSquashes(ctx context.Context, owner, repo, user string, commits []*github.RepositoryCommit) ([]*github.RepositoryCommit, error) {
+	squashed, err := c.squashedCommits(ctx, owner, repo)
+	moved := make(map[string]bool, len(squashed))
+	for _, s := range squashed {
+		moved[s.commit.GetSHA()] = true
+	}
+	var kept []*github.RepositoryCommit
+	for _, commit := range commits {
+		if !moved[commit.GetSHA()] {
+			kept = append(kept, commit)
+		}
+	}
+	for _, s := range squashed {
+		if c.Identities.IsCommitAuthor(user, s.author, "") {
+			if c.Verbose {
+				log.Printf("Commit %s squash-merging pull request #%d in repo %s/%s attributed to %s\n", s.commit.GetSHA(), s.number, owner, repo, user)
+			}
+			kept = append(kept, s.commit)
+		}
+	}
+	return kept, err
+}
+
+// squashedCommits finds the commits of the window that squash-merged a pull
+// request under another author than the pull request's, once per repository.
+// A merge commit is taken for a squash when its author wrote none of the
+// pull request's commits; otherwise it is a rebased commit of the pull
+// request, or a squash by one of its contributors, and keeps its author.
+func (c *GitHubCollector) squashedCommits(ctx context.Context, owner, repo string) ([]squashedCommit, error) {
+	result, err := c.shared.do("squashed/"+owner+"/"+repo, func() (interface{}, error) {
+		commits, err := c.repoCommits(ctx, owner, repo)
+		if err != nil {
+			return []squashedCommit(nil), err
+		}
+		bySHA := make(map[string]*github.RepositoryCommit, len(commits))
+		for _, commit := range commits {
+			bySHA[commit.GetSHA()] = commit
+		}
+		pulls, err := c.repoMergedPulls(ctx, owner, repo)
+		var squashed []squashedCommit
+		for _, pull := range pulls {
+			commit := bySHA[pull.mergeSHA]
+			if commit == nil || isMergeCommit(commit) {
+				continue
+			}
+			merger := commit.GetAuthor().GetLogin()
+			if strings.EqualFold(merger, pull.author) {
+				continue
+			}
+			authors, pullErr := c.pullCommitAuthors(ctx, owner, repo, pull.number)
+			if pullErr != nil {
+				logError(ctx, pullErr, "fetching commits of pull request #%d in repo %s/%s", pull.number, owner, repo)
+				continue
+			}
+			if merger != "" && containsFold(authors, merger) {
+				continue
+			}
+			squashed = append(squashed, squashedCommit{commit: commit, author: pull.author, number: pull.number})
+		}
+		sort.Slice(squashed, func(i, j int) bool { return squashed[i].number < squashed[j].number })
+		return squashed, err
+	})
+	return result.([]squashedCommit), err
+}
+
+// pullCommitAuthors returns the logins of the authors of a pull request's
+// commits, as they were before merging.
+func (c *GitHubCollector) pullCommitAuthors(ctx context.Context, owner, repo string, number int) ([]string, error) {
+	var authors []string
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/pulls/{pull_number}/commits", func() (interface{}, *github.Response, error) {
+			return c.api().ListPullCommits(ctx, owner, repo, number, opts)
+		})
+		if err != nil {
+			return authors, err
+		}
+		for _, commit := range result.([]*github.RepositoryCommit) {
+			if login := commit.GetAuthor().GetLogin(); login != "" {
+				authors = append(authors, login)
+			}
+		}
+		if resp.NextPage == 0 {
+			return authors, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
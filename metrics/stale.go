@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// DefaultStaleDays is how many days a pull request stays open before it is
+// reported as stale by default.
+const DefaultStaleDays = 14
+
+// OpenPull is a pull request still open when the metrics were collected.
+type OpenPull struct {
+	Repo      string    `json:"repo"`
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// StalePulls is a row of the stale pull request report: a user's pull
+// requests in a repository open for longer than the threshold.
+type StalePulls struct {
+	User   string
+	Repo   string
+	Count  int
+	Oldest OpenPull
+	Age    float64 // Hours the oldest pull request has been open
+}
+
+// stalePulls lists the user's pull requests in the repository that are
+// still open and were opened more than StaleDays ago, or nothing when
+// StaleDays is not set.
+func (c *GitHubCollector) stalePulls(ctx context.Context, owner, repo, user string) []OpenPull {
+	if c.StaleDays <= 0 {
+		return nil
+	}
+	pulls, err := c.openPulls(ctx, owner, repo)
+	if err != nil {
+		logError(ctx, err, "fetching open pull requests in repo %s/%s", owner, repo)
+	}
+	cutoff := time.Now().AddDate(0, 0, -c.StaleDays)
+	var stale []OpenPull
+	for _, pull := range pulls {
+		if strings.EqualFold(pull.GetUser().GetLogin(), user) && pull.GetCreatedAt().Before(cutoff) && c.Labels.MatchPull(labelNames(pull.Labels)) {
+			stale = append(stale, OpenPull{
+				Repo:      owner + "/" + repo,
+				Number:    pull.GetNumber(),
+				Title:     pull.GetTitle(),
+				URL:       pull.GetHTMLURL(),
+				CreatedAt: pull.GetCreatedAt().Time,
+			})
+		}
+	}
+	return stale
+}
+
+// openPulls lists the open pull requests of the repository, once for every
+// user. The pull requests fetched before an error are returned with it.
+func (c *GitHubCollector) openPulls(ctx context.Context, owner, repo string) ([]*github.PullRequest, error) {
+	result, err := c.shared.do("open-pulls/"+owner+"/"+repo, func() (interface{}, error) {
+		var pulls []*github.PullRequest
+		opts := &github.PullRequestListOptions{State: "open", ListOptions: github.ListOptions{PerPage: 100}}
+		for {
+			result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/pulls", func() (interface{}, *github.Response, error) {
+				return c.api().ListPullRequests(ctx, owner, repo, opts)
+			})
+			if err != nil {
+				return pulls, err
+			}
+			pulls = append(pulls, result.([]*github.PullRequest)...)
+			if resp.NextPage == 0 {
+				return pulls, nil
+			}
+			opts.Page = resp.NextPage
+		}
+	})
+	return result.([]*github.PullRequest), err
+}
+
+// StalePullViews groups the stale pull requests of the users by repository,
+// with their age at the time given, oldest first.
+func StalePullViews(views []UserMetricsView, at time.Time) []StalePulls {
+	var rows []StalePulls
+	for _, view := range views {
+		byRepo := make(map[string]int)
+		for _, pull := range view.Metrics.StalePulls {
+			i, ok := byRepo[pull.Repo]
+			if !ok {
+				i = len(rows)
+				byRepo[pull.Repo] = i
+				rows = append(rows, StalePulls{User: view.User, Repo: pull.Repo, Oldest: pull})
+			}
+			rows[i].Count++
+			if pull.CreatedAt.Before(rows[i].Oldest.CreatedAt) {
+				rows[i].Oldest = pull
+			}
+		}
+	}
+	for i := range rows {
+		rows[i].Age = at.Sub(rows[i].Oldest.CreatedAt).Hours()
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Oldest.CreatedAt.Before(rows[j].Oldest.CreatedAt)
+	})
+	return rows
+}
@@ -0,0 +1,133 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// Sources of the HoC of GitHubCollector.
+const (
+	// HoCSourceCommits sums the files of every commit of the user, a
+	// request per commit.
+	HoCSourceCommits = "commits"
+	// HoCSourceStats reads the weekly additions and deletions of each
+	// contributor from the repository statistics, a request per repository.
+	// It counts whole weeks, cannot filter files and only knows the commits
+	// linked to an account.
+	HoCSourceStats = "stats"
+)
+
+// statsAttempts is how many times repository statistics are requested while
+// GitHub is still computing them, waiting twice as long each time.
+const statsAttempts = 6
+
+// statsWeek is how long a week of the repository statistics lasts.
+const statsWeek = 7 * 24 * time.Hour
+
+// statsHoC returns the user's HoC and line metrics in the repository from
+// the contributor statistics, and false when they are not available, for the caller to
+// fall back to the commits.
+func (c *GitHubCollector) statsHoC(ctx context.Context, owner, repo, user string) (UserMetrics, bool) {
+	stats, err := c.contributorStats(ctx, owner, repo)
+	if err != nil {
+		log.Printf("Repository statistics of %s/%s unavailable, counting HoC from commits: %v\n", owner, repo, err)
+		return UserMetrics{}, false
+	}
+
+	logins := []string{user}
+	for _, alias := range c.Identities.Aliases(user) {
+		if !strings.Contains(alias, "@") {
+			logins = append(logins, alias)
+		}
+	}
+	end := c.Until
+	if end.IsZero() {
+		end = time.Now()
+	}
+	var m UserMetrics
+	lines, commits := 0, 0
+	for _, contributor := range stats {
+		for _, week := range contributor.Weeks {
+			lines += week.GetAdditions() + week.GetDeletions()
+			commits += week.GetCommits()
+		}
+		if !containsFold(logins, contributor.GetAuthor().GetLogin()) {
+			continue
+		}
+		for _, week := range contributor.Weeks {
+			start := week.GetWeek().Time
+			if !start.Add(statsWeek).After(c.Since) || start.After(end) {
+				continue
+			}
+			// Counted like the files of a commit: additions plus changes.
+			weekHoC := 2*week.GetAdditions() + week.GetDeletions()
+			m.HoC += weekHoC
+			m.addLines(week.GetAdditions(), week.GetDeletions())
+			if weekHoC > 0 {
+				c.Evidence.Add(user, EvidenceItem{Metric: MetricHoC, Kind: "week", Repo: owner + "/" + repo, ID: start.Format("2006-01-02"), Value: float64(weekHoC)})
+			}
+		}
+	}
+	// GitHub leaves additions and deletions at 0 for repositories with
+	// 10,000 commits or more.
+	if commits > 0 && lines == 0 {
+		log.Printf("Repository statistics of %s/%s have no line counts, counting HoC from commits\n", owner, repo)
+		return UserMetrics{}, false
+	}
+	if c.Verbose {
+		log.Printf("HoC of %s in repo %s/%s from repository statistics: %d\n", user, owner, repo, m.HoC)
+	}
+	return m, true
+}
+
+// contributorStats fetches the contributor statistics of the repository,
+// once per run. GitHub answers 202 Accepted while it computes them, so the
+// request is repeated until they are ready; those answers are not failures
+// to retry.
+func (c *GitHubCollector) contributorStats(ctx context.Context, owner, repo string) ([]*github.ContributorStats, error) {
+	result, err := c.shared.do("contributor-stats/"+owner+"/"+repo, func() (interface{}, error) {
+		wait := 2 * time.Second
+		for i := 0; ; i++ {
+			result, _, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/stats/contributors", func() (interface{}, *github.Response, error) {
+				stats, resp, err := c.api().ListContributorsStats(ctx, owner, repo)
+				var accepted *github.AcceptedError
+				if errors.As(err, &accepted) {
+					return nil, resp, nil
+				}
+				return stats, resp, err
+			})
+			if err != nil {
+				return []*github.ContributorStats(nil), err
+			}
+			if result != nil {
+				return result.([]*github.ContributorStats), nil
+			}
+			if i == statsAttempts-1 {
+				return []*github.ContributorStats(nil), fmt.Errorf("still computing after %d requests", statsAttempts)
+			}
+			if c.Verbose {
+				log.Printf("GitHub is computing the statistics of %s/%s, asking again in %s\n", owner, repo, wait)
+			}
+			if err := sleep(ctx, wait); err != nil {
+				return []*github.ContributorStats(nil), err
+			}
+			wait *= 2
+		}
+	})
+	return result.([]*github.ContributorStats), err
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Snapshot is the result of one run as kept in a Store.
+type Snapshot struct {
+	ID      int64
+	TakenAt time.Time // When the run finished
+	Since   time.Time // Start of the measured window
+	Metric  string
+	Users   map[string]UserMetrics
+	// Reviews of pull requests still open, which a WebhookIngester credits
+	// once they are merged
+	PendingReviews []PendingReview
+}
+
+// PendingReview is a user's review of a pull request that is still open.
+type PendingReview struct {
+	Pull       string    // owner/name#number
+	User       string    // Measured user who reviewed it
+	ReviewedAt time.Time // When the user last submitted a review of it
+}
+
+// SnapshotInfo summarizes a stored snapshot without its metrics.
+type SnapshotInfo struct {
+	ID      int64
+	TakenAt time.Time
+	Since   time.Time
+	Metric  string
+	Users   int
+}
+
+// Store keeps historical snapshots so trends can be computed across runs.
+type Store interface {
+	// Save appends a snapshot and sets its ID.
+	Save(ctx context.Context, snapshot *Snapshot) error
+	// Update replaces the metrics of users in the stored snapshot with the
+	// snapshot's ID and moves its TakenAt, for a snapshot kept up to date
+	// as events come in.
+	Update(ctx context.Context, snapshot *Snapshot, users []string) error
+	// Latest returns the newest snapshot of the metric, or nil when there
+	// is none.
+	Latest(ctx context.Context, metric string) (*Snapshot, error)
+	// History returns the snapshots of the metric taken from from until to,
+	// oldest first; a zero bound leaves that side open.
+	History(ctx context.Context, metric string, from, to time.Time) ([]*Snapshot, error)
+	// Scores returns each user's score in every snapshot of the metric,
+	// sorted by user.
+	Scores(ctx context.Context, metric string) ([]ScoreSeries, error)
+	// Snapshots lists all stored snapshots, oldest first.
+	Snapshots(ctx context.Context) ([]SnapshotInfo, error)
+	// Prune deletes all but the newest keep snapshots and returns how many
+	// were deleted.
+	Prune(ctx context.Context, keep int) (int, error)
+	// Purge deletes the snapshots taken before the given time and returns
+	// how many were deleted.
+	Purge(ctx context.Context, before time.Time) (int, error)
+	// DeleteUser deletes the user's metrics from every snapshot, comparing
+	// logins case-insensitively, and returns how many snapshots held them.
+	DeleteUser(ctx context.Context, user string) (int, error)
+	Close() error
+}
+
+// OpenStore opens the store described by uri. Supported schemes:
+//
+//	sqlite://path/to/metrics.db
+func OpenStore(uri string) (Store, error) {
+	scheme, path, ok := strings.Cut(uri, "://")
+	if !ok {
+		return nil, fmt.Errorf("invalid store %q, expected scheme://path", uri)
+	}
+	switch scheme {
+	case "sqlite":
+		return OpenSQLiteStore(path)
+	default:
+		return nil, fmt.Errorf("unsupported store scheme: %s", scheme)
+	}
+}
@@ -0,0 +1,181 @@
+package metrics
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// StoreAPI is an http.Handler answering queries about the snapshots in a
+// history Store as JSON, for other tools to consume:
+//
+//	GET /api/v1/users/{login}/metrics?from=2024-01-01&to=2024-03-31
+//	GET /api/v1/leaderboard?period=2024-03
+//
+// Both take the stored metric with ?metric=, defaulting to Metric. Users who
+// opted out are never returned.
+type StoreAPI struct {
+	Store        Store
+	Metric       string // Metric of the snapshots queried by default
+	Organization string
+	WebURL       string
+	OptOut       []string
+}
+
+// apiSnapshot is a snapshot of one user in the users endpoint.
+type apiSnapshot struct {
+	TakenAt time.Time   `json:"takenAt"`
+	Since   time.Time   `json:"since"`
+	Rank    int         `json:"rank"`
+	Metrics jsonMetrics `json:"metrics"`
+}
+
+type apiUserMetrics struct {
+	User      string        `json:"user"`
+	Metric    string        `json:"metric"`
+	Snapshots []apiSnapshot `json:"snapshots"` // Oldest first
+}
+
+type apiRankedUser struct {
+	Rank int `json:"rank"`
+	jsonUser
+}
+
+type apiLeaderboard struct {
+	Metric  string          `json:"metric"`
+	TakenAt time.Time       `json:"takenAt"`
+	Since   time.Time       `json:"since"`
+	Users   []apiRankedUser `json:"users"`
+}
+
+func (a *StoreAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	metric := r.URL.Query().Get("metric")
+	if metric == "" {
+		metric = a.Metric
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1")
+	switch {
+	case path == "/leaderboard":
+		a.leaderboard(w, r, metric)
+	case strings.HasPrefix(path, "/users/") && strings.HasSuffix(path, "/metrics"):
+		login := strings.TrimSuffix(strings.TrimPrefix(path, "/users/"), "/metrics")
+		if login == "" || strings.Contains(login, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		a.userMetrics(w, r, metric, login)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// userMetrics answers with the user's metrics in every snapshot taken from
+// the from date to the end of the to date.
+func (a *StoreAPI) userMetrics(w http.ResponseWriter, r *http.Request, metric, login string) {
+	from, err := apiDate(r.URL.Query().Get("from"))
+	if err != nil {
+		http.Error(w, "invalid from: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := apiDate(r.URL.Query().Get("to"))
+	if err != nil {
+		http.Error(w, "invalid to: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !to.IsZero() {
+		to = to.AddDate(0, 0, 1)
+	}
+	snapshots, err := a.Store.History(r.Context(), metric, from, to)
+	if err != nil {
+		log.Printf("Error loading snapshots: %v", err)
+		http.Error(w, "snapshots not available", http.StatusInternalServerError)
+		return
+	}
+	result := apiUserMetrics{Metric: metric, Snapshots: []apiSnapshot{}}
+	for _, snapshot := range snapshots {
+		for _, view := range a.report(snapshot).Users {
+			if strings.EqualFold(view.User, login) {
+				result.User = view.User
+				result.Snapshots = append(result.Snapshots, apiSnapshot{TakenAt: snapshot.TakenAt, Since: snapshot.Since, Rank: view.Rank, Metrics: newJSONMetrics(view.Metrics)})
+			}
+		}
+	}
+	if result.User == "" {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, result)
+}
+
+// leaderboard answers with the leaderboard of the newest snapshot taken
+// during the period: a day (2024-03-31), a month (2024-03), a year (2024)
+// or, by default, latest.
+func (a *StoreAPI) leaderboard(w http.ResponseWriter, r *http.Request, metric string) {
+	from, to, err := apiPeriod(r.URL.Query().Get("period"))
+	if err != nil {
+		http.Error(w, "invalid period: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	snapshots, err := a.Store.History(r.Context(), metric, from, to)
+	if err != nil {
+		log.Printf("Error loading snapshots: %v", err)
+		http.Error(w, "snapshots not available", http.StatusInternalServerError)
+		return
+	}
+	if len(snapshots) == 0 {
+		http.Error(w, "no snapshot in the period", http.StatusNotFound)
+		return
+	}
+	snapshot := snapshots[len(snapshots)-1]
+	result := apiLeaderboard{Metric: metric, TakenAt: snapshot.TakenAt, Since: snapshot.Since, Users: []apiRankedUser{}}
+	for _, view := range a.report(snapshot).Users {
+		result.Users = append(result.Users, apiRankedUser{Rank: view.Rank, jsonUser: newJSONUser(view)})
+	}
+	writeJSON(w, result)
+}
+
+// report builds the report of a snapshot, ranked and without the users who
+// opted out.
+func (a *StoreAPI) report(snapshot *Snapshot) Report {
+	return NewReport(snapshot.Users, ViewOptions{
+		Since:        snapshot.Since,
+		Until:        snapshot.TakenAt,
+		CollectedAt:  snapshot.TakenAt,
+		Organization: a.Organization,
+		WebURL:       a.WebURL,
+		OptOut:       a.OptOut,
+	})
+}
+
+// apiDate parses a YYYY-MM-DD date in local time; empty is the zero time.
+func apiDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation("2006-01-02", s, time.Local)
+}
+
+// apiPeriod returns the start and end of a day, month or year in local
+// time; latest or empty is open on both sides.
+func apiPeriod(s string) (from, to time.Time, err error) {
+	if s == "" || s == "latest" {
+		return time.Time{}, time.Time{}, nil
+	}
+	for _, p := range []struct {
+		layout string
+		years  int
+		months int
+		days   int
+	}{{"2006-01-02", 0, 0, 1}, {"2006-01", 0, 1, 0}, {"2006", 1, 0, 0}} {
+		if from, err = time.ParseInLocation(p.layout, s, time.Local); err == nil {
+			return from, from.AddDate(p.years, p.months, p.days), nil
+		}
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("%q is not latest, YYYY-MM-DD, YYYY-MM or YYYY", s)
+}
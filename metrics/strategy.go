@@ -0,0 +1,206 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// Collection strategies of GitHubCollector.
+const (
+	// StrategyUser queries GitHub for each user in each repository, which
+	// suits a few users in busy repositories.
+	StrategyUser = "user"
+	// StrategyRepo lists the commits, issues and merged pull requests of
+	// each repository in the window once and attributes them to the users
+	// locally, which suits many users: the requests grow with the
+	// repositories instead of with users times repositories.
+	StrategyRepo = "repo"
+)
+
+// mergedPull is a pull request merged during the window.
+type mergedPull struct {
+	number   int
+	author   string
+	url      string
+	created  time.Time
+	merged   time.Time
+	mergeSHA string // Commit the pull request was merged, squashed or rebased as; unknown from search results
+	body     string // Description, which may close issues with a keyword
+	labels   []string
+}
+
+// repoCommits lists every commit of the repository in the window, once for
+// reverts, squash merges and, with StrategyRepo, for every user's commits
+// and HoC. The
+// commits fetched before an error are returned with it.
+func (c *GitHubCollector) repoCommits(ctx context.Context, owner, repo string) ([]*github.RepositoryCommit, error) {
+	result, err := c.shared.do("repo-commits/"+owner+"/"+repo, func() (interface{}, error) {
+		var commits []*github.RepositoryCommit
+		opts := &github.CommitsListOptions{
+			Since: c.Since,
+			Until: c.Until,
+			ListOptions: github.ListOptions{
+				PerPage: 100,
+			},
+		}
+		for {
+			result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/commits", func() (interface{}, *github.Response, error) {
+				return c.api().ListCommits(ctx, owner, repo, opts)
+			})
+			if err != nil {
+				return commits, err
+			}
+			commits = append(commits, result.([]*github.RepositoryCommit)...)
+			if resp.NextPage == 0 {
+				return commits, nil
+			}
+			opts.Page = resp.NextPage
+		}
+	})
+	return result.([]*github.RepositoryCommit), err
+}
+
+// repoIssues lists every issue and pull request of the repository updated
+// since the start of the window, once for all users with StrategyRepo.
+func (c *GitHubCollector) repoIssues(ctx context.Context, owner, repo string) ([]*github.Issue, error) {
+	result, err := c.shared.do("repo-issues/"+owner+"/"+repo, func() (interface{}, error) {
+		var issues []*github.Issue
+		opts := &github.IssueListByRepoOptions{
+			State: "all",
+			Since: c.Since,
+			ListOptions: github.ListOptions{
+				PerPage: 100,
+			},
+		}
+		for {
+			result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/issues", func() (interface{}, *github.Response, error) {
+				return c.api().ListIssues(ctx, owner, repo, opts)
+			})
+			if err != nil {
+				return issues, err
+			}
+			issues = append(issues, result.([]*github.Issue)...)
+			if resp.NextPage == 0 {
+				return issues, nil
+			}
+			opts.Page = resp.NextPage
+		}
+	})
+	return result.([]*github.Issue), err
+}
+
+// repoMergedPulls lists the pull requests of the repository merged during
+// the window, once for all users with StrategyRepo or SquashAttribution. Closed pull requests
+// are listed by last update, newest first, until they were last updated
+// before the window started.
+func (c *GitHubCollector) repoMergedPulls(ctx context.Context, owner, repo string) ([]mergedPull, error) {
+	result, err := c.shared.do("repo-pulls/"+owner+"/"+repo, func() (interface{}, error) {
+		var pulls []mergedPull
+		opts := &github.PullRequestListOptions{
+			State:     "closed",
+			Sort:      "updated",
+			Direction: "desc",
+			ListOptions: github.ListOptions{
+				PerPage: 100,
+			},
+		}
+		for {
+			result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/pulls", func() (interface{}, *github.Response, error) {
+				return c.api().ListPullRequests(ctx, owner, repo, opts)
+			})
+			if err != nil {
+				return pulls, err
+			}
+			for _, pr := range result.([]*github.PullRequest) {
+				if pr.GetUpdatedAt().Before(c.Since) {
+					return pulls, nil
+				}
+				if pr.MergedAt != nil && c.inWindow(pr.MergedAt.Time) {
+					pulls = append(pulls, mergedPull{number: pr.GetNumber(), author: pr.GetUser().GetLogin(), url: pr.GetHTMLURL(), created: pr.GetCreatedAt().Time, merged: pr.MergedAt.Time, mergeSHA: pr.GetMergeCommitSHA(), body: pr.GetBody(), labels: labelNames(pr.Labels)})
+				}
+			}
+			if resp.NextPage == 0 {
+				return pulls, nil
+			}
+			opts.Page = resp.NextPage
+		}
+	})
+	return result.([]mergedPull), err
+}
+
+// searchPulls runs a pull request search, such as the merged pull requests
+// of an author, for StrategyUser. Search results are closed when merged, so
+// the closing time stands in for the merge.
+func (c *GitHubCollector) searchPulls(ctx context.Context, query string) ([]mergedPull, error) {
+	var pulls []mergedPull
+	opts := &github.SearchOptions{
+		Sort:  "created",
+		Order: "desc",
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	for {
+		result, resp, err := c.retryWithBackoff(ctx, resourceSearch, "GET /search/issues", func() (interface{}, *github.Response, error) {
+			return c.api().SearchIssues(ctx, query, opts)
+		})
+		if err != nil {
+			return pulls, err
+		}
+		for _, issue := range result.(*github.IssuesSearchResult).Issues {
+			if issue.IsPullRequest() && issue.ClosedAt != nil {
+				pulls = append(pulls, mergedPull{number: issue.GetNumber(), author: issue.GetUser().GetLogin(), url: issue.GetHTMLURL(), created: issue.GetCreatedAt().Time, merged: issue.ClosedAt.Time, body: issue.GetBody(), labels: labelNames(issue.Labels)})
+			}
+		}
+		if resp.NextPage == 0 {
+			return pulls, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// authoredPulls lists the user's pull requests merged during the window
+// that Labels lets through, once for the pulls and lcp metrics.
+func (c *GitHubCollector) authoredPulls(ctx context.Context, owner, repo, user string) ([]mergedPull, error) {
+	if c.Strategy != StrategyRepo {
+		result, err := c.shared.do("authored-pulls/"+owner+"/"+repo+"/"+user, func() (interface{}, error) {
+			return c.searchPulls(ctx, fmt.Sprintf("repo:%s/%s is:pr author:%s merged:%s", owner, repo, user, c.dateRange(">"))+labelQualifier(c.Labels.Pulls))
+		})
+		return result.([]mergedPull), err
+	}
+	all, err := c.repoMergedPulls(ctx, owner, repo)
+	var pulls []mergedPull
+	for _, pull := range c.labeledPulls(all) {
+		if strings.EqualFold(pull.author, user) {
+			pulls = append(pulls, pull)
+		}
+	}
+	return pulls, err
+}
+
+// reviewedPulls lists the pull requests merged during the window that the
+// user reviewed and Labels lets through.
+func (c *GitHubCollector) reviewedPulls(ctx context.Context, owner, repo, user string) ([]mergedPull, error) {
+	if c.Strategy != StrategyRepo {
+		return c.searchPulls(ctx, fmt.Sprintf("repo:%s/%s reviewed-by:%s is:pr merged:%s", owner, repo, user, c.dateRange(">"))+labelQualifier(c.Labels.Pulls))
+	}
+	all, err := c.repoMergedPulls(ctx, owner, repo)
+	var pulls []mergedPull
+	for _, pull := range c.labeledPulls(all) {
+		reviews, reviewErr := c.pullReviews(ctx, owner, repo, pull.number)
+		if reviewErr != nil && err == nil {
+			err = reviewErr
+		}
+		for _, review := range reviews {
+			if strings.EqualFold(review.GetUser().GetLogin(), user) {
+				pulls = append(pulls, pull)
+				break
+			}
+		}
+	}
+	return pulls, err
+}
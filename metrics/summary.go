@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Summary condenses a report for chat notifications: totals over all users,
+// the highest scores and, when there is history, the biggest movers.
+type Summary struct {
+	Organization string
+	Since        time.Time
+	Until        time.Time // Zero when unknown
+	Window       string    // e.g. "from 2024-01-01 to 2024-03-31"
+	Users        int
+	Totals       UserMetrics       // Sum over all users
+	Leaders      []UserMetricsView // Highest scores first
+	Movers       []UserMetricsView // Largest score changes against the previous run; empty without history
+	Inactive     []string          // Users without activity, with --include-inactive
+	Alert        bool              // Set when Inactive reached the alerting threshold
+}
+
+// NewSummary summarizes report with at most n leaders and movers.
+func NewSummary(report Report, n int) Summary {
+	s := Summary{
+		Organization: report.Organization,
+		Since:        report.Since,
+		Until:        report.Until,
+		Window:       report.Window(),
+		Users:        len(report.Users) + len(report.Inactive),
+		Inactive:     report.Inactive,
+	}
+	for _, view := range report.Users {
+		s.Totals = Merge(s.Totals, view.Metrics)
+		s.Totals.Score += view.Metrics.Score
+		if view.Delta != nil && view.Delta.Score.Change != 0 {
+			s.Movers = append(s.Movers, view)
+		}
+	}
+
+	s.Leaders = report.Users
+	if len(s.Leaders) > n {
+		s.Leaders = s.Leaders[:n]
+	}
+	sort.SliceStable(s.Movers, func(i, j int) bool {
+		return math.Abs(s.Movers[i].Delta.Score.Change) > math.Abs(s.Movers[j].Delta.Score.Change)
+	})
+	if len(s.Movers) > n {
+		s.Movers = s.Movers[:n]
+	}
+	return s
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// TeamsNotifier posts a run summary as an Adaptive Card to a Microsoft Teams
+// incoming webhook or Workflows webhook.
+type TeamsNotifier struct {
+	WebhookURL string
+	Client     *http.Client // Defaults to http.DefaultClient
+}
+
+// Notify posts the summary as a card with the totals, the biggest movers and
+// the leaderboard.
+func (n TeamsNotifier) Notify(ctx context.Context, summary Summary) error {
+	return postJSON(ctx, n.Client, n.WebhookURL, teamsMessage(summary))
+}
+
+func teamsMessage(s Summary) map[string]interface{} {
+	title := "GitHub Metrics"
+	if s.Organization != "" {
+		title += " for " + s.Organization
+	}
+	type fact struct {
+		Title string `json:"title"`
+		Value string `json:"value"`
+	}
+	text := func(text string, bold bool) map[string]interface{} {
+		block := map[string]interface{}{"type": "TextBlock", "text": text, "wrap": true}
+		if bold {
+			block["weight"] = "Bolder"
+		}
+		return block
+	}
+	factSet := func(facts []fact) map[string]interface{} {
+		return map[string]interface{}{"type": "FactSet", "facts": facts}
+	}
+
+	hoc, _ := formatNumber(s.Totals.HoC)
+	body := []map[string]interface{}{
+		{"type": "TextBlock", "text": title, "size": "Large", "weight": "Bolder", "wrap": true},
+		text(fmt.Sprintf("Activity %s · %d users", s.Window, s.Users), false),
+		factSet([]fact{
+			{"Commits", strconv.Itoa(s.Totals.Commits)},
+			{"HoC", hoc},
+			{"Pull requests", strconv.Itoa(s.Totals.Pulls)},
+			{"Reviews", strconv.Itoa(s.Totals.Reviews)},
+			{"Issues", strconv.Itoa(s.Totals.Issues)},
+		}),
+	}
+	if len(s.Inactive) > 0 {
+		block := text("No activity: "+strings.Join(s.Inactive, ", "), s.Alert)
+		if s.Alert {
+			block["color"] = "Attention"
+		}
+		body = append(body, block)
+	}
+	if len(s.Movers) > 0 {
+		var facts []fact
+		for _, view := range s.Movers {
+			score, _ := formatNumber(view.Metrics.Score)
+			facts = append(facts, fact{view.User, fmt.Sprintf("%s (%s)", score, view.Delta.Score)})
+		}
+		body = append(body, text("Top movers", true), factSet(facts))
+	}
+	if len(s.Leaders) > 0 {
+		var facts []fact
+		for _, view := range s.Leaders {
+			score, _ := formatNumber(view.Metrics.Score)
+			facts = append(facts, fact{fmt.Sprintf("%d. %s", view.Rank, view.User), score})
+		}
+		body = append(body, text("Leaderboard", true), factSet(facts))
+	}
+
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]interface{}{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+}
@@ -0,0 +1,443 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// TokenPool is an http.RoundTripper sending every GitHub API request with
+// one of several tokens, so a collection can spend the rate limits of all of
+// them. Each request takes the token with the most requests left in the
+// quota of its resource, which rotates the tokens as they are used. A token
+// GitHub rejects as bad credentials, e.g. because it was revoked, is dropped
+// and the request is sent again with another; so is a request whose token
+// ran out of quota while others have some left.
+//
+// Responses report the rate limit of the whole pool: the limits and the
+// remaining requests of the tokens left added up, resetting when the first
+// of them does. The collector paces itself by the pool as it does by a
+// single token.
+type TokenPool struct {
+	Base    http.RoundTripper // Defaults to http.DefaultTransport
+	Verbose bool
+
+	mu     sync.Mutex
+	tokens []*pooledToken
+}
+
+// pooledToken is a token of a pool with its last known quotas.
+type pooledToken struct {
+	token    string
+	revoked  bool
+	requests int64
+	quotas   map[string]*tokenQuota // Keyed by resource
+}
+
+type tokenQuota struct {
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+// TokenUsage is what a token of a pool was used for.
+type TokenUsage struct {
+	Token     string     `json:"token"`   // The last characters of the token
+	Revoked   bool       `json:"revoked"` // Rejected as bad credentials and no longer used
+	Requests  int64      `json:"requests"`
+	Limit     int        `json:"limit,omitempty"` // Core quota, when known
+	Remaining int        `json:"remaining"`
+	Reset     *time.Time `json:"reset,omitempty"`
+}
+
+// NewTokenPool returns a pool of the tokens, leaving out duplicates.
+func NewTokenPool(tokens []string) *TokenPool {
+	p := &TokenPool{}
+	seen := make(map[string]bool)
+	for _, token := range tokens {
+		if token == "" || seen[token] {
+			continue
+		}
+		seen[token] = true
+		p.tokens = append(p.tokens, &pooledToken{token: token, quotas: make(map[string]*tokenQuota)})
+	}
+	return p
+}
+
+// NewTokenPoolClient returns a GitHub client sending its requests through
+// the pool. baseURL and uploadURL are as for NewGitHubClient.
+func NewTokenPoolClient(pool *TokenPool, baseURL, uploadURL string) (*github.Client, error) {
+	if len(pool.tokens) == 0 {
+		return nil, fmt.Errorf("no tokens in the pool")
+	}
+	return newClient(&http.Client{Transport: pool}, baseURL, uploadURL)
+}
+
+// LoadTokens reads a token file with one token per line. Blank lines and
+// lines starting with # are skipped.
+func LoadTokens(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var tokens []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tokens = append(tokens, line)
+	}
+	return tokens, scanner.Err()
+}
+
+// Usage returns what each token of the pool was used for, in the order the
+// tokens were given.
+func (p *TokenPool) Usage() []TokenUsage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var usage []TokenUsage
+	for _, t := range p.tokens {
+		u := TokenUsage{Token: MaskToken(t.token), Revoked: t.revoked, Requests: t.requests}
+		if q, ok := t.quotas[resourceCore]; ok {
+			reset := q.reset
+			u.Limit, u.Remaining, u.Reset = q.limit, q.remaining, &reset
+		}
+		usage = append(usage, u)
+	}
+	return usage
+}
+
+func (p *TokenPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(req.URL.Path, "/rate_limit") && req.Method == http.MethodGet {
+		return p.rateLimits(req)
+	}
+	resource := requestResource(req)
+	tried := make(map[*pooledToken]bool)
+	for {
+		t := p.pick(resource, tried)
+		tried[t] = true
+		resp, err := p.send(req, t)
+		if err != nil {
+			return nil, err
+		}
+		retry := false
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized:
+			p.revoke(t)
+			retry = true
+		case (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests) && resp.Header.Get("X-RateLimit-Remaining") == "0":
+			p.record(t, resp.Header)
+			retry = p.hasQuota(resource, tried)
+		default:
+			p.record(t, resp.Header)
+		}
+		if retry && p.untried(tried) && (req.Body == nil || req.GetBody != nil) {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			continue
+		}
+		p.pooledHeaders(resp.Header, resource)
+		return resp, nil
+	}
+}
+
+// send sends the request authenticated with the token.
+func (p *TokenPool) send(req *http.Request, t *pooledToken) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		out.Body = body
+	}
+	out.Header.Set("Authorization", "Bearer "+t.token)
+	base := p.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(out)
+}
+
+// pick returns the untried token with the most requests left in the
+// resource's quota, counting the request against it. Tokens whose quota is
+// unknown go first, and when all are spent the one resetting first is used.
+// When every token was revoked it returns the first, for GitHub to reject
+// the request as it would with a single token.
+func (p *TokenPool) pick(resource string, tried map[*pooledToken]bool) *pooledToken {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	var best *pooledToken
+	bestLeft, bestReset := -1, time.Time{}
+	for _, t := range p.tokens {
+		if t.revoked || tried[t] {
+			continue
+		}
+		left, reset := int(^uint(0)>>1), time.Time{}
+		if q, ok := t.quotas[resource]; ok && q.reset.After(now) {
+			left, reset = q.remaining, q.reset
+		}
+		if best == nil || left > bestLeft || (left == 0 && bestLeft == 0 && reset.Before(bestReset)) {
+			best, bestLeft, bestReset = t, left, reset
+		}
+	}
+	if best == nil {
+		best = p.tokens[0]
+	}
+	best.requests++
+	if q, ok := best.quotas[resource]; ok && q.remaining > 0 {
+		q.remaining--
+	}
+	return best
+}
+
+// untried reports whether a token that was not tried yet is left.
+func (p *TokenPool) untried(tried map[*pooledToken]bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, t := range p.tokens {
+		if !t.revoked && !tried[t] {
+			return true
+		}
+	}
+	return false
+}
+
+// hasQuota reports whether an untried token may have requests left in the
+// resource's quota.
+func (p *TokenPool) hasQuota(resource string, tried map[*pooledToken]bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	for _, t := range p.tokens {
+		if t.revoked || tried[t] {
+			continue
+		}
+		if q, ok := t.quotas[resource]; !ok || q.remaining > 0 || !q.reset.After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// revoke stops using a token GitHub rejected.
+func (p *TokenPool) revoke(t *pooledToken) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if t.revoked {
+		return
+	}
+	t.revoked = true
+	left := 0
+	for _, other := range p.tokens {
+		if !other.revoked {
+			left++
+		}
+	}
+	log.Printf("Token %s was rejected as bad credentials and is no longer used; %d of %d tokens left\n", MaskToken(t.token), left, len(p.tokens))
+}
+
+// record keeps the quota a response reports for its token.
+func (p *TokenPool) record(t *pooledToken, header http.Header) {
+	resource := header.Get("X-RateLimit-Resource")
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if resource == "" || err != nil || limit == 0 {
+		return
+	}
+	remaining, _ := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	resetUnix, _ := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	p.setQuota(t, resource, tokenQuota{limit: limit, remaining: remaining, reset: time.Unix(resetUnix, 0)})
+}
+
+func (p *TokenPool) setQuota(t *pooledToken, resource string, quota tokenQuota) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	q, ok := t.quotas[resource]
+	if !ok {
+		t.quotas[resource] = &quota
+		return
+	}
+	// Responses of concurrent requests arrive out of order; within one
+	// window the lowest remaining count is the latest.
+	if quota.reset.Equal(q.reset) && quota.remaining > q.remaining {
+		return
+	}
+	*q = quota
+}
+
+// pooledHeaders replaces the rate limit headers with the quota of the pool,
+// when the quotas of the resource are known.
+func (p *TokenPool) pooledHeaders(header http.Header, resource string) {
+	if r := header.Get("X-RateLimit-Resource"); r != "" {
+		resource = r
+	}
+	quota, ok := p.pooledQuota(resource)
+	if !ok {
+		return
+	}
+	header.Set("X-RateLimit-Limit", strconv.Itoa(quota.limit))
+	header.Set("X-RateLimit-Remaining", strconv.Itoa(quota.remaining))
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(quota.reset.Unix(), 10))
+}
+
+// pooledQuota adds up the quotas of the resource over the tokens left,
+// resetting when the first of them does.
+func (p *TokenPool) pooledQuota(resource string) (tokenQuota, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	var pooled tokenQuota
+	for _, t := range p.tokens {
+		q, ok := t.quotas[resource]
+		if t.revoked || !ok {
+			continue
+		}
+		pooled.limit += q.limit
+		if !q.reset.After(now) {
+			// The quota was renewed since it was last reported.
+			pooled.remaining += q.limit
+			continue
+		}
+		pooled.remaining += q.remaining
+		if pooled.reset.IsZero() || q.reset.Before(pooled.reset) {
+			pooled.reset = q.reset
+		}
+	}
+	if pooled.reset.IsZero() {
+		pooled.reset = now.Add(time.Hour)
+	}
+	return pooled, pooled.limit > 0
+}
+
+// rateLimitsBody is the body of a /rate_limit response.
+type rateLimitsBody struct {
+	Resources map[string]rateJSON `json:"resources"`
+	Rate      rateJSON            `json:"rate"`
+}
+
+type rateJSON struct {
+	Limit     int   `json:"limit"`
+	Remaining int   `json:"remaining"`
+	Reset     int64 `json:"reset"`
+	Used      int   `json:"used"`
+}
+
+// rateLimits asks for the rate limits of every token, which does not count
+// against them, recording their quotas and dropping those GitHub rejects,
+// and answers with the quotas of the pool.
+func (p *TokenPool) rateLimits(req *http.Request) (*http.Response, error) {
+	p.mu.Lock()
+	var live []*pooledToken
+	for _, t := range p.tokens {
+		if !t.revoked {
+			live = append(live, t)
+		}
+	}
+	p.mu.Unlock()
+
+	var last *http.Response
+	for _, t := range live {
+		resp, err := p.send(req, t)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			p.revoke(t)
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			// e.g. 404 from GitHub Enterprise Server without rate
+			// limiting, which every token gets alike
+			return resp, nil
+		}
+		var body rateLimitsBody
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for resource, rate := range body.Resources {
+			p.setQuota(t, resource, tokenQuota{limit: rate.Limit, remaining: rate.Remaining, reset: time.Unix(rate.Reset, 0)})
+		}
+		if p.Verbose {
+			if core, ok := body.Resources[resourceCore]; ok {
+				log.Printf("Token %s: %d of %d core requests remaining\n", MaskToken(t.token), core.Remaining, core.Limit)
+			}
+		}
+		last = resp
+	}
+	if last == nil {
+		return p.send(req, p.tokens[0])
+	}
+
+	body := rateLimitsBody{Resources: make(map[string]rateJSON)}
+	var resources []string
+	p.mu.Lock()
+	for _, t := range p.tokens {
+		for resource := range t.quotas {
+			if !containsFold(resources, resource) {
+				resources = append(resources, resource)
+			}
+		}
+	}
+	p.mu.Unlock()
+	sort.Strings(resources)
+	for _, resource := range resources {
+		if quota, ok := p.pooledQuota(resource); ok {
+			body.Resources[resource] = rateJSON{Limit: quota.limit, Remaining: quota.remaining, Reset: quota.reset.Unix(), Used: quota.limit - quota.remaining}
+		}
+	}
+	body.Rate = body.Resources[resourceCore]
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	resp := *last
+	resp.Header = last.Header.Clone()
+	resp.Header.Del("ETag")
+	resp.Header.Set("Content-Length", strconv.Itoa(len(data)))
+	resp.ContentLength = int64(len(data))
+	resp.TransferEncoding = nil
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	p.pooledHeaders(resp.Header, resourceCore)
+	return &resp, nil
+}
+
+// requestResource returns the rate limit resource a request counts against.
+func requestResource(req *http.Request) string {
+	switch path := req.URL.Path; {
+	case strings.HasSuffix(path, "/graphql"):
+		return resourceGraphQL
+	case strings.Contains(path, "/search/"):
+		return resourceSearch
+	}
+	return resourceCore
+}
+
+// MaskToken returns the last four characters of a token, enough to tell
+// tokens apart in logs without revealing them.
+func MaskToken(token string) string {
+	if len(token) <= 8 {
+		return "****"
+	}
+	return "…" + token[len(token)-4:]
+}
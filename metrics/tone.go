@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// The tone of review comments is an experimental indicator: a word list
+// cannot read sarcasm, context or other languages than English, so it is
+// only collected with Tone and never counts towards the score unless a score
+// expression asks for it.
+
+// politeMarkers are words and phrases that make a comment friendlier:
+// thanks, praise and suggestions rather than orders.
+var politeMarkers = []string{
+	"thanks", "thank you", "thx", "please", "appreciate", "sorry",
+	"nice", "great", "good catch", "well done", "lgtm", "looks good", "love", "awesome", "neat", "clever",
+	"what do you think", "could we", "could you", "would you", "how about", "maybe", "perhaps", "consider", "suggestion", "nit",
+}
+
+// harshMarkers are words and phrases that make a comment harsher: insults,
+// condescension and orders.
+var harshMarkers = []string{
+	"wrong", "bad", "terrible", "horrible", "awful", "stupid", "dumb", "ugly", "garbage", "crap", "useless", "nonsense", "ridiculous", "lazy", "sloppy",
+	"obviously", "clearly", "why would you", "why did you", "just do", "never do", "hate", "wtf", "what the hell", "seriously",
+}
+
+var (
+	// codeBlock matches fenced code blocks and inline code, whose words
+	// are not the reviewer's tone.
+	codeBlock = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`")
+	// quotedLine matches quoted lines, which repeat someone else's words.
+	quotedLine = regexp.MustCompile(`(?m)^\s*>.*$`)
+	// nonWord matches what separates words.
+	nonWord = regexp.MustCompile(`[^a-z']+`)
+)
+
+// commentTone rates a comment from -1, harsh, to 1, polite, by the markers
+// it contains; a comment without any is neutral, 0. Repeated exclamation
+// marks count as harsh.
+func commentTone(body string) float64 {
+	body = quotedLine.ReplaceAllString(codeBlock.ReplaceAllString(body, " "), " ")
+	text := " " + strings.TrimSpace(nonWord.ReplaceAllString(strings.ToLower(body), " ")) + " "
+	count := func(markers []string) int {
+		n := 0
+		for _, marker := range markers {
+			n += strings.Count(text, " "+marker+" ")
+		}
+		return n
+	}
+	polite, harsh := count(politeMarkers), count(harshMarkers)
+	if strings.Contains(body, "!!") {
+		harsh++
+	}
+	if polite+harsh == 0 {
+		return 0
+	}
+	return float64(polite-harsh) / float64(polite+harsh)
+}
+
+// tone rates the review comments the user wrote on pull request diffs in the
+// repository during the window, from the comments listed for msgs.
+func (c *GitHubCollector) tone(ctx context.Context, owner, repo, user string, m *UserMetrics) {
+	comments, err := c.comments(ctx, owner, repo)
+	if err != nil {
+		logError(ctx, err, "fetching review comments for user %s in repo %s/%s", user, owner, repo)
+	}
+	total := 0.0
+	for _, comment := range comments {
+		if comment.item.Kind != "review_comment" || !strings.EqualFold(comment.author, user) || !c.inWindow(comment.created) {
+			continue
+		}
+		tone := commentTone(comment.body)
+		total += tone
+		m.ToneComments++
+		if tone < 0 {
+			m.HarshComments++
+		}
+	}
+	if m.ToneComments > 0 {
+		m.Tone = total / float64(m.ToneComments)
+	}
+	if c.Verbose && m.ToneComments > 0 {
+		log.Printf("User %s in repo %s/%s: tone %.2f over %d review comments, %d harsh\n", user, owner, repo, m.Tone, m.ToneComments, m.HarshComments)
+	}
+}
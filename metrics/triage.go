@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// issueEvents holds the issue events of one repository, fetched once and
+// shared by every user measured in it.
+type issueEvents struct {
+	once   sync.Once
+	events []*github.IssueEvent
+	err    error
+}
+
+// triage counts the issues the user labeled, assigned and closed, by hand
+// or by merging a pull request that fixes them, and the average hours from
+// an issue being opened to its first label, for issues the user labeled
+// first.
+func (c *GitHubCollector) triage(ctx context.Context, owner, repo, user string) UserMetrics {
+	var m UserMetrics
+	events, err := c.issueEvents(ctx, owner, repo)
+	if err != nil {
+		logError(ctx, err, "fetching issue events in repo %s/%s", owner, repo)
+		return m
+	}
+
+	firstLabel := make(map[int64]*github.IssueEvent)
+	for _, event := range events {
+		issue := event.GetIssue()
+		if issue.IsPullRequest() || !c.inWindow(event.GetCreatedAt().Time) || !c.Labels.MatchIssue(labelNames(issue.Labels)) {
+			continue
+		}
+		if event.GetEvent() == "labeled" {
+			if first, ok := firstLabel[issue.GetID()]; !ok || event.GetCreatedAt().Before(first.GetCreatedAt().Time) {
+				firstLabel[issue.GetID()] = event
+			}
+		}
+		if !strings.EqualFold(event.GetActor().GetLogin(), user) {
+			continue
+		}
+		switch event.GetEvent() {
+		case "labeled":
+			m.Labeled++
+		case "assigned":
+			m.Assigned++
+		case "closed":
+			m.IssuesClosed++
+			if event.GetCommitID() == "" {
+				m.IssuesClosedManually++
+			}
+		default:
+			continue
+		}
+		c.Evidence.Add(user, EvidenceItem{Metric: MetricTriage, Kind: event.GetEvent(), Repo: owner + "/" + repo, ID: strconv.FormatInt(event.GetID(), 10), URL: issue.GetHTMLURL()})
+	}
+
+	totalTime := 0.0
+	for _, event := range firstLabel {
+		if !strings.EqualFold(event.GetActor().GetLogin(), user) {
+			continue
+		}
+		opened := event.GetIssue().GetCreatedAt().Time
+		if !c.inWindow(opened) {
+			continue
+		}
+		duration := event.GetCreatedAt().Sub(opened).Hours()
+		totalTime += duration
+		m.Triaged++
+		if c.Verbose {
+			log.Printf("Issue #%d in repo %s/%s: opened at %s, first labeled by %s after %.2f hours\n", event.GetIssue().GetNumber(), owner, repo, opened, user, duration)
+		}
+	}
+	if m.Triaged > 0 {
+		m.TimeToTriage = totalTime / float64(m.Triaged)
+	}
+	m.IssuesFixed = c.issuesFixed(ctx, owner, repo, user, events)
+	return m
+}
+
+// issueEvents returns the repository's issue events created since the start
+// of the window, newest first.
+func (c *GitHubCollector) issueEvents(ctx context.Context, owner, repo string) ([]*github.IssueEvent, error) {
+	c.issueEventsMu.Lock()
+	if c.issueEventsByRepo == nil {
+		c.issueEventsByRepo = make(map[string]*issueEvents)
+	}
+	entry, ok := c.issueEventsByRepo[owner+"/"+repo]
+	if !ok {
+		entry = &issueEvents{}
+		c.issueEventsByRepo[owner+"/"+repo] = entry
+	}
+	c.issueEventsMu.Unlock()
+
+	entry.once.Do(func() {
+		entry.events, entry.err = c.listIssueEvents(ctx, owner, repo)
+	})
+	return entry.events, entry.err
+}
+
+func (c *GitHubCollector) listIssueEvents(ctx context.Context, owner, repo string) ([]*github.IssueEvent, error) {
+	var events []*github.IssueEvent
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/issues/events", func() (interface{}, *github.Response, error) {
+			return c.api().ListIssueEvents(ctx, owner, repo, opts)
+		})
+		if err != nil {
+			return events, err
+		}
+		for _, event := range result.([]*github.IssueEvent) {
+			// Events are listed newest first, so the window ends here.
+			if event.GetCreatedAt().Before(c.Since) {
+				return events, nil
+			}
+			events = append(events, event)
+		}
+		if resp.NextPage == 0 {
+			return events, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"bufio"
+	"os"
+	"sort"
+	"strings"
+)
+
+// botLogins are automation accounts recognized without the [bot] suffix.
+var botLogins = []string{"dependabot", "renovate", "dependabot-preview", "renovate-bot", "github-actions"}
+
+// IsBot reports whether login belongs to an automation account: GitHub App
+// bots ending in [bot] and well-known dependency update services.
+func IsBot(login string) bool {
+	login = strings.ToLower(login)
+	if strings.HasSuffix(login, "[bot]") || strings.HasSuffix(login, "-bot") {
+		return true
+	}
+	for _, bot := range botLogins {
+		if login == bot {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadOptOut reads a list of users who opted out of individual reporting:
+// one login per line, optionally prefixed with @, with blank lines and lines
+// starting with # ignored.
+func LoadOptOut(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var users []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		users = append(users, strings.TrimPrefix(line, "@"))
+	}
+	return users, scanner.Err()
+}
+
+// FilterUsers returns users without the excluded logins and, unless
+// includeBots is set, without bots. Logins are compared case-insensitively.
+func FilterUsers(users, exclude []string, includeBots bool) []string {
+	excluded := make(map[string]bool, len(exclude))
+	for _, login := range exclude {
+		excluded[strings.ToLower(login)] = true
+	}
+
+	var filtered []string
+	for _, user := range users {
+		if excluded[strings.ToLower(user)] || (!includeBots && IsBot(user)) {
+			continue
+		}
+		filtered = append(filtered, user)
+	}
+	return filtered
+}
+
+// InactiveUsers returns, sorted, the users without any activity in metrics,
+// including users missing from it.
+func InactiveUsers(users []string, metrics map[string]UserMetrics) []string {
+	var inactive []string
+	for _, user := range users {
+		if !active(metrics[user]) {
+			inactive = append(inactive, user)
+		}
+	}
+	sort.Strings(inactive)
+	return inactive
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// EventRunCompleted is the event of a WebhookNotifier payload sent after a
+// collection run.
+const EventRunCompleted = "run.completed"
+
+// WebhookNotifier posts a run summary as plain JSON to any URL, for wiring
+// runs into automation systems.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client // Defaults to http.DefaultClient
+}
+
+type webhookUser struct {
+	Rank   int     `json:"rank"`
+	User   string  `json:"user"`
+	Score  float64 `json:"score"`
+	Change float64 `json:"change"` // Score change against the previous run, 0 without history
+}
+
+type webhookPayload struct {
+	Event        string        `json:"event"`
+	Organization string        `json:"organization,omitempty"`
+	Since        time.Time     `json:"since"`
+	Until        *time.Time    `json:"until,omitempty"`
+	Users        int           `json:"users"`
+	Totals       jsonMetrics   `json:"totals"`
+	Leaders      []webhookUser `json:"leaders"`
+	Movers       []webhookUser `json:"movers"`
+	Inactive     []string      `json:"inactive"` // Users without activity, with --include-inactive
+	Alert        bool          `json:"alert"`    // Inactive reached the alerting threshold
+}
+
+// Notify posts the summary as a run.completed event.
+func (n WebhookNotifier) Notify(ctx context.Context, summary Summary) error {
+	return postJSON(ctx, n.Client, n.URL, newWebhookPayload(summary))
+}
+
+func newWebhookPayload(s Summary) webhookPayload {
+	payload := webhookPayload{
+		Event:        EventRunCompleted,
+		Organization: s.Organization,
+		Since:        s.Since,
+		Users:        s.Users,
+		Totals:       newJSONMetrics(s.Totals),
+		Leaders:      []webhookUser{},
+		Movers:       []webhookUser{},
+		Inactive:     []string{},
+		Alert:        s.Alert,
+	}
+	if s.Inactive != nil {
+		payload.Inactive = s.Inactive
+	}
+	if !s.Until.IsZero() {
+		payload.Until = &s.Until
+	}
+	for _, view := range s.Leaders {
+		payload.Leaders = append(payload.Leaders, newWebhookUser(view))
+	}
+	for _, view := range s.Movers {
+		payload.Movers = append(payload.Movers, newWebhookUser(view))
+	}
+	return payload
+}
+
+func newWebhookUser(view UserMetricsView) webhookUser {
+	user := webhookUser{Rank: view.Rank, User: view.User, Score: view.Metrics.Score}
+	if view.Delta != nil {
+		user.Change = view.Delta.Score.Change
+	}
+	return user
+}
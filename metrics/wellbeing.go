@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WorkingHours are the hours of the working days, Monday to Friday, in a
+// time zone. Commits and reviews at other times, or on weekends, are off
+// hours: they are reported to spot people at risk of burning out, and never
+// count towards the score.
+type WorkingHours struct {
+	Start    time.Duration // Since midnight
+	End      time.Duration // Since midnight, after Start
+	Location *time.Location
+}
+
+// ParseWorkingHours reads working hours given as HH:MM-HH:MM, e.g.
+// 09:00-18:00, in the location.
+func ParseWorkingHours(value string, location *time.Location) (WorkingHours, error) {
+	from, to, ok := strings.Cut(value, "-")
+	if !ok {
+		return WorkingHours{}, fmt.Errorf("working hours %q are not HH:MM-HH:MM", value)
+	}
+	start, err := time.Parse("15:04", strings.TrimSpace(from))
+	if err != nil {
+		return WorkingHours{}, fmt.Errorf("working hours %q are not HH:MM-HH:MM", value)
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(to))
+	if err != nil {
+		return WorkingHours{}, fmt.Errorf("working hours %q are not HH:MM-HH:MM", value)
+	}
+	hours := WorkingHours{
+		Start:    time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute,
+		End:      time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute,
+		Location: location,
+	}
+	if hours.End <= hours.Start {
+		return WorkingHours{}, fmt.Errorf("working hours %q end before they start", value)
+	}
+	return hours, nil
+}
+
+// IsZero reports whether no working hours are set, so off hours are not
+// collected.
+func (w WorkingHours) IsZero() bool {
+	return w.End == 0
+}
+
+// String describes the working hours, e.g. 09:00-18:00 Europe/Berlin.
+func (w WorkingHours) String() string {
+	if w.IsZero() {
+		return ""
+	}
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	location := "UTC"
+	if w.Location != nil {
+		location = w.Location.String()
+	}
+	return clock(w.Start) + "-" + clock(w.End) + " " + location
+}
+
+// OffHours reports whether t falls on a weekend or outside the working
+// hours of its day.
+func (w WorkingHours) OffHours(t time.Time) bool {
+	if w.Location != nil {
+		t = t.In(w.Location)
+	}
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return true
+	}
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	return clock < w.Start || clock >= w.End
+}
+
+// OffHoursShare returns the percentage of the user's commits and submitted
+// reviews made off hours, or 0 without any.
+func (m UserMetrics) OffHoursShare() float64 {
+	total := m.Commits + m.SubmittedReviews
+	if total == 0 {
+		return 0
+	}
+	return float64(m.OffHoursCommits+m.OffHoursReviews) * 100 / float64(total)
+}
@@ -0,0 +1,171 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// WorkflowsDir is where GitHub Actions reads a repository's workflows from.
+const WorkflowsDir = ".github/workflows"
+
+// isWorkflowFile reports whether a file is a GitHub Actions workflow.
+func isWorkflowFile(filename string) bool {
+	ext := path.Ext(filename)
+	return path.Dir(filename) == WorkflowsDir && (ext == ".yml" || ext == ".yaml")
+}
+
+// workflows counts the workflow files the user added or changed and the
+// workflow runs the user's commits fixed during the window.
+func (c *GitHubCollector) workflows(ctx context.Context, owner, repo, user string) UserMetrics {
+	var m UserMetrics
+	commits, err := c.workflowCommits(ctx, owner, repo)
+	if err != nil {
+		logError(ctx, err, "fetching workflow commits in repo %s/%s", owner, repo)
+	}
+	added := make(map[string]bool)
+	for _, commit := range commits {
+		if isMergeCommit(commit) || !c.Identities.IsCommitAuthor(user, commit.GetAuthor().GetLogin(), commit.GetCommit().GetAuthor().GetEmail()) {
+			continue
+		}
+		details, err := c.commitDetails(ctx, owner, repo, commit.GetSHA())
+		if err != nil {
+			logError(ctx, err, "fetching commit details for commit %s", commit.GetSHA())
+			continue
+		}
+		for _, file := range details.Files {
+			if !isWorkflowFile(file.Filename) {
+				continue
+			}
+			added[file.Filename] = added[file.Filename] || file.Status == "added"
+			c.Evidence.Add(user, EvidenceItem{Metric: MetricWorkflows, Kind: "workflow_change", Repo: owner + "/" + repo, ID: commit.GetSHA() + ":" + file.Filename, URL: commit.GetHTMLURL()})
+		}
+	}
+	for _, isNew := range added {
+		if isNew {
+			m.WorkflowsAdded++
+		} else {
+			m.WorkflowsModified++
+		}
+	}
+
+	runs, err := c.workflowRuns(ctx, owner, repo)
+	if err != nil {
+		logError(ctx, err, "fetching workflow runs in repo %s/%s", owner, repo)
+	}
+	for _, run := range fixingRuns(runs) {
+		if c.isRunAuthor(user, run) {
+			m.RunsFixed++
+			c.Evidence.Add(user, EvidenceItem{Metric: MetricWorkflows, Kind: "run_fixed", Repo: owner + "/" + repo, ID: strconv.FormatInt(run.GetID(), 10), URL: run.GetHTMLURL()})
+		}
+	}
+	if c.Verbose && (len(added) > 0 || m.RunsFixed > 0) {
+		log.Printf("User %s in repo %s/%s: %d workflow files added, %d changed, %d runs fixed\n", user, owner, repo, m.WorkflowsAdded, m.WorkflowsModified, m.RunsFixed)
+	}
+	return m
+}
+
+// fixingRuns returns the runs that succeeded right after the previous run
+// of the same workflow on the same branch failed. The first run of the
+// window on a branch has no previous run to compare with.
+func fixingRuns(runs []*github.WorkflowRun) []*github.WorkflowRun {
+	type key struct {
+		workflow int64
+		branch   string
+	}
+	byBranch := make(map[key][]*github.WorkflowRun)
+	var keys []key
+	for _, run := range runs {
+		switch run.GetConclusion() {
+		case "success", "failure", "timed_out":
+		default:
+			// Cancelled and skipped runs say nothing about the code.
+			continue
+		}
+		k := key{run.GetWorkflowID(), run.GetHeadBranch()}
+		if _, ok := byBranch[k]; !ok {
+			keys = append(keys, k)
+		}
+		byBranch[k] = append(byBranch[k], run)
+	}
+	var fixed []*github.WorkflowRun
+	for _, k := range keys {
+		branch := byBranch[k]
+		sort.SliceStable(branch, func(i, j int) bool {
+			return branch[i].GetCreatedAt().Before(branch[j].GetCreatedAt().Time)
+		})
+		for i := 1; i < len(branch); i++ {
+			if branch[i].GetConclusion() == "success" && branch[i-1].GetConclusion() != "success" {
+				fixed = append(fixed, branch[i])
+			}
+		}
+	}
+	return fixed
+}
+
+// isRunAuthor reports whether the commit a run tested is the user's, by the
+// author's email, or whether the user triggered the run when the email is
+// not known.
+func (c *GitHubCollector) isRunAuthor(user string, run *github.WorkflowRun) bool {
+	email := run.GetHeadCommit().GetAuthor().GetEmail()
+	if match := noreplyEmail.FindStringSubmatch(email); match != nil {
+		return c.Identities.IsCommitAuthor(user, match[1], email)
+	}
+	if c.Identities.IsCommitAuthor(user, "", email) {
+		return true
+	}
+	return strings.EqualFold(run.GetActor().GetLogin(), user)
+}
+
+// workflowCommits lists the commits of the window that changed the
+// repository's workflows, once for every user. The commits fetched before
+// an error are returned with it.
+func (c *GitHubCollector) workflowCommits(ctx context.Context, owner, repo string) ([]*github.RepositoryCommit, error) {
+	result, err := c.shared.do("workflow-commits/"+owner+"/"+repo, func() (interface{}, error) {
+		var commits []*github.RepositoryCommit
+		opts := &github.CommitsListOptions{Path: WorkflowsDir, Since: c.Since, Until: c.Until, ListOptions: github.ListOptions{PerPage: 100}}
+		for {
+			result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/commits", func() (interface{}, *github.Response, error) {
+				return c.api().ListCommits(ctx, owner, repo, opts)
+			})
+			if err != nil {
+				return commits, err
+			}
+			commits = append(commits, result.([]*github.RepositoryCommit)...)
+			if resp.NextPage == 0 {
+				return commits, nil
+			}
+			opts.Page = resp.NextPage
+		}
+	})
+	return result.([]*github.RepositoryCommit), err
+}
+
+// workflowRuns lists the completed workflow runs created during the
+// window, once for every user. The runs fetched before an error are
+// returned with it.
+func (c *GitHubCollector) workflowRuns(ctx context.Context, owner, repo string) ([]*github.WorkflowRun, error) {
+	result, err := c.shared.do("workflow-runs/"+owner+"/"+repo, func() (interface{}, error) {
+		var runs []*github.WorkflowRun
+		opts := &github.ListWorkflowRunsOptions{Status: "completed", Created: c.dateRange(">="), ListOptions: github.ListOptions{PerPage: 100}}
+		for {
+			result, resp, err := c.retryWithBackoff(ctx, resourceCore, "GET /repos/{owner}/{repo}/actions/runs", func() (interface{}, *github.Response, error) {
+				return c.api().ListWorkflowRuns(ctx, owner, repo, opts)
+			})
+			if err != nil {
+				return runs, err
+			}
+			runs = append(runs, result.(*github.WorkflowRuns).WorkflowRuns...)
+			if resp.NextPage == 0 {
+				return runs, nil
+			}
+			opts.Page = resp.NextPage
+		}
+	})
+	return result.([]*github.WorkflowRun), err
+}
@@ -0,0 +1,5 @@
+// Package metricspb holds the Go code generated from metrics.proto, the
+// schema of the gRPC API serve offers with --grpc-listen.
+package metricspb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative metrics.proto
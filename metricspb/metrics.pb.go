@@ -0,0 +1,1755 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        (unknown)
+// source: metrics.proto
+
+package metricspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Run is a stored snapshot of the metrics of all users.
+type Run struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id      int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	TakenAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=taken_at,json=takenAt,proto3" json:"taken_at,omitempty"` // When the run finished
+	Since   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=since,proto3" json:"since,omitempty"`                    // Start of the measured window
+	Metric  string                 `protobuf:"bytes,4,opt,name=metric,proto3" json:"metric,omitempty"`
+	Users   int32                  `protobuf:"varint,5,opt,name=users,proto3" json:"users,omitempty"` // Users with metrics in the run
+}
+
+func (x *Run) Reset() {
+	*x = Run{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Run) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Run) ProtoMessage() {}
+
+func (x *Run) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Run.ProtoReflect.Descriptor instead.
+func (*Run) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Run) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Run) GetTakenAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TakenAt
+	}
+	return nil
+}
+
+func (x *Run) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *Run) GetMetric() string {
+	if x != nil {
+		return x.Metric
+	}
+	return ""
+}
+
+func (x *Run) GetUsers() int32 {
+	if x != nil {
+		return x.Users
+	}
+	return 0
+}
+
+type ListRunsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Metric string `protobuf:"bytes,1,opt,name=metric,proto3" json:"metric,omitempty"`
+	// Runs taken from from until to; an unset bound leaves that side open.
+	From *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+}
+
+func (x *ListRunsRequest) Reset() {
+	*x = ListRunsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListRunsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRunsRequest) ProtoMessage() {}
+
+func (x *ListRunsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRunsRequest.ProtoReflect.Descriptor instead.
+func (*ListRunsRequest) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListRunsRequest) GetMetric() string {
+	if x != nil {
+		return x.Metric
+	}
+	return ""
+}
+
+func (x *ListRunsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ListRunsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+type ListRunsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Runs []*Run `protobuf:"bytes,1,rep,name=runs,proto3" json:"runs,omitempty"`
+}
+
+func (x *ListRunsResponse) Reset() {
+	*x = ListRunsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListRunsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRunsResponse) ProtoMessage() {}
+
+func (x *ListRunsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRunsResponse.ProtoReflect.Descriptor instead.
+func (*ListRunsResponse) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListRunsResponse) GetRuns() []*Run {
+	if x != nil {
+		return x.Runs
+	}
+	return nil
+}
+
+type GetUserMetricsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Login  string `protobuf:"bytes,1,opt,name=login,proto3" json:"login,omitempty"` // Compared case-insensitively
+	Metric string `protobuf:"bytes,2,opt,name=metric,proto3" json:"metric,omitempty"`
+	// Runs taken from from until to; an unset bound leaves that side open.
+	From *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+}
+
+func (x *GetUserMetricsRequest) Reset() {
+	*x = GetUserMetricsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetUserMetricsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserMetricsRequest) ProtoMessage() {}
+
+func (x *GetUserMetricsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserMetricsRequest.ProtoReflect.Descriptor instead.
+func (*GetUserMetricsRequest) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetUserMetricsRequest) GetLogin() string {
+	if x != nil {
+		return x.Login
+	}
+	return ""
+}
+
+func (x *GetUserMetricsRequest) GetMetric() string {
+	if x != nil {
+		return x.Metric
+	}
+	return ""
+}
+
+func (x *GetUserMetricsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *GetUserMetricsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+type GetUserMetricsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	User      string          `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Metric    string          `protobuf:"bytes,2,opt,name=metric,proto3" json:"metric,omitempty"`
+	Snapshots []*UserSnapshot `protobuf:"bytes,3,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
+}
+
+func (x *GetUserMetricsResponse) Reset() {
+	*x = GetUserMetricsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetUserMetricsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserMetricsResponse) ProtoMessage() {}
+
+func (x *GetUserMetricsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserMetricsResponse.ProtoReflect.Descriptor instead.
+func (*GetUserMetricsResponse) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetUserMetricsResponse) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *GetUserMetricsResponse) GetMetric() string {
+	if x != nil {
+		return x.Metric
+	}
+	return ""
+}
+
+func (x *GetUserMetricsResponse) GetSnapshots() []*UserSnapshot {
+	if x != nil {
+		return x.Snapshots
+	}
+	return nil
+}
+
+// UserSnapshot is a user's metrics in one run.
+type UserSnapshot struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Run     *Run         `protobuf:"bytes,1,opt,name=run,proto3" json:"run,omitempty"`
+	Rank    int32        `protobuf:"varint,2,opt,name=rank,proto3" json:"rank,omitempty"` // Position by descending score, starting at 1
+	Metrics *UserMetrics `protobuf:"bytes,3,opt,name=metrics,proto3" json:"metrics,omitempty"`
+}
+
+func (x *UserSnapshot) Reset() {
+	*x = UserSnapshot{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UserSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserSnapshot) ProtoMessage() {}
+
+func (x *UserSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserSnapshot.ProtoReflect.Descriptor instead.
+func (*UserSnapshot) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UserSnapshot) GetRun() *Run {
+	if x != nil {
+		return x.Run
+	}
+	return nil
+}
+
+func (x *UserSnapshot) GetRank() int32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *UserSnapshot) GetMetrics() *UserMetrics {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+type GetLeaderboardRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Metric string `protobuf:"bytes,1,opt,name=metric,proto3" json:"metric,omitempty"`
+	// A day (2024-03-31), a month (2024-03), a year (2024) or, by default,
+	// latest, in the server's local time.
+	Period string `protobuf:"bytes,2,opt,name=period,proto3" json:"period,omitempty"`
+}
+
+func (x *GetLeaderboardRequest) Reset() {
+	*x = GetLeaderboardRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetLeaderboardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLeaderboardRequest) ProtoMessage() {}
+
+func (x *GetLeaderboardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLeaderboardRequest.ProtoReflect.Descriptor instead.
+func (*GetLeaderboardRequest) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetLeaderboardRequest) GetMetric() string {
+	if x != nil {
+		return x.Metric
+	}
+	return ""
+}
+
+func (x *GetLeaderboardRequest) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+type GetLeaderboardResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Run   *Run          `protobuf:"bytes,1,opt,name=run,proto3" json:"run,omitempty"`
+	Users []*RankedUser `protobuf:"bytes,2,rep,name=users,proto3" json:"users,omitempty"` // Best first
+}
+
+func (x *GetLeaderboardResponse) Reset() {
+	*x = GetLeaderboardResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetLeaderboardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLeaderboardResponse) ProtoMessage() {}
+
+func (x *GetLeaderboardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLeaderboardResponse.ProtoReflect.Descriptor instead.
+func (*GetLeaderboardResponse) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetLeaderboardResponse) GetRun() *Run {
+	if x != nil {
+		return x.Run
+	}
+	return nil
+}
+
+func (x *GetLeaderboardResponse) GetUsers() []*RankedUser {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+type RankedUser struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rank     int32        `protobuf:"varint,1,opt,name=rank,proto3" json:"rank,omitempty"`
+	User     string       `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	Metrics  *UserMetrics `protobuf:"bytes,3,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	Newcomer bool         `protobuf:"varint,4,opt,name=newcomer,proto3" json:"newcomer,omitempty"` // First pull request falls into the window
+}
+
+func (x *RankedUser) Reset() {
+	*x = RankedUser{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RankedUser) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RankedUser) ProtoMessage() {}
+
+func (x *RankedUser) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RankedUser.ProtoReflect.Descriptor instead.
+func (*RankedUser) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RankedUser) GetRank() int32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *RankedUser) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *RankedUser) GetMetrics() *UserMetrics {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *RankedUser) GetNewcomer() bool {
+	if x != nil {
+		return x.Newcomer
+	}
+	return false
+}
+
+type PullSizeCount struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Label   string `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	Count   int64  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	Percent int64  `protobuf:"varint,3,opt,name=percent,proto3" json:"percent,omitempty"`
+}
+
+func (x *PullSizeCount) Reset() {
+	*x = PullSizeCount{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PullSizeCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullSizeCount) ProtoMessage() {}
+
+func (x *PullSizeCount) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullSizeCount.ProtoReflect.Descriptor instead.
+func (*PullSizeCount) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PullSizeCount) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *PullSizeCount) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *PullSizeCount) GetPercent() int64 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+// UserMetrics has the metrics of the JSON output format.
+type UserMetrics struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Commits                 int64            `protobuf:"varint,1,opt,name=commits,proto3" json:"commits,omitempty"`
+	Hoc                     int64            `protobuf:"varint,2,opt,name=hoc,proto3" json:"hoc,omitempty"`
+	Issues                  int64            `protobuf:"varint,3,opt,name=issues,proto3" json:"issues,omitempty"`
+	Lcp                     float64          `protobuf:"fixed64,4,opt,name=lcp,proto3" json:"lcp,omitempty"`
+	Msgs                    int64            `protobuf:"varint,5,opt,name=msgs,proto3" json:"msgs,omitempty"`
+	Pulls                   int64            `protobuf:"varint,6,opt,name=pulls,proto3" json:"pulls,omitempty"`
+	Reviews                 int64            `protobuf:"varint,7,opt,name=reviews,proto3" json:"reviews,omitempty"`
+	Score                   float64          `protobuf:"fixed64,8,opt,name=score,proto3" json:"score,omitempty"`
+	Repos                   map[string]int64 `protobuf:"bytes,9,rep,name=repos,proto3" json:"repos,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"` // Lines changed by repository
+	Deletions               int64            `protobuf:"varint,10,opt,name=deletions,proto3" json:"deletions,omitempty"`
+	Churn                   int64            `protobuf:"varint,11,opt,name=churn,proto3" json:"churn,omitempty"`
+	NetLines                int64            `protobuf:"varint,12,opt,name=net_lines,json=netLines,proto3" json:"net_lines,omitempty"`
+	Docs                    int64            `protobuf:"varint,13,opt,name=docs,proto3" json:"docs,omitempty"`
+	DocsCommits             int64            `protobuf:"varint,14,opt,name=docs_commits,json=docsCommits,proto3" json:"docs_commits,omitempty"`
+	IssueComments           int64            `protobuf:"varint,15,opt,name=issue_comments,json=issueComments,proto3" json:"issue_comments,omitempty"`
+	PrComments              int64            `protobuf:"varint,16,opt,name=pr_comments,json=prComments,proto3" json:"pr_comments,omitempty"`
+	ReviewComments          int64            `protobuf:"varint,17,opt,name=review_comments,json=reviewComments,proto3" json:"review_comments,omitempty"`
+	Approvals               int64            `protobuf:"varint,18,opt,name=approvals,proto3" json:"approvals,omitempty"`
+	ChangesRequested        int64            `protobuf:"varint,19,opt,name=changes_requested,json=changesRequested,proto3" json:"changes_requested,omitempty"`
+	TimeToFirstReview       float64          `protobuf:"fixed64,20,opt,name=time_to_first_review,json=timeToFirstReview,proto3" json:"time_to_first_review,omitempty"`
+	PendingReviewRequests   int64            `protobuf:"varint,21,opt,name=pending_review_requests,json=pendingReviewRequests,proto3" json:"pending_review_requests,omitempty"`
+	IgnoredReviewRequests   int64            `protobuf:"varint,22,opt,name=ignored_review_requests,json=ignoredReviewRequests,proto3" json:"ignored_review_requests,omitempty"`
+	CodeOwnerPulls          int64            `protobuf:"varint,23,opt,name=code_owner_pulls,json=codeOwnerPulls,proto3" json:"code_owner_pulls,omitempty"`
+	CodeOwnerReviews        int64            `protobuf:"varint,24,opt,name=code_owner_reviews,json=codeOwnerReviews,proto3" json:"code_owner_reviews,omitempty"`
+	PullSizes               []*PullSizeCount `protobuf:"bytes,25,rep,name=pull_sizes,json=pullSizes,proto3" json:"pull_sizes,omitempty"`
+	MedianPullSize          int64            `protobuf:"varint,26,opt,name=median_pull_size,json=medianPullSize,proto3" json:"median_pull_size,omitempty"`
+	ReviewedPulls           int64            `protobuf:"varint,27,opt,name=reviewed_pulls,json=reviewedPulls,proto3" json:"reviewed_pulls,omitempty"`
+	MedianLcp               float64          `protobuf:"fixed64,28,opt,name=median_lcp,json=medianLcp,proto3" json:"median_lcp,omitempty"`
+	FirstReviewWait         float64          `protobuf:"fixed64,29,opt,name=first_review_wait,json=firstReviewWait,proto3" json:"first_review_wait,omitempty"`
+	MedianFirstReviewWait   float64          `protobuf:"fixed64,30,opt,name=median_first_review_wait,json=medianFirstReviewWait,proto3" json:"median_first_review_wait,omitempty"`
+	ReviewRounds            float64          `protobuf:"fixed64,31,opt,name=review_rounds,json=reviewRounds,proto3" json:"review_rounds,omitempty"`
+	MedianReviewRounds      float64          `protobuf:"fixed64,32,opt,name=median_review_rounds,json=medianReviewRounds,proto3" json:"median_review_rounds,omitempty"`
+	MergeWait               float64          `protobuf:"fixed64,33,opt,name=merge_wait,json=mergeWait,proto3" json:"merge_wait,omitempty"`
+	MedianMergeWait         float64          `protobuf:"fixed64,34,opt,name=median_merge_wait,json=medianMergeWait,proto3" json:"median_merge_wait,omitempty"`
+	Labeled                 int64            `protobuf:"varint,35,opt,name=labeled,proto3" json:"labeled,omitempty"`
+	Assigned                int64            `protobuf:"varint,36,opt,name=assigned,proto3" json:"assigned,omitempty"`
+	IssuesClosed            int64            `protobuf:"varint,37,opt,name=issues_closed,json=issuesClosed,proto3" json:"issues_closed,omitempty"`
+	TimeToTriage            float64          `protobuf:"fixed64,38,opt,name=time_to_triage,json=timeToTriage,proto3" json:"time_to_triage,omitempty"`
+	IssuesClosedManually    int64            `protobuf:"varint,39,opt,name=issues_closed_manually,json=issuesClosedManually,proto3" json:"issues_closed_manually,omitempty"`
+	IssuesFixed             int64            `protobuf:"varint,40,opt,name=issues_fixed,json=issuesFixed,proto3" json:"issues_fixed,omitempty"`
+	PlannedIssues           int64            `protobuf:"varint,41,opt,name=planned_issues,json=plannedIssues,proto3" json:"planned_issues,omitempty"`
+	PlannedPulls            int64            `protobuf:"varint,42,opt,name=planned_pulls,json=plannedPulls,proto3" json:"planned_pulls,omitempty"`
+	DiscussionsStarted      int64            `protobuf:"varint,43,opt,name=discussions_started,json=discussionsStarted,proto3" json:"discussions_started,omitempty"`
+	DiscussionComments      int64            `protobuf:"varint,44,opt,name=discussion_comments,json=discussionComments,proto3" json:"discussion_comments,omitempty"`
+	DiscussionAnswers       int64            `protobuf:"varint,45,opt,name=discussion_answers,json=discussionAnswers,proto3" json:"discussion_answers,omitempty"`
+	Reverts                 int64            `protobuf:"varint,46,opt,name=reverts,proto3" json:"reverts,omitempty"`
+	ForcePushes             int64            `protobuf:"varint,47,opt,name=force_pushes,json=forcePushes,proto3" json:"force_pushes,omitempty"`
+	ReleasesPublished       int64            `protobuf:"varint,48,opt,name=releases_published,json=releasesPublished,proto3" json:"releases_published,omitempty"`
+	TagsCreated             int64            `protobuf:"varint,49,opt,name=tags_created,json=tagsCreated,proto3" json:"tags_created,omitempty"`
+	ShippedIn               int64            `protobuf:"varint,50,opt,name=shipped_in,json=shippedIn,proto3" json:"shipped_in,omitempty"`
+	WorkflowsAdded          int64            `protobuf:"varint,51,opt,name=workflows_added,json=workflowsAdded,proto3" json:"workflows_added,omitempty"`
+	WorkflowsModified       int64            `protobuf:"varint,52,opt,name=workflows_modified,json=workflowsModified,proto3" json:"workflows_modified,omitempty"`
+	RunsFixed               int64            `protobuf:"varint,53,opt,name=runs_fixed,json=runsFixed,proto3" json:"runs_fixed,omitempty"`
+	SecurityAlertsFixed     int64            `protobuf:"varint,54,opt,name=security_alerts_fixed,json=securityAlertsFixed,proto3" json:"security_alerts_fixed,omitempty"`
+	SecurityAlertsDismissed int64            `protobuf:"varint,55,opt,name=security_alerts_dismissed,json=securityAlertsDismissed,proto3" json:"security_alerts_dismissed,omitempty"`
+	DependabotPullsMerged   int64            `protobuf:"varint,56,opt,name=dependabot_pulls_merged,json=dependabotPullsMerged,proto3" json:"dependabot_pulls_merged,omitempty"`
+	DependabotPullsReviewed int64            `protobuf:"varint,57,opt,name=dependabot_pulls_reviewed,json=dependabotPullsReviewed,proto3" json:"dependabot_pulls_reviewed,omitempty"`
+	PublicActivity          int64            `protobuf:"varint,58,opt,name=public_activity,json=publicActivity,proto3" json:"public_activity,omitempty"`
+	PrivateActivity         int64            `protobuf:"varint,59,opt,name=private_activity,json=privateActivity,proto3" json:"private_activity,omitempty"`
+	InternalActivity        int64            `protobuf:"varint,60,opt,name=internal_activity,json=internalActivity,proto3" json:"internal_activity,omitempty"`
+	OffHoursCommits         int64            `protobuf:"varint,61,opt,name=off_hours_commits,json=offHoursCommits,proto3" json:"off_hours_commits,omitempty"`
+	OffHoursReviews         int64            `protobuf:"varint,62,opt,name=off_hours_reviews,json=offHoursReviews,proto3" json:"off_hours_reviews,omitempty"`
+	SubmittedReviews        int64            `protobuf:"varint,63,opt,name=submitted_reviews,json=submittedReviews,proto3" json:"submitted_reviews,omitempty"`
+	OffHoursShare           float64          `protobuf:"fixed64,64,opt,name=off_hours_share,json=offHoursShare,proto3" json:"off_hours_share,omitempty"`
+	Tone                    float64          `protobuf:"fixed64,65,opt,name=tone,proto3" json:"tone,omitempty"`
+	ToneComments            int64            `protobuf:"varint,66,opt,name=tone_comments,json=toneComments,proto3" json:"tone_comments,omitempty"`
+	HarshComments           int64            `protobuf:"varint,67,opt,name=harsh_comments,json=harshComments,proto3" json:"harsh_comments,omitempty"`
+	ActiveDays              int64            `protobuf:"varint,68,opt,name=active_days,json=activeDays,proto3" json:"active_days,omitempty"`
+	LongestStreak           int64            `protobuf:"varint,69,opt,name=longest_streak,json=longestStreak,proto3" json:"longest_streak,omitempty"`
+	Unknown                 []string         `protobuf:"bytes,70,rep,name=unknown,proto3" json:"unknown,omitempty"` // Metrics that could not be collected
+}
+
+func (x *UserMetrics) Reset() {
+	*x = UserMetrics{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_metrics_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UserMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserMetrics) ProtoMessage() {}
+
+func (x *UserMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserMetrics.ProtoReflect.Descriptor instead.
+func (*UserMetrics) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UserMetrics) GetCommits() int64 {
+	if x != nil {
+		return x.Commits
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetHoc() int64 {
+	if x != nil {
+		return x.Hoc
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetIssues() int64 {
+	if x != nil {
+		return x.Issues
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetLcp() float64 {
+	if x != nil {
+		return x.Lcp
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetMsgs() int64 {
+	if x != nil {
+		return x.Msgs
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetPulls() int64 {
+	if x != nil {
+		return x.Pulls
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetReviews() int64 {
+	if x != nil {
+		return x.Reviews
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetRepos() map[string]int64 {
+	if x != nil {
+		return x.Repos
+	}
+	return nil
+}
+
+func (x *UserMetrics) GetDeletions() int64 {
+	if x != nil {
+		return x.Deletions
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetChurn() int64 {
+	if x != nil {
+		return x.Churn
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetNetLines() int64 {
+	if x != nil {
+		return x.NetLines
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetDocs() int64 {
+	if x != nil {
+		return x.Docs
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetDocsCommits() int64 {
+	if x != nil {
+		return x.DocsCommits
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetIssueComments() int64 {
+	if x != nil {
+		return x.IssueComments
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetPrComments() int64 {
+	if x != nil {
+		return x.PrComments
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetReviewComments() int64 {
+	if x != nil {
+		return x.ReviewComments
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetApprovals() int64 {
+	if x != nil {
+		return x.Approvals
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetChangesRequested() int64 {
+	if x != nil {
+		return x.ChangesRequested
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetTimeToFirstReview() float64 {
+	if x != nil {
+		return x.TimeToFirstReview
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetPendingReviewRequests() int64 {
+	if x != nil {
+		return x.PendingReviewRequests
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetIgnoredReviewRequests() int64 {
+	if x != nil {
+		return x.IgnoredReviewRequests
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetCodeOwnerPulls() int64 {
+	if x != nil {
+		return x.CodeOwnerPulls
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetCodeOwnerReviews() int64 {
+	if x != nil {
+		return x.CodeOwnerReviews
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetPullSizes() []*PullSizeCount {
+	if x != nil {
+		return x.PullSizes
+	}
+	return nil
+}
+
+func (x *UserMetrics) GetMedianPullSize() int64 {
+	if x != nil {
+		return x.MedianPullSize
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetReviewedPulls() int64 {
+	if x != nil {
+		return x.ReviewedPulls
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetMedianLcp() float64 {
+	if x != nil {
+		return x.MedianLcp
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetFirstReviewWait() float64 {
+	if x != nil {
+		return x.FirstReviewWait
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetMedianFirstReviewWait() float64 {
+	if x != nil {
+		return x.MedianFirstReviewWait
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetReviewRounds() float64 {
+	if x != nil {
+		return x.ReviewRounds
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetMedianReviewRounds() float64 {
+	if x != nil {
+		return x.MedianReviewRounds
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetMergeWait() float64 {
+	if x != nil {
+		return x.MergeWait
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetMedianMergeWait() float64 {
+	if x != nil {
+		return x.MedianMergeWait
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetLabeled() int64 {
+	if x != nil {
+		return x.Labeled
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetAssigned() int64 {
+	if x != nil {
+		return x.Assigned
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetIssuesClosed() int64 {
+	if x != nil {
+		return x.IssuesClosed
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetTimeToTriage() float64 {
+	if x != nil {
+		return x.TimeToTriage
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetIssuesClosedManually() int64 {
+	if x != nil {
+		return x.IssuesClosedManually
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetIssuesFixed() int64 {
+	if x != nil {
+		return x.IssuesFixed
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetPlannedIssues() int64 {
+	if x != nil {
+		return x.PlannedIssues
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetPlannedPulls() int64 {
+	if x != nil {
+		return x.PlannedPulls
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetDiscussionsStarted() int64 {
+	if x != nil {
+		return x.DiscussionsStarted
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetDiscussionComments() int64 {
+	if x != nil {
+		return x.DiscussionComments
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetDiscussionAnswers() int64 {
+	if x != nil {
+		return x.DiscussionAnswers
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetReverts() int64 {
+	if x != nil {
+		return x.Reverts
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetForcePushes() int64 {
+	if x != nil {
+		return x.ForcePushes
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetReleasesPublished() int64 {
+	if x != nil {
+		return x.ReleasesPublished
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetTagsCreated() int64 {
+	if x != nil {
+		return x.TagsCreated
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetShippedIn() int64 {
+	if x != nil {
+		return x.ShippedIn
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetWorkflowsAdded() int64 {
+	if x != nil {
+		return x.WorkflowsAdded
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetWorkflowsModified() int64 {
+	if x != nil {
+		return x.WorkflowsModified
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetRunsFixed() int64 {
+	if x != nil {
+		return x.RunsFixed
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetSecurityAlertsFixed() int64 {
+	if x != nil {
+		return x.SecurityAlertsFixed
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetSecurityAlertsDismissed() int64 {
+	if x != nil {
+		return x.SecurityAlertsDismissed
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetDependabotPullsMerged() int64 {
+	if x != nil {
+		return x.DependabotPullsMerged
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetDependabotPullsReviewed() int64 {
+	if x != nil {
+		return x.DependabotPullsReviewed
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetPublicActivity() int64 {
+	if x != nil {
+		return x.PublicActivity
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetPrivateActivity() int64 {
+	if x != nil {
+		return x.PrivateActivity
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetInternalActivity() int64 {
+	if x != nil {
+		return x.InternalActivity
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetOffHoursCommits() int64 {
+	if x != nil {
+		return x.OffHoursCommits
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetOffHoursReviews() int64 {
+	if x != nil {
+		return x.OffHoursReviews
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetSubmittedReviews() int64 {
+	if x != nil {
+		return x.SubmittedReviews
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetOffHoursShare() float64 {
+	if x != nil {
+		return x.OffHoursShare
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetTone() float64 {
+	if x != nil {
+		return x.Tone
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetToneComments() int64 {
+	if x != nil {
+		return x.ToneComments
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetHarshComments() int64 {
+	if x != nil {
+		return x.HarshComments
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetActiveDays() int64 {
+	if x != nil {
+		return x.ActiveDays
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetLongestStreak() int64 {
+	if x != nil {
+		return x.LongestStreak
+	}
+	return 0
+}
+
+func (x *UserMetrics) GetUnknown() []string {
+	if x != nil {
+		return x.Unknown
+	}
+	return nil
+}
+
+var File_metrics_proto protoreflect.FileDescriptor
+
+var file_metrics_proto_rawDesc = []byte{
+	0x0a, 0x0d, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x10, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76,
+	0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x22, 0xac, 0x01, 0x0a, 0x03, 0x52, 0x75, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x35, 0x0a, 0x08, 0x74, 0x61,
+	0x6b, 0x65, 0x6e, 0x5f, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x07, 0x74, 0x61, 0x6b, 0x65, 0x6e, 0x41,
+	0x74, 0x12, 0x30, 0x0a, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x73, 0x69,
+	0x6e, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x12, 0x14, 0x0a, 0x05, 0x75,
+	0x73, 0x65, 0x72, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x75, 0x73, 0x65, 0x72,
+	0x73, 0x22, 0x85, 0x01, 0x0a, 0x0f, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x75, 0x6e, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x12, 0x2e, 0x0a,
+	0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x2a, 0x0a,
+	0x02, 0x74, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x02, 0x74, 0x6f, 0x22, 0x3d, 0x0a, 0x10, 0x4c, 0x69, 0x73,
+	0x74, 0x52, 0x75, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x29, 0x0a,
+	0x04, 0x72, 0x75, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x52,
+	0x75, 0x6e, 0x52, 0x04, 0x72, 0x75, 0x6e, 0x73, 0x22, 0xa1, 0x01, 0x0a, 0x15, 0x47, 0x65, 0x74,
+	0x55, 0x73, 0x65, 0x72, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x72,
+	0x69, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63,
+	0x12, 0x2e, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d,
+	0x12, 0x2a, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x02, 0x74, 0x6f, 0x22, 0x82, 0x01, 0x0a,
+	0x16, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x6d,
+	0x65, 0x74, 0x72, 0x69, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x74,
+	0x72, 0x69, 0x63, 0x12, 0x3c, 0x0a, 0x09, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x6d,
+	0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x53, 0x6e,
+	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x09, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74,
+	0x73, 0x22, 0x84, 0x01, 0x0a, 0x0c, 0x55, 0x73, 0x65, 0x72, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68,
+	0x6f, 0x74, 0x12, 0x27, 0x0a, 0x03, 0x72, 0x75, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x52, 0x75, 0x6e, 0x52, 0x03, 0x72, 0x75, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x72,
+	0x61, 0x6e, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x72, 0x61, 0x6e, 0x6b, 0x12,
+	0x37, 0x0a, 0x07, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1d, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x52,
+	0x07, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x22, 0x47, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x4c,
+	0x65, 0x61, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x65, 0x72,
+	0x69, 0x6f, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x65, 0x72, 0x69, 0x6f,
+	0x64, 0x22, 0x75, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x62, 0x6f,
+	0x61, 0x72, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x27, 0x0a, 0x03, 0x72,
+	0x75, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x75, 0x6e, 0x52,
+	0x03, 0x72, 0x75, 0x6e, 0x12, 0x32, 0x0a, 0x05, 0x75, 0x73, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x6d, 0x65, 0x74, 0x72,
+	0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x61, 0x6e, 0x6b, 0x65, 0x64, 0x55, 0x73, 0x65,
+	0x72, 0x52, 0x05, 0x75, 0x73, 0x65, 0x72, 0x73, 0x22, 0x89, 0x01, 0x0a, 0x0a, 0x52, 0x61, 0x6e,
+	0x6b, 0x65, 0x64, 0x55, 0x73, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x61, 0x6e, 0x6b, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x72, 0x61, 0x6e, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x75,
+	0x73, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12,
+	0x37, 0x0a, 0x07, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1d, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x52,
+	0x07, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x6e, 0x65, 0x77, 0x63,
+	0x6f, 0x6d, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x6e, 0x65, 0x77, 0x63,
+	0x6f, 0x6d, 0x65, 0x72, 0x22, 0x55, 0x0a, 0x0d, 0x50, 0x75, 0x6c, 0x6c, 0x53, 0x69, 0x7a, 0x65,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x07, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x22, 0xdb, 0x15, 0x0a, 0x0b,
+	0x55, 0x73, 0x65, 0x72, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x63,
+	0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x63, 0x6f,
+	0x6d, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x68, 0x6f, 0x63, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x03, 0x68, 0x6f, 0x63, 0x12, 0x16, 0x0a, 0x06, 0x69, 0x73, 0x73, 0x75, 0x65,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x69, 0x73, 0x73, 0x75, 0x65, 0x73, 0x12,
+	0x10, 0x0a, 0x03, 0x6c, 0x63, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x63,
+	0x70, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x73, 0x67, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x04, 0x6d, 0x73, 0x67, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x75, 0x6c, 0x6c, 0x73, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x70, 0x75, 0x6c, 0x6c, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x72,
+	0x65, 0x76, 0x69, 0x65, 0x77, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x72, 0x65,
+	0x76, 0x69, 0x65, 0x77, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x3e, 0x0a, 0x05, 0x72,
+	0x65, 0x70, 0x6f, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73,
+	0x65, 0x72, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x52, 0x65, 0x70, 0x6f, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x05, 0x72, 0x65, 0x70, 0x6f, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x64,
+	0x65, 0x6c, 0x65, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09,
+	0x64, 0x65, 0x6c, 0x65, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x68, 0x75,
+	0x72, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x63, 0x68, 0x75, 0x72, 0x6e, 0x12,
+	0x1b, 0x0a, 0x09, 0x6e, 0x65, 0x74, 0x5f, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x18, 0x0c, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x08, 0x6e, 0x65, 0x74, 0x4c, 0x69, 0x6e, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04,
+	0x64, 0x6f, 0x63, 0x73, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x64, 0x6f, 0x63, 0x73,
+	0x12, 0x21, 0x0a, 0x0c, 0x64, 0x6f, 0x63, 0x73, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x73,
+	0x18, 0x0e, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x64, 0x6f, 0x63, 0x73, 0x43, 0x6f, 0x6d, 0x6d,
+	0x69, 0x74, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x69, 0x73, 0x73, 0x75, 0x65, 0x5f, 0x63, 0x6f, 0x6d,
+	0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x69, 0x73, 0x73,
+	0x75, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x72,
+	0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x10, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0a, 0x70, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x72,
+	0x65, 0x76, 0x69, 0x65, 0x77, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x11,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x43, 0x6f, 0x6d, 0x6d,
+	0x65, 0x6e, 0x74, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x61, 0x6c,
+	0x73, 0x18, 0x12, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x61,
+	0x6c, 0x73, 0x12, 0x2b, 0x0a, 0x11, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x5f, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x18, 0x13, 0x20, 0x01, 0x28, 0x03, 0x52, 0x10, 0x63,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x12,
+	0x2f, 0x0a, 0x14, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x74, 0x6f, 0x5f, 0x66, 0x69, 0x72, 0x73, 0x74,
+	0x5f, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x18, 0x14, 0x20, 0x01, 0x28, 0x01, 0x52, 0x11, 0x74,
+	0x69, 0x6d, 0x65, 0x54, 0x6f, 0x46, 0x69, 0x72, 0x73, 0x74, 0x52, 0x65, 0x76, 0x69, 0x65, 0x77,
+	0x12, 0x36, 0x0a, 0x17, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x76, 0x69,
+	0x65, 0x77, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x18, 0x15, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x15, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x76, 0x69, 0x65, 0x77,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x12, 0x36, 0x0a, 0x17, 0x69, 0x67, 0x6e, 0x6f,
+	0x72, 0x65, 0x64, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x73, 0x18, 0x16, 0x20, 0x01, 0x28, 0x03, 0x52, 0x15, 0x69, 0x67, 0x6e, 0x6f, 0x72,
+	0x65, 0x64, 0x52, 0x65, 0x76, 0x69, 0x65, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73,
+	0x12, 0x28, 0x0a, 0x10, 0x63, 0x6f, 0x64, 0x65, 0x5f, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x5f, 0x70,
+	0x75, 0x6c, 0x6c, 0x73, 0x18, 0x17, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x63, 0x6f, 0x64, 0x65,
+	0x4f, 0x77, 0x6e, 0x65, 0x72, 0x50, 0x75, 0x6c, 0x6c, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x63, 0x6f,
+	0x64, 0x65, 0x5f, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x73,
+	0x18, 0x18, 0x20, 0x01, 0x28, 0x03, 0x52, 0x10, 0x63, 0x6f, 0x64, 0x65, 0x4f, 0x77, 0x6e, 0x65,
+	0x72, 0x52, 0x65, 0x76, 0x69, 0x65, 0x77, 0x73, 0x12, 0x3e, 0x0a, 0x0a, 0x70, 0x75, 0x6c, 0x6c,
+	0x5f, 0x73, 0x69, 0x7a, 0x65, 0x73, 0x18, 0x19, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x50, 0x75, 0x6c, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x09, 0x70,
+	0x75, 0x6c, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x6d, 0x65, 0x64, 0x69,
+	0x61, 0x6e, 0x5f, 0x70, 0x75, 0x6c, 0x6c, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x1a, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0e, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x6e, 0x50, 0x75, 0x6c, 0x6c, 0x53, 0x69,
+	0x7a, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x65, 0x64, 0x5f, 0x70,
+	0x75, 0x6c, 0x6c, 0x73, 0x18, 0x1b, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x72, 0x65, 0x76, 0x69,
+	0x65, 0x77, 0x65, 0x64, 0x50, 0x75, 0x6c, 0x6c, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x65, 0x64,
+	0x69, 0x61, 0x6e, 0x5f, 0x6c, 0x63, 0x70, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x6d,
+	0x65, 0x64, 0x69, 0x61, 0x6e, 0x4c, 0x63, 0x70, 0x12, 0x2a, 0x0a, 0x11, 0x66, 0x69, 0x72, 0x73,
+	0x74, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x5f, 0x77, 0x61, 0x69, 0x74, 0x18, 0x1d, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0f, 0x66, 0x69, 0x72, 0x73, 0x74, 0x52, 0x65, 0x76, 0x69, 0x65, 0x77,
+	0x57, 0x61, 0x69, 0x74, 0x12, 0x37, 0x0a, 0x18, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x6e, 0x5f, 0x66,
+	0x69, 0x72, 0x73, 0x74, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x5f, 0x77, 0x61, 0x69, 0x74,
+	0x18, 0x1e, 0x20, 0x01, 0x28, 0x01, 0x52, 0x15, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x6e, 0x46, 0x69,
+	0x72, 0x73, 0x74, 0x52, 0x65, 0x76, 0x69, 0x65, 0x77, 0x57, 0x61, 0x69, 0x74, 0x12, 0x23, 0x0a,
+	0x0d, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x5f, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x18, 0x1f,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x52, 0x6f, 0x75, 0x6e,
+	0x64, 0x73, 0x12, 0x30, 0x0a, 0x14, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x6e, 0x5f, 0x72, 0x65, 0x76,
+	0x69, 0x65, 0x77, 0x5f, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x18, 0x20, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x12, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x6e, 0x52, 0x65, 0x76, 0x69, 0x65, 0x77, 0x52, 0x6f,
+	0x75, 0x6e, 0x64, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x65, 0x72, 0x67, 0x65, 0x5f, 0x77, 0x61,
+	0x69, 0x74, 0x18, 0x21, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x6d, 0x65, 0x72, 0x67, 0x65, 0x57,
+	0x61, 0x69, 0x74, 0x12, 0x2a, 0x0a, 0x11, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x6e, 0x5f, 0x6d, 0x65,
+	0x72, 0x67, 0x65, 0x5f, 0x77, 0x61, 0x69, 0x74, 0x18, 0x22, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f,
+	0x6d, 0x65, 0x64, 0x69, 0x61, 0x6e, 0x4d, 0x65, 0x72, 0x67, 0x65, 0x57, 0x61, 0x69, 0x74, 0x12,
+	0x18, 0x0a, 0x07, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x65, 0x64, 0x18, 0x23, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x07, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x73, 0x73,
+	0x69, 0x67, 0x6e, 0x65, 0x64, 0x18, 0x24, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x61, 0x73, 0x73,
+	0x69, 0x67, 0x6e, 0x65, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x69, 0x73, 0x73, 0x75, 0x65, 0x73, 0x5f,
+	0x63, 0x6c, 0x6f, 0x73, 0x65, 0x64, 0x18, 0x25, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x69, 0x73,
+	0x73, 0x75, 0x65, 0x73, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x64, 0x12, 0x24, 0x0a, 0x0e, 0x74, 0x69,
+	0x6d, 0x65, 0x5f, 0x74, 0x6f, 0x5f, 0x74, 0x72, 0x69, 0x61, 0x67, 0x65, 0x18, 0x26, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x54, 0x6f, 0x54, 0x72, 0x69, 0x61, 0x67, 0x65,
+	0x12, 0x34, 0x0a, 0x16, 0x69, 0x73, 0x73, 0x75, 0x65, 0x73, 0x5f, 0x63, 0x6c, 0x6f, 0x73, 0x65,
+	0x64, 0x5f, 0x6d, 0x61, 0x6e, 0x75, 0x61, 0x6c, 0x6c, 0x79, 0x18, 0x27, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x14, 0x69, 0x73, 0x73, 0x75, 0x65, 0x73, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x64, 0x4d, 0x61,
+	0x6e, 0x75, 0x61, 0x6c, 0x6c, 0x79, 0x12, 0x21, 0x0a, 0x0c, 0x69, 0x73, 0x73, 0x75, 0x65, 0x73,
+	0x5f, 0x66, 0x69, 0x78, 0x65, 0x64, 0x18, 0x28, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x69, 0x73,
+	0x73, 0x75, 0x65, 0x73, 0x46, 0x69, 0x78, 0x65, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x70, 0x6c, 0x61,
+	0x6e, 0x6e, 0x65, 0x64, 0x5f, 0x69, 0x73, 0x73, 0x75, 0x65, 0x73, 0x18, 0x29, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0d, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x64, 0x49, 0x73, 0x73, 0x75, 0x65, 0x73,
+	0x12, 0x23, 0x0a, 0x0d, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x64, 0x5f, 0x70, 0x75, 0x6c, 0x6c,
+	0x73, 0x18, 0x2a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x70, 0x6c, 0x61, 0x6e, 0x6e, 0x65, 0x64,
+	0x50, 0x75, 0x6c, 0x6c, 0x73, 0x12, 0x2f, 0x0a, 0x13, 0x64, 0x69, 0x73, 0x63, 0x75, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x73, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x18, 0x2b, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x12, 0x64, 0x69, 0x73, 0x63, 0x75, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x53,
+	0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x12, 0x2f, 0x0a, 0x13, 0x64, 0x69, 0x73, 0x63, 0x75, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x2c, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x12, 0x64, 0x69, 0x73, 0x63, 0x75, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43,
+	0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x64, 0x69, 0x73, 0x63, 0x75,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x73, 0x18, 0x2d, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x11, 0x64, 0x69, 0x73, 0x63, 0x75, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x41,
+	0x6e, 0x73, 0x77, 0x65, 0x72, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x76, 0x65, 0x72, 0x74,
+	0x73, 0x18, 0x2e, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x72, 0x65, 0x76, 0x65, 0x72, 0x74, 0x73,
+	0x12, 0x21, 0x0a, 0x0c, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x5f, 0x70, 0x75, 0x73, 0x68, 0x65, 0x73,
+	0x18, 0x2f, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x50, 0x75, 0x73,
+	0x68, 0x65, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x73, 0x5f,
+	0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x64, 0x18, 0x30, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x11, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x73, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68,
+	0x65, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x61, 0x67, 0x73, 0x5f, 0x63, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x64, 0x18, 0x31, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x74, 0x61, 0x67, 0x73, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x68, 0x69, 0x70, 0x70, 0x65, 0x64,
+	0x5f, 0x69, 0x6e, 0x18, 0x32, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x68, 0x69, 0x70, 0x70,
+	0x65, 0x64, 0x49, 0x6e, 0x12, 0x27, 0x0a, 0x0f, 0x77, 0x6f, 0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77,
+	0x73, 0x5f, 0x61, 0x64, 0x64, 0x65, 0x64, 0x18, 0x33, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x77,
+	0x6f, 0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x73, 0x41, 0x64, 0x64, 0x65, 0x64, 0x12, 0x2d, 0x0a,
+	0x12, 0x77, 0x6f, 0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x73, 0x5f, 0x6d, 0x6f, 0x64, 0x69, 0x66,
+	0x69, 0x65, 0x64, 0x18, 0x34, 0x20, 0x01, 0x28, 0x03, 0x52, 0x11, 0x77, 0x6f, 0x72, 0x6b, 0x66,
+	0x6c, 0x6f, 0x77, 0x73, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x12, 0x1d, 0x0a, 0x0a,
+	0x72, 0x75, 0x6e, 0x73, 0x5f, 0x66, 0x69, 0x78, 0x65, 0x64, 0x18, 0x35, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x09, 0x72, 0x75, 0x6e, 0x73, 0x46, 0x69, 0x78, 0x65, 0x64, 0x12, 0x32, 0x0a, 0x15, 0x73,
+	0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x5f, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x73, 0x5f, 0x66,
+	0x69, 0x78, 0x65, 0x64, 0x18, 0x36, 0x20, 0x01, 0x28, 0x03, 0x52, 0x13, 0x73, 0x65, 0x63, 0x75,
+	0x72, 0x69, 0x74, 0x79, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x73, 0x46, 0x69, 0x78, 0x65, 0x64, 0x12,
+	0x3a, 0x0a, 0x19, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x5f, 0x61, 0x6c, 0x65, 0x72,
+	0x74, 0x73, 0x5f, 0x64, 0x69, 0x73, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x64, 0x18, 0x37, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x17, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x41, 0x6c, 0x65, 0x72,
+	0x74, 0x73, 0x44, 0x69, 0x73, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x64, 0x12, 0x36, 0x0a, 0x17, 0x64,
+	0x65, 0x70, 0x65, 0x6e, 0x64, 0x61, 0x62, 0x6f, 0x74, 0x5f, 0x70, 0x75, 0x6c, 0x6c, 0x73, 0x5f,
+	0x6d, 0x65, 0x72, 0x67, 0x65, 0x64, 0x18, 0x38, 0x20, 0x01, 0x28, 0x03, 0x52, 0x15, 0x64, 0x65,
+	0x70, 0x65, 0x6e, 0x64, 0x61, 0x62, 0x6f, 0x74, 0x50, 0x75, 0x6c, 0x6c, 0x73, 0x4d, 0x65, 0x72,
+	0x67, 0x65, 0x64, 0x12, 0x3a, 0x0a, 0x19, 0x64, 0x65, 0x70, 0x65, 0x6e, 0x64, 0x61, 0x62, 0x6f,
+	0x74, 0x5f, 0x70, 0x75, 0x6c, 0x6c, 0x73, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x65, 0x64,
+	0x18, 0x39, 0x20, 0x01, 0x28, 0x03, 0x52, 0x17, 0x64, 0x65, 0x70, 0x65, 0x6e, 0x64, 0x61, 0x62,
+	0x6f, 0x74, 0x50, 0x75, 0x6c, 0x6c, 0x73, 0x52, 0x65, 0x76, 0x69, 0x65, 0x77, 0x65, 0x64, 0x12,
+	0x27, 0x0a, 0x0f, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x76, 0x69,
+	0x74, 0x79, 0x18, 0x3a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63,
+	0x41, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x12, 0x29, 0x0a, 0x10, 0x70, 0x72, 0x69, 0x76,
+	0x61, 0x74, 0x65, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x18, 0x3b, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0f, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x41, 0x63, 0x74, 0x69, 0x76,
+	0x69, 0x74, 0x79, 0x12, 0x2b, 0x0a, 0x11, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x5f,
+	0x61, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x18, 0x3c, 0x20, 0x01, 0x28, 0x03, 0x52, 0x10,
+	0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x41, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79,
+	0x12, 0x2a, 0x0a, 0x11, 0x6f, 0x66, 0x66, 0x5f, 0x68, 0x6f, 0x75, 0x72, 0x73, 0x5f, 0x63, 0x6f,
+	0x6d, 0x6d, 0x69, 0x74, 0x73, 0x18, 0x3d, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x6f, 0x66, 0x66,
+	0x48, 0x6f, 0x75, 0x72, 0x73, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x2a, 0x0a, 0x11,
+	0x6f, 0x66, 0x66, 0x5f, 0x68, 0x6f, 0x75, 0x72, 0x73, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77,
+	0x73, 0x18, 0x3e, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x6f, 0x66, 0x66, 0x48, 0x6f, 0x75, 0x72,
+	0x73, 0x52, 0x65, 0x76, 0x69, 0x65, 0x77, 0x73, 0x12, 0x2b, 0x0a, 0x11, 0x73, 0x75, 0x62, 0x6d,
+	0x69, 0x74, 0x74, 0x65, 0x64, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x73, 0x18, 0x3f, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x10, 0x73, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x52, 0x65,
+	0x76, 0x69, 0x65, 0x77, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6f, 0x66, 0x66, 0x5f, 0x68, 0x6f, 0x75,
+	0x72, 0x73, 0x5f, 0x73, 0x68, 0x61, 0x72, 0x65, 0x18, 0x40, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d,
+	0x6f, 0x66, 0x66, 0x48, 0x6f, 0x75, 0x72, 0x73, 0x53, 0x68, 0x61, 0x72, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x74, 0x6f, 0x6e, 0x65, 0x18, 0x41, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x74, 0x6f, 0x6e,
+	0x65, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x6f, 0x6e, 0x65, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e,
+	0x74, 0x73, 0x18, 0x42, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x74, 0x6f, 0x6e, 0x65, 0x43, 0x6f,
+	0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x68, 0x61, 0x72, 0x73, 0x68, 0x5f,
+	0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x43, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d,
+	0x68, 0x61, 0x72, 0x73, 0x68, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1f, 0x0a,
+	0x0b, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x5f, 0x64, 0x61, 0x79, 0x73, 0x18, 0x44, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0a, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x44, 0x61, 0x79, 0x73, 0x12, 0x25,
+	0x0a, 0x0e, 0x6c, 0x6f, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x5f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6b,
+	0x18, 0x45, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x6c, 0x6f, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x53,
+	0x74, 0x72, 0x65, 0x61, 0x6b, 0x12, 0x18, 0x0a, 0x07, 0x75, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e,
+	0x18, 0x46, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x75, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x1a,
+	0x38, 0x0a, 0x0a, 0x52, 0x65, 0x70, 0x6f, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x32, 0xad, 0x02, 0x0a, 0x0e, 0x4d, 0x65,
+	0x74, 0x72, 0x69, 0x63, 0x73, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x51, 0x0a, 0x08,
+	0x4c, 0x69, 0x73, 0x74, 0x52, 0x75, 0x6e, 0x73, 0x12, 0x21, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x52, 0x75, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x52, 0x75, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x63, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63,
+	0x73, 0x12, 0x27, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x4d, 0x65, 0x74, 0x72,
+	0x69, 0x63, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65,
+	0x74, 0x55, 0x73, 0x65, 0x72, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x63, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x12, 0x27, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x6d,
+	0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x28, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x61, 0x72,
+	0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x1b, 0x5a, 0x19, 0x68, 0x61, 0x6e,
+	0x64, 0x73, 0x68, 0x61, 0x6b, 0x65, 0x2f, 0x73, 0x74, 0x61, 0x74, 0x73, 0x2f, 0x6d, 0x65, 0x74,
+	0x72, 0x69, 0x63, 0x73, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_metrics_proto_rawDescOnce sync.Once
+	file_metrics_proto_rawDescData = file_metrics_proto_rawDesc
+)
+
+func file_metrics_proto_rawDescGZIP() []byte {
+	file_metrics_proto_rawDescOnce.Do(func() {
+		file_metrics_proto_rawDescData = protoimpl.X.CompressGZIP(file_metrics_proto_rawDescData)
+	})
+	return file_metrics_proto_rawDescData
+}
+
+var file_metrics_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_metrics_proto_goTypes = []interface{}{
+	(*Run)(nil),                    // 0: githubmetrics.v1.Run
+	(*ListRunsRequest)(nil),        // 1: githubmetrics.v1.ListRunsRequest
+	(*ListRunsResponse)(nil),       // 2: githubmetrics.v1.ListRunsResponse
+	(*GetUserMetricsRequest)(nil),  // 3: githubmetrics.v1.GetUserMetricsRequest
+	(*GetUserMetricsResponse)(nil), // 4: githubmetrics.v1.GetUserMetricsResponse
+	(*UserSnapshot)(nil),           // 5: githubmetrics.v1.UserSnapshot
+	(*GetLeaderboardRequest)(nil),  // 6: githubmetrics.v1.GetLeaderboardRequest
+	(*GetLeaderboardResponse)(nil), // 7: githubmetrics.v1.GetLeaderboardResponse
+	(*RankedUser)(nil),             // 8: githubmetrics.v1.RankedUser
+	(*PullSizeCount)(nil),          // 9: githubmetrics.v1.PullSizeCount
+	(*UserMetrics)(nil),            // 10: githubmetrics.v1.UserMetrics
+	nil,                            // 11: githubmetrics.v1.UserMetrics.ReposEntry
+	(*timestamppb.Timestamp)(nil),  // 12: google.protobuf.Timestamp
+}
+var file_metrics_proto_depIdxs = []int32{
+	12, // 0: githubmetrics.v1.Run.taken_at:type_name -> google.protobuf.Timestamp
+	12, // 1: githubmetrics.v1.Run.since:type_name -> google.protobuf.Timestamp
+	12, // 2: githubmetrics.v1.ListRunsRequest.from:type_name -> google.protobuf.Timestamp
+	12, // 3: githubmetrics.v1.ListRunsRequest.to:type_name -> google.protobuf.Timestamp
+	0,  // 4: githubmetrics.v1.ListRunsResponse.runs:type_name -> githubmetrics.v1.Run
+	12, // 5: githubmetrics.v1.GetUserMetricsRequest.from:type_name -> google.protobuf.Timestamp
+	12, // 6: githubmetrics.v1.GetUserMetricsRequest.to:type_name -> google.protobuf.Timestamp
+	5,  // 7: githubmetrics.v1.GetUserMetricsResponse.snapshots:type_name -> githubmetrics.v1.UserSnapshot
+	0,  // 8: githubmetrics.v1.UserSnapshot.run:type_name -> githubmetrics.v1.Run
+	10, // 9: githubmetrics.v1.UserSnapshot.metrics:type_name -> githubmetrics.v1.UserMetrics
+	0,  // 10: githubmetrics.v1.GetLeaderboardResponse.run:type_name -> githubmetrics.v1.Run
+	8,  // 11: githubmetrics.v1.GetLeaderboardResponse.users:type_name -> githubmetrics.v1.RankedUser
+	10, // 12: githubmetrics.v1.RankedUser.metrics:type_name -> githubmetrics.v1.UserMetrics
+	11, // 13: githubmetrics.v1.UserMetrics.repos:type_name -> githubmetrics.v1.UserMetrics.ReposEntry
+	9,  // 14: githubmetrics.v1.UserMetrics.pull_sizes:type_name -> githubmetrics.v1.PullSizeCount
+	1,  // 15: githubmetrics.v1.MetricsService.ListRuns:input_type -> githubmetrics.v1.ListRunsRequest
+	3,  // 16: githubmetrics.v1.MetricsService.GetUserMetrics:input_type -> githubmetrics.v1.GetUserMetricsRequest
+	6,  // 17: githubmetrics.v1.MetricsService.GetLeaderboard:input_type -> githubmetrics.v1.GetLeaderboardRequest
+	2,  // 18: githubmetrics.v1.MetricsService.ListRuns:output_type -> githubmetrics.v1.ListRunsResponse
+	4,  // 19: githubmetrics.v1.MetricsService.GetUserMetrics:output_type -> githubmetrics.v1.GetUserMetricsResponse
+	7,  // 20: githubmetrics.v1.MetricsService.GetLeaderboard:output_type -> githubmetrics.v1.GetLeaderboardResponse
+	18, // [18:21] is the sub-list for method output_type
+	15, // [15:18] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_metrics_proto_init() }
+func file_metrics_proto_init() {
+	if File_metrics_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_metrics_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Run); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListRunsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListRunsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetUserMetricsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetUserMetricsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UserSnapshot); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetLeaderboardRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetLeaderboardResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RankedUser); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PullSizeCount); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_metrics_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UserMetrics); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_metrics_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_metrics_proto_goTypes,
+		DependencyIndexes: file_metrics_proto_depIdxs,
+		MessageInfos:      file_metrics_proto_msgTypes,
+	}.Build()
+	File_metrics_proto = out.File
+	file_metrics_proto_rawDesc = nil
+	file_metrics_proto_goTypes = nil
+	file_metrics_proto_depIdxs = nil
+}